@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// AccountService handles communication with Crackle API relating to the
+// client's user's email, notifications, two-factor authentication, signing
+// key and subscriptions
+type AccountService service
+
+// SetEmail emails a verification code to a given address, it becomes the
+// user's once VerifyEmail is sent the code
+func (s *AccountService) SetEmail(ctx context.Context, email string) (*http.Response, error) {
+	return s.client.SetEmail(ctx, &models.EmailRequest{Email: email})
+}
+
+// VerifyEmail verifies the address SetEmail sent a given code to
+func (s *AccountService) VerifyEmail(ctx context.Context, code string) (*http.Response, error) {
+	return s.client.VerifyEmail(ctx, &models.EmailVerification{Code: code})
+}
+
+// SetNotifications chooses the kinds of email the user is sent, none turns them all off
+func (s *AccountService) SetNotifications(ctx context.Context, notifications []string) (*http.Response, error) {
+	if notifications == nil {
+		notifications = []string{}
+	}
+	return s.client.SetNotifications(ctx, &models.NotificationSettings{Notifications: notifications})
+}
+
+// StartTwoFactor creates a TOTP secret to add to an authenticator app, two-factor
+// authentication is only on once EnableTwoFactor is sent a code of it
+func (s *AccountService) StartTwoFactor(ctx context.Context) (*models.TwoFactorSetup, *http.Response, error) {
+	return s.client.StartTwoFactor(ctx)
+}
+
+// EnableTwoFactor turns two-factor authentication on with a code of the secret
+// StartTwoFactor created, with publish publishing and yanking need a fresh
+// code too. Once it's on it changes publish.
+func (s *AccountService) EnableTwoFactor(ctx context.Context, code string, publish bool) (*http.Response, error) {
+	return s.client.EnableTwoFactor(ctx, &models.TwoFactorRequest{Code: code, Publish: publish})
+}
+
+// DisableTwoFactor turns two-factor authentication off, Client.OTP has to hold
+// a fresh code
+func (s *AccountService) DisableTwoFactor(ctx context.Context) (*http.Response, error) {
+	return s.client.DisableTwoFactor(ctx)
+}
+
+// SetSigningKey registers the base64 ed25519 public key the user signs packages with
+func (s *AccountService) SetSigningKey(ctx context.Context, publicKey string) (*http.Response, error) {
+	return s.client.SetSigningKey(ctx, &models.SigningKey{PublicKey: publicKey})
+}
+
+// RemoveSigningKey forgets the user's signing key
+func (s *AccountService) RemoveSigningKey(ctx context.Context) (*http.Response, error) {
+	return s.client.RemoveSigningKey(ctx)
+}
+
+// Subscribe emails the user whenever a version of a given Package name is published
+func (s *AccountService) Subscribe(ctx context.Context, p string) (*http.Response, error) {
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.SubscribeInNamespace(ctx, namespace, name)
+	}
+	return s.client.Subscribe(ctx, p)
+}
+
+// Unsubscribe stops Subscribe's emails about a given Package name
+func (s *AccountService) Unsubscribe(ctx context.Context, p string) (*http.Response, error) {
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.UnsubscribeInNamespace(ctx, namespace, name)
+	}
+	return s.client.Unsubscribe(ctx, p)
+}
+
+// ListSubscriptions fetchs every Package the user is subscribed to, alphabetically
+func (s *AccountService) ListSubscriptions(ctx context.Context) ([]models.Subscription, *http.Response, error) {
+	subscriptions := []models.Subscription{}
+	next := ""
+	for {
+		page, resp, err := s.client.ReadSubscriptions(ctx, &ReadSubscriptionsParams{Limit: maxPageSize, Next: next})
+		if err != nil {
+			return nil, resp, err
+		}
+		subscriptions = append(subscriptions, page.Subscription...)
+		if next = page.Next; !morePages(next, 0, len(subscriptions)) {
+			return subscriptions, resp, nil
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// AdminService handles communication with Crackle API relating to moderating
+// the registry, only its admins can
+type AdminService service
+
+// SuspendUser keeps a given username from using the registry until
+// UnsuspendUser, a reason is required
+func (s *AdminService) SuspendUser(ctx context.Context, username string, reason string) (*http.Response, error) {
+	return s.client.SuspendUser(ctx, username, &models.Moderation{Reason: reason})
+}
+
+// UnsuspendUser lifts the suspension of a given username
+func (s *AdminService) UnsuspendUser(ctx context.Context, username string) (*http.Response, error) {
+	return s.client.UnsuspendUser(ctx, username)
+}
+
+// RemovePackage deletes every version of a given Package name, a reason is
+// required. With reserve its name can't be published again until unreserved.
+func (s *AdminService) RemovePackage(ctx context.Context, p string, reason string, reserve bool) (*http.Response, error) {
+	params := &RemovePackageParams{Reason: reason}
+	if reserve {
+		params.Reserve = "true"
+	}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.RemovePackageInNamespace(ctx, namespace, name, params)
+	}
+	return s.client.RemovePackage(ctx, p, params)
+}
+
+// ReserveName keeps anyone from publishing a given Package name, reason may be empty
+func (s *AdminService) ReserveName(ctx context.Context, p string, reason string) (*http.Response, error) {
+	moderation := &models.Moderation{Reason: reason}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.ReserveNameInNamespace(ctx, namespace, name, moderation)
+	}
+	return s.client.ReserveName(ctx, p, moderation)
+}
+
+// UnreserveName lets a given Package name be published again
+func (s *AdminService) UnreserveName(ctx context.Context, p string) (*http.Response, error) {
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.UnreserveNameInNamespace(ctx, namespace, name)
+	}
+	return s.client.UnreserveName(ctx, p)
+}
+
+// ListReservedNames fetchs every reserved Package name, alphabetically
+func (s *AdminService) ListReservedNames(ctx context.Context) ([]models.ReservedName, *http.Response, error) {
+	reserved := []models.ReservedName{}
+	next := ""
+	for {
+		page, resp, err := s.client.ReadReservedNames(ctx, &ReadReservedNamesParams{Limit: maxPageSize, Next: next})
+		if err != nil {
+			return nil, resp, err
+		}
+		reserved = append(reserved, page.Reserved...)
+		if next = page.Next; !morePages(next, 0, len(reserved)) {
+			return reserved, resp, nil
+		}
+	}
+}
+
+// ListReports fetchs every report of a package with a given status, oldest
+// first, every report when status is empty
+func (s *AdminService) ListReports(ctx context.Context, status string) ([]models.Report, *http.Response, error) {
+	params := &ReadReportsParams{Status: status, Limit: maxPageSize}
+	reports := []models.Report{}
+	for {
+		page, resp, err := s.client.ReadReports(ctx, params)
+		if err != nil {
+			return nil, resp, err
+		}
+		reports = append(reports, page.Report...)
+		if params.Next = page.Next; !morePages(params.Next, 0, len(reports)) {
+			return reports, resp, nil
+		}
+	}
+}
+
+// ResolveReport sets the status of a given report id, dismissed or open again,
+// reason may be empty
+func (s *AdminService) ResolveReport(ctx context.Context, id int, status string, reason string) (*http.Response, error) {
+	return s.client.ResolveReport(ctx, id, &models.ReportResolution{Status: status, Reason: reason})
+}
+
+// SetCategory adds a category to the registry's taxonomy, or changes its title
+// and description, which may be empty
+func (s *AdminService) SetCategory(ctx context.Context, slug string, title string, description string) (*http.Response, error) {
+	category := &models.Category{Title: title}
+	if description != "" {
+		category.Description = &description
+	}
+	return s.client.SetCategory(ctx, slug, category)
+}
+
+// RemoveCategory removes a category from the registry's taxonomy
+func (s *AdminService) RemoveCategory(ctx context.Context, slug string) (*http.Response, error) {
+	return s.client.DeleteCategory(ctx, slug)
+}
+
+// SetCollection adds a collection of packages, or replaces its title,
+// description, which may be empty, and packages
+func (s *AdminService) SetCollection(ctx context.Context, slug string, title string, description string, packages []string) (*http.Response, error) {
+	collection := &models.Collection{Title: title, Packages: packages}
+	if description != "" {
+		collection.Description = &description
+	}
+	return s.client.SetCollection(ctx, slug, collection)
+}
+
+// RemoveCollection removes a collection, the packages it lists stay
+func (s *AdminService) RemoveCollection(ctx context.Context, slug string) (*http.Response, error) {
+	return s.client.DeleteCollection(ctx, slug)
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// AuditService handles communication with Crackle API relating to the audit log
+type AuditService service
+
+// AuditOptions filters the registry's audit log, a Limit of 0 fetchs every entry
+type AuditOptions struct {
+	Package string
+	Actor   string
+	Action  string
+	Limit   int
+}
+
+// ListPackage fetchs the audit log of a given Package name, newest first, only
+// its owners can. opts.Package is ignored.
+func (s *AuditService) ListPackage(ctx context.Context, p string, opts *AuditOptions) ([]models.AuditEntry, *http.Response, error) {
+	params := &ReadPackageAuditParams{}
+	limit := 0
+	if opts != nil {
+		params.Actor = opts.Actor
+		params.Action = opts.Action
+		limit = opts.Limit
+	}
+	namespace, name := splitName(p)
+	return s.list(limit, func(size int, next string) (*models.AuditLog, *http.Response, error) {
+		params.Limit, params.Next = size, next
+		if namespace != "" {
+			return s.client.ReadPackageAuditInNamespace(ctx, namespace, name, params)
+		}
+		return s.client.ReadPackageAudit(ctx, name, params)
+	})
+}
+
+// List fetchs the audit log of the whole registry, newest first, only its
+// admins can
+func (s *AuditService) List(ctx context.Context, opts *AuditOptions) ([]models.AuditEntry, *http.Response, error) {
+	params := &ReadAuditLogParams{}
+	limit := 0
+	if opts != nil {
+		params.Package = opts.Package
+		params.Actor = opts.Actor
+		params.Action = opts.Action
+		limit = opts.Limit
+	}
+	return s.list(limit, func(size int, next string) (*models.AuditLog, *http.Response, error) {
+		params.Limit, params.Next = size, next
+		return s.client.ReadAuditLog(ctx, params)
+	})
+}
+
+// list follows the pages read by readPage, size entries after next, until
+// limit entries are read
+func (s *AuditService) list(limit int, readPage func(size int, next string) (*models.AuditLog, *http.Response, error)) ([]models.AuditEntry, *http.Response, error) {
+	entries := []models.AuditEntry{}
+	next := ""
+	for {
+		page, resp, err := readPage(pageSize(limit, len(entries)), next)
+		if err != nil {
+			return nil, resp, err
+		}
+		entries = append(entries, page.Entry...)
+		if next = page.Next; !morePages(next, limit, len(entries)) {
+			return entries, resp, nil
+		}
+	}
+}
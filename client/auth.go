@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// AuthService handles logging in and out of the Crackle API
+type AuthService service
+
+// Login exchanges a username and password for an API token
+func (s *AuthService) Login(ctx context.Context, username string, password string) (*models.Token, *http.Response, error) {
+	return s.client.Login(ctx, &models.Login{Username: username, Password: password})
+}
+
+// StartGithubLogin begins a GitHub login, the user authorizes it in a browser
+// while the client polls GithubLogin with the device's code
+func (s *AuthService) StartGithubLogin(ctx context.Context) (*models.GithubDevice, *http.Response, error) {
+	return s.client.StartGithubLogin(ctx)
+}
+
+// GithubLogin exchanges an authorized GitHub login for an API token. Until the
+// user authorizes it the response is a 202 and the returned GithubDevice holds
+// the interval to poll at.
+func (s *AuthService) GithubLogin(ctx context.Context, deviceCode string) (*models.Token, *models.GithubDevice, *http.Response, error) {
+	body, resp, err := s.client.GithubLogin(ctx, &models.GithubDevice{DeviceCode: deviceCode})
+	if err != nil {
+		return nil, nil, resp, err
+	}
+	if resp.StatusCode == http.StatusAccepted {
+		device := new(models.GithubDevice)
+		return nil, device, resp, json.Unmarshal(body, device)
+	}
+
+	token := new(models.Token)
+	return token, nil, resp, json.Unmarshal(body, token)
+}
+
+// WhoAmI fetchs the Identity the client's API token authenticates as
+func (s *AuthService) WhoAmI(ctx context.Context) (*models.Identity, *http.Response, error) {
+	return s.client.WhoAmI(ctx)
+}
+
+// Logout revokes the client's API token
+func (s *AuthService) Logout(ctx context.Context) (*http.Response, error) {
+	return s.client.Logout(ctx)
+}
+
+// ListTokens fetchs the API tokens of the client's user, newest first, without
+// the tokens themselves
+func (s *AuthService) ListTokens(ctx context.Context) ([]models.Token, *http.Response, error) {
+	tokens, resp, err := s.client.ReadTokens(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return tokens.Token, resp, nil
+}
+
+// CreateToken issues the client's user a new API token with scopes, the
+// returned Token is the only time it's known
+func (s *AuthService) CreateToken(ctx context.Context, name string, scopes []string) (*models.Token, *http.Response, error) {
+	return s.client.CreateToken(ctx, &models.TokenRequest{Name: name, Scopes: scopes})
+}
+
+// RevokeToken revokes one of the client's user's API tokens by its id
+func (s *AuthService) RevokeToken(ctx context.Context, id int) (*http.Response, error) {
+	return s.client.RevokeToken(ctx, id)
+}
@@ -1,4 +1,4 @@
-package crackle
+package client
 
 import (
 	"bytes"
@@ -9,8 +9,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -18,6 +20,19 @@ const (
 	userAgent      = "go-crackle/" + libraryVersion
 	baseURL        = "http://crackle.pm/api/"
 	accept         = "application/json"
+	// pullClientHeader carries PullClient, matching helpers.PullClientHeader
+	pullClientHeader = "X-Crackle-Client"
+	// otpHeader carries OTP, matching helpers.OTPHeader
+	otpHeader = "X-Crackle-OTP"
+
+	// defaultMaxRetries is how many times a rate limited request is retried
+	defaultMaxRetries = 3
+	// retryBackoff is the first wait between retries without a Retry-After, it
+	// doubles after each
+	retryBackoff = time.Second
+	// maxRetryWait is the longest Retry-After waited for, a request told to
+	// wait longer fails instead
+	maxRetryWait = time.Minute
 )
 
 // A Client manages communication with the GitHub API.
@@ -31,9 +46,34 @@ type Client struct {
 	// BaseURL used for Crackle API
 	BaseURL *url.URL
 
+	// Token sent as a bearer token to authenticate requests, from logging in
+	Token string
+
+	// OTP is a TOTP code sent with every request, for logging in and, when
+	// the user requires it, publishing and yanking with two-factor
+	// authentication on. A code can only be used once.
+	OTP string
+
+	// PullClient identifies this machine when recording pulls, so it's only
+	// counted once a day
+	PullClient string
+
+	// MaxRetries is how many times a request refused with a 429 is sent again,
+	// after its Retry-After or a growing backoff
+	MaxRetries int
+
+	// Mirrors are registries read from, in order, when BaseURL is unreachable.
+	// Each needs a trailing slash, only GET requests fall back to them.
+	Mirrors []*url.URL
+
 	common service // Reuse a single struct instead of allocating one for each service on the heap.
 
 	// Services used for talking to different parts of the Crackle API.
+	Account *AccountService
+	Admin   *AdminService
+	Audit   *AuditService
+	Auth    *AuthService
+	Org     *OrgService
 	Package *PackageService
 	Version *VersionService
 }
@@ -66,8 +106,13 @@ func NewClient(httpClient *http.Client) *Client {
 	}
 	baseURL, _ := url.Parse(baseURL)
 
-	c := &Client{client: httpClient, UserAgent: userAgent, BaseURL: baseURL}
+	c := &Client{client: httpClient, UserAgent: userAgent, BaseURL: baseURL, MaxRetries: defaultMaxRetries}
 	c.common.client = c
+	c.Account = (*AccountService)(&c.common)
+	c.Admin = (*AdminService)(&c.common)
+	c.Audit = (*AuditService)(&c.common)
+	c.Auth = (*AuthService)(&c.common)
+	c.Org = (*OrgService)(&c.common)
 	c.Package = (*PackageService)(&c.common)
 	c.Version = (*VersionService)(&c.common)
 	return c
@@ -80,11 +125,41 @@ func NewClient(httpClient *http.Client) *Client {
 // first decode it.
 //
 // The provided ctx must be non-nil. If it is canceled or times out,
-// ctx.Err() will be returned.
+// ctx.Err() will be returned. Rate limited requests are retried up to
+// MaxRetries times, and GET requests go to Mirrors when BaseURL is unreachable.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
 	req = withContext(ctx, req)
 
 	resp, err := c.client.Do(req)
+	for attempt := 0; err == nil && resp.StatusCode == http.StatusTooManyRequests && attempt < c.MaxRetries; attempt++ {
+		wait, ok := retryWait(resp, attempt)
+		if !ok || (req.Body != nil && req.GetBody == nil) {
+			break
+		}
+		io.CopyN(ioutil.Discard, resp.Body, 512)
+		resp.Body.Close()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = c.client.Do(req)
+	}
+	if unreachable(resp, err) && ctx.Err() == nil {
+		if mirrorResp, ok := c.doMirrors(req); ok {
+			if resp != nil {
+				io.CopyN(ioutil.Discard, resp.Body, 512)
+				resp.Body.Close()
+			}
+			resp, err = mirrorResp, nil
+		}
+	}
 	if err != nil {
 		// If we got an error, and the context has been canceled,
 		// the context's error is probably more useful.
@@ -164,9 +239,21 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.OTP != "" {
+		req.Header.Set(otpHeader, c.OTP)
+	}
 	return req, nil
 }
 
+// OTPRequired reports whether a response refused a request for not sending a
+// fresh TOTP code in Client.OTP
+func OTPRequired(resp *http.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusUnauthorized && resp.Header.Get(otpHeader) == "required"
+}
+
 // CheckResponse checks the API response for errors, and returns them if
 // present. A response is considered an error if it has a status code outside
 // the 200 range.
@@ -189,6 +276,70 @@ func CheckResponse(r *http.Response) error {
 	return errorResponse
 }
 
+// unreachable returns true when a request couldn't reach the registry, or its
+// proxy answered that the registry is down
+func unreachable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// doMirrors sends a GET request to each of the Mirrors in turn, returning the
+// first response from one that's reachable. The token isn't sent, it's only
+// good on BaseURL.
+func (c *Client) doMirrors(req *http.Request) (*http.Response, bool) {
+	if req.Method != "GET" || !strings.HasPrefix(req.URL.String(), c.BaseURL.String()) {
+		return nil, false
+	}
+	path := strings.TrimPrefix(req.URL.String(), c.BaseURL.String())
+	for _, mirror := range c.Mirrors {
+		u, err := mirror.Parse(path)
+		if err != nil {
+			continue
+		}
+		mirrorReq, err := http.NewRequest(req.Method, u.String(), nil)
+		if err != nil {
+			continue
+		}
+		for k, v := range req.Header {
+			mirrorReq.Header[k] = v
+		}
+		mirrorReq.Header.Del("Authorization")
+		mirrorReq.Header.Del(otpHeader)
+		resp, err := c.client.Do(mirrorReq.WithContext(req.Context()))
+		if !unreachable(resp, err) {
+			return resp, true
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+	return nil, false
+}
+
+// retryWait returns how long to wait before retrying a rate limited response,
+// its Retry-After or else a backoff doubling with each attempt. ok is false when
+// it asks for longer than maxRetryWait.
+func retryWait(resp *http.Response, attempt int) (wait time.Duration, ok bool) {
+	wait = retryBackoff << uint(attempt)
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if seconds, err := strconv.Atoi(after); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		} else if t, err := http.ParseTime(after); err == nil {
+			wait = time.Until(t)
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, wait <= maxRetryWait
+}
+
 func withContext(ctx context.Context, req *http.Request) *http.Request {
 	return req.WithContext(ctx)
 }
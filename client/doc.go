@@ -0,0 +1,14 @@
+// Package client is the Go client of the Crackle registry API, which cr talks
+// to the registry through. A method of Client calls each operation of the
+// registry's OpenAPI spec, generated from server/openapi.json into
+// operations.go, and the services of a Client wrap them to follow pages and
+// take namespaced Package names:
+//
+//	c := client.NewClient(nil)
+//	c.BaseURL, _ = url.Parse("https://crackle.example.com/api/")
+//	p, _, err := c.Package.GetPackageVersion(ctx, "testing", "1.2.0")
+//
+// Run go generate after changing the spec.
+package client
+
+//go:generate go run ./internal/gen ../server/openapi.json operations.go
@@ -0,0 +1,414 @@
+// Command gen writes the methods of client.Client calling each operation of
+// the registry's OpenAPI spec, run it with go generate in client:
+//
+//	gen ../server/openapi.json operations.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// methods are the HTTP methods an operation can have, in the order their
+// operations are written
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+// clientHeaders are header parameters filled in from a field of the Client
+// instead of a parameter of the operation's method
+var clientHeaders = map[string]string{
+	"X-Crackle-Client": "PullClient",
+}
+
+// sentHeaders are header parameters NewRequest already sends with every request
+var sentHeaders = map[string]bool{
+	"X-Crackle-OTP": true,
+}
+
+// namespaceSuffix ends the operationId of the namespaced twin of an operation,
+// they share the type of their parameters
+const namespaceSuffix = "InNamespace"
+
+// commentWidth is how wide doc comments are wrapped
+const commentWidth = 80
+
+type spec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Parameters map[string]parameter `json:"parameters"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string          `json:"operationId"`
+	Summary     string          `json:"summary"`
+	Description string          `json:"description"`
+	Parameters  []parameter     `json:"parameters"`
+	RequestBody *body           `json:"requestBody"`
+	Responses   map[string]body `json:"responses"`
+	path        string
+	method      string
+}
+
+type parameter struct {
+	Ref    string  `json:"$ref"`
+	Name   string  `json:"name"`
+	In     string  `json:"in"`
+	Schema *schema `json:"schema"`
+}
+
+type body struct {
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+type schema struct {
+	Ref  string `json:"$ref"`
+	Type string `json:"type"`
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		log.Fatal("usage: gen <openapi.json> <output.go>")
+	}
+	b, err := ioutil.ReadFile(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	var s spec
+	if err = json.Unmarshal(b, &s); err != nil {
+		log.Fatalf("%s: %s", os.Args[1], err)
+	}
+	ops, err := operations(&s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(ops)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile(os.Args[2], src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// operations returns every operation of the spec sorted by path then method,
+// with their parameters' references resolved
+func operations(s *spec) ([]*operation, error) {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ops := []*operation{}
+	for _, path := range paths {
+		for _, method := range methods {
+			raw, ok := s.Paths[path][method]
+			if !ok {
+				continue
+			}
+			op := &operation{path: path, method: strings.ToUpper(method)}
+			if err := json.Unmarshal(raw, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %s", op.method, path, err)
+			}
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("%s %s has no operationId", op.method, path)
+			}
+			for i, p := range op.Parameters {
+				if p.Ref == "" {
+					continue
+				}
+				resolved, ok := s.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+				if !ok {
+					return nil, fmt.Errorf("%s: unknown parameter %s", op.OperationID, p.Ref)
+				}
+				op.Parameters[i] = resolved
+			}
+			ops = append(ops, op)
+		}
+	}
+	return ops, nil
+}
+
+// generate writes the source of the Client methods calling ops, importing
+// the packages they use
+func generate(ops []*operation) ([]byte, error) {
+	var buf bytes.Buffer
+	written := make(map[string]bool)
+	for _, op := range ops {
+		if err := writeOperation(&buf, op, written); err != nil {
+			return nil, fmt.Errorf("%s: %s", op.OperationID, err)
+		}
+	}
+	code := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if !strings.HasPrefix(line, "//") {
+			code = append(code, line)
+		}
+	}
+	var out bytes.Buffer
+	out.WriteString("// Code generated by gen from server/openapi.json. DO NOT EDIT.\n\npackage client\n\nimport (\n")
+	for _, pkg := range []string{"bytes", "context", "encoding/json", "fmt", "net/http", "net/url", "strconv"} {
+		if strings.Contains(strings.Join(code, "\n"), pkg[strings.LastIndex(pkg, "/")+1:]+".") {
+			fmt.Fprintf(&out, "%q\n", pkg)
+		}
+	}
+	out.WriteString("\n\"github.com/sunshinekitty/cr/models\"\n)\n")
+	out.Write(buf.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting: %s\n%s", err, out.String())
+	}
+	return src, nil
+}
+
+// writeOperation writes the method calling op, and the type of its parameters
+// unless its twin's was already written
+func writeOperation(buf *bytes.Buffer, op *operation, written map[string]bool) error {
+	name := exported(op.OperationID)
+
+	var path, query, header []parameter
+	for _, p := range op.Parameters {
+		switch {
+		case p.In == "path":
+			path = append(path, p)
+		case p.In == "query":
+			query = append(query, p)
+		case p.In == "header" && sentHeaders[p.Name]:
+		case p.In == "header" && clientHeaders[p.Name] != "":
+		case p.In == "header":
+			header = append(header, p)
+		default:
+			return fmt.Errorf("unsupported parameter %s in %s", p.Name, p.In)
+		}
+	}
+
+	paramsType := ""
+	if len(query)+len(header) > 0 {
+		paramsType = strings.TrimSuffix(name, namespaceSuffix) + "Params"
+		if !written[paramsType] {
+			written[paramsType] = true
+			buf.WriteString("\n" + comment(fmt.Sprintf("%s holds the optional parameters of %s, zero values aren't sent",
+				paramsType, strings.TrimSuffix(name, namespaceSuffix))))
+			fmt.Fprintf(buf, "type %s struct {\n", paramsType)
+			for _, p := range append(query, header...) {
+				fmt.Fprintf(buf, "%s %s\n", exported(p.Name), goType(p.Schema))
+			}
+			buf.WriteString("}\n")
+		}
+	}
+
+	bodyType, err := requestType(op)
+	if err != nil {
+		return err
+	}
+	resultType, err := responseType(op)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"ctx context.Context"}
+	pattern := strings.TrimPrefix(op.path, "/")
+	values := []string{}
+	for _, p := range path {
+		arg := unexported(p.Name)
+		args = append(args, fmt.Sprintf("%s %s", arg, goType(p.Schema)))
+		pattern = strings.Replace(pattern, "{"+p.Name+"}", "%s", 1)
+		if goType(p.Schema) == "int" {
+			values = append(values, fmt.Sprintf("strconv.Itoa(%s)", arg))
+		} else {
+			values = append(values, fmt.Sprintf("url.PathEscape(%s)", arg))
+		}
+	}
+	if bodyType != "" {
+		args = append(args, "body "+bodyType)
+	}
+	if paramsType != "" {
+		args = append(args, "params *"+paramsType)
+	}
+	results := "(*http.Response, error)"
+	if resultType != "" {
+		results = fmt.Sprintf("(%s, *http.Response, error)", resultType)
+	}
+
+	doc := fmt.Sprintf("%s calls %s %s, %s", name, op.method, op.path, lowerFirst(op.Summary))
+	buf.WriteString("\n" + comment(doc))
+	if op.Description != "" {
+		buf.WriteString("//\n" + comment(op.Description))
+	}
+	fmt.Fprintf(buf, "func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), results)
+
+	fail := "return nil, err"
+	if resultType != "" {
+		fail = "return nil, nil, err"
+	}
+	if len(values) > 0 {
+		fmt.Fprintf(buf, "u := fmt.Sprintf(%q, %s)\n", pattern, strings.Join(values, ", "))
+	} else {
+		fmt.Fprintf(buf, "u := %q\n", pattern)
+	}
+	if len(query) > 0 {
+		buf.WriteString("q := url.Values{}\nif params != nil {\n")
+		for _, p := range query {
+			field := exported(p.Name)
+			if goType(p.Schema) == "int" {
+				fmt.Fprintf(buf, "if params.%s != 0 {\nq.Set(%q, strconv.Itoa(params.%s))\n}\n", field, p.Name, field)
+			} else {
+				fmt.Fprintf(buf, "if params.%s != \"\" {\nq.Set(%q, params.%s)\n}\n", field, p.Name, field)
+			}
+		}
+		buf.WriteString("}\nif len(q) > 0 {\nu += \"?\" + q.Encode()\n}\n")
+	}
+	reqBody := "nil"
+	if bodyType != "" {
+		reqBody = "body"
+	}
+	fmt.Fprintf(buf, "req, err := c.NewRequest(%q, u, %s)\nif err != nil {\n%s\n}\n", op.method, reqBody, fail)
+	for _, p := range op.Parameters {
+		if field := clientHeaders[p.Name]; p.In == "header" && field != "" {
+			fmt.Fprintf(buf, "if c.%s != \"\" {\nreq.Header.Set(%q, c.%s)\n}\n", field, p.Name, field)
+		}
+	}
+	if len(header) > 0 {
+		buf.WriteString("if params != nil {\n")
+		for _, p := range header {
+			field := exported(p.Name)
+			fmt.Fprintf(buf, "if params.%s != \"\" {\nreq.Header.Set(%q, params.%s)\n}\n", field, p.Name, field)
+		}
+		buf.WriteString("}\n")
+	}
+
+	switch {
+	case resultType == "":
+		buf.WriteString("return c.Do(ctx, req, nil)\n")
+	case resultType == "[]byte":
+		buf.WriteString("var v bytes.Buffer\nresp, err := c.Do(ctx, req, &v)\nif err != nil {\nreturn nil, resp, err\n}\nreturn v.Bytes(), resp, nil\n")
+	case resultType == "json.RawMessage":
+		buf.WriteString("var v json.RawMessage\nresp, err := c.Do(ctx, req, &v)\nif err != nil {\nreturn nil, resp, err\n}\nreturn v, resp, nil\n")
+	default:
+		fmt.Fprintf(buf, "v := new(%s)\nresp, err := c.Do(ctx, req, v)\nif err != nil {\nreturn nil, resp, err\n}\nreturn v, resp, nil\n",
+			strings.TrimPrefix(resultType, "*"))
+	}
+	buf.WriteString("}\n")
+	return nil
+}
+
+// requestType returns the Go type of op's JSON request body, empty without one
+func requestType(op *operation) (string, error) {
+	if op.RequestBody == nil {
+		return "", nil
+	}
+	content, ok := op.RequestBody.Content["application/json"]
+	if !ok || content.Schema == nil {
+		return "", fmt.Errorf("request body isn't json")
+	}
+	if content.Schema.Ref == "" {
+		return "interface{}", nil
+	}
+	return "*" + modelType(content.Schema.Ref), nil
+}
+
+// responseType returns the Go type of op's successful responses' bodies, raw
+// JSON when they differ by status and empty when they have none
+func responseType(op *operation) (string, error) {
+	types := map[string]bool{}
+	for status, response := range op.Responses {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		for contentType, content := range response.Content {
+			switch {
+			case contentType == "application/json" && content.Schema != nil && content.Schema.Ref != "":
+				types["*"+modelType(content.Schema.Ref)] = true
+			case contentType == "application/json":
+				types["json.RawMessage"] = true
+			default:
+				types["[]byte"] = true
+			}
+		}
+	}
+	switch len(types) {
+	case 0:
+		return "", nil
+	case 1:
+		for t := range types {
+			return t, nil
+		}
+	}
+	if types["[]byte"] {
+		return "", fmt.Errorf("responses mix json and other content")
+	}
+	return "json.RawMessage", nil
+}
+
+// modelType returns the models type of a schema reference
+func modelType(ref string) string {
+	return "models." + strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+// goType returns the Go type of a parameter's schema
+func goType(s *schema) string {
+	if s != nil && s.Type == "integer" {
+		return "int"
+	}
+	return "string"
+}
+
+// exported returns a name such as X-GitHub-Event or next as a Go identifier
+// starting with an upper case letter, XGitHubEvent or Next
+func exported(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// unexported returns a parameter name as a Go identifier starting with a lower
+// case letter
+func unexported(name string) string {
+	return lowerFirst(exported(name))
+}
+
+// comment returns text as lines of a comment no wider than commentWidth
+func comment(text string) string {
+	var b strings.Builder
+	line := "//"
+	for _, word := range strings.Fields(text) {
+		if len(line) > len("//") && len(line)+1+len(word) > commentWidth {
+			b.WriteString(line + "\n")
+			line = "//"
+		}
+		line += " " + word
+	}
+	b.WriteString(line + "\n")
+	return b.String()
+}
+
+// lowerFirst lower cases the first letter of s
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,1940 @@
+// Code generated by gen from server/openapi.json. DO NOT EDIT.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// StartTwoFactor calls POST /account/2fa, start turning on two-factor
+// authentication
+func (c *Client) StartTwoFactor(ctx context.Context) (*models.TwoFactorSetup, *http.Response, error) {
+	u := "account/2fa"
+	req, err := c.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.TwoFactorSetup)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// EnableTwoFactor calls PUT /account/2fa, turn on two-factor authentication
+func (c *Client) EnableTwoFactor(ctx context.Context, body *models.TwoFactorRequest) (*http.Response, error) {
+	u := "account/2fa"
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// DisableTwoFactor calls DELETE /account/2fa, turn off two-factor
+// authentication
+func (c *Client) DisableTwoFactor(ctx context.Context) (*http.Response, error) {
+	u := "account/2fa"
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// SetEmail calls PUT /account/email, send a verification code to an email
+// address
+func (c *Client) SetEmail(ctx context.Context, body *models.EmailRequest) (*http.Response, error) {
+	u := "account/email"
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// VerifyEmail calls POST /account/email/verify, verify an email address
+func (c *Client) VerifyEmail(ctx context.Context, body *models.EmailVerification) (*http.Response, error) {
+	u := "account/email/verify"
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// SetNotifications calls PUT /account/notifications, choose the email the user
+// is sent
+func (c *Client) SetNotifications(ctx context.Context, body *models.NotificationSettings) (*http.Response, error) {
+	u := "account/notifications"
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// SetSigningKey calls PUT /account/signing-key, register the key packages are
+// signed with
+func (c *Client) SetSigningKey(ctx context.Context, body *models.SigningKey) (*http.Response, error) {
+	u := "account/signing-key"
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// RemoveSigningKey calls DELETE /account/signing-key, remove the user's signing
+// key
+func (c *Client) RemoveSigningKey(ctx context.Context) (*http.Response, error) {
+	u := "account/signing-key"
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// SetCategory calls PUT /admin/categories/{slug}, add a category, or change it
+func (c *Client) SetCategory(ctx context.Context, slug string, body *models.Category) (*http.Response, error) {
+	u := fmt.Sprintf("admin/categories/%s", url.PathEscape(slug))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// DeleteCategory calls DELETE /admin/categories/{slug}, remove a category
+func (c *Client) DeleteCategory(ctx context.Context, slug string) (*http.Response, error) {
+	u := fmt.Sprintf("admin/categories/%s", url.PathEscape(slug))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// SetCollection calls PUT /admin/collections/{slug}, add a collection, or
+// change it
+func (c *Client) SetCollection(ctx context.Context, slug string, body *models.Collection) (*http.Response, error) {
+	u := fmt.Sprintf("admin/collections/%s", url.PathEscape(slug))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// DeleteCollection calls DELETE /admin/collections/{slug}, remove a collection
+func (c *Client) DeleteCollection(ctx context.Context, slug string) (*http.Response, error) {
+	u := fmt.Sprintf("admin/collections/%s", url.PathEscape(slug))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// RemovePackageParams holds the optional parameters of RemovePackage, zero
+// values aren't sent
+type RemovePackageParams struct {
+	Reason  string
+	Reserve string
+}
+
+// RemovePackageInNamespace calls DELETE /admin/ns/{namespace}/package/{name},
+// remove a package, such as a malicious one
+func (c *Client) RemovePackageInNamespace(ctx context.Context, namespace string, name string, params *RemovePackageParams) (*http.Response, error) {
+	u := fmt.Sprintf("admin/ns/%s/package/%s", url.PathEscape(namespace), url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Reason != "" {
+			q.Set("reason", params.Reason)
+		}
+		if params.Reserve != "" {
+			q.Set("reserve", params.Reserve)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReserveNameInNamespace calls PUT
+// /admin/ns/{namespace}/package/{name}/reservation, reserve a package name
+func (c *Client) ReserveNameInNamespace(ctx context.Context, namespace string, name string, body *models.Moderation) (*http.Response, error) {
+	u := fmt.Sprintf("admin/ns/%s/package/%s/reservation", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// UnreserveNameInNamespace calls DELETE
+// /admin/ns/{namespace}/package/{name}/reservation, release a reserved package
+// name
+func (c *Client) UnreserveNameInNamespace(ctx context.Context, namespace string, name string) (*http.Response, error) {
+	u := fmt.Sprintf("admin/ns/%s/package/%s/reservation", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// RemovePackage calls DELETE /admin/package/{name}, remove a package, such as a
+// malicious one
+func (c *Client) RemovePackage(ctx context.Context, name string, params *RemovePackageParams) (*http.Response, error) {
+	u := fmt.Sprintf("admin/package/%s", url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Reason != "" {
+			q.Set("reason", params.Reason)
+		}
+		if params.Reserve != "" {
+			q.Set("reserve", params.Reserve)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReserveName calls PUT /admin/package/{name}/reservation, reserve a package
+// name
+func (c *Client) ReserveName(ctx context.Context, name string, body *models.Moderation) (*http.Response, error) {
+	u := fmt.Sprintf("admin/package/%s/reservation", url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// UnreserveName calls DELETE /admin/package/{name}/reservation, release a
+// reserved package name
+func (c *Client) UnreserveName(ctx context.Context, name string) (*http.Response, error) {
+	u := fmt.Sprintf("admin/package/%s/reservation", url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadReportsParams holds the optional parameters of ReadReports, zero values
+// aren't sent
+type ReadReportsParams struct {
+	Status string
+	Limit  int
+	Next   string
+}
+
+// ReadReports calls GET /admin/reports, list reported packages, oldest first
+func (c *Client) ReadReports(ctx context.Context, params *ReadReportsParams) (*models.Reports, *http.Response, error) {
+	u := "admin/reports"
+	q := url.Values{}
+	if params != nil {
+		if params.Status != "" {
+			q.Set("status", params.Status)
+		}
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Reports)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ResolveReport calls PUT /admin/reports/{id}, dismiss a report, or open it
+// again
+func (c *Client) ResolveReport(ctx context.Context, id int, body *models.ReportResolution) (*http.Response, error) {
+	u := fmt.Sprintf("admin/reports/%s", strconv.Itoa(id))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadReservedNamesParams holds the optional parameters of ReadReservedNames,
+// zero values aren't sent
+type ReadReservedNamesParams struct {
+	Limit int
+	Next  string
+}
+
+// ReadReservedNames calls GET /admin/reserved, list reserved package names
+func (c *Client) ReadReservedNames(ctx context.Context, params *ReadReservedNamesParams) (*models.ReservedNames, *http.Response, error) {
+	u := "admin/reserved"
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.ReservedNames)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// SuspendUser calls PUT /admin/users/{username}/suspension, suspend a user
+func (c *Client) SuspendUser(ctx context.Context, username string, body *models.Moderation) (*http.Response, error) {
+	u := fmt.Sprintf("admin/users/%s/suspension", url.PathEscape(username))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// UnsuspendUser calls DELETE /admin/users/{username}/suspension, lift a user's
+// suspension
+func (c *Client) UnsuspendUser(ctx context.Context, username string) (*http.Response, error) {
+	u := fmt.Sprintf("admin/users/%s/suspension", url.PathEscape(username))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadAuditLogParams holds the optional parameters of ReadAuditLog, zero values
+// aren't sent
+type ReadAuditLogParams struct {
+	Package string
+	Actor   string
+	Action  string
+	Limit   int
+	Next    string
+}
+
+// ReadAuditLog calls GET /audit, read the registry's audit log
+func (c *Client) ReadAuditLog(ctx context.Context, params *ReadAuditLogParams) (*models.AuditLog, *http.Response, error) {
+	u := "audit"
+	q := url.Values{}
+	if params != nil {
+		if params.Package != "" {
+			q.Set("package", params.Package)
+		}
+		if params.Actor != "" {
+			q.Set("actor", params.Actor)
+		}
+		if params.Action != "" {
+			q.Set("action", params.Action)
+		}
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.AuditLog)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadPullsBadge calls GET /badge/{name}/pulls.svg, badge of how often a public
+// package was pulled, for READMEs
+func (c *Client) ReadPullsBadge(ctx context.Context, name string) ([]byte, *http.Response, error) {
+	u := fmt.Sprintf("badge/%s/pulls.svg", url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var v bytes.Buffer
+	resp, err := c.Do(ctx, req, &v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v.Bytes(), resp, nil
+}
+
+// ReadVersionBadge calls GET /badge/{name}/version.svg, badge of a public
+// package's latest version, for READMEs
+func (c *Client) ReadVersionBadge(ctx context.Context, name string) ([]byte, *http.Response, error) {
+	u := fmt.Sprintf("badge/%s/version.svg", url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var v bytes.Buffer
+	resp, err := c.Do(ctx, req, &v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v.Bytes(), resp, nil
+}
+
+// ReadCategories calls GET /categories, list every category, with how many
+// packages are in each
+func (c *Client) ReadCategories(ctx context.Context) (*models.Categories, *http.Response, error) {
+	u := "categories"
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Categories)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadCollections calls GET /collections, list every curated collection
+func (c *Client) ReadCollections(ctx context.Context) (*models.Collections, *http.Response, error) {
+	u := "collections"
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Collections)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadCollection calls GET /collections/{slug}, read a collection with the
+// latest version of each package it lists
+func (c *Client) ReadCollection(ctx context.Context, slug string) (*models.Collection, *http.Response, error) {
+	u := fmt.Sprintf("collections/%s", url.PathEscape(slug))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Collection)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// Login calls POST /login, log in with a password
+//
+// Exchanges a username and password, and a TOTP code when two-factor
+// authentication is on, for an API token.
+func (c *Client) Login(ctx context.Context, body *models.Login) (*models.Token, *http.Response, error) {
+	u := "login"
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Token)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// Logout calls DELETE /login, revoke the token sent
+func (c *Client) Logout(ctx context.Context) (*http.Response, error) {
+	u := "login"
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// StartGithubLogin calls POST /login/github, start logging in with GitHub
+func (c *Client) StartGithubLogin(ctx context.Context) (*models.GithubDevice, *http.Response, error) {
+	u := "login/github"
+	req, err := c.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.GithubDevice)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// GithubLogin calls POST /login/github/token, finish logging in with GitHub
+//
+// Returns a 202 with the interval to poll at until the user authorizes the
+// device.
+func (c *Client) GithubLogin(ctx context.Context, body *models.GithubDevice) (json.RawMessage, *http.Response, error) {
+	u := "login/github/token"
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	var v json.RawMessage
+	resp, err := c.Do(ctx, req, &v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadPullsBadgeInNamespace calls GET /ns/{namespace}/badge/{name}/pulls.svg,
+// badge of how often a public package was pulled, for READMEs
+func (c *Client) ReadPullsBadgeInNamespace(ctx context.Context, namespace string, name string) ([]byte, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/badge/%s/pulls.svg", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var v bytes.Buffer
+	resp, err := c.Do(ctx, req, &v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v.Bytes(), resp, nil
+}
+
+// ReadVersionBadgeInNamespace calls GET
+// /ns/{namespace}/badge/{name}/version.svg, badge of a public package's latest
+// version, for READMEs
+func (c *Client) ReadVersionBadgeInNamespace(ctx context.Context, namespace string, name string) ([]byte, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/badge/%s/version.svg", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var v bytes.Buffer
+	resp, err := c.Do(ctx, req, &v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v.Bytes(), resp, nil
+}
+
+// ReadPackageParams holds the optional parameters of ReadPackage, zero values
+// aren't sent
+type ReadPackageParams struct {
+	Version string
+}
+
+// ReadPackageInNamespace calls GET /ns/{namespace}/package/{name}, read a
+// version of a package, the latest by default
+func (c *Client) ReadPackageInNamespace(ctx context.Context, namespace string, name string, params *ReadPackageParams) (*models.Package, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s", url.PathEscape(namespace), url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Version != "" {
+			q.Set("version", params.Version)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Package)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// DeletePackageInNamespace calls DELETE /ns/{namespace}/package/{name}, delete
+// a package
+func (c *Client) DeletePackageInNamespace(ctx context.Context, namespace string, name string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadPackageAuditParams holds the optional parameters of ReadPackageAudit,
+// zero values aren't sent
+type ReadPackageAuditParams struct {
+	Actor  string
+	Action string
+	Limit  int
+	Next   string
+}
+
+// ReadPackageAuditInNamespace calls GET /ns/{namespace}/package/{name}/audit,
+// read a package's audit log
+func (c *Client) ReadPackageAuditInNamespace(ctx context.Context, namespace string, name string, params *ReadPackageAuditParams) (*models.AuditLog, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/audit", url.PathEscape(namespace), url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Actor != "" {
+			q.Set("actor", params.Actor)
+		}
+		if params.Action != "" {
+			q.Set("action", params.Action)
+		}
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.AuditLog)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadPackageCategoriesInNamespace calls GET
+// /ns/{namespace}/package/{name}/categories, list the categories a package is
+// in
+func (c *Client) ReadPackageCategoriesInNamespace(ctx context.Context, namespace string, name string) (*models.PackageCategories, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/categories", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.PackageCategories)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// SetPackageCategoriesInNamespace calls PUT
+// /ns/{namespace}/package/{name}/categories, put a package in categories,
+// replacing those it was in
+func (c *Client) SetPackageCategoriesInNamespace(ctx context.Context, namespace string, name string, body *models.PackageCategories) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/categories", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// DeprecatePackageInNamespace calls PUT
+// /ns/{namespace}/package/{name}/deprecate, deprecate a package
+func (c *Client) DeprecatePackageInNamespace(ctx context.Context, namespace string, name string, body *models.Deprecation) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/deprecate", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// UndeprecatePackageInNamespace calls DELETE
+// /ns/{namespace}/package/{name}/deprecate, undeprecate a package
+func (c *Client) UndeprecatePackageInNamespace(ctx context.Context, namespace string, name string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/deprecate", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadPackageOwnersParams holds the optional parameters of ReadPackageOwners,
+// zero values aren't sent
+type ReadPackageOwnersParams struct {
+	Limit int
+	Next  string
+}
+
+// ReadPackageOwnersInNamespace calls GET /ns/{namespace}/package/{name}/owners,
+// list a package's owners
+func (c *Client) ReadPackageOwnersInNamespace(ctx context.Context, namespace string, name string, params *ReadPackageOwnersParams) (*models.Owners, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/owners", url.PathEscape(namespace), url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Owners)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// AddPackageOwnerInNamespace calls PUT
+// /ns/{namespace}/package/{name}/owners/{username}, add an owner to a package
+func (c *Client) AddPackageOwnerInNamespace(ctx context.Context, namespace string, name string, username string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/owners/%s", url.PathEscape(namespace), url.PathEscape(name), url.PathEscape(username))
+	req, err := c.NewRequest("PUT", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// RemovePackageOwnerInNamespace calls DELETE
+// /ns/{namespace}/package/{name}/owners/{username}, remove an owner from a
+// package
+func (c *Client) RemovePackageOwnerInNamespace(ctx context.Context, namespace string, name string, username string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/owners/%s", url.PathEscape(namespace), url.PathEscape(name), url.PathEscape(username))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadPackageReadmeParams holds the optional parameters of ReadPackageReadme,
+// zero values aren't sent
+type ReadPackageReadmeParams struct {
+	Version string
+	Color   string
+}
+
+// ReadPackageReadmeInNamespace calls GET /ns/{namespace}/package/{name}/readme,
+// read a package's rendered long description
+func (c *Client) ReadPackageReadmeInNamespace(ctx context.Context, namespace string, name string, params *ReadPackageReadmeParams) (*models.Readme, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/readme", url.PathEscape(namespace), url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Version != "" {
+			q.Set("version", params.Version)
+		}
+		if params.Color != "" {
+			q.Set("color", params.Color)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Readme)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReportPackageInNamespace calls POST /ns/{namespace}/package/{name}/report,
+// report a malicious or abusive package to the registry's admins
+func (c *Client) ReportPackageInNamespace(ctx context.Context, namespace string, name string, body *models.Report) (*models.Report, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/report", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Report)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ResolvePackageVersionParams holds the optional parameters of
+// ResolvePackageVersion, zero values aren't sent
+type ResolvePackageVersionParams struct {
+	Range string
+}
+
+// ResolvePackageVersionInNamespace calls GET
+// /ns/{namespace}/package/{name}/resolve, resolve a version range to the newest
+// version in it
+func (c *Client) ResolvePackageVersionInNamespace(ctx context.Context, namespace string, name string, params *ResolvePackageVersionParams) (*models.PackageVersion, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/resolve", url.PathEscape(namespace), url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Range != "" {
+			q.Set("range", params.Range)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.PackageVersion)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadPackageStatsParams holds the optional parameters of ReadPackageStats,
+// zero values aren't sent
+type ReadPackageStatsParams struct {
+	Period string
+	Range  string
+}
+
+// ReadPackageStatsInNamespace calls GET /ns/{namespace}/package/{name}/stats,
+// read a package's pulls over time
+func (c *Client) ReadPackageStatsInNamespace(ctx context.Context, namespace string, name string, params *ReadPackageStatsParams) (*models.PackageStats, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/stats", url.PathEscape(namespace), url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Period != "" {
+			q.Set("period", params.Period)
+		}
+		if params.Range != "" {
+			q.Set("range", params.Range)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.PackageStats)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// SubscribeInNamespace calls PUT /ns/{namespace}/package/{name}/subscription,
+// subscribe to a package's new versions
+func (c *Client) SubscribeInNamespace(ctx context.Context, namespace string, name string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/subscription", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// UnsubscribeInNamespace calls DELETE
+// /ns/{namespace}/package/{name}/subscription, unsubscribe from a package
+func (c *Client) UnsubscribeInNamespace(ctx context.Context, namespace string, name string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/subscription", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// OfferTransferInNamespace calls PUT /ns/{namespace}/package/{name}/transfer,
+// offer a package to another user
+func (c *Client) OfferTransferInNamespace(ctx context.Context, namespace string, name string, body *models.Transfer) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/transfer", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// CancelTransferInNamespace calls DELETE
+// /ns/{namespace}/package/{name}/transfer, cancel a package's transfer
+func (c *Client) CancelTransferInNamespace(ctx context.Context, namespace string, name string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/transfer", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// AcceptTransferInNamespace calls POST
+// /ns/{namespace}/package/{name}/transfer/accept, accept a package offered to
+// the user
+func (c *Client) AcceptTransferInNamespace(ctx context.Context, namespace string, name string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/transfer/accept", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadTriggersInNamespace calls GET /ns/{namespace}/package/{name}/triggers,
+// list a package's triggers
+func (c *Client) ReadTriggersInNamespace(ctx context.Context, namespace string, name string) (*models.Triggers, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/triggers", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Triggers)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// CreateTriggerInNamespace calls POST /ns/{namespace}/package/{name}/triggers,
+// create a trigger publishing a package when a tag of its image is pushed
+func (c *Client) CreateTriggerInNamespace(ctx context.Context, namespace string, name string, body *models.Trigger) (*models.Trigger, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/triggers", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Trigger)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// DeleteTriggerInNamespace calls DELETE
+// /ns/{namespace}/package/{name}/triggers/{id}, delete a trigger
+func (c *Client) DeleteTriggerInNamespace(ctx context.Context, namespace string, name string, id int) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/triggers/%s", url.PathEscape(namespace), url.PathEscape(name), strconv.Itoa(id))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadPackageVersionsParams holds the optional parameters of
+// ReadPackageVersions, zero values aren't sent
+type ReadPackageVersionsParams struct {
+	Limit int
+	Next  string
+}
+
+// ReadPackageVersionsInNamespace calls GET
+// /ns/{namespace}/package/{name}/versions, list a package's versions
+func (c *Client) ReadPackageVersionsInNamespace(ctx context.Context, namespace string, name string, params *ReadPackageVersionsParams) (*models.PackageVersions, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/versions", url.PathEscape(namespace), url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.PackageVersions)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// RecordPullInNamespace calls POST
+// /ns/{namespace}/package/{name}/versions/{version}/pulls, count a pull of a
+// version
+func (c *Client) RecordPullInNamespace(ctx context.Context, namespace string, name string, version string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/versions/%s/pulls", url.PathEscape(namespace), url.PathEscape(name), url.PathEscape(version))
+	req, err := c.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.PullClient != "" {
+		req.Header.Set("X-Crackle-Client", c.PullClient)
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// YankPackageVersionInNamespace calls PUT
+// /ns/{namespace}/package/{name}/versions/{version}/yank, yank a version
+func (c *Client) YankPackageVersionInNamespace(ctx context.Context, namespace string, name string, version string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/versions/%s/yank", url.PathEscape(namespace), url.PathEscape(name), url.PathEscape(version))
+	req, err := c.NewRequest("PUT", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// UnyankPackageVersionInNamespace calls DELETE
+// /ns/{namespace}/package/{name}/versions/{version}/yank, unyank a version
+func (c *Client) UnyankPackageVersionInNamespace(ctx context.Context, namespace string, name string, version string) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/versions/%s/yank", url.PathEscape(namespace), url.PathEscape(name), url.PathEscape(version))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadWebhooksInNamespace calls GET /ns/{namespace}/package/{name}/webhooks,
+// list a package's webhooks
+func (c *Client) ReadWebhooksInNamespace(ctx context.Context, namespace string, name string) (*models.Webhooks, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/webhooks", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Webhooks)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// CreateWebhookInNamespace calls POST /ns/{namespace}/package/{name}/webhooks,
+// create a webhook for a package
+func (c *Client) CreateWebhookInNamespace(ctx context.Context, namespace string, name string, body *models.Webhook) (*models.Webhook, *http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/webhooks", url.PathEscape(namespace), url.PathEscape(name))
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Webhook)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// DeleteWebhookInNamespace calls DELETE
+// /ns/{namespace}/package/{name}/webhooks/{id}, delete a webhook
+func (c *Client) DeleteWebhookInNamespace(ctx context.Context, namespace string, name string, id int) (*http.Response, error) {
+	u := fmt.Sprintf("ns/%s/package/%s/webhooks/%s", url.PathEscape(namespace), url.PathEscape(name), strconv.Itoa(id))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// CreateOrg calls POST /orgs, create an org
+func (c *Client) CreateOrg(ctx context.Context, body *models.Org) (*models.Org, *http.Response, error) {
+	u := "orgs"
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Org)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadOrgMembersParams holds the optional parameters of ReadOrgMembers, zero
+// values aren't sent
+type ReadOrgMembersParams struct {
+	Limit int
+	Next  string
+}
+
+// ReadOrgMembers calls GET /orgs/{org}/members, list an org's members
+func (c *Client) ReadOrgMembers(ctx context.Context, org string, params *ReadOrgMembersParams) (*models.OrgMembers, *http.Response, error) {
+	u := fmt.Sprintf("orgs/%s/members", url.PathEscape(org))
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.OrgMembers)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// SetOrgMember calls PUT /orgs/{org}/members/{username}, add a member to an org
+// or change their role
+func (c *Client) SetOrgMember(ctx context.Context, org string, username string, body *models.OrgMember) (*http.Response, error) {
+	u := fmt.Sprintf("orgs/%s/members/%s", url.PathEscape(org), url.PathEscape(username))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// RemoveOrgMember calls DELETE /orgs/{org}/members/{username}, remove a member
+// from an org
+func (c *Client) RemoveOrgMember(ctx context.Context, org string, username string) (*http.Response, error) {
+	u := fmt.Sprintf("orgs/%s/members/%s", url.PathEscape(org), url.PathEscape(username))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// CreatePackage calls POST /package/, publish a version of a package
+func (c *Client) CreatePackage(ctx context.Context, body *models.Package) (*models.Package, *http.Response, error) {
+	u := "package/"
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Package)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadPackage calls GET /package/{name}, read a version of a package, the
+// latest by default
+func (c *Client) ReadPackage(ctx context.Context, name string, params *ReadPackageParams) (*models.Package, *http.Response, error) {
+	u := fmt.Sprintf("package/%s", url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Version != "" {
+			q.Set("version", params.Version)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Package)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// DeletePackage calls DELETE /package/{name}, delete a package
+func (c *Client) DeletePackage(ctx context.Context, name string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s", url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadPackageAudit calls GET /package/{name}/audit, read a package's audit log
+func (c *Client) ReadPackageAudit(ctx context.Context, name string, params *ReadPackageAuditParams) (*models.AuditLog, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/audit", url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Actor != "" {
+			q.Set("actor", params.Actor)
+		}
+		if params.Action != "" {
+			q.Set("action", params.Action)
+		}
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.AuditLog)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadPackageCategories calls GET /package/{name}/categories, list the
+// categories a package is in
+func (c *Client) ReadPackageCategories(ctx context.Context, name string) (*models.PackageCategories, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/categories", url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.PackageCategories)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// SetPackageCategories calls PUT /package/{name}/categories, put a package in
+// categories, replacing those it was in
+func (c *Client) SetPackageCategories(ctx context.Context, name string, body *models.PackageCategories) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/categories", url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// DeprecatePackage calls PUT /package/{name}/deprecate, deprecate a package
+func (c *Client) DeprecatePackage(ctx context.Context, name string, body *models.Deprecation) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/deprecate", url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// UndeprecatePackage calls DELETE /package/{name}/deprecate, undeprecate a
+// package
+func (c *Client) UndeprecatePackage(ctx context.Context, name string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/deprecate", url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadPackageOwners calls GET /package/{name}/owners, list a package's owners
+func (c *Client) ReadPackageOwners(ctx context.Context, name string, params *ReadPackageOwnersParams) (*models.Owners, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/owners", url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Owners)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// AddPackageOwner calls PUT /package/{name}/owners/{username}, add an owner to
+// a package
+func (c *Client) AddPackageOwner(ctx context.Context, name string, username string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/owners/%s", url.PathEscape(name), url.PathEscape(username))
+	req, err := c.NewRequest("PUT", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// RemovePackageOwner calls DELETE /package/{name}/owners/{username}, remove an
+// owner from a package
+func (c *Client) RemovePackageOwner(ctx context.Context, name string, username string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/owners/%s", url.PathEscape(name), url.PathEscape(username))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadPackageReadme calls GET /package/{name}/readme, read a package's rendered
+// long description
+func (c *Client) ReadPackageReadme(ctx context.Context, name string, params *ReadPackageReadmeParams) (*models.Readme, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/readme", url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Version != "" {
+			q.Set("version", params.Version)
+		}
+		if params.Color != "" {
+			q.Set("color", params.Color)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Readme)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReportPackage calls POST /package/{name}/report, report a malicious or
+// abusive package to the registry's admins
+func (c *Client) ReportPackage(ctx context.Context, name string, body *models.Report) (*models.Report, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/report", url.PathEscape(name))
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Report)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ResolvePackageVersion calls GET /package/{name}/resolve, resolve a version
+// range to the newest version in it
+func (c *Client) ResolvePackageVersion(ctx context.Context, name string, params *ResolvePackageVersionParams) (*models.PackageVersion, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/resolve", url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Range != "" {
+			q.Set("range", params.Range)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.PackageVersion)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadPackageStats calls GET /package/{name}/stats, read a package's pulls over
+// time
+func (c *Client) ReadPackageStats(ctx context.Context, name string, params *ReadPackageStatsParams) (*models.PackageStats, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/stats", url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Period != "" {
+			q.Set("period", params.Period)
+		}
+		if params.Range != "" {
+			q.Set("range", params.Range)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.PackageStats)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// Subscribe calls PUT /package/{name}/subscription, subscribe to a package's
+// new versions
+func (c *Client) Subscribe(ctx context.Context, name string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/subscription", url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// Unsubscribe calls DELETE /package/{name}/subscription, unsubscribe from a
+// package
+func (c *Client) Unsubscribe(ctx context.Context, name string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/subscription", url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// OfferTransfer calls PUT /package/{name}/transfer, offer a package to another
+// user
+func (c *Client) OfferTransfer(ctx context.Context, name string, body *models.Transfer) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/transfer", url.PathEscape(name))
+	req, err := c.NewRequest("PUT", u, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// CancelTransfer calls DELETE /package/{name}/transfer, cancel a package's
+// transfer
+func (c *Client) CancelTransfer(ctx context.Context, name string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/transfer", url.PathEscape(name))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// AcceptTransfer calls POST /package/{name}/transfer/accept, accept a package
+// offered to the user
+func (c *Client) AcceptTransfer(ctx context.Context, name string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/transfer/accept", url.PathEscape(name))
+	req, err := c.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadTriggers calls GET /package/{name}/triggers, list a package's triggers
+func (c *Client) ReadTriggers(ctx context.Context, name string) (*models.Triggers, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/triggers", url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Triggers)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// CreateTrigger calls POST /package/{name}/triggers, create a trigger
+// publishing a package when a tag of its image is pushed
+func (c *Client) CreateTrigger(ctx context.Context, name string, body *models.Trigger) (*models.Trigger, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/triggers", url.PathEscape(name))
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Trigger)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// DeleteTrigger calls DELETE /package/{name}/triggers/{id}, delete a trigger
+func (c *Client) DeleteTrigger(ctx context.Context, name string, id int) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/triggers/%s", url.PathEscape(name), strconv.Itoa(id))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadPackageVersions calls GET /package/{name}/versions, list a package's
+// versions
+func (c *Client) ReadPackageVersions(ctx context.Context, name string, params *ReadPackageVersionsParams) (*models.PackageVersions, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/versions", url.PathEscape(name))
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.PackageVersions)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// RecordPull calls POST /package/{name}/versions/{version}/pulls, count a pull
+// of a version
+func (c *Client) RecordPull(ctx context.Context, name string, version string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/versions/%s/pulls", url.PathEscape(name), url.PathEscape(version))
+	req, err := c.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.PullClient != "" {
+		req.Header.Set("X-Crackle-Client", c.PullClient)
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// YankPackageVersion calls PUT /package/{name}/versions/{version}/yank, yank a
+// version
+func (c *Client) YankPackageVersion(ctx context.Context, name string, version string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/versions/%s/yank", url.PathEscape(name), url.PathEscape(version))
+	req, err := c.NewRequest("PUT", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// UnyankPackageVersion calls DELETE /package/{name}/versions/{version}/yank,
+// unyank a version
+func (c *Client) UnyankPackageVersion(ctx context.Context, name string, version string) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/versions/%s/yank", url.PathEscape(name), url.PathEscape(version))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadWebhooks calls GET /package/{name}/webhooks, list a package's webhooks
+func (c *Client) ReadWebhooks(ctx context.Context, name string) (*models.Webhooks, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/webhooks", url.PathEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Webhooks)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// CreateWebhook calls POST /package/{name}/webhooks, create a webhook for a
+// package
+func (c *Client) CreateWebhook(ctx context.Context, name string, body *models.Webhook) (*models.Webhook, *http.Response, error) {
+	u := fmt.Sprintf("package/%s/webhooks", url.PathEscape(name))
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Webhook)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// DeleteWebhook calls DELETE /package/{name}/webhooks/{id}, delete a webhook
+func (c *Client) DeleteWebhook(ctx context.Context, name string, id int) (*http.Response, error) {
+	u := fmt.Sprintf("package/%s/webhooks/%s", url.PathEscape(name), strconv.Itoa(id))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadRecentParams holds the optional parameters of ReadRecent, zero values
+// aren't sent
+type ReadRecentParams struct {
+	Limit int
+	Next  string
+}
+
+// ReadRecent calls GET /recent, list the packages published most recently
+func (c *Client) ReadRecent(ctx context.Context, params *ReadRecentParams) (*models.Packages, *http.Response, error) {
+	u := "recent"
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Packages)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// SearchPackagesParams holds the optional parameters of SearchPackages, zero
+// values aren't sent
+type SearchPackagesParams struct {
+	Q        string
+	Owner    string
+	Keyword  string
+	Category string
+	Sort     string
+	Limit    int
+	Next     string
+}
+
+// SearchPackages calls GET /search, search the latest version of every package
+func (c *Client) SearchPackages(ctx context.Context, params *SearchPackagesParams) (*models.SearchResults, *http.Response, error) {
+	u := "search"
+	q := url.Values{}
+	if params != nil {
+		if params.Q != "" {
+			q.Set("q", params.Q)
+		}
+		if params.Owner != "" {
+			q.Set("owner", params.Owner)
+		}
+		if params.Keyword != "" {
+			q.Set("keyword", params.Keyword)
+		}
+		if params.Category != "" {
+			q.Set("category", params.Category)
+		}
+		if params.Sort != "" {
+			q.Set("sort", params.Sort)
+		}
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.SearchResults)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadSubscriptionsParams holds the optional parameters of ReadSubscriptions,
+// zero values aren't sent
+type ReadSubscriptionsParams struct {
+	Limit int
+	Next  string
+}
+
+// ReadSubscriptions calls GET /subscriptions, list the packages the user is
+// subscribed to
+func (c *Client) ReadSubscriptions(ctx context.Context, params *ReadSubscriptionsParams) (*models.Subscriptions, *http.Response, error) {
+	u := "subscriptions"
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Subscriptions)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadTokens calls GET /tokens, list the user's API tokens
+func (c *Client) ReadTokens(ctx context.Context) (*models.Tokens, *http.Response, error) {
+	u := "tokens"
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Tokens)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// CreateToken calls POST /tokens, create an API token with scopes
+func (c *Client) CreateToken(ctx context.Context, body *models.TokenRequest) (*models.Token, *http.Response, error) {
+	u := "tokens"
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Token)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// RevokeToken calls DELETE /tokens/{id}, revoke an API token
+func (c *Client) RevokeToken(ctx context.Context, id int) (*http.Response, error) {
+	u := fmt.Sprintf("tokens/%s", strconv.Itoa(id))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// ReadTransfersParams holds the optional parameters of ReadTransfers, zero
+// values aren't sent
+type ReadTransfersParams struct {
+	Limit int
+	Next  string
+}
+
+// ReadTransfers calls GET /transfers, list the packages offered to the user
+func (c *Client) ReadTransfers(ctx context.Context, params *ReadTransfersParams) (*models.Transfers, *http.Response, error) {
+	u := "transfers"
+	q := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Transfers)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadTrendingParams holds the optional parameters of ReadTrending, zero values
+// aren't sent
+type ReadTrendingParams struct {
+	Period string
+	Limit  int
+	Next   string
+}
+
+// ReadTrending calls GET /trending, list the packages pulled most recently
+func (c *Client) ReadTrending(ctx context.Context, params *ReadTrendingParams) (*models.TrendingPackages, *http.Response, error) {
+	u := "trending"
+	q := url.Values{}
+	if params != nil {
+		if params.Period != "" {
+			q.Set("period", params.Period)
+		}
+		if params.Limit != 0 {
+			q.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Next != "" {
+			q.Set("next", params.Next)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.TrendingPackages)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// PublishFromTriggerParams holds the optional parameters of PublishFromTrigger,
+// zero values aren't sent
+type PublishFromTriggerParams struct {
+	XGitHubEvent string
+}
+
+// PublishFromTrigger calls POST /triggers/{token}, publish a tag pushed to a
+// package's image, called by Docker Hub or GHCR webhooks
+//
+// Docker Hub push webhooks and GitHub package or registry_package events, told
+// apart by X-GitHub-Event, publish the pushed tag as a new version copying the
+// latest when it matches the trigger's Tags. Pushes that aren't published are
+// answered with a 200 saying why.
+func (c *Client) PublishFromTrigger(ctx context.Context, token string, body interface{}, params *PublishFromTriggerParams) (json.RawMessage, *http.Response, error) {
+	u := fmt.Sprintf("triggers/%s", url.PathEscape(token))
+	req, err := c.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if params != nil {
+		if params.XGitHubEvent != "" {
+			req.Header.Set("X-GitHub-Event", params.XGitHubEvent)
+		}
+	}
+	var v json.RawMessage
+	resp, err := c.Do(ctx, req, &v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// ReadVersion calls GET /version, read the registry's version
+func (c *Client) ReadVersion(ctx context.Context) (*models.Version, *http.Response, error) {
+	u := "version"
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Version)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
+
+// WhoAmI calls GET /whoami, read the user the token authenticates as
+func (c *Client) WhoAmI(ctx context.Context) (*models.Identity, *http.Response, error) {
+	u := "whoami"
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(models.Identity)
+	resp, err := c.Do(ctx, req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	return v, resp, nil
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// OrgService handles communication with Crackle API relating to orgs
+type OrgService service
+
+// CreateOrg creates an org, the client's user becomes its first owner
+func (s *OrgService) CreateOrg(ctx context.Context, name string) (*models.Org, *http.Response, error) {
+	return s.client.CreateOrg(ctx, &models.Org{Name: name})
+}
+
+// ListMembers fetchs the members of a given org name, oldest first
+func (s *OrgService) ListMembers(ctx context.Context, org string) ([]models.OrgMember, *http.Response, error) {
+	members := []models.OrgMember{}
+	next := ""
+	for {
+		page, resp, err := s.client.ReadOrgMembers(ctx, org, &ReadOrgMembersParams{Limit: maxPageSize, Next: next})
+		if err != nil {
+			return nil, resp, err
+		}
+		members = append(members, page.Member...)
+		if next = page.Next; !morePages(next, 0, len(members)) {
+			return members, resp, nil
+		}
+	}
+}
+
+// SetMember adds a user to a given org name with a role, or changes their role
+func (s *OrgService) SetMember(ctx context.Context, org string, username string, role string) (*http.Response, error) {
+	return s.client.SetOrgMember(ctx, org, username, &models.OrgMember{Role: role})
+}
+
+// RemoveMember removes a user from a given org name
+func (s *OrgService) RemoveMember(ctx context.Context, org string, username string) (*http.Response, error) {
+	return s.client.RemoveOrgMember(ctx, org, username)
+}
@@ -0,0 +1,476 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// PackageService handles communication with Crackle API relating to Package endpoint
+type PackageService service
+
+// splitName splits a Package name into its namespace, empty for names that
+// aren't namespaced, and the name under it. alice/tool is tool under alice.
+func splitName(p string) (string, string) {
+	if i := strings.Index(p, "/"); i != -1 {
+		return p[:i], p[i+1:]
+	}
+	return "", p
+}
+
+// GetPackage fetchs the Package object for a given Package name
+func (s *PackageService) GetPackage(ctx context.Context, p string) (*models.Package, *http.Response, error) {
+	return s.GetPackageVersion(ctx, p, "")
+}
+
+// fetchLongDescription downloads a long description the registry keeps in
+// object storage, which it only links to
+func (s *PackageService) fetchLongDescription(ctx context.Context, p *models.Package) error {
+	if p.LongDescription != nil || p.LongDescriptionURL == "" {
+		return nil
+	}
+	req, err := http.NewRequest("GET", p.LongDescriptionURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("long description of %s: %s", p.Name, resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	description := string(b)
+	p.LongDescription = &description
+	return nil
+}
+
+// GetPackageVersion fetchs the Package object for a given Package name and version
+func (s *PackageService) GetPackageVersion(ctx context.Context, p string, version string) (*models.Package, *http.Response, error) {
+	params := &ReadPackageParams{Version: version}
+	var c *models.Package
+	var resp *http.Response
+	var err error
+	if namespace, name := splitName(p); namespace != "" {
+		c, resp, err = s.client.ReadPackageInNamespace(ctx, namespace, name, params)
+	} else {
+		c, resp, err = s.client.ReadPackage(ctx, name, params)
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+	if err = s.fetchLongDescription(ctx, c); err != nil {
+		return c, resp, err
+	}
+
+	return c, resp, nil
+}
+
+// GetReadme fetchs the long description of a given Package name and version
+// rendered as HTML and as text for a terminal, with color the text is styled
+// with ANSI codes
+func (s *PackageService) GetReadme(ctx context.Context, p string, version string, color bool) (*models.Readme, *http.Response, error) {
+	params := &ReadPackageReadmeParams{Version: version, Color: strconv.FormatBool(color)}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.ReadPackageReadmeInNamespace(ctx, namespace, name, params)
+	}
+	return s.client.ReadPackageReadme(ctx, p, params)
+}
+
+// ResolveVersion fetchs the newest version of a given Package name in a range
+// such as ^1.2, yanked versions are never picked
+func (s *PackageService) ResolveVersion(ctx context.Context, p string, versionRange string) (*models.PackageVersion, *http.Response, error) {
+	params := &ResolvePackageVersionParams{Range: versionRange}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.ResolvePackageVersionInNamespace(ctx, namespace, name, params)
+	}
+	return s.client.ResolvePackageVersion(ctx, p, params)
+}
+
+// ListVersions fetchs every published version of a given Package name, newest first
+func (s *PackageService) ListVersions(ctx context.Context, p string) ([]models.PackageVersion, *http.Response, error) {
+	namespace, name := splitName(p)
+	versions := []models.PackageVersion{}
+	next := ""
+	for {
+		params := &ReadPackageVersionsParams{Limit: maxPageSize, Next: next}
+		var page *models.PackageVersions
+		var resp *http.Response
+		var err error
+		if namespace != "" {
+			page, resp, err = s.client.ReadPackageVersionsInNamespace(ctx, namespace, name, params)
+		} else {
+			page, resp, err = s.client.ReadPackageVersions(ctx, name, params)
+		}
+		if err != nil {
+			return nil, resp, err
+		}
+		versions = append(versions, page.Version...)
+		if next = page.Next; !morePages(next, 0, len(versions)) {
+			return versions, resp, nil
+		}
+	}
+}
+
+// ListOwners fetchs the owners of a given Package name, oldest first
+func (s *PackageService) ListOwners(ctx context.Context, p string) ([]models.Owner, *http.Response, error) {
+	namespace, name := splitName(p)
+	owners := []models.Owner{}
+	next := ""
+	for {
+		params := &ReadPackageOwnersParams{Limit: maxPageSize, Next: next}
+		var page *models.Owners
+		var resp *http.Response
+		var err error
+		if namespace != "" {
+			page, resp, err = s.client.ReadPackageOwnersInNamespace(ctx, namespace, name, params)
+		} else {
+			page, resp, err = s.client.ReadPackageOwners(ctx, name, params)
+		}
+		if err != nil {
+			return nil, resp, err
+		}
+		owners = append(owners, page.Owner...)
+		if next = page.Next; !morePages(next, 0, len(owners)) {
+			return owners, resp, nil
+		}
+	}
+}
+
+// SetOwner lets a user publish and manage a given Package name, or stops them
+// when owner is false
+func (s *PackageService) SetOwner(ctx context.Context, p string, username string, owner bool) (*http.Response, error) {
+	namespace, name := splitName(p)
+	switch {
+	case owner && namespace != "":
+		return s.client.AddPackageOwnerInNamespace(ctx, namespace, name, username)
+	case owner:
+		return s.client.AddPackageOwner(ctx, name, username)
+	case namespace != "":
+		return s.client.RemovePackageOwnerInNamespace(ctx, namespace, name, username)
+	}
+	return s.client.RemovePackageOwner(ctx, name, username)
+}
+
+// ListTransfers fetchs the pending transfers offered to or by the client's
+// user, newest first
+func (s *PackageService) ListTransfers(ctx context.Context) ([]models.Transfer, *http.Response, error) {
+	transfers := []models.Transfer{}
+	next := ""
+	for {
+		page, resp, err := s.client.ReadTransfers(ctx, &ReadTransfersParams{Limit: maxPageSize, Next: next})
+		if err != nil {
+			return nil, resp, err
+		}
+		transfers = append(transfers, page.Transfer...)
+		if next = page.Next; !morePages(next, 0, len(transfers)) {
+			return transfers, resp, nil
+		}
+	}
+}
+
+// OfferTransfer offers a given Package name to another user, it changes hands
+// once they accept
+func (s *PackageService) OfferTransfer(ctx context.Context, p string, username string) (*http.Response, error) {
+	transfer := &models.Transfer{To: username}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.OfferTransferInNamespace(ctx, namespace, name, transfer)
+	}
+	return s.client.OfferTransfer(ctx, p, transfer)
+}
+
+// AcceptTransfer accepts the transfer of a given Package name offered to the
+// client's user
+func (s *PackageService) AcceptTransfer(ctx context.Context, p string) (*http.Response, error) {
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.AcceptTransferInNamespace(ctx, namespace, name)
+	}
+	return s.client.AcceptTransfer(ctx, p)
+}
+
+// CancelTransfer cancels the pending transfer of a given Package name, or
+// declines it when it was offered to the client's user
+func (s *PackageService) CancelTransfer(ctx context.Context, p string) (*http.Response, error) {
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.CancelTransferInNamespace(ctx, namespace, name)
+	}
+	return s.client.CancelTransfer(ctx, p)
+}
+
+// RecordPull counts a pull of a version of a given Package name, a client
+// with a PullClient is counted once a day
+func (s *PackageService) RecordPull(ctx context.Context, p string, version string) (*http.Response, error) {
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.RecordPullInNamespace(ctx, namespace, name, version)
+	}
+	return s.client.RecordPull(ctx, p, version)
+}
+
+// GetStats fetchs the pulls of a given Package name grouped by period, one of
+// day, week or month, over a range such as 30d. An empty range is the period's
+// default.
+func (s *PackageService) GetStats(ctx context.Context, p string, period string, statsRange string) (*models.PackageStats, *http.Response, error) {
+	params := &ReadPackageStatsParams{Period: period, Range: statsRange}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.ReadPackageStatsInNamespace(ctx, namespace, name, params)
+	}
+	return s.client.ReadPackageStats(ctx, p, params)
+}
+
+// YankVersion marks a version of a given Package name as yanked, or undoes it
+// when yanked is false
+func (s *PackageService) YankVersion(ctx context.Context, p string, version string, yanked bool) (*http.Response, error) {
+	namespace, name := splitName(p)
+	switch {
+	case yanked && namespace != "":
+		return s.client.YankPackageVersionInNamespace(ctx, namespace, name, version)
+	case yanked:
+		return s.client.YankPackageVersion(ctx, name, version)
+	case namespace != "":
+		return s.client.UnyankPackageVersionInNamespace(ctx, namespace, name, version)
+	}
+	return s.client.UnyankPackageVersion(ctx, name, version)
+}
+
+// Deprecate marks every version of a given Package name as deprecated with a
+// message, or undoes it when the message is empty
+func (s *PackageService) Deprecate(ctx context.Context, p string, message string) (*http.Response, error) {
+	namespace, name := splitName(p)
+	deprecation := &models.Deprecation{Message: message}
+	switch {
+	case message != "" && namespace != "":
+		return s.client.DeprecatePackageInNamespace(ctx, namespace, name, deprecation)
+	case message != "":
+		return s.client.DeprecatePackage(ctx, name, deprecation)
+	case namespace != "":
+		return s.client.UndeprecatePackageInNamespace(ctx, namespace, name)
+	}
+	return s.client.UndeprecatePackage(ctx, name)
+}
+
+// CreatePackage creates a new Package from a given Package model
+func (s *PackageService) CreatePackage(ctx context.Context, p *models.Package) (*models.Package, *http.Response, error) {
+	return s.client.CreatePackage(ctx, p)
+}
+
+// SearchOptions specifies the filters and ordering of SearchPackages, Limit is
+// the most results to fetch across every page, 0 fetchs them all
+type SearchOptions struct {
+	Query    string
+	Owner    string
+	Keyword  string
+	Category string
+	Sort     string
+	Limit    int
+}
+
+// SearchPackages fetchs the latest version of every Package matching the search
+// options, by default the best matches of the query come first
+func (s *PackageService) SearchPackages(ctx context.Context, opts *SearchOptions) ([]models.SearchResult, *http.Response, error) {
+	params := &SearchPackagesParams{}
+	limit := 0
+	if opts != nil {
+		params.Q = opts.Query
+		params.Owner = opts.Owner
+		params.Keyword = opts.Keyword
+		params.Category = opts.Category
+		params.Sort = opts.Sort
+		limit = opts.Limit
+	}
+	results := []models.SearchResult{}
+	for {
+		params.Limit = pageSize(limit, len(results))
+		page, resp, err := s.client.SearchPackages(ctx, params)
+		if err != nil {
+			return nil, resp, err
+		}
+		results = append(results, page.Package...)
+		if params.Next = page.Next; !morePages(params.Next, limit, len(results)) {
+			return results, resp, nil
+		}
+	}
+}
+
+// ListTrending fetchs the latest version of the packages pulled most over a
+// period, one of day, week or month
+func (s *PackageService) ListTrending(ctx context.Context, period string, limit int) ([]models.TrendingPackage, *http.Response, error) {
+	params := &ReadTrendingParams{Period: period}
+	results := []models.TrendingPackage{}
+	for {
+		params.Limit = pageSize(limit, len(results))
+		page, resp, err := s.client.ReadTrending(ctx, params)
+		if err != nil {
+			return nil, resp, err
+		}
+		results = append(results, page.Package...)
+		if params.Next = page.Next; !morePages(params.Next, limit, len(results)) {
+			return results, resp, nil
+		}
+	}
+}
+
+// ListRecent fetchs the latest version of the most recently published packages,
+// a limit of 0 fetchs every one
+func (s *PackageService) ListRecent(ctx context.Context, limit int) ([]models.Package, *http.Response, error) {
+	params := &ReadRecentParams{}
+	results := []models.Package{}
+	for {
+		params.Limit = pageSize(limit, len(results))
+		page, resp, err := s.client.ReadRecent(ctx, params)
+		if err != nil {
+			return nil, resp, err
+		}
+		results = append(results, page.Package...)
+		if params.Next = page.Next; !morePages(params.Next, limit, len(results)) {
+			return results, resp, nil
+		}
+	}
+}
+
+// ListWebhooks fetchs the webhooks of a given Package name, without their secrets
+func (s *PackageService) ListWebhooks(ctx context.Context, p string) ([]models.Webhook, *http.Response, error) {
+	var webhooks *models.Webhooks
+	var resp *http.Response
+	var err error
+	if namespace, name := splitName(p); namespace != "" {
+		webhooks, resp, err = s.client.ReadWebhooksInNamespace(ctx, namespace, name)
+	} else {
+		webhooks, resp, err = s.client.ReadWebhooks(ctx, name)
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return webhooks.Webhook, resp, nil
+}
+
+// CreateWebhook registers a URL the registry calls when a given Package name
+// changes, the returned Webhook holds the secret its calls are signed with
+func (s *PackageService) CreateWebhook(ctx context.Context, p string, hookURL string, events []string) (*models.Webhook, *http.Response, error) {
+	webhook := &models.Webhook{URL: hookURL, Events: events}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.CreateWebhookInNamespace(ctx, namespace, name, webhook)
+	}
+	return s.client.CreateWebhook(ctx, p, webhook)
+}
+
+// DeleteWebhook stops calling one of a given Package name's webhooks
+func (s *PackageService) DeleteWebhook(ctx context.Context, p string, id int) (*http.Response, error) {
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.DeleteWebhookInNamespace(ctx, namespace, name, id)
+	}
+	return s.client.DeleteWebhook(ctx, p, id)
+}
+
+// ListTriggers fetchs the triggers of a given Package name, without their URLs
+func (s *PackageService) ListTriggers(ctx context.Context, p string) ([]models.Trigger, *http.Response, error) {
+	var triggers *models.Triggers
+	var resp *http.Response
+	var err error
+	if namespace, name := splitName(p); namespace != "" {
+		triggers, resp, err = s.client.ReadTriggersInNamespace(ctx, namespace, name)
+	} else {
+		triggers, resp, err = s.client.ReadTriggers(ctx, name)
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return triggers.Trigger, resp, nil
+}
+
+// CreateTrigger adds a URL to a given Package name that Docker Hub or GHCR
+// webhooks call to publish the tags matching tags, the returned Trigger holds
+// the URL
+func (s *PackageService) CreateTrigger(ctx context.Context, p string, tags string) (*models.Trigger, *http.Response, error) {
+	trigger := &models.Trigger{Tags: tags}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.CreateTriggerInNamespace(ctx, namespace, name, trigger)
+	}
+	return s.client.CreateTrigger(ctx, p, trigger)
+}
+
+// DeleteTrigger removes one of a given Package name's triggers
+func (s *PackageService) DeleteTrigger(ctx context.Context, p string, id int) (*http.Response, error) {
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.DeleteTriggerInNamespace(ctx, namespace, name, id)
+	}
+	return s.client.DeleteTrigger(ctx, p, id)
+}
+
+// Report flags a given Package name to the registry's admins for reason,
+// version may be empty to report the whole package
+func (s *PackageService) Report(ctx context.Context, p string, version string, reason string) (*models.Report, *http.Response, error) {
+	r := &models.Report{Reason: reason}
+	if version != "" {
+		r.Version = &version
+	}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.ReportPackageInNamespace(ctx, namespace, name, r)
+	}
+	return s.client.ReportPackage(ctx, p, r)
+}
+
+// ListCategories fetchs every category of the registry's taxonomy, alphabetically
+func (s *PackageService) ListCategories(ctx context.Context) ([]models.Category, *http.Response, error) {
+	categories, resp, err := s.client.ReadCategories(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return categories.Category, resp, nil
+}
+
+// GetCategories fetchs the slugs of the categories a given Package name is in
+func (s *PackageService) GetCategories(ctx context.Context, p string) ([]string, *http.Response, error) {
+	var categories *models.PackageCategories
+	var resp *http.Response
+	var err error
+	if namespace, name := splitName(p); namespace != "" {
+		categories, resp, err = s.client.ReadPackageCategoriesInNamespace(ctx, namespace, name)
+	} else {
+		categories, resp, err = s.client.ReadPackageCategories(ctx, name)
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return categories.Categories, resp, nil
+}
+
+// SetCategories puts a given Package name in categories, replacing those it was
+// in, none takes it out of every category
+func (s *PackageService) SetCategories(ctx context.Context, p string, categories []string) (*http.Response, error) {
+	body := &models.PackageCategories{Categories: categories}
+	if namespace, name := splitName(p); namespace != "" {
+		return s.client.SetPackageCategoriesInNamespace(ctx, namespace, name, body)
+	}
+	return s.client.SetPackageCategories(ctx, p, body)
+}
+
+// ListCollections fetchs every curated collection, alphabetically, with the
+// names of the packages they list
+func (s *PackageService) ListCollections(ctx context.Context) ([]models.Collection, *http.Response, error) {
+	collections, resp, err := s.client.ReadCollections(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return collections.Collection, resp, nil
+}
+
+// GetCollection fetchs a collection by slug with the latest version of each
+// package it lists
+func (s *PackageService) GetCollection(ctx context.Context, slug string) (*models.Collection, *http.Response, error) {
+	return s.client.ReadCollection(ctx, slug)
+}
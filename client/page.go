@@ -0,0 +1,19 @@
+package client
+
+// maxPageSize is the most results the registry returns in a page
+const maxPageSize = 100
+
+// pageSize returns how many results to ask for in the next page when have of
+// limit results were already read, a limit of 0 reads everything
+func pageSize(limit, have int) int {
+	if limit <= 0 || limit-have > maxPageSize {
+		return maxPageSize
+	}
+	return limit - have
+}
+
+// morePages reports whether another page should be fetched after one ending
+// at next, with have of limit results read
+func morePages(next string, limit, have int) bool {
+	return next != "" && (limit <= 0 || have < limit)
+}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// VersionService retrieves version information
+type VersionService service
+
+// Server fetchs the Package object for a given Package name
+func (s *VersionService) Server(ctx context.Context) (*models.Version, *http.Response, error) {
+	return s.client.ReadVersion(ctx)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var notifyNone bool
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage your email and the notifications sent to it",
+	Long: `Sets the email the registry notifies you at, once you've verified it with the
+code emailed to it. You're emailed when a package you subscribed to with cr
+subscribe publishes a version, and when a package is offered to you with cr
+transfer. cr whoami shows your email and notifications.`,
+}
+
+var accountEmailCmd = &cobra.Command{
+	Use:   "email [address]",
+	Short: "Emails a verification code to an address",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if err := helpers.ValidEmail(args[0]); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		resp, err := client.Account.SetEmail(context.Background(), args[0])
+		checkAccountResponse(resp, err)
+		helpers.Infof("Check %s for a code and verify it with cr account verify [code]", args[0])
+	},
+}
+
+var accountVerifyCmd = &cobra.Command{
+	Use:   "verify [code]",
+	Short: "Verifies your email with the code sent to it",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+
+		client := newClient()
+		resp, err := client.Account.VerifyEmail(context.Background(), strings.TrimSpace(args[0]))
+		if resp != nil && resp.StatusCode == 400 {
+			exit1("The code is wrong or expired, request another with cr account email")
+		}
+		checkAccountResponse(resp, err)
+		helpers.Infof("Verified your email")
+	},
+}
+
+var accountNotifyCmd = &cobra.Command{
+	Use:   "notify [notification...]",
+	Short: "Chooses the emails you're sent",
+	Long: fmt.Sprintf(`Chooses the kinds of email you're sent: %s for new versions of packages
+you subscribed to, %s for packages offered to you. Kinds not given are turned
+off, --none turns every one off.`, helpers.NotifyPublish, helpers.NotifyInvite),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && !notifyNone || len(args) != 0 && notifyNone {
+			exit1(cmd.UsageString())
+		}
+		if err := helpers.ValidNotifications(args); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		resp, err := client.Account.SetNotifications(context.Background(), args)
+		checkAccountResponse(resp, err)
+		if notifyNone {
+			helpers.Infof("Turned off every notification")
+		} else {
+			helpers.Infof("You'll be notified of %s", strings.Join(args, ", "))
+		}
+	},
+}
+
+// checkAccountResponse exits unless a request changing the client's user succeeded
+func checkAccountResponse(resp *http.Response, err error) {
+	if resp == nil {
+		exit1(err.Error())
+	}
+	switch resp.StatusCode {
+	case 202, 204:
+	case 401:
+		exit1("Login with `cr login` first")
+	case 501:
+		exit1("This registry doesn't send email")
+	default:
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+}
+
+func init() {
+	accountNotifyCmd.Flags().BoolVar(&notifyNone, "none", false, "Turn off every notification")
+	accountCmd.AddCommand(accountEmailCmd)
+	accountCmd.AddCommand(accountVerifyCmd)
+	accountCmd.AddCommand(accountNotifyCmd)
+	Root.AddCommand(accountCmd)
+}
@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+var (
+	adminReason  string
+	adminReserve bool
+	adminYes     bool
+	adminStatus  string
+	adminTitle   string
+	adminDesc    string
+)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Moderates the registry",
+	Long: `Suspends users, removes malicious packages, reserves package names, works
+through the packages users reported with cr report and curates the categories
+and collections of cr browse. Only admins listed in the registry's server.toml
+can, with a token that has the admin scope. Every moderation is recorded in the
+audit log.`,
+}
+
+var adminSuspendCmd = &cobra.Command{
+	Use:   "suspend [username]",
+	Short: "Keeps a user from logging in or using their tokens",
+	Long: `Suspends a user until cr admin unsuspend, their tokens stop working and they
+can't login again. Their packages stay published, remove them with cr admin
+remove. --reason is required and shown to the user.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if err := helpers.ValidModerationReason(adminReason, false); err != nil {
+			exit1(fmt.Sprintf("Provide why %s is suspended with --reason: %s", args[0], err))
+		}
+
+		client := newClient()
+		resp, err := client.Admin.SuspendUser(context.Background(), args[0], adminReason)
+		checkAdminResponse(resp, err, fmt.Sprintf("User %s not found", args[0]))
+		helpers.Infof("Suspended %s", args[0])
+	},
+}
+
+var adminUnsuspendCmd = &cobra.Command{
+	Use:   "unsuspend [username]",
+	Short: "Lifts the suspension of a user",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+
+		client := newClient()
+		resp, err := client.Admin.UnsuspendUser(context.Background(), args[0])
+		checkAdminResponse(resp, err, fmt.Sprintf("User %s not found", args[0]))
+		helpers.Infof("Unsuspended %s", args[0])
+	},
+}
+
+var adminRemoveCmd = &cobra.Command{
+	Use:   "remove [package]",
+	Short: "Deletes every version of a package, whoever owns it",
+	Long: `Removes a package from the registry, such as when it's malicious. Its owners
+can publish the name again unless --reserve is given. --reason is required and
+recorded in the audit log. Removing has to be confirmed, or pass --yes to skip
+the prompt.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+		if err := helpers.ValidModerationReason(adminReason, false); err != nil {
+			exit1(fmt.Sprintf("Provide why %s is removed with --reason: %s", name, err))
+		}
+		if !adminYes {
+			if !helpers.Interactive() || !isTerminal(os.Stdin) {
+				exit1("Not removing without confirmation, use --yes to remove non-interactively")
+			}
+			if !confirm(fmt.Sprintf("Remove every version of %s?", name)) {
+				exit1("Not removed")
+			}
+		}
+
+		client := newClient()
+		resp, err := client.Admin.RemovePackage(context.Background(), name, adminReason, adminReserve)
+		checkAdminResponse(resp, err, fmt.Sprintf("Package %s not found", name))
+		if adminReserve {
+			helpers.Infof("Removed and reserved %s", name)
+		} else {
+			helpers.Infof("Removed %s", name)
+		}
+	},
+}
+
+var adminReserveCmd = &cobra.Command{
+	Use:   "reserve [package]",
+	Short: "Keeps anyone from publishing a package name",
+	Long: `Reserves a package name, whether or not it's published, so no one can publish
+it until cr admin unreserve. Versions already published stay.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+		if err := helpers.ValidModerationReason(adminReason, true); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		resp, err := client.Admin.ReserveName(context.Background(), args[0], adminReason)
+		checkAdminResponse(resp, err, "")
+		helpers.Infof("Reserved %s", args[0])
+	},
+}
+
+var adminUnreserveCmd = &cobra.Command{
+	Use:   "unreserve [package]",
+	Short: "Lets a reserved package name be published again",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		resp, err := client.Admin.UnreserveName(context.Background(), args[0])
+		checkAdminResponse(resp, err, fmt.Sprintf("%s isn't reserved", args[0]))
+		helpers.Infof("Unreserved %s", args[0])
+	},
+}
+
+var adminReservedCmd = &cobra.Command{
+	Use:   "reserved",
+	Short: "Lists the reserved package names",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := newClient()
+		reserved, resp, err := client.Admin.ListReservedNames(context.Background())
+		checkAdminResponse(resp, err, "")
+
+		render(reserved, func() {
+			if len(reserved) == 0 {
+				fmt.Println("No names are reserved")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tBY\tRESERVED\tREASON")
+			for _, r := range reserved {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.ReservedBy, date(r.CreatedAt), optional(r.Reason))
+			}
+			w.Flush()
+		})
+	},
+}
+
+var adminReportsCmd = &cobra.Command{
+	Use:   "reports",
+	Short: "Lists the packages users reported, oldest first",
+	Long: `Lists the reports made with cr report, only open ones unless --status is given.
+Dismiss a report with cr admin dismiss, or remove the reported package with
+cr admin remove, which resolves its open reports as removed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if adminStatus != "all" {
+			if err := helpers.ValidReportStatus(adminStatus); err != nil {
+				exit1(err.Error())
+			}
+		} else {
+			adminStatus = ""
+		}
+
+		client := newClient()
+		reports, resp, err := client.Admin.ListReports(context.Background(), adminStatus)
+		checkAdminResponse(resp, err, "")
+
+		render(reports, func() {
+			if len(reports) == 0 {
+				fmt.Println("No packages are reported")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tPACKAGE\tVERSION\tBY\tREPORTED\tSTATUS\tREASON")
+			for _, r := range reports {
+				reporter := "-"
+				if r.Reporter != nil {
+					reporter = *r.Reporter
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Package, optional(r.Version), reporter,
+					date(r.CreatedAt), r.Status, r.Reason)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var adminDismissCmd = &cobra.Command{
+	Use:   "dismiss [id]",
+	Short: "Closes a report without removing its package",
+	Run: func(cmd *cobra.Command, args []string) {
+		resolveReport(cmd, args, helpers.ReportDismissed)
+	},
+}
+
+var adminReopenCmd = &cobra.Command{
+	Use:   "reopen [id]",
+	Short: "Opens a dismissed report again",
+	Run: func(cmd *cobra.Command, args []string) {
+		resolveReport(cmd, args, helpers.ReportOpen)
+	},
+}
+
+// resolveReport sets the status of the report whose id is the only arg
+func resolveReport(cmd *cobra.Command, args []string, status string) {
+	if len(args) != 1 {
+		exit1(cmd.UsageString())
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		exit1(fmt.Sprintf("Report id \"%s\" is invalid", args[0]))
+	}
+	if err = helpers.ValidModerationReason(adminReason, true); err != nil {
+		exit1(err.Error())
+	}
+
+	client := newClient()
+	resp, err := client.Admin.ResolveReport(context.Background(), id, status, adminReason)
+	if resp != nil && resp.StatusCode == 409 {
+		exit1(fmt.Sprintf("The reporter of %d has another open report of the package", id))
+	}
+	checkAdminResponse(resp, err, fmt.Sprintf("Report %d not found", id))
+	if status == helpers.ReportOpen {
+		helpers.Infof("Reopened report %d", id)
+	} else {
+		helpers.Infof("Dismissed report %d", id)
+	}
+}
+
+var adminCategoryCmd = &cobra.Command{
+	Use:   "category",
+	Short: "Manages the categories packages are put in",
+}
+
+var adminCategorySetCmd = &cobra.Command{
+	Use:   "set [slug]",
+	Short: "Adds a category, or changes its title and description",
+	Long: `Adds a category owners can put their packages in with cr categorize, or
+changes the title and description of one. --title is required.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		category := &models.Category{Slug: args[0], Title: adminTitle}
+		if adminDesc != "" {
+			category.Description = &adminDesc
+		}
+		if err := helpers.ValidCategory(category); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		resp, err := client.Admin.SetCategory(context.Background(), args[0], adminTitle, adminDesc)
+		checkAdminResponse(resp, err, "")
+		helpers.Infof("Set category %s", args[0])
+	},
+}
+
+var adminCategoryRemoveCmd = &cobra.Command{
+	Use:   "remove [slug]",
+	Short: "Removes a category, its packages are taken out of it",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+
+		client := newClient()
+		resp, err := client.Admin.RemoveCategory(context.Background(), args[0])
+		checkAdminResponse(resp, err, fmt.Sprintf("Category %s not found", args[0]))
+		helpers.Infof("Removed category %s", args[0])
+	},
+}
+
+var adminCollectionCmd = &cobra.Command{
+	Use:   "collection",
+	Short: "Manages the curated collections of packages",
+}
+
+var adminCollectionSetCmd = &cobra.Command{
+	Use:   "set [slug] [package...]",
+	Short: "Adds a collection, or replaces its title, description and packages",
+	Long: `Adds a collection listing packages in the order given, or replaces an existing
+one. Only published public packages can be listed. --title is required.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			exit1(cmd.UsageString())
+		}
+		collection := &models.Collection{Slug: args[0], Title: adminTitle, Packages: args[1:]}
+		if adminDesc != "" {
+			collection.Description = &adminDesc
+		}
+		if err := helpers.ValidCollection(collection); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		resp, err := client.Admin.SetCollection(context.Background(), args[0], adminTitle, adminDesc, args[1:])
+		checkAdminResponse(resp, err, "")
+		helpers.Infof("Set collection %s", args[0])
+	},
+}
+
+var adminCollectionRemoveCmd = &cobra.Command{
+	Use:   "remove [slug]",
+	Short: "Removes a collection, the packages it lists stay",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+
+		client := newClient()
+		resp, err := client.Admin.RemoveCollection(context.Background(), args[0])
+		checkAdminResponse(resp, err, fmt.Sprintf("Collection %s not found", args[0]))
+		helpers.Infof("Removed collection %s", args[0])
+	},
+}
+
+// checkAdminResponse exits unless an admin request succeeded, notFound is the
+// message shown for a 404
+func checkAdminResponse(resp *http.Response, err error, notFound string) {
+	if resp == nil {
+		exit1(err.Error())
+	}
+	switch resp.StatusCode {
+	case 200, 204:
+	case 401:
+		exit1("Login with `cr login` first")
+	case 403:
+		exit1("Only registry admins can moderate, with a token that has the admin scope")
+	case 404:
+		if notFound == "" {
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+		exit1(notFound)
+	default:
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+}
+
+func init() {
+	adminSuspendCmd.Flags().StringVar(&adminReason, "reason", "", "Why the user is suspended")
+	adminRemoveCmd.Flags().StringVar(&adminReason, "reason", "", "Why the package is removed")
+	adminRemoveCmd.Flags().BoolVar(&adminReserve, "reserve", false, "Reserve the name so it can't be published again")
+	adminRemoveCmd.Flags().BoolVarP(&adminYes, "yes", "y", false, "Remove without asking for confirmation")
+	adminReserveCmd.Flags().StringVar(&adminReason, "reason", "", "Why the name is reserved")
+	adminReportsCmd.Flags().StringVar(&adminStatus, "status", helpers.ReportOpen, "Only list reports with this status: open, dismissed, removed or all")
+	adminDismissCmd.Flags().StringVar(&adminReason, "reason", "", "Why the report is dismissed")
+	for _, cmd := range []*cobra.Command{adminCategorySetCmd, adminCollectionSetCmd} {
+		cmd.Flags().StringVar(&adminTitle, "title", "", "Title shown by cr browse")
+		cmd.Flags().StringVar(&adminDesc, "description", "", "Description shown by cr browse")
+	}
+	adminRemoveCmd.ValidArgsFunction = completeRegistry
+	adminCmd.AddCommand(adminSuspendCmd)
+	adminCmd.AddCommand(adminUnsuspendCmd)
+	adminCmd.AddCommand(adminRemoveCmd)
+	adminCmd.AddCommand(adminReserveCmd)
+	adminCmd.AddCommand(adminUnreserveCmd)
+	adminCmd.AddCommand(adminReservedCmd)
+	adminCmd.AddCommand(adminReportsCmd)
+	adminCmd.AddCommand(adminDismissCmd)
+	adminCmd.AddCommand(adminReopenCmd)
+	adminCategoryCmd.AddCommand(adminCategorySetCmd)
+	adminCategoryCmd.AddCommand(adminCategoryRemoveCmd)
+	adminCmd.AddCommand(adminCategoryCmd)
+	adminCollectionCmd.AddCommand(adminCollectionSetCmd)
+	adminCollectionCmd.AddCommand(adminCollectionRemoveCmd)
+	adminCmd.AddCommand(adminCollectionCmd)
+	Root.AddCommand(adminCmd)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage other names packages can be run by",
+	Long: `Aliases are other names for packages kept in the client config, cr run, exec,
+logs and stop accept them in place of the package name.`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add [name]=[package]",
+	Short: "Adds or replaces an alias",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		alias, err := helpers.ParseAlias(args[0])
+		if err != nil {
+			exit1(err.Error())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+		if _, ok := state.Packages[alias.Name]; ok {
+			helpers.Warnf("%s is an installed package, the alias will be run instead of it", alias.Name)
+		}
+		if err = helpers.SetAlias(alias.Name, alias.Package); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("%s now runs %s", alias.Name, alias.Package)
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists every alias",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		aliases := helpers.Aliases()
+		render(aliases, func() {
+			if len(aliases) == 0 {
+				helpers.Infof("No aliases, add one with `cr alias add [name]=[package]`")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ALIAS\tPACKAGE")
+			for _, a := range aliases {
+				fmt.Fprintf(w, "%s\t%s\n", a.Name, a.Package)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Removes an alias",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if helpers.ResolveAlias(args[0]) == args[0] {
+			exit1(fmt.Sprintf("%s isn't an alias", args[0]))
+		}
+		if err := helpers.SetAlias(args[0], ""); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Removed alias %s", args[0])
+	},
+}
+
+// completeAliases completes the argument with alias names
+func completeAliases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, a := range helpers.Aliases() {
+		if strings.HasPrefix(a.Name, toComplete) {
+			names = append(names, a.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	aliasRemoveCmd.ValidArgsFunction = completeAliases
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+	Root.AddCommand(aliasCmd)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+	"github.com/sunshinekitty/cr/client"
+)
+
+// defaultAuditLimit is how many audit log entries cr audit shows without --limit
+const defaultAuditLimit = 50
+
+var auditOptions client.AuditOptions
+
+var auditCmd = &cobra.Command{
+	Use:   "audit [package]",
+	Short: "Shows who changed a package, when and from where",
+	Long: `Shows the audit log of a package you own, newest first: every publish, yank,
+deprecation, ownership change, transfer and webhook change, who made it and
+from which IP. Without a package the whole registry's log is shown, including
+tokens and orgs, which only admins listed in the registry's server.toml can.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit1(cmd.UsageString())
+		}
+		if auditOptions.Action != "" {
+			if err := helpers.ValidAuditAction(auditOptions.Action); err != nil {
+				exit1(err.Error())
+			}
+		}
+
+		client := newClient()
+		var (
+			entries []models.AuditEntry
+			resp    *http.Response
+			err     error
+		)
+		if len(args) == 1 {
+			if !helpers.ValidPackageName(args[0]) {
+				exit1("Invalid package")
+			}
+			entries, resp, err = client.Audit.ListPackage(context.Background(), args[0], &auditOptions)
+		} else {
+			entries, resp, err = client.Audit.List(context.Background(), &auditOptions)
+		}
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 401:
+			exit1("Login with `cr login` first")
+		case 403:
+			if len(args) == 1 {
+				exit1(fmt.Sprintf("Only owners of %s can read its audit log", args[0]))
+			}
+			exit1("Only registry admins can read the whole audit log, give a package you own")
+		case 404:
+			exit1(fmt.Sprintf("Package %s not found", args[0]))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(entries, func() { printAuditLog(entries, len(args) == 0) })
+	},
+}
+
+// printAuditLog prints audit log entries, with the package of each when they
+// aren't all of one
+func printAuditLog(entries []models.AuditEntry, packages bool) {
+	if len(entries) == 0 {
+		fmt.Println("No changes recorded")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if packages {
+		fmt.Fprintln(w, "TIME\tPACKAGE\tACTION\tACTOR\tIP\tDETAIL")
+	} else {
+		fmt.Fprintln(w, "TIME\tACTION\tACTOR\tIP\tDETAIL")
+	}
+	for _, e := range entries {
+		if packages {
+			fmt.Fprintf(w, "%s\t%s\t", auditTime(e.CreatedAt), optional(e.Package))
+		} else {
+			fmt.Fprintf(w, "%s\t", auditTime(e.CreatedAt))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Action, e.Actor, e.IP, optional(e.Detail))
+	}
+	w.Flush()
+}
+
+// auditTime shortens a timestamp to the second
+func auditTime(timestamp string) string {
+	if len(timestamp) < 19 {
+		return timestamp
+	}
+	return timestamp[:10] + " " + timestamp[11:19]
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditOptions.Actor, "actor", "", "Only show changes made by this user")
+	auditCmd.Flags().StringVar(&auditOptions.Action, "action", "", "Only show this action, such as publish, yank or owner.add")
+	auditCmd.Flags().IntVarP(&auditOptions.Limit, "limit", "n", defaultAuditLimit, "Show at most this many changes, 0 for every one")
+	auditCmd.ValidArgsFunction = completeRegistry
+	Root.AddCommand(auditCmd)
+}
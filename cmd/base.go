@@ -1,19 +1,117 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/client"
 )
 
 // Root is our command object
 var Root = &cobra.Command{
 	Use:   "cr",
 	Short: "Package manager for container based applications",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		switch {
+		case logVerbose && logQuiet:
+			exit1("Only one of --verbose and --quiet can be given")
+		case logVerbose:
+			helpers.SetLogLevel(helpers.LogDebug)
+		case logQuiet:
+			helpers.SetLogLevel(helpers.LogError)
+		}
+		helpers.SetLogJSON(logJSON)
+		// CI and output piped elsewhere mean nobody is watching to answer prompts
+		helpers.SetInteractive(!helpers.CIFromEnv() && isTerminal(os.Stdout))
+		if f := viper.ConfigFileUsed(); f != "" {
+			helpers.Debugf("using config %s", f)
+		}
+		if err := helpers.ValidClientEnv(); err != nil {
+			exit1(err.Error())
+		}
+		if f, err := helpers.MergeProjectConfig(); err != nil {
+			exit1(err.Error())
+		} else if f != "" {
+			helpers.Debugf("using project config %s", f)
+		}
+
+		if !validOutputFormat(outputFormat) {
+			exit1(fmt.Sprintf("Output \"%s\" is invalid, use table, json or yaml", outputFormat))
+		}
+		if err := selectRegistry(args); err != nil {
+			exit1(err.Error())
+		}
+	},
+}
+
+// pullRecordTimeout bounds how long counting a pull may hold up a command
+const pullRecordTimeout = 2 * time.Second
+
+var (
+	logVerbose bool
+	logQuiet   bool
+	logJSON    bool
+)
+
+func init() {
+	Root.PersistentFlags().BoolVar(&logVerbose, "verbose", false, "Show docker commands, registry requests and timings")
+	Root.PersistentFlags().BoolVarP(&logQuiet, "quiet", "q", false, "Only print errors")
+	Root.PersistentFlags().BoolVar(&logJSON, "log-json", false, "Print log messages to stderr as json")
+	Root.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format of search, info, list, versions, stats and owner list, table, json or yaml")
+}
+
+// newClient returns a Crackle API client for the configured endpoint,
+// authenticated when logged in
+func newClient() *client.Client {
+	c := client.NewClient(&http.Client{Transport: loggingTransport{http.DefaultTransport}})
+	c.BaseURL, _ = url.Parse(viper.GetString("crackle.api"))
+	if credentials, err := helpers.LoadCredentials(); err == nil {
+		c.Token = credentials.Token
+	}
+	c.Mirrors = helpers.Mirrors()
+	c.OTP = otpCode
+	return c
+}
+
+// recordPull counts a pull of a package version with Crackle, failing to count
+// it never fails the command
+func recordPull(ctx context.Context, client *client.Client, name string, version string) {
+	if client.PullClient == "" {
+		client.PullClient = helpers.PullClientID()
+	}
+	ctx, cancel := context.WithTimeout(ctx, pullRecordTimeout)
+	defer cancel()
+	if _, err := client.Package.RecordPull(ctx, name, version); err != nil {
+		helpers.Debugf("couldn't record pull of %s %s: %s", name, version, err)
+	}
+}
+
+// loggingTransport logs every registry request and how long it took
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		helpers.Debugf("%s %s failed after %s: %s", req.Method, req.URL, time.Since(start), err)
+		return nil, err
+	}
+	helpers.Debugf("%s %s %d in %s", req.Method, req.URL, resp.StatusCode, time.Since(start))
+	return resp, nil
 }
 
 func exit1(exitString string) {
-	fmt.Println(exitString)
+	helpers.Error(exitString)
 	os.Exit(1)
 }
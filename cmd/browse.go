@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+	"github.com/sunshinekitty/cr/client"
+)
+
+var browseLimit int
+
+var browseCmd = &cobra.Command{
+	Use:   "browse [category or collection]",
+	Short: "Browse packages by category or curated collection",
+	Long: `Lists the registry's categories and the collections of packages its admins
+curated. Given a category, lists the packages in it, most pulled first, or
+given a collection, the packages it lists in its order.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit1(cmd.UsageString())
+		}
+		if len(args) == 0 {
+			browseAll()
+			return
+		}
+		if !helpers.ValidCategorySlug(args[0]) {
+			exit1(fmt.Sprintf("No category or collection %s, see cr browse", args[0]))
+		}
+		if browseLimit < 1 || browseLimit > helpers.MaxSearchLimit {
+			exit1(fmt.Sprintf("Limit should be between 1 and %d", helpers.MaxSearchLimit))
+		}
+
+		opts := &client.SearchOptions{
+			Category: args[0],
+			Sort:     helpers.SearchSortPulls,
+			Limit:    browseLimit,
+		}
+		client := newClient()
+		pkgs, resp, err := client.Package.SearchPackages(context.Background(), opts)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+			packages := make([]models.Package, len(pkgs))
+			for i := range pkgs {
+				packages[i] = pkgs[i].Package
+			}
+			render(pkgs, func() {
+				printBrowsed(packages)
+			})
+			return
+		case 404:
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		// Not a category, so it may be a collection
+		collection, resp, err := client.Package.GetCollection(context.Background(), args[0])
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 404:
+			exit1(fmt.Sprintf("No category or collection %s, see cr browse", args[0]))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(collection, func() {
+			fmt.Println(collection.Title)
+			if collection.Description != nil {
+				fmt.Println(*collection.Description)
+			}
+			fmt.Println()
+			printBrowsed(collection.Package)
+		})
+	},
+}
+
+// browseAll prints every category and collection
+func browseAll() {
+	client := newClient()
+	categories, resp, err := client.Package.ListCategories(context.Background())
+	if resp == nil {
+		exit1(err.Error())
+	}
+	if err != nil {
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+	collections, resp, err := client.Package.ListCollections(context.Background())
+	if resp == nil {
+		exit1(err.Error())
+	}
+	if err != nil {
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+
+	all := struct {
+		Categories  []models.Category
+		Collections []models.Collection
+	}{categories, collections}
+	render(all, func() {
+		if len(categories) == 0 && len(collections) == 0 {
+			fmt.Println("No categories or collections")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if len(categories) != 0 {
+			fmt.Fprintln(w, "CATEGORY\tTITLE\tPACKAGES\tDESCRIPTION")
+			for _, c := range categories {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", c.Slug, c.Title, c.Packages, optional(c.Description))
+			}
+		}
+		if len(collections) != 0 {
+			if len(categories) != 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, "COLLECTION\tTITLE\tPACKAGES\tDESCRIPTION")
+			for _, c := range collections {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", c.Slug, c.Title, len(c.Packages), optional(c.Description))
+			}
+		}
+		w.Flush()
+	})
+}
+
+// printBrowsed prints the packages of a category or collection
+func printBrowsed(pkgs []models.Package) {
+	if len(pkgs) == 0 {
+		fmt.Println("No packages found")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tOWNER\tPULLS\tUPDATED\tDESCRIPTION")
+	for _, p := range pkgs {
+		description := ""
+		if p.ShortDescription != nil {
+			description = truncate(strings.Join(strings.Fields(*p.ShortDescription), " "), 50)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", p.Name, p.Version, p.Owner, p.Pulls, date(p.UpdatedAt), description)
+	}
+	w.Flush()
+}
+
+func init() {
+	browseCmd.Flags().IntVarP(&browseLimit, "limit", "l", helpers.DefaultSearchLimit, "Most packages of a category to show")
+	Root.AddCommand(browseCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var bumpGitTag bool
+
+var bumpCmd = &cobra.Command{
+	Use:       "bump [major|minor|patch] [path]",
+	Short:     "Bumps the version tagged on a package manifest's repository",
+	ValidArgs: []string{helpers.BumpMajor, helpers.BumpMinor, helpers.BumpPatch},
+	Long: `Bumps the version tagged on a package manifest's repository, so
+repository = "me/tool:1.2.3" becomes "me/tool:1.3.0" after cr bump minor.
+
+Only the repository is rewritten, comments and formatting in the manifest are
+kept. The bumped manifest is validated, and with --git-tag the new version is
+tagged in the git repository holding it. Path defaults to the current directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || len(args) > 2 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidBumpPart(args[0]) {
+			exit1(fmt.Sprintf("Unknown version part \"%s\", use major, minor or patch", args[0]))
+		}
+		path := "."
+		if len(args) == 2 {
+			path = args[1]
+		}
+		manifest, err := helpers.ManifestPath(path)
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		from, to, err := helpers.BumpManifestFile(manifest, args[0])
+		if err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Bumped %s from %s to %s", manifest, from, to)
+
+		pt, err := helpers.ConfigFileToPackageTomlContext(context.Background(), manifest)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.ValidPackageToml(pt); err != nil {
+			exit1(fmt.Sprintf("error: %s", err))
+		}
+
+		if bumpGitTag {
+			git := exec.Command("git", "tag", to)
+			git.Dir = filepath.Dir(manifest)
+			git.Stdout = os.Stdout
+			git.Stderr = os.Stderr
+			if err = git.Run(); err != nil {
+				exit1(fmt.Sprintf("Couldn't tag %s in git: %s", to, err))
+			}
+			helpers.Infof("Tagged %s in git", to)
+		}
+	},
+}
+
+func init() {
+	bumpCmd.Flags().BoolVar(&bumpGitTag, "git-tag", false, "Tag the new version in git")
+	Root.AddCommand(bumpCmd)
+}
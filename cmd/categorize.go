@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var categorizeClear bool
+
+var categorizeCmd = &cobra.Command{
+	Use:   "categorize [package] [category...]",
+	Short: "Puts a package in categories people browse",
+	Long: `Puts a package in up to 3 of the registry's categories, replacing those it was
+in, so it's listed by cr browse. Without categories the package's categories are
+shown, --clear takes it out of every category. cr browse lists the categories.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			exit1(cmd.UsageString())
+		}
+		name, categories := args[0], args[1:]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		if len(categories) == 0 && !categorizeClear {
+			current, resp, err := client.Package.GetCategories(context.Background(), name)
+			if resp == nil {
+				exit1(err.Error())
+			}
+			switch resp.StatusCode {
+			case 200:
+			case 404:
+				exit1(fmt.Sprintf("Package %s not found", name))
+			default:
+				exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+			}
+			render(current, func() {
+				if len(current) == 0 {
+					fmt.Printf("%s isn't in any category\n", name)
+					return
+				}
+				fmt.Println(strings.Join(current, "\n"))
+			})
+			return
+		}
+		if categorizeClear && len(categories) != 0 {
+			exit1("Give categories or --clear, not both")
+		}
+		if err := helpers.ValidPackageCategories(categories); err != nil {
+			exit1(err.Error())
+		}
+
+		resp, err := client.Package.SetCategories(context.Background(), name, categories)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			if categorizeClear {
+				helpers.Infof("Took %s out of every category", name)
+			} else {
+				helpers.Infof("Put %s in %s", name, strings.Join(categories, ", "))
+			}
+		case 401:
+			exit1("Login with `cr login` first")
+		case 404:
+			exit1(fmt.Sprintf("Package %s not found", name))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+func init() {
+	categorizeCmd.ValidArgsFunction = completeRegistry
+	categorizeCmd.Flags().BoolVar(&categorizeClear, "clear", false, "Take the package out of every category")
+	Root.AddCommand(categorizeCmd)
+}
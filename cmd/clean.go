@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var (
+	cleanAll       bool
+	cleanOlderThan string
+	cleanDryRun    bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Removes stopped containers and unused images of packages",
+	Long: `Removes the stopped containers cr started and the images of packages that
+aren't used any more, such as versions from before an upgrade or images left
+untagged when a tag was pulled again.
+
+--all also removes the images of installed packages, they're pulled again the
+next time they're run. Images used by running packages are never removed.
+--older-than only removes containers and images created before an age such as
+12h, 30d or 2w.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		before := time.Now()
+		if cleanOlderThan != "" {
+			age, err := helpers.ParseAge(cleanOlderThan)
+			if err != nil {
+				exit1(err.Error())
+			}
+			before = before.Add(-age)
+		}
+
+		ctx := context.Background()
+		plan := planClean(ctx, before)
+		if len(plan.Containers) == 0 && len(plan.Images) == 0 {
+			helpers.Infof("Nothing to clean")
+			return
+		}
+
+		if cleanDryRun {
+			for _, c := range plan.Containers {
+				helpers.Infof("Would remove container %s of %s", c.Name, c.Package)
+			}
+			for _, i := range plan.Images {
+				helpers.Infof("Would remove image %s (%s)", i.Reference(), helpers.FormatSize(i.Size))
+			}
+			helpers.Infof("Would reclaim up to %s", helpers.FormatSize(plan.Size()))
+			return
+		}
+
+		// A container or image that can't be removed is skipped so the rest are
+		removed := &helpers.CleanPlan{}
+		for _, c := range plan.Containers {
+			if _, err := helpers.DockerOutputContext(ctx, "rm", c.ID); err != nil {
+				helpers.Warnf("Couldn't remove container %s: %s", c.Name, err)
+				continue
+			}
+			removed.Containers = append(removed.Containers, c)
+		}
+		for _, i := range plan.Images {
+			if _, err := helpers.DockerOutputContext(ctx, "rmi", i.Reference()); err != nil {
+				helpers.Warnf("Couldn't remove image %s: %s", i.Reference(), err)
+				continue
+			}
+			helpers.Debugf("removed image %s (%s)", i.Reference(), helpers.FormatSize(i.Size))
+			removed.Images = append(removed.Images, i)
+		}
+		helpers.Infof("Removed %d containers and %d images, reclaimed up to %s",
+			len(removed.Containers), len(removed.Images), helpers.FormatSize(removed.Size()))
+	},
+}
+
+// planClean finds what cr clean removes, the images of installed and pinned
+// packages and of cr's containers are the ones considered
+func planClean(ctx context.Context, before time.Time) *helpers.CleanPlan {
+	state, err := helpers.LoadState()
+	if err != nil {
+		exit1(err.Error())
+	}
+	lock, err := helpers.LoadLock(helpers.LockPath())
+	if err != nil {
+		exit1(err.Error())
+	}
+	stopped, err := helpers.ListStoppedContainersContext(ctx)
+	if err != nil {
+		exit1(err.Error())
+	}
+	running, err := helpers.ListContainersContext(ctx, "")
+	if err != nil {
+		exit1(err.Error())
+	}
+
+	var installed, runningImages []string
+	for _, p := range state.Installed() {
+		installed = append(installed, p.Repository)
+	}
+	for _, p := range lock.Locked() {
+		installed = append(installed, p.Repository+"@"+p.Digest)
+	}
+	for _, c := range running {
+		runningImages = append(runningImages, c.Image)
+	}
+
+	repositories := make(map[string]bool)
+	for _, image := range installed {
+		repositories[helpers.ImageRepository(image)] = true
+	}
+	for _, c := range append(stopped, running...) {
+		repositories[helpers.ImageRepository(c.Image)] = true
+	}
+	var images []helpers.Image
+	for repository := range repositories {
+		found, err := helpers.ListImagesContext(ctx, repository)
+		if err != nil {
+			exit1(err.Error())
+		}
+		images = append(images, found...)
+	}
+
+	return helpers.PlanClean(stopped, images, runningImages, installed, cleanAll, before)
+}
+
+func init() {
+	cleanCmd.Flags().BoolVarP(&cleanAll, "all", "a", false, "Also remove the images of installed packages")
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "", "Only remove containers and images created before this age, such as 30d")
+	cleanCmd.Flags().BoolVarP(&cleanDryRun, "dry-run", "n", false, "Print what would be removed without removing it")
+	Root.AddCommand(cleanCmd)
+}
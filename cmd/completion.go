@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/client"
+)
+
+// completionTimeout bounds how long completion waits on docker or the registry
+const completionTimeout = 2 * time.Second
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generates a shell completion script",
+	Long: `Generates a shell completion script for cr, package names are completed from
+installed packages, running packages or the registry depending on the command.
+
+  bash:       source <(cr completion bash)
+  zsh:        cr completion zsh > "${fpath[1]}/_cr"
+  fish:       cr completion fish > ~/.config/fish/completions/cr.fish
+  powershell: cr completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+
+		var err error
+		switch args[0] {
+		case "bash":
+			err = Root.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			err = Root.GenZshCompletion(os.Stdout)
+		case "fish":
+			err = Root.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			err = Root.GenPowerShellCompletionWithDesc(os.Stdout)
+		default:
+			exit1(cmd.UsageString())
+		}
+		if err != nil {
+			exit1(err.Error())
+		}
+	},
+}
+
+// completeInstalled completes the package argument with installed packages
+func completeInstalled(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	state, err := helpers.LoadState()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, p := range state.Installed() {
+		if strings.HasPrefix(p.Name, toComplete) {
+			names = append(names, p.Name)
+		}
+	}
+	for _, a := range helpers.Aliases() {
+		if strings.HasPrefix(a.Name, toComplete) {
+			names = append(names, a.Name+"\talias of "+a.Package)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRunning completes the package argument with running packages
+func completeRunning(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	containers, err := helpers.ListContainersContext(ctx, "")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, c := range containers {
+		if !seen[c.Package] && strings.HasPrefix(c.Package, toComplete) {
+			names = append(names, c.Package)
+			seen[c.Package] = true
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRegistry completes the package argument by searching the registry
+func completeRegistry(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	// Search also matches descriptions, only names with the prefix are wanted
+	pkgs, _, err := newClient().Package.SearchPackages(ctx, &client.SearchOptions{
+		Query: toComplete,
+		Sort:  helpers.SearchSortName,
+		Limit: helpers.MaxSearchLimit,
+	})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, p := range pkgs {
+		if strings.HasPrefix(p.Name, toComplete) {
+			names = append(names, p.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	Root.AddCommand(completionCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Reads and changes the cr client config",
+	Long: `Reads and changes the cr client config. Values are validated before they're
+written, list settings take each value as a separate argument.
+
+Every setting can also be set by a CR_ environment variable named after its key,
+such as CR_CRACKLE_API or CR_SEARCH_LIMIT, with list values separated by spaces.
+Flags win over them, and they win over a project's .cr.toml and the client
+config. CR_REGISTRY_URL and CR_REGISTRY are short for CR_CRACKLE_API and
+CR_CRACKLE_REGISTRY, and CR_AUTH_TOKEN for CR_TOKEN.`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists every setting and its current value",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		fmt.Printf("# %s\n", helpers.ClientConfigPath())
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tDESCRIPTION")
+		for _, s := range helpers.Settings {
+			value := s.Value()
+			if env := helpers.EnvOverride(s.Key); env != "" {
+				value = fmt.Sprintf("%s (%s)", value, env)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, value, s.Description)
+		}
+		w.Flush()
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Prints the current value of a setting",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		setting, err := helpers.LookupSetting(args[0])
+		if err != nil {
+			exit1(err.Error())
+		}
+		fmt.Println(setting.Value())
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value...]",
+	Short: "Changes a setting",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			exit1(cmd.UsageString())
+		}
+		setting, err := helpers.LookupSetting(args[0])
+		if err != nil {
+			exit1(err.Error())
+		}
+		value, err := setting.Parse(args[1:])
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.SetClientConfig(setting.Key, value); err != nil {
+			exit1(err.Error())
+		}
+		if env := helpers.EnvOverride(setting.Key); env != "" {
+			helpers.Warnf("%s is set, it wins over the client config", env)
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset [key]",
+	Short: "Removes a setting, returning it to the default",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		setting, err := helpers.LookupSetting(args[0])
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.SetClientConfig(setting.Key, nil); err != nil {
+			exit1(err.Error())
+		}
+	},
+}
+
+// completeSettings completes the key argument with known settings
+func completeSettings(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var keys []string
+	for _, s := range helpers.Settings {
+		keys = append(keys, s.Key)
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	configGetCmd.ValidArgsFunction = completeSettings
+	configSetCmd.ValidArgsFunction = completeSettings
+	configUnsetCmd.ValidArgsFunction = completeSettings
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configUnsetCmd)
+	Root.AddCommand(configCmd)
+}
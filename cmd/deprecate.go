@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var (
+	deprecateMessage string
+	deprecateUndo    bool
+)
+
+var deprecateCmd = &cobra.Command{
+	Use:   "deprecate [package]",
+	Short: "Marks a package as deprecated",
+	Long: `Deprecates every version of a package with a message, such as the package to
+use instead. The message is shown whenever the package is installed or run, and
+versions published later stay deprecated. --undo reverses a deprecation.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+		if deprecateUndo {
+			deprecateMessage = ""
+		} else if err := helpers.ValidDeprecation(deprecateMessage); err != nil {
+			exit1(fmt.Sprintf("Provide why %s is deprecated with --message: %s", name, err))
+		}
+
+		client := newClient()
+		resp, err := client.Package.Deprecate(context.Background(), name, deprecateMessage)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			if deprecateUndo {
+				helpers.Infof("Restored %s", name)
+			} else {
+				helpers.Infof("Deprecated %s", name)
+			}
+		case 404:
+			exit1(fmt.Sprintf("Package %s not found", name))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+func init() {
+	deprecateCmd.ValidArgsFunction = completeRegistry
+	deprecateCmd.Flags().StringVarP(&deprecateMessage, "message", "m", "", "Why the package is deprecated, such as the package to use instead")
+	deprecateCmd.Flags().BoolVar(&deprecateUndo, "undo", false, "Restore a deprecated package")
+	Root.AddCommand(deprecateCmd)
+}
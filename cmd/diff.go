@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+	"github.com/sunshinekitty/cr/client"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [package] [version] [version]",
+	Short: "Shows what changed in a package between two published versions",
+	Long: `Shows what changed in a package between two published versions, field by
+field: the image, command, ports, volumes, environment and descriptions. With
+one version it is compared to the latest version.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 || len(args) > 3 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		ctx := context.Background()
+		a := getPackageVersion(ctx, client, args[0], args[1])
+		to := ""
+		if len(args) == 3 {
+			to = args[2]
+		}
+		b := getPackageVersion(ctx, client, args[0], to)
+
+		diffs, err := helpers.DiffPackages(a, b)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if diffs == nil {
+			diffs = []helpers.PackageDiff{}
+		}
+		render(diffs, func() {
+			if len(diffs) == 0 {
+				fmt.Printf("No differences between %s %s and %s\n", args[0], a.Version, b.Version)
+				return
+			}
+			for _, d := range diffs {
+				fmt.Println(d)
+			}
+		})
+	},
+}
+
+// getPackageVersion fetches a single package version, or the latest version when
+// version is empty, exiting when it can't be found. A short name is looked up in
+// the default namespace first.
+func getPackageVersion(ctx context.Context, client *client.Client, name string, version string) *models.Package {
+	if namespaced := helpers.QualifyPackageName(helpers.DefaultNamespace(), name); namespaced != name {
+		pkg, resp, _ := client.Package.GetPackageVersion(ctx, namespaced, version)
+		if resp != nil && resp.StatusCode == 200 {
+			return pkg
+		}
+	}
+	pkg, resp, err := client.Package.GetPackageVersion(ctx, name, version)
+	if resp == nil {
+		exit1(err.Error())
+	}
+	switch resp.StatusCode {
+	case 200:
+		return pkg
+	case 404:
+		// Private packages are only found when logged in
+		hint := ""
+		if client.Token == "" {
+			hint = ", login first if it's private"
+		}
+		if version == "" {
+			exit1(fmt.Sprintf("Package %s not found%s", name, hint))
+		}
+		exit1(fmt.Sprintf("Package %s version %s not found%s", name, version, hint))
+	default:
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+	return nil
+}
+
+func init() {
+	diffCmd.ValidArgsFunction = completeRegistry
+	Root.AddCommand(diffCmd)
+}
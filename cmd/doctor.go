@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// doctorTimeout bounds each check that talks to docker or the registry
+const doctorTimeout = 10 * time.Second
+
+// diagnosis represents the result of a cr doctor check, fix is how to resolve
+// a problem and warn marks one that doesn't stop cr working
+type diagnosis struct {
+	detail string
+	fix    string
+	failed bool
+	warn   bool
+}
+
+// doctorCheck represents a single cr doctor check
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) diagnosis
+}
+
+var doctorChecks = []doctorCheck{
+	{"docker", checkDocker},
+	{"docker daemon", checkDockerDaemon},
+	{"config", checkConfig},
+	{"registry", checkRegistry},
+	{"login", checkLogin},
+	{"PATH", checkPath},
+	{"shims", checkShims},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Checks cr is set up correctly",
+	Long: `Checks docker, the client config, the registry, your login and package shims,
+printing how to fix anything that's wrong. Exits 1 when a check fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+
+		failed := false
+		for _, c := range doctorChecks {
+			ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+			d := c.run(ctx)
+			cancel()
+
+			status := "ok"
+			switch {
+			case d.failed:
+				status = "FAIL"
+				failed = true
+			case d.warn:
+				status = "warn"
+			}
+			fmt.Printf("[%s] %s: %s\n", status, c.name, d.detail)
+			if d.fix != "" && (d.failed || d.warn) {
+				fmt.Printf("       %s\n", d.fix)
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func checkDocker(ctx context.Context) diagnosis {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return diagnosis{detail: "docker isn't installed or isn't on your PATH",
+			fix: "Install docker from https://docs.docker.com/install/", failed: true}
+	}
+	client, _, _ := helpers.DockerVersionContext(ctx)
+	if client == "" {
+		return diagnosis{detail: "couldn't get the docker version",
+			fix: "Check `docker version` runs", failed: true}
+	}
+	if !helpers.DockerVersionAtLeast(client, helpers.MinDockerVersion) {
+		return diagnosis{detail: fmt.Sprintf("docker %s is older than %s", client, helpers.MinDockerVersion),
+			fix: "Upgrade docker", failed: true}
+	}
+	return diagnosis{detail: fmt.Sprintf("docker %s", client)}
+}
+
+func checkDockerDaemon(ctx context.Context) diagnosis {
+	_, server, err := helpers.DockerVersionContext(ctx)
+	if err != nil {
+		return diagnosis{detail: err.Error(),
+			fix: "Start the docker daemon and check your user can use it, usually by joining the docker group", failed: true}
+	}
+	return diagnosis{detail: fmt.Sprintf("daemon %s is reachable", server)}
+}
+
+func checkConfig(ctx context.Context) diagnosis {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return diagnosis{detail: "no config file, using the defaults"}
+	}
+	if err := helpers.ValidClientConfig(path); err != nil {
+		return diagnosis{detail: fmt.Sprintf("%s: %s", path, err),
+			fix: "Fix the value with `cr config set` or remove it with `cr config unset`", failed: true}
+	}
+	return diagnosis{detail: path}
+}
+
+func checkRegistry(ctx context.Context) diagnosis {
+	api := viper.GetString("crackle.api")
+	server, resp, err := newClient().Version.Server(ctx)
+	if err != nil || resp == nil || resp.StatusCode != 200 {
+		detail := fmt.Sprintf("couldn't reach %s", api)
+		if err != nil {
+			detail = fmt.Sprintf("%s: %s", detail, err)
+		}
+		return diagnosis{detail: detail,
+			fix: "Check your network connection and crackle.api with `cr config get crackle.api`", failed: true}
+	}
+	return diagnosis{detail: fmt.Sprintf("%s is running %s", api, server.Version)}
+}
+
+func checkLogin(ctx context.Context) diagnosis {
+	if _, err := helpers.LoadCredentials(); err == helpers.ErrNotLoggedIn {
+		return diagnosis{detail: "not logged in, only needed to publish",
+			fix: "Login with `cr login`", warn: true}
+	} else if err != nil {
+		return diagnosis{detail: err.Error(), fix: "Login again with `cr login`", failed: true}
+	}
+	identity, resp, err := newClient().Auth.WhoAmI(ctx)
+	if resp != nil && resp.StatusCode == 401 {
+		return diagnosis{detail: "your token is invalid or revoked",
+			fix: "Login again with `cr login`", failed: true}
+	}
+	if err != nil {
+		return diagnosis{detail: fmt.Sprintf("couldn't check your token: %s", err), warn: true}
+	}
+	return diagnosis{detail: fmt.Sprintf("logged in as %s", identity.Username)}
+}
+
+func checkPath(ctx context.Context) diagnosis {
+	if !helpers.BinDirOnPath() {
+		return diagnosis{detail: fmt.Sprintf("%s isn't on your PATH, packages can't be run by name", helpers.BinDir()),
+			fix: fmt.Sprintf("Add `export PATH=\"%s:$PATH\"` to your shell profile", helpers.BinDir()), warn: true}
+	}
+	return diagnosis{detail: fmt.Sprintf("%s is on your PATH", helpers.BinDir())}
+}
+
+func checkShims(ctx context.Context) diagnosis {
+	state, err := helpers.LoadState()
+	if err != nil {
+		return diagnosis{detail: err.Error(), failed: true}
+	}
+	var missing []string
+	installed := state.Installed()
+	for _, p := range installed {
+		info, err := os.Stat(helpers.ShimPath(p.Name))
+		if err != nil || info.Mode().Perm()&0111 == 0 {
+			missing = append(missing, p.Name)
+		}
+	}
+	if len(missing) != 0 {
+		return diagnosis{detail: fmt.Sprintf("missing or not executable for %s", strings.Join(missing, ", ")),
+			fix: "Reinstall each with `cr install [package]`", failed: true}
+	}
+	return diagnosis{detail: fmt.Sprintf("%d installed", len(installed))}
+}
+
+func init() {
+	Root.AddCommand(doctorCmd)
+}
@@ -1,45 +1,65 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"strings"
+	"os/exec"
 
-	"github.com/codeskyblue/go-sh"
 	"github.com/spf13/cobra"
 
 	"github.com/sunshinekitty/cr/helpers"
 )
 
+// execDefaultCmd is run by cr exec when no command is given
+const execDefaultCmd = "sh"
+
 var execCmd = &cobra.Command{
-	Use:   "exec [package]",
-	Short: "Executes package based on package config",
+	Use:   "exec [package] [command...]",
+	Short: "Runs a command inside a running package",
+	Long: `Runs a command inside a running package, by default an interactive shell. When
+the package is running more than once the newest container is used.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) < 1 {
-			exit1("Provide package to exec")
+			exit1(cmd.UsageString())
 		}
-
-		pkg := args[0]
-		if len(args) > 1 || !helpers.ValidPackageName(pkg) {
+		args[0] = helpers.ResolveAlias(args[0])
+		if !helpers.ValidPackageName(args[0]) {
 			exit1("Invalid package")
 		}
+		command := args[1:]
+		if len(command) == 0 {
+			command = []string{execDefaultCmd}
+		}
 
-		configFile := fmt.Sprintf("%s/packages/%s.toml", helpers.ConfigDir(), pkg)
+		ctx := context.Background()
+		container, err := helpers.FindContainerContext(ctx, args[0])
+		if err == helpers.ErrNotRunning {
+			exit1(fmt.Sprintf("Package %s isn't running, start it with `cr run --detach %s`", args[0], args[0]))
+		} else if err != nil {
+			exit1(err.Error())
+		}
 
-		if _, err := os.Stat(configFile); os.IsNotExist(err) {
-			fmt.Printf("Config for package %s doesn't exist\n", pkg)
-			exit1("Download a package with `cr get [package]`")
+		// Only allocate a tty when there's a terminal to attach it to
+		execArgs := []string{"exec", "-i"}
+		if helpers.Interactive() && isTerminal(os.Stdin) {
+			execArgs = append(execArgs, "-t")
 		}
+		execArgs = append(append(execArgs, container.ID), command...)
 
-		runCmd, runArgs, err := helpers.ConfigFileToCmd(configFile)
-		if err != nil {
+		name, dockerArgs := helpers.DockerCmd(execArgs...)
+		err = helpers.RunCmdContext(ctx, name, dockerArgs)
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		} else if err != nil {
 			exit1(err.Error())
 		}
-
-		sh.Command(runCmd, strings.Split(runArgs, " ")).Run()
 	},
 }
 
 func init() {
+	execCmd.ValidArgsFunction = completeRunning
+	// Flags after the package belong to the command run in the container
+	execCmd.Flags().SetInterspersed(false)
 	Root.AddCommand(execCmd)
 }
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/helpers/k8s"
+	"github.com/sunshinekitty/cr/models"
+)
+
+var exportRestart string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a package to run without cr, or the installed packages",
+}
+
+var exportListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Prints every installed package and version, for cr import",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.EncodePackageSet(os.Stdout, helpers.StateToPackageSet(state)); err != nil {
+			exit1(err.Error())
+		}
+	},
+}
+
+var exportComposeCmd = &cobra.Command{
+	Use:   "compose [package] [version]",
+	Short: "Prints a docker-compose service for a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg := exportPackage(cmd, args)
+
+		compose, err := helpers.PackageToCompose(pkg)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.EncodeCompose(os.Stdout, compose); err != nil {
+			exit1(err.Error())
+		}
+	},
+}
+
+var exportK8sCmd = &cobra.Command{
+	Use:   "k8s [package] [version]",
+	Short: "Prints Kubernetes manifests for a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg := exportPackage(cmd, args)
+
+		objects, err := k8s.FromPackage(pkg)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = k8s.Encode(os.Stdout, objects); err != nil {
+			exit1(err.Error())
+		}
+	},
+}
+
+var exportSystemdCmd = &cobra.Command{
+	Use:   "systemd [package] [version]",
+	Short: "Prints a user-level systemd unit for a package",
+	Long: `Prints a user-level systemd unit that runs a package as a daemon, save it to
+~/.config/systemd/user/cr-[package].service and enable it with
+systemctl --user enable --now cr-[package]`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg := exportPackage(cmd, args)
+
+		unit, err := helpers.PackageToSystemdUnit(pkg, exportRestart)
+		if err != nil {
+			exit1(err.Error())
+		}
+		fmt.Print(unit)
+	},
+}
+
+// exportPackage fetches the package and optional version named by an export
+// sub-command's args
+func exportPackage(cmd *cobra.Command, args []string) *models.Package {
+	if len(args) < 1 || len(args) > 2 {
+		exit1(cmd.UsageString())
+	}
+	if !helpers.ValidPackageName(args[0]) {
+		exit1("Invalid package")
+	}
+	version := ""
+	if len(args) == 2 {
+		version = args[1]
+	}
+
+	client := newClient()
+	return getPackageVersion(context.Background(), client, args[0], version)
+}
+
+func init() {
+	exportCmd.AddCommand(exportComposeCmd)
+	exportCmd.AddCommand(exportK8sCmd)
+	exportSystemdCmd.Flags().StringVarP(&exportRestart, "restart", "r", "unless-stopped", "Docker restart policy mapped to systemd's Restart=")
+	exportCmd.AddCommand(exportSystemdCmd)
+	exportCmd.AddCommand(exportListCmd)
+	Root.AddCommand(exportCmd)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var importLatest bool
+
+var importCmd = &cobra.Command{
+	Use:   "import [path]",
+	Short: "Installs every package in a file written by cr export list",
+	Long: `Installs every package in a file written by cr export list, at the version it
+lists. Packages already installed at that version are skipped. Use - to read
+from stdin.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		f := os.Stdin
+		if args[0] != "-" {
+			var err error
+			if f, err = os.Open(args[0]); err != nil {
+				exit1(err.Error())
+			}
+			defer f.Close()
+		}
+		set, err := helpers.DecodePackageSet(f)
+		if err != nil {
+			exit1(err.Error())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		ctx := context.Background()
+		installed := 0
+		for _, p := range set.Packages {
+			version := p.Version
+			if importLatest {
+				version = ""
+			}
+			pkg := getPackageVersion(ctx, client, p.Name, version)
+			if current, ok := state.Packages[p.Name]; ok && current.Version == pkg.Version {
+				continue
+			}
+			if err = installPackage(ctx, client, state, pkg); err != nil {
+				exit1(err.Error())
+			}
+			// Saved as it goes so a failure part way doesn't lose what's installed
+			if err = state.Save(); err != nil {
+				exit1(err.Error())
+			}
+			helpers.Infof("Installed %s %s", pkg.Name, pkg.Version)
+			installed++
+		}
+		helpers.Infof("Imported %d packages, %d already installed", installed, len(set.Packages)-installed)
+	},
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&importLatest, "latest", false, "Install the latest version of each package instead of the listed one")
+	Root.AddCommand(importCmd)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+var infoJSON bool
+
+var infoCmd = &cobra.Command{
+	Use:   "info [package][@version]",
+	Short: "Shows everything about a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		name, version := helpers.SplitPackageVersion(args[0])
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		pkg := getPackageVersion(context.Background(), client, name, version)
+
+		if infoJSON {
+			outputFormat = outputJSON
+		}
+		render(pkg, func() { printInfo(pkg) })
+	},
+}
+
+// printInfo prints a package as a table of its fields
+func printInfo(pkg *models.Package) {
+	pt, err := helpers.PackageToPackageToml(pkg)
+	if err != nil {
+		exit1(err.Error())
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	row := func(field string, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", field, value)
+		}
+	}
+	row("Name", pkg.Name)
+	row("Version", pkg.Version)
+	row("Repository", pt.Repository)
+	row("Owner", pkg.Owner)
+	row("Org", optional(pkg.Org))
+	if pkg.Private {
+		row("Private", "yes, only its owners and org members can see it")
+	}
+	row("Pulls", fmt.Sprint(pkg.Pulls))
+	row("Deprecated", optional(pkg.Deprecated))
+	if pkg.Yanked {
+		row("Yanked", "yes, only installed when asked for by version")
+	}
+	row("Homepage", optional(pt.Homepage))
+	row("Description", optional(pt.ShortDescription))
+	row("Keywords", strings.Join(pt.Keywords, ", "))
+	row("Command", optional(pt.CommandStart))
+	row("Test", optional(pt.TestCommand))
+	for _, p := range pt.Ports {
+		row("Port", fmt.Sprintf("%s:%s", p.Local, p.Container))
+	}
+	for _, v := range pt.Volumes {
+		row("Volume", fmt.Sprintf("%s:%s", v.Local, v.Container))
+	}
+	for _, k := range pt.Env.Keys() {
+		row("Env", fmt.Sprintf("%s=%s", k, pt.Env[k]))
+	}
+	row("Created", date(pkg.CreatedAt))
+	row("Updated", date(pkg.UpdatedAt))
+	w.Flush()
+
+	if long := optional(pt.LongDescription); long != "" {
+		fmt.Printf("\n%s\n", long)
+	}
+}
+
+// optional returns the value of an optional field, or "" when it isn't set
+func optional(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func init() {
+	infoCmd.ValidArgsFunction = completeRegistry
+	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "Print the package as json")
+	infoCmd.Flags().MarkDeprecated("json", "use --output json")
+	Root.AddCommand(infoCmd)
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+var (
+	initFromCompose string
+	initService     string
+	initOutput      string
+	initForce       bool
+
+	initImage            string
+	initCommandStart     string
+	initShortDescription string
+	initHomepage         string
+	initMappings         helpers.RunOverrides
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [name]",
+	Short: "Creates a package manifest",
+	Long: `Creates a package manifest from flags, asking for anything missing when run
+in a terminal, or from a docker-compose service with --from-compose. The
+manifest is validated before it's written so it can always be published.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 || (len(args) == 0 && initFromCompose == "") {
+			exit1(cmd.UsageString())
+		}
+		if len(args) == 1 && !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+		if _, err := os.Stat(initOutput); err == nil && !initForce {
+			exit1(fmt.Sprintf("%s already exists, use --force to overwrite it", initOutput))
+		}
+
+		var pt *models.PackageToml
+		if initFromCompose != "" {
+			pt = initPackageTomlFromCompose()
+			if len(args) == 1 {
+				pt.Package = args[0]
+			}
+		} else {
+			pt = initPackageToml(args[0])
+		}
+
+		if err := helpers.ValidPackageToml(pt); err != nil {
+			exit1(err.Error())
+		}
+		if err := helpers.WritePackageTomlFile(initOutput, pt); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Wrote manifest for %s to %s", pt.Package, initOutput)
+	},
+}
+
+// initPackageTomlFromCompose converts the service picked from --from-compose
+func initPackageTomlFromCompose() *models.PackageToml {
+	f, err := os.Open(initFromCompose)
+	if err != nil {
+		exit1(err.Error())
+	}
+	defer f.Close()
+	pt, err := helpers.ComposeToPackageToml(f, initService)
+	if err != nil {
+		exit1(fmt.Sprintf("%s: %s", initFromCompose, err))
+	}
+	return pt
+}
+
+// initPackageToml builds a manifest from flags, prompting for the image and any
+// unset fields when stdin is a terminal
+func initPackageToml(name string) *models.PackageToml {
+	interactive := initImage == "" && helpers.Interactive() && isTerminal(os.Stdin)
+	if interactive {
+		initImage = prompt("Image (repository:tag)", "")
+		initShortDescription = prompt("Short description", initShortDescription)
+		initHomepage = prompt("Homepage", initHomepage)
+		initCommandStart = prompt("Command to start with (blank for the image's default)", initCommandStart)
+		if len(initMappings.Ports) == 0 {
+			initMappings.Ports = promptList("Ports as local:container, comma separated")
+		}
+		if len(initMappings.Volumes) == 0 {
+			initMappings.Volumes = promptList("Volumes as local:container, comma separated")
+		}
+	}
+	if initImage == "" {
+		exit1("An image is required, set one with --image")
+	}
+
+	pt := &models.PackageToml{
+		Package:    name,
+		Repository: initImage,
+	}
+	if initShortDescription != "" {
+		pt.ShortDescription = &initShortDescription
+	}
+	if initHomepage != "" {
+		pt.Homepage = &initHomepage
+	}
+	if initCommandStart != "" {
+		pt.CommandStart = &initCommandStart
+	}
+
+	// Unlike cr run a bare KEY isn't read from the environment, it would end up published
+	for _, e := range initMappings.Env {
+		if !strings.Contains(e, "=") {
+			exit1(fmt.Sprintf("Environment variable \"%s\" should be in the form KEY=value", e))
+		}
+	}
+
+	pt, err := helpers.ApplyOverrides(pt, initMappings)
+	if err != nil {
+		exit1(err.Error())
+	}
+	return pt
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initFromCompose, "from-compose", "", "Convert a service of a docker-compose file")
+	initCmd.Flags().StringVarP(&initService, "service", "s", "", "Service to convert when the compose file has more than one")
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "package.toml", "Path to write the manifest to")
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Overwrite output if it exists")
+
+	initCmd.Flags().StringVarP(&initImage, "image", "i", "", "Docker image the package runs (repository:tag)")
+	initCmd.Flags().StringVarP(&initCommandStart, "command", "c", "", "Command to start the container with")
+	initCmd.Flags().StringVarP(&initShortDescription, "description", "d", "", "Short description of the package")
+	initCmd.Flags().StringVar(&initHomepage, "homepage", "", "Homepage of the package")
+	initCmd.Flags().StringArrayVarP(&initMappings.Ports, "publish", "p", nil, "Publish a container port to the host (local:container)")
+	initCmd.Flags().StringArrayVarP(&initMappings.Volumes, "volume", "v", nil, "Bind mount a volume (local:container)")
+	initCmd.Flags().StringArrayVarP(&initMappings.Env, "env", "e", nil, "Set an environment variable (KEY=value)")
+	Root.AddCommand(initCmd)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+	"github.com/sunshinekitty/cr/client"
+)
+
+var update bool
+
+var installCmd = &cobra.Command{
+	Use:     "install [package][@version]",
+	Aliases: []string{"get"},
+	Short:   "Install tool from Crackle",
+	Long: `Install tool from Crackle, its config is downloaded and a shim that runs it
+is written to ~/.local/share/cr/bin so it can be run by name once that's on
+your PATH.
+
+The version can be a range such as @^1.2, @~1.2.3 or @1.x, Crackle picks the
+newest version in it that isn't yanked. Tags that aren't versions, such as
+@latest, are installed as they are.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			exit1("Provide package to install")
+		}
+
+		name, version := helpers.SplitPackageVersion(args[0])
+		if len(args) > 1 || !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		ctx := context.Background()
+		if version != "" {
+			version = resolveVersion(ctx, client, name, version)
+		}
+		pkg := getPackageVersion(ctx, client, name, version)
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = installPackage(ctx, client, state, pkg); err != nil {
+			exit1(err.Error())
+		}
+		if err = state.Save(); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Installed %s", pkg.Name)
+		if !helpers.BinDirOnPath() {
+			helpers.Warnf("Add %s to your PATH to run it as %s", helpers.BinDir(), helpers.PackageFileName(pkg.Name))
+		}
+	},
+}
+
+// resolveVersion returns the newest version of a package in a range, a
+// version that isn't a range such as latest is returned as it is. It exits
+// when no version is in the range.
+func resolveVersion(ctx context.Context, client *client.Client, name string, versionRange string) string {
+	if _, err := helpers.ParseVersionRange(versionRange); err != nil {
+		return versionRange
+	}
+	if namespaced := helpers.QualifyPackageName(helpers.DefaultNamespace(), name); namespaced != name {
+		resolved, resp, _ := client.Package.ResolveVersion(ctx, namespaced, versionRange)
+		if resp != nil && resp.StatusCode == 200 {
+			return resolved.Version
+		}
+	}
+	resolved, resp, err := client.Package.ResolveVersion(ctx, name, versionRange)
+	if resp == nil {
+		exit1(err.Error())
+	}
+	switch resp.StatusCode {
+	case 200:
+		return resolved.Version
+	case 400:
+		exit1(fmt.Sprintf("Version range \"%s\" is invalid", versionRange))
+	case 404:
+		// getPackageVersion exits for packages that don't exist
+		getPackageVersion(ctx, client, name, "")
+		exit1(fmt.Sprintf("No version of %s matches %s", name, versionRange))
+	default:
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+	return ""
+}
+
+// installPackage writes a package's config and shim and records it in state,
+// the caller saves state. The pull is counted by Crackle.
+func installPackage(ctx context.Context, client *client.Client, state *helpers.State, pkg *models.Package) error {
+	pkgToml, err := helpers.PackageToPackageToml(pkg)
+	if err != nil {
+		return err
+	}
+	if err = trustSigningKey(pkg); err != nil {
+		return err
+	}
+	if err = helpers.EnsureConfigDirs(); err != nil {
+		return err
+	}
+	crPackageFile, err := helpers.CreatePackageFiles(pkgToml.Package)
+	if err != nil {
+		return err
+	}
+	if err = helpers.EncodePackageToml(crPackageFile, pkgToml); err != nil {
+		crPackageFile.Close()
+		return err
+	}
+	if err = crPackageFile.Close(); err != nil {
+		return err
+	}
+	state.Add(pkg)
+	if pkg.Deprecated != nil {
+		helpers.Warnf("%s is deprecated: %s", pkg.Name, *pkg.Deprecated)
+	}
+
+	recordPull(ctx, client, pkg.Name, pkg.Version)
+	return nil
+}
+
+// trustSigningKey pins the key a signed package arrives with for its
+// publisher the first time one of theirs is installed, later packages signed
+// by another key are refused until the publisher is forgotten with cr key
+// forget
+func trustSigningKey(pkg *models.Package) error {
+	if pkg.Signature == nil || pkg.SigningKey == nil {
+		return nil
+	}
+	trusted, err := helpers.LoadTrustedKeys()
+	if err != nil {
+		return err
+	}
+	pinned, err := trusted.Trust(pkg.Owner, *pkg.SigningKey)
+	if errors.Is(err, helpers.ErrSigningKeyChanged) {
+		return fmt.Errorf("%s, if %s changed their key on purpose run `cr key forget %s` and install again", err, pkg.Owner, pkg.Owner)
+	}
+	if err != nil || !pinned {
+		return err
+	}
+	helpers.Infof("Trusting %s's signing key %s", pkg.Owner, helpers.KeyFingerprint(*pkg.SigningKey))
+	return trusted.Save()
+}
+
+func init() {
+	installCmd.ValidArgsFunction = completeRegistry
+	installCmd.Flags().BoolVarP(&update, "update", "u", false, "Update package to latest available")
+	Root.AddCommand(installCmd)
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var keyForce bool
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Manage the key you sign packages with",
+	Long: `Generates the ed25519 key cr publish --sign signs packages with and registers
+its public key with Crackle, which refuses signatures made by any other key.
+The private key is kept in ~/.config/cr/signing.key and never leaves this machine.
+cr run --verify-signatures only runs packages signed by their publisher.
+
+The first key a publisher's package is installed with is trusted for them from
+then on, packages of theirs signed by another key aren't installed or run until
+it's forgotten with cr key forget.`,
+}
+
+var keyGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generates a signing key",
+	Long: `Generates a signing key, register it with cr key register. --force replaces a
+key generated before, packages signed with it can still be verified.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if _, err := os.Stat(helpers.SigningKeyPath()); err == nil && !keyForce {
+			exit1(fmt.Sprintf("%s already exists, use --force to replace it", helpers.SigningKeyPath()))
+		}
+
+		key, err := helpers.GenerateSigningKey()
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.SaveSigningKey(key); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Generated %s, register it with cr key register", helpers.KeyFingerprint(helpers.EncodePublicKey(key)))
+	},
+}
+
+var keyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the public key of your signing key",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		key, err := helpers.LoadSigningKey()
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		publicKey := helpers.EncodePublicKey(key)
+		render(map[string]string{"public_key": publicKey, "fingerprint": helpers.KeyFingerprint(publicKey)}, func() {
+			fmt.Println(publicKey)
+			fmt.Println(helpers.KeyFingerprint(publicKey))
+		})
+	},
+}
+
+var keyRegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Registers your signing key with Crackle",
+	Long: `Registers the public key of your signing key with Crackle, replacing any
+registered before. Versions already published keep the key they were signed
+with.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		key, err := helpers.LoadSigningKey()
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		publicKey := helpers.EncodePublicKey(key)
+		resp, err := client.Account.SetSigningKey(context.Background(), publicKey)
+		checkAccountResponse(resp, err)
+		helpers.Infof("Registered %s, sign packages with cr publish --sign", helpers.KeyFingerprint(publicKey))
+	},
+}
+
+var keyRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Removes your signing key from Crackle",
+	Long: `Removes your registered signing key from Crackle, so packages can't be
+published signed by it. The key in ~/.config/cr/signing.key is kept.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+
+		client := newClient()
+		resp, err := client.Account.RemoveSigningKey(context.Background())
+		if resp != nil && resp.StatusCode == 404 {
+			exit1("You haven't registered a signing key")
+		}
+		checkAccountResponse(resp, err)
+		helpers.Infof("Removed your signing key")
+	},
+}
+
+var keyTrustedCmd = &cobra.Command{
+	Use:   "trusted",
+	Short: "Lists the signing keys trusted for publishers",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		trusted, err := helpers.LoadTrustedKeys()
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		keys := trusted.Trusted()
+		render(keys, func() {
+			if len(keys) == 0 {
+				helpers.Infof("No trusted keys, they're trusted when a signed package is installed")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PUBLISHER\tFINGERPRINT")
+			for _, k := range keys {
+				fmt.Fprintf(w, "%s\t%s\n", k.Publisher, k.Fingerprint)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var keyForgetCmd = &cobra.Command{
+	Use:   "forget [publisher]",
+	Short: "Forgets the signing key trusted for a publisher",
+	Long: `Forgets the signing key trusted for a publisher, the key their next package is
+installed with is trusted in its place. Check with them that they changed it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		trusted, err := helpers.LoadTrustedKeys()
+		if err != nil {
+			exit1(err.Error())
+		}
+		if !trusted.Forget(args[0]) {
+			exit1(fmt.Sprintf("No key is trusted for %s", args[0]))
+		}
+		if err = trusted.Save(); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Forgot the signing key of %s", args[0])
+	},
+}
+
+func init() {
+	keyGenerateCmd.Flags().BoolVarP(&keyForce, "force", "f", false, "Replace the signing key if it exists")
+	keyCmd.AddCommand(keyGenerateCmd)
+	keyCmd.AddCommand(keyShowCmd)
+	keyCmd.AddCommand(keyRegisterCmd)
+	keyCmd.AddCommand(keyRemoveCmd)
+	keyCmd.AddCommand(keyTrustedCmd)
+	keyCmd.AddCommand(keyForgetCmd)
+	Root.AddCommand(keyCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [path]",
+	Short: "Checks a package manifest for errors and likely mistakes",
+	Long: `Checks a package manifest for errors and likely mistakes.
+
+Errors would stop the package from being published and exit 1, warnings are
+printed but don't stop a publish. Path defaults to the current directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit1(cmd.UsageString())
+		}
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		pt, err := helpers.LoadPackageTomlContext(context.Background(), path)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.ValidPackageToml(pt); err != nil {
+			exit1(fmt.Sprintf("error: %s", err))
+		}
+		warnings := helpers.LintPackageToml(pt)
+		for _, w := range warnings {
+			fmt.Println(w)
+		}
+		if len(warnings) == 0 {
+			helpers.Infof("%s looks good", pt.Package)
+		}
+	},
+}
+
+func init() {
+	Root.AddCommand(lintCmd)
+}
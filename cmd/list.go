@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/client"
+)
+
+var listOffline bool
+
+// listedPackage represents an installed package as printed by cr list, Latest
+// is empty when Crackle wasn't checked
+type listedPackage struct {
+	helpers.InstalledPackage
+	Latest string `json:"latest,omitempty"`
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists installed packages and whether they're outdated",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+		client := newClient()
+		ctx := context.Background()
+
+		listed := make([]listedPackage, 0, len(state.Packages))
+		for _, p := range state.Installed() {
+			l := listedPackage{InstalledPackage: p}
+			if !listOffline {
+				l.Latest = latestVersion(ctx, client, p.Name)
+			}
+			listed = append(listed, l)
+		}
+
+		render(listed, func() {
+			if len(listed) == 0 {
+				fmt.Println("No packages installed, install one with `cr install [package]`")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tVERSION\tINSTALLED\tLATEST")
+			for _, l := range listed {
+				latest := l.Latest
+				switch latest {
+				case "":
+					latest = "-"
+				case l.Version:
+					latest = "up to date"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", l.Name, l.Version, l.InstalledAt.Local().Format("2006-01-02"), latest)
+			}
+			w.Flush()
+		})
+	},
+}
+
+// latestVersion returns the latest published version of a package, or "?" when
+// it can't be fetched
+func latestVersion(ctx context.Context, client *client.Client, name string) string {
+	pkg, resp, err := client.Package.GetPackage(ctx, name)
+	if err != nil || resp.StatusCode != 200 {
+		return "?"
+	}
+	return pkg.Version
+}
+
+func init() {
+	listCmd.Flags().BoolVar(&listOffline, "offline", false, "Don't check Crackle for newer versions")
+	Root.AddCommand(listCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Writes a cr.lock of the exact installed packages to the project",
+	Long: `Writes a cr.lock recording the exact version and image digest of every
+installed package, next to the project's .cr.toml or at its lock.path, and to
+the current directory outside a project. Commit it with your project and run
+cr sync to install the same packages on another machine.
+
+Pinned packages are locked to their pinned digest, anything else to the digest
+its tag currently points at.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+		pins, err := helpers.LoadLock(helpers.LockPath())
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		ctx := context.Background()
+		lock := &helpers.Lock{Packages: make(map[string]helpers.LockedPackage)}
+		for _, p := range state.Installed() {
+			locked := helpers.LockedPackage{Name: p.Name, Version: p.Version, Repository: p.Repository}
+			if pin, ok := pins.Packages[p.Name]; ok && pin.Repository == p.Repository {
+				locked.Digest = pin.Digest
+			} else if locked.Digest, err = helpers.ResolveDigestContext(ctx, p.Repository); err != nil {
+				exit1(err.Error())
+			}
+			lock.Packages[p.Name] = locked
+		}
+		lockPath := helpers.ProjectLockPath()
+		if err = lock.Save(lockPath); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Locked %d packages in %s", len(lock.Packages), lockPath)
+	},
+}
+
+func init() {
+	Root.AddCommand(lockCmd)
+}
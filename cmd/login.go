@@ -1,19 +1,164 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+var (
+	loginUsername      string
+	loginPasswordStdin bool
+	loginGithub        bool
 )
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login to crackle.pm or configured Crackle endpoint",
+	Long: `Login to crackle.pm or configured Crackle endpoint. Your username and password
+are exchanged for an API token which is kept in the OS keychain, or when there
+isn't one in ~/.config/cr/credentials.json readable only by you. With --github you
+authorize cr with your GitHub account in a browser instead, the first time
+creates an account named after your GitHub login. With two-factor
+authentication on you're asked for a code, or pass one with --otp.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("todo")
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if loginGithub {
+			saveLogin(loginWithGithub())
+			return
+		}
+		if loginUsername == "" {
+			configured := viper.GetString("crackle.username")
+			switch {
+			case helpers.Interactive() && isTerminal(os.Stdin) && !loginPasswordStdin:
+				loginUsername = prompt("Username", configured)
+			case configured != "":
+				loginUsername = configured
+			default:
+				exit1("Provide a username with --username")
+			}
+		}
+		var password string
+		if loginPasswordStdin || !isTerminal(os.Stdin) {
+			password = readPasswordStdin()
+		} else if !helpers.Interactive() {
+			exit1("Not asking for a password when not interactive, use --password-stdin")
+		} else {
+			password = promptPassword("Password")
+		}
+
+		client := newClient()
+		token, resp, err := client.Auth.Login(context.Background(), loginUsername, password)
+		if otpRetry(client, resp) {
+			token, resp, err = client.Auth.Login(context.Background(), loginUsername, password)
+		}
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+		case 401:
+			exit1("Username or password is incorrect")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		saveLogin(token)
 	},
 }
 
+// saveLogin keeps the API token a login was given
+func saveLogin(token *models.Token) {
+	if err := helpers.SaveCredentials(&helpers.Credentials{Username: token.Username, Token: token.Token}); err != nil {
+		exit1(err.Error())
+	}
+	helpers.Infof("Logged in to %s as %s", viper.GetString("crackle.api"), token.Username)
+}
+
+// loginWithGithub logs in through GitHub, the user enters a code in their
+// browser while the registry is polled until they've authorized cr
+func loginWithGithub() *models.Token {
+	if !helpers.Interactive() {
+		exit1("Logging in with GitHub needs a browser, use --password-stdin or CR_TOKEN when not interactive")
+	}
+	ctx := context.Background()
+	client := newClient()
+	device, resp, err := client.Auth.StartGithubLogin(ctx)
+	if resp == nil {
+		exit1(err.Error())
+	}
+	switch resp.StatusCode {
+	case 200:
+	case 501:
+		exit1(fmt.Sprintf("%s doesn't support logging in with GitHub", viper.GetString("crackle.api")))
+	default:
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+
+	fmt.Fprintf(os.Stderr, "Enter the code %s at %s\n", device.UserCode, device.VerificationURI)
+	if err = helpers.OpenURLContext(ctx, device.VerificationURI); err != nil {
+		helpers.Debugf("couldn't open a browser: %s", err)
+	}
+	interval := githubPollInterval(device.Interval)
+	for {
+		time.Sleep(interval)
+		token, pending, resp, err := client.Auth.GithubLogin(ctx, device.DeviceCode)
+		if otpRetry(client, resp) {
+			token, pending, resp, err = client.Auth.GithubLogin(ctx, device.DeviceCode)
+		}
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+			return token
+		case 202:
+			if pending.Interval > 0 {
+				interval = githubPollInterval(pending.Interval)
+			}
+		case 401:
+			exit1("The GitHub login was denied")
+		case 410:
+			exit1("The GitHub login expired, try again")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	}
+}
+
+// githubPollInterval returns how long to wait between polls for a GitHub login
+// from the seconds GitHub asked for, 5 when it didn't say
+func githubPollInterval(seconds int) time.Duration {
+	if seconds <= 0 {
+		seconds = 5
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// readPasswordStdin reads a password piped to stdin, so it's kept out of
+// arguments and shell history
+func readPasswordStdin() string {
+	password, err := stdin.ReadString('\n')
+	if err != nil && password == "" {
+		exit1("No password given on stdin")
+	}
+	return strings.TrimRight(password, "\r\n")
+}
+
 func init() {
+	loginCmd.Flags().StringVarP(&loginUsername, "username", "u", "", "Username to login as")
+	loginCmd.Flags().BoolVar(&loginPasswordStdin, "password-stdin", false, "Read the password from stdin")
+	loginCmd.Flags().BoolVar(&loginGithub, "github", false, "Login with your GitHub account in a browser")
+	addOTPFlag(loginCmd)
 	Root.AddCommand(loginCmd)
 }
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Logout of crackle.pm or configured Crackle endpoint",
+	Long: `Logout of crackle.pm or configured Crackle endpoint, the API token is revoked
+and removed from this machine.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if _, err := helpers.LoadCredentials(); err == helpers.ErrNotLoggedIn {
+			exit1(fmt.Sprintf("Not logged in to %s", viper.GetString("crackle.api")))
+		}
+
+		// The token is removed locally even when it can't be revoked
+		client := newClient()
+		resp, err := client.Auth.Logout(context.Background())
+		if resp == nil || (resp.StatusCode != 204 && resp.StatusCode != 401) {
+			helpers.Warnf("Couldn't revoke token: %s", err)
+		}
+
+		if err = helpers.DeleteCredentials(); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Logged out of %s", viper.GetString("crackle.api"))
+	},
+}
+
+func init() {
+	Root.AddCommand(logoutCmd)
+}
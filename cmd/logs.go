@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var logsTail string
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [package]",
+	Short: "Streams the output of a running package",
+	Long: `Streams the output of a running package until interrupted. When the package is
+running more than once the newest container is used.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		args[0] = helpers.ResolveAlias(args[0])
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		ctx := context.Background()
+		container, err := helpers.FindContainerContext(ctx, args[0])
+		if err == helpers.ErrNotRunning {
+			exit1(fmt.Sprintf("Package %s isn't running", args[0]))
+		} else if err != nil {
+			exit1(err.Error())
+		}
+
+		name, dockerArgs := helpers.DockerCmd("logs", "--follow", "--tail", logsTail, container.ID)
+		err = helpers.RunCmdContext(ctx, name, dockerArgs)
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		} else if err != nil {
+			exit1(err.Error())
+		}
+	},
+}
+
+func init() {
+	logsCmd.ValidArgsFunction = completeRunning
+	logsCmd.Flags().StringVar(&logsTail, "tail", "all", "Number of lines to show from the end of the logs")
+	Root.AddCommand(logsCmd)
+}
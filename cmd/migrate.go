@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/db"
+	"github.com/sunshinekitty/cr/handlers"
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var (
+	migrateTo     int64
+	migrateDryRun bool
+	migrateForce  int64
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrates the registry's database schema to the version this cr expects",
+	Long: `Migrates the database configured in server.toml or by --db to the latest
+schema migration built into cr, or up or down to --to. Each migration runs in
+a transaction, so one that fails leaves the schema as it was. --dry-run lists
+the migrations that would run.
+
+--force records a version as the schema's without running anything, for a
+schema migrated by hand or left dirty by another migration tool.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if err := readServerConfig(); err != nil {
+			exit1(err.Error())
+		}
+		db.InitDB()
+
+		if cmd.Flags().Changed("force") {
+			if err := db.ForceSchemaVersion(handlers.DB, migrateForce); err != nil {
+				exit1(err.Error())
+			}
+			helpers.Infof("Schema version set to %d", migrateForce)
+			return
+		}
+		if err := migrateSchema(migrateTo, migrateDryRun); err != nil {
+			exit1(err.Error())
+		}
+	},
+}
+
+func init() {
+	migrateCmd.Flags().Int64Var(&migrateTo, "to", 0, "Version to migrate up or down to (default the latest)")
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "List the migrations that would run without running them")
+	migrateCmd.Flags().Int64Var(&migrateForce, "force", 0, "Record this version as the schema's without running migrations")
+	webCmd.AddCommand(migrateCmd)
+}
+
+// migrateSchema migrates handlers.DB to version to, 0 being the latest
+func migrateSchema(to int64, dryRun bool) error {
+	migrations, err := db.Migrations()
+	if err != nil {
+		return err
+	}
+	if to == 0 {
+		to = helpers.LatestMigration(migrations)
+	}
+	from, dirty, err := db.SchemaVersion(handlers.DB)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("The schema is dirty at version %d, fix it then run cr server migrate --force %d", from, from)
+	}
+	steps, err := helpers.MigrationPlan(migrations, from, to)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		helpers.Infof("Schema is at version %d, nothing to migrate", from)
+		return nil
+	}
+
+	if dryRun {
+		render(steps, func() { printMigrationSteps(steps) })
+		return nil
+	}
+	err = db.Migrate(handlers.DB, steps, func(step helpers.MigrationStep) {
+		helpers.Infof("Migrated %s %d_%s", migrationDirection(step), step.Version, step.Name)
+	})
+	if err != nil {
+		return err
+	}
+	helpers.Infof("Schema is at version %d", to)
+	return nil
+}
+
+// printMigrationSteps lists migrations that would run
+func printMigrationSteps(steps []helpers.MigrationStep) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tDIRECTION")
+	for _, s := range steps {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, migrationDirection(s))
+	}
+	w.Flush()
+}
+
+// migrationDirection names which way a step migrates
+func migrationDirection(step helpers.MigrationStep) string {
+	if step.Up {
+		return "up"
+	}
+	return "down"
+}
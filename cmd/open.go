@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var (
+	openRegistry bool
+	openPrint    bool
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open [package]",
+	Short: "Opens a package's homepage in the browser",
+	Long: `Opens a package's homepage in the browser, or its page on the Crackle website
+when it has no homepage or --registry is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		ctx := context.Background()
+		pkg := getPackageVersion(ctx, newClient(), args[0], "")
+		url := optional(pkg.Homepage)
+		if url == "" || openRegistry {
+			url = helpers.PackagePageURL(viper.GetString("crackle.web"), pkg.Name)
+		}
+
+		if openPrint {
+			fmt.Println(url)
+			return
+		}
+		if err := helpers.OpenURLContext(ctx, url); err != nil {
+			exit1(fmt.Sprintf("Couldn't open %s: %s", url, err))
+		}
+	},
+}
+
+func init() {
+	openCmd.ValidArgsFunction = completeRegistry
+	openCmd.Flags().BoolVarP(&openRegistry, "registry", "r", false, "Open the package's page on the Crackle website")
+	openCmd.Flags().BoolVar(&openPrint, "print", false, "Print the URL rather than opening it")
+	Root.AddCommand(openCmd)
+}
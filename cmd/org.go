@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var orgMemberRole string
+
+var orgCmd = &cobra.Command{
+	Use:   "org",
+	Short: "Manage organizations and their members",
+	Long: `Packages published with org = "<name>" in their manifest belong to that org,
+its owners and publishers can publish and manage them while readers can't
+change anything. Owners also manage the org's members. A package joins an org
+when it's first published.`,
+}
+
+var orgCreateCmd = &cobra.Command{
+	Use:   "create [org]",
+	Short: "Creates an org with you as its owner",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidOrgName(args[0]) {
+			exit1("Invalid org, org names follow the rules of package names")
+		}
+
+		client := newClient()
+		org, resp, err := client.Org.CreateOrg(context.Background(), args[0])
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+			helpers.Infof("Created org %s", org.Name)
+		case 401:
+			exit1("Login with `cr login` first")
+		case 409:
+			exit1(fmt.Sprintf("The name %s is already taken", args[0]))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+var orgMembersCmd = &cobra.Command{
+	Use:   "members [org]",
+	Short: "Lists the members of an org",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+
+		client := newClient()
+		members, resp, err := client.Org.ListMembers(context.Background(), args[0])
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 404:
+			exit1(fmt.Sprintf("Org %s not found", args[0]))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(members, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "MEMBER\tROLE\tADDED")
+			for _, m := range members {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", m.Username, m.Role, date(m.AddedAt))
+			}
+			w.Flush()
+		})
+	},
+}
+
+var orgAddMemberCmd = &cobra.Command{
+	Use:   "add-member [org] [user]",
+	Short: "Adds a user to an org, or changes their role",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidOrgRole(orgMemberRole) {
+			exit1(fmt.Sprintf("Role \"%s\" is invalid, use owner, publisher or reader", orgMemberRole))
+		}
+		org, username := args[0], args[1]
+
+		client := newClient()
+		resp, err := client.Org.SetMember(context.Background(), org, username, orgMemberRole)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			helpers.Infof("%s is now a %s of %s", username, orgMemberRole, org)
+		case 401:
+			exit1("Login with `cr login` first")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+var orgRemoveMemberCmd = &cobra.Command{
+	Use:   "remove-member [org] [user]",
+	Short: "Removes a user from an org, an org always keeps one owner",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit1(cmd.UsageString())
+		}
+		org, username := args[0], args[1]
+
+		client := newClient()
+		resp, err := client.Org.RemoveMember(context.Background(), org, username)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			helpers.Infof("Removed %s from %s", username, org)
+		case 401:
+			exit1("Login with `cr login` first")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+func init() {
+	orgAddMemberCmd.Flags().StringVar(&orgMemberRole, "role", helpers.OrgRolePublisher, "Role of the member, owner, publisher or reader")
+	orgCmd.AddCommand(orgCreateCmd)
+	orgCmd.AddCommand(orgMembersCmd)
+	orgCmd.AddCommand(orgAddMemberCmd)
+	orgCmd.AddCommand(orgRemoveMemberCmd)
+	Root.AddCommand(orgCmd)
+}
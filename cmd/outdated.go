@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Lists installed packages with a newer version on Crackle",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		ctx := context.Background()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		outdated := 0
+		for _, p := range state.Installed() {
+			latest := latestVersion(ctx, client, p.Name)
+			if latest == p.Version || latest == "?" {
+				continue
+			}
+			if outdated == 0 {
+				fmt.Fprintln(w, "NAME\tVERSION\tLATEST")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Version, latest)
+			outdated++
+		}
+		w.Flush()
+		if outdated == 0 {
+			helpers.Infof("All packages are up to date")
+		}
+	},
+}
+
+func init() {
+	Root.AddCommand(outdatedCmd)
+}
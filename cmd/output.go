@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// outputTable prints results as aligned columns for people to read
+	outputTable = "table"
+	// outputJSON prints results as json
+	outputJSON = "json"
+	// outputYAML prints results as yaml
+	outputYAML = "yaml"
+)
+
+var outputFormat string
+
+// validOutputFormat returns true for a format render knows how to print
+func validOutputFormat(format string) bool {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return true
+	}
+	return false
+}
+
+// render prints v in the --output format, table is called to print it for
+// people. yaml uses the same keys as json.
+func render(v interface{}, table func()) {
+	switch outputFormat {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			exit1(err.Error())
+		}
+	case outputYAML:
+		b, err := json.Marshal(v)
+		if err != nil {
+			exit1(err.Error())
+		}
+		// json is yaml, going through it gives yaml the json keys
+		var doc interface{}
+		if err = yaml.Unmarshal(b, &doc); err != nil {
+			exit1(err.Error())
+		}
+		if b, err = yaml.Marshal(doc); err != nil {
+			exit1(err.Error())
+		}
+		fmt.Print(string(b))
+	default:
+		table()
+	}
+}
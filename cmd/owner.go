@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var ownerCmd = &cobra.Command{
+	Use:   "owner",
+	Short: "Manage who can publish a package",
+	Long: `Owners can publish new versions of a package and yank, deprecate or delete it,
+the first publisher is its first owner.`,
+}
+
+var ownerListCmd = &cobra.Command{
+	Use:   "list [package]",
+	Short: "Lists the owners of a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		owners, resp, err := client.Package.ListOwners(context.Background(), args[0])
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 404:
+			exit1(fmt.Sprintf("Package %s not found", args[0]))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(owners, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "OWNER\tADDED")
+			for _, o := range owners {
+				fmt.Fprintf(w, "%s\t%s\n", o.Username, date(o.AddedAt))
+			}
+			w.Flush()
+		})
+	},
+}
+
+var ownerAddCmd = &cobra.Command{
+	Use:   "add [package] [user]",
+	Short: "Lets another user publish a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		setOwner(cmd, args, true)
+	},
+}
+
+var ownerRemoveCmd = &cobra.Command{
+	Use:   "remove [package] [user]",
+	Short: "Stops a user publishing a package, a package always keeps one owner",
+	Run: func(cmd *cobra.Command, args []string) {
+		setOwner(cmd, args, false)
+	},
+}
+
+// setOwner adds or removes the user named by an owner sub-command's args
+func setOwner(cmd *cobra.Command, args []string, owner bool) {
+	if len(args) != 2 {
+		exit1(cmd.UsageString())
+	}
+	name, username := args[0], args[1]
+	if !helpers.ValidPackageName(name) {
+		exit1("Invalid package")
+	}
+
+	client := newClient()
+	resp, err := client.Package.SetOwner(context.Background(), name, username, owner)
+	if resp == nil {
+		exit1(err.Error())
+	}
+	switch resp.StatusCode {
+	case 204:
+		if owner {
+			helpers.Infof("Added %s as an owner of %s", username, name)
+		} else {
+			helpers.Infof("Removed %s as an owner of %s", username, name)
+		}
+	default:
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+}
+
+func init() {
+	ownerListCmd.ValidArgsFunction = completeRegistry
+	ownerAddCmd.ValidArgsFunction = completeRegistry
+	ownerRemoveCmd.ValidArgsFunction = completeRegistry
+	ownerCmd.AddCommand(ownerListCmd)
+	ownerCmd.AddCommand(ownerAddCmd)
+	ownerCmd.AddCommand(ownerRemoveCmd)
+	Root.AddCommand(ownerCmd)
+}
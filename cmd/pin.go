@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var pinRemove bool
+
+var pinCmd = &cobra.Command{
+	Use:   "pin [package]",
+	Short: "Pins an installed package to the image digest its tag points at",
+	Long: `Pins an installed package to the image digest its tag points at, so it keeps
+running exactly the same image even when the tag is pushed again. Pins are kept
+in ~/.local/share/cr/cr.lock, pin again to move to the tag's current image.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+
+		lock, err := helpers.LoadLock(helpers.LockPath())
+		if err != nil {
+			exit1(err.Error())
+		}
+		if pinRemove {
+			if _, ok := lock.Packages[name]; !ok {
+				exit1(fmt.Sprintf("Package %s isn't pinned", name))
+			}
+			delete(lock.Packages, name)
+			if err = lock.Save(helpers.LockPath()); err != nil {
+				exit1(err.Error())
+			}
+			helpers.Infof("Unpinned %s", name)
+			return
+		}
+
+		configFile := helpers.PackageConfigPath(name)
+		if _, err = os.Stat(configFile); os.IsNotExist(err) {
+			exit1(fmt.Sprintf("Package %s isn't installed", name))
+		}
+		ctx := context.Background()
+		pt, err := helpers.ConfigFileToPackageTomlContext(ctx, configFile)
+		if err != nil {
+			exit1(err.Error())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		digest, err := helpers.ResolveDigestContext(ctx, pt.Repository)
+		if err != nil {
+			exit1(err.Error())
+		}
+		lock.Packages[name] = helpers.LockedPackage{
+			Name:       name,
+			Version:    state.Packages[name].Version,
+			Repository: pt.Repository,
+			Digest:     digest,
+		}
+		if err = lock.Save(helpers.LockPath()); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Pinned %s to %s", name, digest)
+	},
+}
+
+func init() {
+	pinCmd.ValidArgsFunction = completeInstalled
+	pinCmd.Flags().BoolVar(&pinRemove, "remove", false, "Remove the package's pin")
+	Root.AddCommand(pinCmd)
+}
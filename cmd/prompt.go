@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// stdin is shared by every prompt so buffered input isn't lost between them
+var stdin = bufio.NewReader(os.Stdin)
+
+// isTerminal returns true when f is attached to a terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// requireInteractive exits rather than wait for an answer nobody will give
+func requireInteractive(question string) {
+	if !helpers.Interactive() {
+		exit1(fmt.Sprintf("Can't ask \"%s\" when not interactive, pass it as a flag", question))
+	}
+}
+
+// prompt asks question on stdout and returns the trimmed answer, or def when
+// nothing is entered
+func prompt(question string, def string) string {
+	requireInteractive(question)
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		exit1("No answer given")
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def
+	}
+	return answer
+}
+
+// promptList asks question and splits a comma separated answer
+func promptList(question string) []string {
+	var list []string
+	for _, s := range strings.Split(prompt(question, ""), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// confirm asks a yes/no question, anything but y or yes is a no
+func confirm(question string) bool {
+	answer := strings.ToLower(prompt(question+" [y/N]", ""))
+	return answer == "y" || answer == "yes"
+}
+
+// promptPassword asks question without echoing the answer when stdin is a terminal
+func promptPassword(question string) string {
+	requireInteractive(question)
+	echoOff := isTerminal(os.Stdin) && runtime.GOOS != "windows" && stty("-echo") == nil
+	if echoOff {
+		defer func() {
+			stty("echo")
+			fmt.Println()
+		}()
+	}
+	fmt.Printf("%s: ", question)
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		exit1("No answer given")
+	}
+	return strings.TrimRight(answer, "\r\n")
+}
+
+// stty changes the settings of the terminal attached to stdin
+func stty(args ...string) error {
+	c := exec.Command("stty", args...)
+	c.Stdin = os.Stdin
+	return c.Run()
+}
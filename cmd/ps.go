@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var psCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "Lists running packages",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+
+		containers, err := helpers.ListContainersContext(context.Background(), "")
+		if err != nil {
+			exit1(err.Error())
+		}
+		if len(containers) == 0 {
+			fmt.Println("No packages are running")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PACKAGE\tCONTAINER\tIMAGE\tSTATUS")
+		for _, c := range containers {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Package, c.Name, c.Image, c.Status)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	Root.AddCommand(psCmd)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var (
+	publishYes  bool
+	publishTest bool
+	publishSign bool
+)
+
+var publishCmd = &cobra.Command{
+	Use:     "publish [path]",
+	Aliases: []string{"upload"},
+	Short:   "Publishes a package via toml, yaml or json definition to crackle.pm or configured Crackle endpoint",
+	Long: `Publishes a package via toml, yaml or json definition to crackle.pm or configured Crackle endpoint.
+
+Path may be a manifest file or a directory holding one of cr.toml, cr.yaml,
+cr.yml, cr.json or package.toml, it defaults to the current directory.
+
+The manifest is validated and linted, and its version must not have been
+published before since published versions can't be changed. Then what changed
+since the published version and the exact metadata to be uploaded are shown.
+Publishing has to be confirmed, or pass --yes to skip the prompt. With --test
+the package has to pass cr test first. With --sign the manifest is signed with
+your key from cr key generate, which has to be registered with cr key
+register.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit1(cmd.UsageString())
+		}
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		ctx := context.Background()
+		pt, err := helpers.LoadPackageTomlContext(ctx, path)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.ValidPackageToml(pt); err != nil {
+			exit1(err.Error())
+		}
+		p, err := helpers.PackageTomlToPackage(pt)
+		if err != nil {
+			exit1(err.Error())
+		}
+		for _, w := range helpers.LintPackageToml(pt) {
+			fmt.Println(w)
+		}
+		if publishTest {
+			testPackage(ctx, pt)
+		}
+		if publishSign {
+			key, err := helpers.LoadSigningKey()
+			if err != nil {
+				exit1(err.Error())
+			}
+			signature, err := helpers.SignManifest(key, pt)
+			if err != nil {
+				exit1(err.Error())
+			}
+			p.Signature = &signature
+		}
+
+		client := newClient()
+
+		// Published versions can't be changed, so don't get as far as confirming
+		_, resp, err := client.Package.GetPackageVersion(ctx, p.Name, p.Version)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+			exit1(fmt.Sprintf("%s %s is already published and can't be changed, publish a new version", p.Name, p.Version))
+		case 404:
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		published, resp, err := client.Package.GetPackage(ctx, p.Name)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+			diffs, err := helpers.DiffPackages(published, p)
+			if err != nil {
+				exit1(err.Error())
+			}
+			fmt.Printf("\nChanges since %s %s:\n", published.Name, published.Version)
+			if len(diffs) == 0 {
+				fmt.Println("  none")
+			}
+			for _, d := range diffs {
+				fmt.Printf("  %s\n", d)
+			}
+		case 404:
+			fmt.Printf("\n%s is a new package\n", p.Name)
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		metadata, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			exit1(err.Error())
+		}
+		fmt.Printf("\nUploading to %s:\n%s\n\n", client.BaseURL, metadata)
+
+		if !publishYes {
+			if !helpers.Interactive() || !isTerminal(os.Stdin) {
+				exit1("Not publishing without confirmation, use --yes to publish non-interactively")
+			}
+			if !confirm(fmt.Sprintf("Publish %s %s?", p.Name, p.Version)) {
+				exit1("Not published")
+			}
+		}
+
+		createdPackage, resp, err := client.Package.CreatePackage(ctx, p)
+		if otpRetry(client, resp) {
+			createdPackage, resp, err = client.Package.CreatePackage(ctx, p)
+		}
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+			helpers.Infof("Created package %s", createdPackage.Name)
+		case 409:
+			exit1(fmt.Sprintf("%s %s was already published and can't be changed, publish a new version", p.Name, p.Version))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+func init() {
+	publishCmd.Flags().BoolVarP(&publishYes, "yes", "y", false, "Publish without asking for confirmation")
+	publishCmd.Flags().BoolVar(&publishTest, "test", false, "Run cr test before publishing")
+	publishCmd.Flags().BoolVar(&publishSign, "sign", false, "Sign the manifest with your signing key")
+	addOTPFlag(publishCmd)
+	publishCmd.Flags().DurationVar(&testTimeout, "test-timeout", time.Minute, "How long to wait for the package to pass with --test")
+	Root.AddCommand(publishCmd)
+}
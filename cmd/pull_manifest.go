@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var pullManifestForce bool
+
+var pullManifestCmd = &cobra.Command{
+	Use:   "pull-manifest [package] [path]",
+	Short: "Downloads a package's manifest as toml to path (default package.toml)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || len(args) > 2 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+		path := "package.toml"
+		if len(args) == 2 {
+			path = args[1]
+		}
+		if _, err := os.Stat(path); err == nil && !pullManifestForce {
+			exit1(fmt.Sprintf("%s already exists, use --force to overwrite it", path))
+		}
+
+		client := newClient()
+		ctx := context.Background()
+		pkg := getPackageVersion(ctx, client, args[0], "")
+		pkgToml, err := helpers.PackageToPackageToml(pkg)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.WritePackageTomlFile(path, pkgToml); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Wrote manifest for %s to %s", pkgToml.Package, path)
+	},
+}
+
+func init() {
+	pullManifestCmd.Flags().BoolVarP(&pullManifestForce, "force", "f", false, "Overwrite path if it exists")
+	Root.AddCommand(pullManifestCmd)
+}
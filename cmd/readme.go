@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var readmeRaw bool
+
+var readmeCmd = &cobra.Command{
+	Use:   "readme [package] [version]",
+	Short: "Shows a package's long description",
+	Long: `Shows a package's long description as Crackle renders its Markdown for the
+terminal, --raw prints the Markdown as it was published.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || len(args) > 2 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+		version := ""
+		if len(args) == 2 {
+			version = args[1]
+		}
+
+		ctx := context.Background()
+		client := newClient()
+		pkg := getPackageVersion(ctx, client, args[0], version)
+		readme := optional(pkg.LongDescription)
+		if readme == "" {
+			exit1(fmt.Sprintf("Package %s has no long description", pkg.Name))
+		}
+
+		if readmeRaw {
+			fmt.Println(readme)
+			return
+		}
+		rendered, resp, err := client.Package.GetReadme(ctx, pkg.Name, pkg.Version, helpers.Interactive())
+		if resp == nil {
+			exit1(err.Error())
+		}
+		if resp.StatusCode != 200 {
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+		fmt.Print(rendered.Text)
+	},
+}
+
+func init() {
+	readmeCmd.ValidArgsFunction = completeRegistry
+	readmeCmd.Flags().BoolVar(&readmeRaw, "raw", false, "Print the Markdown without rendering it")
+	Root.AddCommand(readmeCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var recentLimit int
+
+var recentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "Lists the most recently published packages",
+	Long: `Lists the packages with the newest published versions, newest first. Yanked
+and deprecated versions are left out.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if recentLimit < 1 || recentLimit > helpers.MaxSearchLimit {
+			exit1(fmt.Sprintf("Limit should be between 1 and %d", helpers.MaxSearchLimit))
+		}
+
+		client := newClient()
+		pkgs, resp, err := client.Package.ListRecent(context.Background(), recentLimit)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		if err != nil {
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(pkgs, func() {
+			if len(pkgs) == 0 {
+				fmt.Println("No packages found")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tVERSION\tOWNER\tPUBLISHED\tDESCRIPTION")
+			for _, p := range pkgs {
+				description := ""
+				if p.ShortDescription != nil {
+					description = truncate(strings.Join(strings.Fields(*p.ShortDescription), " "), 50)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.Version, p.Owner, date(p.CreatedAt), description)
+			}
+			w.Flush()
+		})
+	},
+}
+
+func init() {
+	recentCmd.Flags().IntVarP(&recentLimit, "limit", "l", helpers.DefaultSearchLimit, "Most results to show")
+	Root.AddCommand(recentCmd)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// registryFlag is the registry chosen with --registry
+var registryFlag string
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Manage the Crackle registries cr can use",
+	Long: `Registries are Crackle APIs kept by name in the client config. Commands use the
+one chosen with --registry, or named before a package as in work:tool, or else
+crackle.registry, falling back to crackle.api when none is set.`,
+}
+
+var registryAddCmd = &cobra.Command{
+	Use:   "add [name] [api]",
+	Short: "Adds or changes a registry",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidRegistryName(args[0]) {
+			exit1(fmt.Sprintf("Registry name \"%s\" is invalid, it follows the rules of package names", args[0]))
+		}
+		api, err := helpers.APIURL(args[1])
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.SetRegistry(args[0], api.String()); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Added registry %s, log in to it with `cr login --registry %s`", args[0], args[0])
+	},
+}
+
+var registryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists every registry",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		registries := helpers.Registries()
+		render(registries, func() {
+			if len(registries) == 0 {
+				helpers.Infof("No registries, add one with `cr registry add [name] [api]`")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "REGISTRY\tAPI\tDEFAULT")
+			for _, r := range registries {
+				def := ""
+				if r.Default {
+					def = "*"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, r.API, def)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var registryRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Removes a registry",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if _, err := helpers.LookupRegistry(args[0]); err != nil {
+			exit1(fmt.Sprintf("%s isn't a registry", args[0]))
+		}
+		if err := helpers.SetRegistry(args[0], ""); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Removed registry %s", args[0])
+		if viper.GetString(helpers.RegistryKey) == args[0] {
+			helpers.Warnf("%s is still crackle.registry, change it with `cr config set crackle.registry`", args[0])
+		}
+	},
+}
+
+// selectRegistry points the command at the registry named by --registry, by
+// a prefix of its package argument or by crackle.registry, in that order. The
+// prefix is taken off the argument.
+func selectRegistry(args []string) error {
+	name := registryFlag
+	if len(args) != 0 {
+		if prefix, pkg := helpers.SplitRegistryPrefix(args[0]); prefix != "" {
+			if name != "" && name != prefix {
+				return fmt.Errorf("%s is in registry %s, not %s", pkg, prefix, name)
+			}
+			name, args[0] = prefix, pkg
+		}
+	}
+	if name != "" {
+		return helpers.UseRegistry(name)
+	}
+
+	// A default that's gone shouldn't stop cr registry and cr config fixing it
+	if name = viper.GetString(helpers.RegistryKey); name != "" {
+		if err := helpers.UseRegistry(name); err != nil {
+			helpers.Warnf("using crackle.api, crackle.registry: %s", err)
+		}
+	}
+	return nil
+}
+
+// completeRegistries completes the argument with registry names
+func completeRegistries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return registryNames(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// registryNames returns the names of registries starting with prefix
+func registryNames(prefix string) []string {
+	var names []string
+	for _, r := range helpers.Registries() {
+		if strings.HasPrefix(r.Name, prefix) {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+func init() {
+	Root.PersistentFlags().StringVar(&registryFlag, "registry", "", "Registry of the client config to use in place of crackle.registry")
+	Root.RegisterFlagCompletionFunc("registry", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return registryNames(toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	registryRemoveCmd.ValidArgsFunction = completeRegistries
+	registryCmd.AddCommand(registryAddCmd)
+	registryCmd.AddCommand(registryListCmd)
+	registryCmd.AddCommand(registryRemoveCmd)
+	Root.AddCommand(registryCmd)
+}
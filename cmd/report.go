@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var (
+	reportReason  string
+	reportVersion string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [package]",
+	Short: "Flags a malicious or abusive package to the registry's admins",
+	Long: `Reports a package, or one of its versions with --version, to the registry's
+admins, who remove it or dismiss the report. --reason is required, say what's
+wrong with it. Its owners aren't told who reported it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+		if err := helpers.ValidReportReason(reportReason); err != nil {
+			exit1(fmt.Sprintf("Provide why %s is reported with --reason: %s", name, err))
+		}
+
+		client := newClient()
+		report, resp, err := client.Package.Report(context.Background(), name, reportVersion, reportReason)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+		case 401:
+			exit1("Login with `cr login` first")
+		case 404:
+			exit1(fmt.Sprintf("Package %s not found", name))
+		case 409:
+			exit1(fmt.Sprintf("You already reported %s, an admin will look at it", name))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(report, func() {
+			helpers.Infof("Reported %s, thanks, an admin will look at it", name)
+		})
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportReason, "reason", "", "What's wrong with the package")
+	reportCmd.Flags().StringVar(&reportVersion, "version", "", "Only report this version")
+	reportCmd.ValidArgsFunction = completeRegistry
+	Root.AddCommand(reportCmd)
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+var (
+	runOverrides helpers.RunOverrides
+	runDetach    bool
+	runPull      string
+	runVerify    bool
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [package] [-- args...]",
+	Short: "Runs package based on package config",
+	Long: `Runs package based on package config.
+
+Ports, volumes and env given with -p, -v and -e replace the package's mappings
+on the same container port, container path or variable name, anything else is
+added. They're applied after any .cr.override.toml in the working directory.
+
+Anything after -- is passed to the container, appended to the package's
+command_start.
+
+With --detach the package runs in the background, see cr ps, cr logs and
+cr stop.
+
+--pull always pulls the image before running so a tag pushed again is picked
+up, missing only pulls images that aren't there and never fails instead. Pulls
+show the progress of each layer, --quiet hides it.
+
+With --verify-signatures packages only run when their publisher signed them
+with cr publish --sign and their config wasn't changed since.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Everything after -- belongs to the container, not cr
+		var extraArgs []string
+		if dash := cmd.ArgsLenAtDash(); dash != -1 {
+			args, extraArgs = args[:dash], args[dash:]
+		}
+		if len(args) < 1 {
+			exit1("Provide package to run")
+		}
+		if !cmd.Flags().Changed("detach") {
+			runDetach = viper.GetBool("run.detach")
+		}
+		if !cmd.Flags().Changed("pull") && viper.IsSet("run.pull") {
+			runPull = viper.GetString("run.pull")
+		}
+		if !cmd.Flags().Changed("verify-signatures") {
+			runVerify = viper.GetBool("run.verify_signatures")
+		}
+		if !helpers.ValidPullPolicy(runPull) {
+			exit1(fmt.Sprintf("Pull policy \"%s\" is invalid, use always, missing or never", runPull))
+		}
+
+		pkg := helpers.ResolveAlias(args[0])
+		if len(args) > 1 || !helpers.ValidPackageName(pkg) {
+			exit1("Invalid package")
+		}
+
+		ctx := context.Background()
+		pt := loadRunnable(ctx, pkg, runOverrides, runVerify)
+
+		if err := helpers.ApplyPullPolicyContext(ctx, pt.Repository, runPull); err != nil {
+			exit1(err.Error())
+		}
+
+		pulled := recordRun(ctx, pt.Package)
+
+		dockerCmd, dockerArgs := helpers.PackageTomlToArgs(pt, extraArgs...)
+		if runDetach {
+			dockerCmd, dockerArgs = helpers.PackageTomlToDetachedArgs(pt, extraArgs...)
+		}
+
+		err := helpers.RunCmdContext(ctx, dockerCmd, dockerArgs)
+		<-pulled
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		} else if err != nil {
+			exit1(err.Error())
+		}
+		if runDetach {
+			helpers.Infof("Started %s in container %s", pkg, helpers.ContainerName(pt.Package))
+		}
+	},
+}
+
+// recordRun counts a pull of the installed version of a package while it runs,
+// the returned channel is closed once it's counted or given up on
+func recordRun(ctx context.Context, pkg string) <-chan struct{} {
+	pulled := make(chan struct{})
+	go func() {
+		defer close(pulled)
+		state, err := helpers.LoadState()
+		if err != nil {
+			helpers.Debugf("couldn't record pull of %s: %s", pkg, err)
+			return
+		}
+		if installed, ok := state.Packages[pkg]; ok {
+			recordPull(ctx, newClient(), pkg, installed.Version)
+		}
+	}()
+	return pulled
+}
+
+// loadRunnable loads an installed package ready to run: with verify its
+// signature is checked, any .cr.override.toml and overrides are applied, its
+// registry is checked, a deprecation is warned about and a pinned image
+// replaces its tag. It exits when it can't be run.
+func loadRunnable(ctx context.Context, pkg string, overrides helpers.RunOverrides, verify bool) *models.PackageToml {
+	configFile := helpers.PackageConfigPath(pkg)
+
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		fmt.Printf("Config for package %s doesn't exist\n", pkg)
+		exit1("Download a package with `cr install [package]`")
+	}
+
+	pt, err := helpers.ConfigFileToPackageTomlContext(ctx, configFile)
+	if err != nil {
+		exit1(err.Error())
+	}
+	state, err := helpers.LoadState()
+	if err != nil {
+		exit1(err.Error())
+	}
+
+	// The signature covers the config as installed, before anything overrides
+	// it, and has to be made by the key pinned for its publisher rather than
+	// whichever the registry sent
+	if verify {
+		trusted, err := helpers.LoadTrustedKeys()
+		if err != nil {
+			exit1(err.Error())
+		}
+		pinned, err := trusted.VerifyInstalled(state.Packages[pt.Package], pt)
+		if err != nil {
+			exit1(fmt.Sprintf("Not running %s, %s", pkg, err))
+		}
+		if pinned {
+			if err = trusted.Save(); err != nil {
+				exit1(err.Error())
+			}
+		}
+	}
+
+	// A local override only applies when it doesn't name a different package
+	overridePath := helpers.ProjectOverridePath()
+	override, err := helpers.LoadOverrideFileContext(ctx, overridePath)
+	if err != nil {
+		exit1(fmt.Sprintf("%s: %s", overridePath, err))
+	}
+	if override != nil && (override.Package == "" || override.Package == pt.Package) {
+		pt = helpers.MergePackageToml(pt, override)
+		if err = helpers.ValidPackageToml(pt); err != nil {
+			exit1(fmt.Sprintf("%s: %s", overridePath, err))
+		}
+	}
+
+	pt, err = helpers.ApplyOverrides(pt, overrides)
+	if err != nil {
+		exit1(err.Error())
+	}
+
+	// Packages downloaded before the allow-list was set aren't trusted either
+	if err = helpers.ValidRegistry(pt.Repository); err != nil {
+		exit1(err.Error())
+	}
+
+	// The deprecation was recorded at install time so run needn't ask the registry
+	if message := state.Packages[pt.Package].Deprecated; message != "" {
+		helpers.Warnf("%s is deprecated: %s", pt.Package, message)
+	}
+
+	// Pinned packages run the digest they were pinned to rather than the tag
+	lock, err := helpers.LoadLock(helpers.LockPath())
+	if err != nil {
+		exit1(err.Error())
+	}
+	if image, ok := lock.PinnedImage(pt.Package, pt.Repository); ok {
+		helpers.Debugf("%s is pinned, running %s", pkg, image)
+		pt.Repository = image
+	} else if _, pinned := lock.Packages[pt.Package]; pinned {
+		helpers.Warnf("%s was pinned for another image, pin it again with `cr pin %s`", pkg, pkg)
+	}
+
+	return pt
+}
+
+func init() {
+	runCmd.ValidArgsFunction = completeInstalled
+	runCmd.Flags().BoolVarP(&runDetach, "detach", "d", false, "Run the package in the background")
+	runCmd.Flags().StringVar(&runPull, "pull", helpers.PullMissing, "Pull the image before running, always, missing or never")
+	runCmd.RegisterFlagCompletionFunc("pull", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{helpers.PullAlways, helpers.PullMissing, helpers.PullNever}, cobra.ShellCompDirectiveNoFileComp
+	})
+	runCmd.Flags().BoolVar(&runVerify, "verify-signatures", false, "Refuse to run packages that are unsigned or changed since they were signed")
+	runCmd.Flags().StringArrayVarP(&runOverrides.Ports, "publish", "p", nil, "Publish a container port to the host (local:container)")
+	runCmd.Flags().StringArrayVarP(&runOverrides.Volumes, "volume", "v", nil, "Bind mount a volume (local:container)")
+	runCmd.Flags().StringArrayVarP(&runOverrides.Env, "env", "e", nil, "Set an environment variable (KEY=value, or KEY to pass it through)")
+	Root.AddCommand(runCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/client"
+)
+
+var searchOptions client.SearchOptions
+
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search Crackle for packages",
+	Long: `Search Crackle for packages by name, keywords and description, showing the
+latest version of each with the best matches first. With no query every package
+matching the filters is listed, most pulled first.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit1(cmd.UsageString())
+		}
+		if len(args) == 1 {
+			searchOptions.Query = args[0]
+		}
+		if !cmd.Flags().Changed("sort") && viper.IsSet("search.sort") {
+			searchOptions.Sort = viper.GetString("search.sort")
+		}
+		if !cmd.Flags().Changed("limit") && viper.IsSet("search.limit") {
+			searchOptions.Limit = viper.GetInt("search.limit")
+		}
+		if searchOptions.Sort != "" && !helpers.ValidSearchSort(searchOptions.Sort) {
+			exit1(fmt.Sprintf("Sort \"%s\" is invalid, use relevance, pulls, updated or name", searchOptions.Sort))
+		}
+		if searchOptions.Limit < 1 || searchOptions.Limit > helpers.MaxSearchLimit {
+			exit1(fmt.Sprintf("Limit should be between 1 and %d", helpers.MaxSearchLimit))
+		}
+
+		client := newClient()
+		pkgs, resp, err := client.Package.SearchPackages(context.Background(), &searchOptions)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		if err != nil {
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(pkgs, func() {
+			if len(pkgs) == 0 {
+				fmt.Println("No packages found")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tVERSION\tOWNER\tPULLS\tUPDATED\tDESCRIPTION")
+			for _, p := range pkgs {
+				description := ""
+				if p.ShortDescription != nil {
+					description = truncate(strings.Join(strings.Fields(*p.ShortDescription), " "), 50)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", p.Name, p.Version, p.Owner, p.Pulls, date(p.UpdatedAt), description)
+			}
+			w.Flush()
+		})
+	},
+}
+
+// truncate shortens s to at most n characters, marking it with "..." when cut
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-3]) + "..."
+}
+
+// date returns the date part of a timestamp returned by the API
+func date(timestamp string) string {
+	if len(timestamp) < 10 {
+		return timestamp
+	}
+	return timestamp[:10]
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchOptions.Owner, "owner", "", "Only show packages published by owner")
+	searchCmd.Flags().StringVarP(&searchOptions.Keyword, "keyword", "k", "", "Only show packages with keyword")
+	searchCmd.Flags().StringVar(&searchOptions.Category, "category", "", "Only show packages in category, see cr browse")
+	searchCmd.Flags().StringVarP(&searchOptions.Sort, "sort", "s", "", "Sort by relevance, pulls, updated or name (default relevance, or pulls without a query)")
+	searchCmd.Flags().IntVarP(&searchOptions.Limit, "limit", "l", helpers.DefaultSearchLimit, "Most results to show")
+	Root.AddCommand(searchCmd)
+}
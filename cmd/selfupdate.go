@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var selfUpdateCheck bool
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Updates cr to the latest release",
+	Long: `Updates cr to the latest release on GitHub. The new binary is checked against
+the release's sha256 checksums before it replaces the running one. Builds that
+weren't made from a release, such as with go get, aren't updated.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		// Any release would compare newer than a dev build and replace it
+		if version == devVersion {
+			exit1("This cr wasn't built from a release, so it can't tell whether a release is newer. Update it the way you built it, such as with go get -u")
+		}
+
+		ctx := context.Background()
+		release, err := helpers.LatestReleaseContext(ctx)
+		if err != nil {
+			exit1(fmt.Sprintf("Couldn't check for a new release: %s", err))
+		}
+		if helpers.CompareVersions(release.Version(), version) <= 0 {
+			helpers.Infof("cr %s is up to date", version)
+			return
+		}
+		if selfUpdateCheck {
+			helpers.Infof("cr %s is available, you have %s", release.Version(), version)
+			return
+		}
+
+		b, err := helpers.DownloadReleaseContext(ctx, release)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.ReplaceExecutable(b); err != nil {
+			exit1(fmt.Sprintf("Couldn't replace cr: %s", err))
+		}
+		helpers.Infof("Updated cr from %s to %s", version, release.Version())
+	},
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false, "Only check whether there's a new release")
+	Root.AddCommand(selfUpdateCmd)
+}
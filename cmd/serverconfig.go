@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var serverConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Checks the registry's server config",
+}
+
+var serverConfigCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks server.toml and CRACKLE_ environment variables for errors",
+	Long: `Checks the server config the way cr server does when it starts: server.toml
+from /etc/crackle/, $XDG_CONFIG_HOME/cr or the working directory, with
+CRACKLE_ environment variables and --db winning over it. Invalid values and missing
+settings are errors and exit 1, keys cr server doesn't know about are printed as
+warnings.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if err := readServerConfig(); err != nil {
+			exit1(err.Error())
+		}
+		path := viper.ConfigFileUsed()
+		problems, unknown, err := helpers.CheckServerConfig(path)
+		if err != nil {
+			exit1(fmt.Sprintf("%s: %s", path, err))
+		}
+		if path == "" {
+			path = "environment"
+		}
+		for _, key := range unknown {
+			helpers.Warnf("%s: unknown setting %s", path, key)
+		}
+		for _, p := range problems {
+			fmt.Printf("error: %s\n", p)
+		}
+		if len(problems) > 0 {
+			exit1(fmt.Sprintf("%s is invalid", path))
+		}
+		helpers.Infof("%s looks good", path)
+	},
+}
+
+func init() {
+	serverConfigCmd.AddCommand(serverConfigCheckCmd)
+	webCmd.AddCommand(serverConfigCmd)
+}
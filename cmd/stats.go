@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// statsBarWidth is the width of the bar drawn for the busiest period
+const statsBarWidth = 40
+
+var (
+	statsPeriod string
+	statsRange  string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [package]",
+	Short: "Shows how often a package has been pulled over time",
+	Long: `Shows how often a package has been pulled, grouped by --period. Days cover the
+last 30 days, weeks the last 12 weeks and months the last 12 months unless
+--range gives how far back to go, such as 90d or 26w.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+		if !helpers.ValidStatsPeriod(statsPeriod) {
+			exit1(fmt.Sprintf("Period \"%s\" is invalid, use day, week or month", statsPeriod))
+		}
+		if statsRange != "" {
+			if _, err := helpers.ParseStatsRange(statsRange); err != nil {
+				exit1(err.Error())
+			}
+		}
+
+		client := newClient()
+		stats, resp, err := client.Package.GetStats(context.Background(), args[0], statsPeriod, statsRange)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 404:
+			exit1(fmt.Sprintf("Package %s not found", args[0]))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(stats, func() { printStats(stats) })
+	},
+}
+
+// printStats prints pull counts with a bar for each period
+func printStats(stats *models.PackageStats) {
+	fmt.Printf("%s has been pulled by %d clients\n\n", stats.Name, stats.Total)
+	busiest := 0
+	for _, p := range stats.Pulls {
+		if p.Pulls > busiest {
+			busiest = p.Pulls
+		}
+	}
+	if busiest == 0 {
+		fmt.Printf("No pulls in the last %s\n", stats.Range)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tPULLS\tUNIQUE\t\n", strings.ToUpper(stats.Period))
+	for _, p := range stats.Pulls {
+		bar := strings.Repeat("#", p.Pulls*statsBarWidth/busiest)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", p.Date, p.Pulls, p.Unique, bar)
+	}
+	w.Flush()
+}
+
+func init() {
+	statsCmd.ValidArgsFunction = completeRegistry
+	statsCmd.Flags().StringVarP(&statsPeriod, "period", "p", helpers.StatsPeriodDay, "Group pulls by day, week or month")
+	statsCmd.Flags().StringVar(&statsRange, "range", "", "How far back to show pulls, such as 90d or 26w")
+	Root.AddCommand(statsCmd)
+}
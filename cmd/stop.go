@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [package]",
+	Short: "Stops every running container of a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		args[0] = helpers.ResolveAlias(args[0])
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		ctx := context.Background()
+		containers, err := helpers.ListContainersContext(ctx, args[0])
+		if err != nil {
+			exit1(err.Error())
+		}
+		if len(containers) == 0 {
+			exit1(fmt.Sprintf("Package %s isn't running", args[0]))
+		}
+
+		stopArgs := []string{"stop"}
+		for _, c := range containers {
+			stopArgs = append(stopArgs, c.ID)
+		}
+		// docker prints the ID of each container as it's stopped
+		name, dockerArgs := helpers.DockerCmd(stopArgs...)
+		if err = helpers.RunCmdContext(ctx, name, dockerArgs); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Stopped %s", args[0])
+	},
+}
+
+func init() {
+	stopCmd.ValidArgsFunction = completeRunning
+	Root.AddCommand(stopCmd)
+}
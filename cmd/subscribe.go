@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var subscribeUndo bool
+
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe [package]",
+	Short: "Emails you when a package publishes a version",
+	Long: `Subscribes you to a package, you're emailed whenever a version of it is
+published as long as your email is verified with cr account and publish
+notifications are on. --undo unsubscribes. With no arguments the packages
+you're subscribed to are listed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := newClient()
+		ctx := context.Background()
+
+		if len(args) == 0 && !subscribeUndo {
+			subscriptions, resp, err := client.Account.ListSubscriptions(ctx)
+			if resp == nil {
+				exit1(err.Error())
+			}
+			switch resp.StatusCode {
+			case 200:
+			case 401:
+				exit1("Login with `cr login` first")
+			default:
+				exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+			}
+			render(subscriptions, func() {
+				if len(subscriptions) == 0 {
+					fmt.Println("You aren't subscribed to any package")
+					return
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "PACKAGE\tSUBSCRIBED")
+				for _, s := range subscriptions {
+					fmt.Fprintf(w, "%s\t%s\n", s.Name, date(s.CreatedAt))
+				}
+				w.Flush()
+			})
+			return
+		}
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+
+		var (
+			resp *http.Response
+			err  error
+		)
+		if subscribeUndo {
+			resp, err = client.Account.Unsubscribe(ctx, name)
+		} else {
+			resp, err = client.Account.Subscribe(ctx, name)
+		}
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+		case 401:
+			exit1("Login with `cr login` first")
+		case 404:
+			if subscribeUndo {
+				exit1(fmt.Sprintf("You aren't subscribed to %s", name))
+			}
+			exit1(fmt.Sprintf("Package %s not found", name))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+		if subscribeUndo {
+			helpers.Infof("Unsubscribed from %s", name)
+		} else {
+			helpers.Infof("Subscribed to %s", name)
+		}
+	},
+}
+
+func init() {
+	subscribeCmd.Flags().BoolVar(&subscribeUndo, "undo", false, "Unsubscribe from the package")
+	subscribeCmd.ValidArgsFunction = completeRegistry
+	Root.AddCommand(subscribeCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Installs the exact packages in the project's cr.lock",
+	Long: `Installs the exact package versions in the project's cr.lock and pins each to
+its locked image digest, reproducing the packages of the machine it was written
+on. Packages not in cr.lock are left alone.
+
+The cr.lock is the one next to the project's .cr.toml, or its lock.path, and
+the current directory's outside a project.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		lockPath := helpers.ProjectLockPath()
+		if _, err := os.Stat(lockPath); os.IsNotExist(err) {
+			exit1(fmt.Sprintf("No %s, write one with `cr lock`", lockPath))
+		}
+		lock, err := helpers.LoadLock(lockPath)
+		if err != nil {
+			exit1(err.Error())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+		pins, err := helpers.LoadLock(helpers.LockPath())
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		ctx := context.Background()
+		for _, locked := range lock.Locked() {
+			if !helpers.ValidPackageName(locked.Name) {
+				exit1(fmt.Sprintf("%s: invalid package %s", lockPath, locked.Name))
+			}
+			pkg := getPackageVersion(ctx, client, locked.Name, locked.Version)
+			// The digest only pins the image it was resolved from
+			if pkg.Repository != locked.Repository {
+				exit1(fmt.Sprintf("%s %s now uses %s but %s locked %s", locked.Name, locked.Version, pkg.Repository, lockPath, locked.Repository))
+			}
+
+			if installed, ok := state.Packages[locked.Name]; !ok || installed.Version != locked.Version {
+				if err = installPackage(ctx, client, state, pkg); err != nil {
+					exit1(err.Error())
+				}
+				helpers.Infof("Installed %s %s", locked.Name, locked.Version)
+			}
+			pins.Packages[locked.Name] = locked
+		}
+
+		if err = state.Save(); err != nil {
+			exit1(err.Error())
+		}
+		if err = pins.Save(helpers.LockPath()); err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("%d packages in sync with %s", len(lock.Packages), lockPath)
+	},
+}
+
+func init() {
+	Root.AddCommand(syncCmd)
+}
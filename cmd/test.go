@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+var testTimeout time.Duration
+
+var testCmd = &cobra.Command{
+	Use:   "test [path]",
+	Short: "Smoke tests a package manifest by running it",
+	Long: `Smoke tests a package manifest by starting its container in the background and
+waiting for it to pass, then removing it again. Run it before cr publish.
+
+A package passes when its test_command exits 0 inside the container, or when
+it has none and its image declares a HEALTHCHECK once it's healthy. Packages
+with neither pass by staying up for a few seconds. Path defaults to the
+current directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit1(cmd.UsageString())
+		}
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		ctx := context.Background()
+		pt, err := helpers.LoadPackageTomlContext(ctx, path)
+		if err != nil {
+			exit1(err.Error())
+		}
+		if err = helpers.ValidPackageToml(pt); err != nil {
+			exit1(fmt.Sprintf("error: %s", err))
+		}
+		testPackage(ctx, pt)
+	},
+}
+
+// testPackage smoke tests a package, exiting when it fails
+func testPackage(ctx context.Context, pt *models.PackageToml) {
+	if err := helpers.ApplyPullPolicyContext(ctx, pt.Repository, helpers.PullMissing); err != nil {
+		exit1(err.Error())
+	}
+	helpers.Infof("Testing %s", pt.Package)
+	method, err := helpers.TestPackageContext(ctx, pt, testTimeout)
+	if err != nil {
+		exit1(fmt.Sprintf("%s failed its %s: %s", pt.Package, method, err))
+	}
+	switch method {
+	case helpers.TestByRunning:
+		helpers.Warnf("%s has no test_command or healthcheck, it only stayed up", pt.Package)
+	default:
+		helpers.Infof("%s passed its %s", pt.Package, method)
+	}
+}
+
+func init() {
+	testCmd.Flags().DurationVar(&testTimeout, "timeout", time.Minute, "How long to wait for the package to pass")
+	Root.AddCommand(testCmd)
+}
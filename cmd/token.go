@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var tokenScopes []string
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage API tokens",
+	Long: `API tokens authenticate requests to Crackle, logging in issues one that can do
+anything. Tokens with fewer scopes suit CI, set CR_TOKEN to use one in place of
+logging in. Scopes are read, publish (any package you own), admin (owners,
+transfers and tokens) and package:<name> (publishing a single package).`,
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists your API tokens",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+
+		client := newClient()
+		tokens, resp, err := client.Auth.ListTokens(context.Background())
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 401:
+			exit1("Login with `cr login` first")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(tokens, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tSCOPES\tCREATED\tLAST USED")
+			for _, t := range tokens {
+				lastUsed := "never"
+				if t.LastUsed != nil {
+					lastUsed = date(*t.LastUsed)
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", t.ID, t.Name, strings.Join(t.Scopes, ","), date(t.CreatedAt), lastUsed)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Creates an API token, it's only shown once",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit1(cmd.UsageString())
+		}
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		if err := helpers.ValidTokenRequest(name, tokenScopes); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		token, resp, err := client.Auth.CreateToken(context.Background(), name, tokenScopes)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+		case 401:
+			exit1("Login with `cr login` first")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(token, func() {
+			fmt.Println(token.Token)
+		})
+		helpers.Infof("Created token %d with scopes %s, it won't be shown again", token.ID, strings.Join(token.Scopes, ", "))
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke [id]",
+	Short: "Revokes an API token by the id cr token list shows",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			exit1(fmt.Sprintf("Token id \"%s\" is invalid", args[0]))
+		}
+
+		client := newClient()
+		resp, err := client.Auth.RevokeToken(context.Background(), id)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			helpers.Infof("Revoked token %d", id)
+		case 401:
+			exit1("Login with `cr login` first")
+		case 404:
+			exit1(fmt.Sprintf("Token %d not found", id))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+func init() {
+	tokenCreateCmd.Flags().StringSliceVar(&tokenScopes, "scope", helpers.DefaultCreatedTokenScopes, "Scopes of the token, read, publish, admin or package:<name>")
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenRevokeCmd)
+	Root.AddCommand(tokenCmd)
+}
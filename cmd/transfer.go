@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/client"
+)
+
+var (
+	transferAccept bool
+	transferCancel bool
+)
+
+var transferCmd = &cobra.Command{
+	Use:   "transfer [package] [user]",
+	Short: "Hands a package over to another user",
+	Long: `Offers a package to another user, nothing changes until they accept it with
+cr transfer --accept [package], they then become its only owner. --cancel
+withdraws an offer, or declines one made to you. With no arguments the pending
+transfers to and from you are listed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if transferAccept && transferCancel {
+			exit1("Only one of --accept and --cancel can be given")
+		}
+		client := newClient()
+		ctx := context.Background()
+
+		if len(args) == 0 && !transferAccept && !transferCancel {
+			listTransfers(ctx, client)
+			return
+		}
+		if len(args) == 0 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+
+		var (
+			resp *http.Response
+			err  error
+			done string
+		)
+		switch {
+		case transferAccept || transferCancel:
+			if len(args) != 1 {
+				exit1(cmd.UsageString())
+			}
+			if transferAccept {
+				resp, err = client.Package.AcceptTransfer(ctx, name)
+				done = fmt.Sprintf("You now own %s", name)
+			} else {
+				resp, err = client.Package.CancelTransfer(ctx, name)
+				done = fmt.Sprintf("Cancelled the transfer of %s", name)
+			}
+		default:
+			if len(args) != 2 {
+				exit1(cmd.UsageString())
+			}
+			resp, err = client.Package.OfferTransfer(ctx, name, args[1])
+			done = fmt.Sprintf("Offered %s to %s, they can accept with `cr transfer --accept %s`", name, args[1], name)
+		}
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			helpers.Infof("%s", done)
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+// listTransfers prints the pending transfers to and from the logged in user
+func listTransfers(ctx context.Context, client *client.Client) {
+	transfers, resp, err := client.Package.ListTransfers(ctx)
+	if resp == nil {
+		exit1(err.Error())
+	}
+	if resp.StatusCode != 200 {
+		exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+	}
+
+	render(transfers, func() {
+		if len(transfers) == 0 {
+			helpers.Infof("No pending transfers")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PACKAGE\tFROM\tTO\tOFFERED")
+		for _, t := range transfers {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, t.From, t.To, date(t.CreatedAt))
+		}
+		w.Flush()
+	})
+}
+
+func init() {
+	transferCmd.ValidArgsFunction = completeRegistry
+	transferCmd.Flags().BoolVar(&transferAccept, "accept", false, "Accept a package offered to you")
+	transferCmd.Flags().BoolVar(&transferCancel, "cancel", false, "Withdraw an offer, or decline one made to you")
+	Root.AddCommand(transferCmd)
+}
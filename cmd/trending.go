@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var (
+	trendingPeriod string
+	trendingLimit  int
+)
+
+var trendingCmd = &cobra.Command{
+	Use:   "trending",
+	Short: "Lists the packages pulled most recently",
+	Long: `Lists the packages pulled most over the last --period, a day, week or month,
+to help find tools others are picking up.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidStatsPeriod(trendingPeriod) {
+			exit1(fmt.Sprintf("Period \"%s\" is invalid, use day, week or month", trendingPeriod))
+		}
+		if trendingLimit < 1 || trendingLimit > helpers.MaxSearchLimit {
+			exit1(fmt.Sprintf("Limit should be between 1 and %d", helpers.MaxSearchLimit))
+		}
+
+		client := newClient()
+		pkgs, resp, err := client.Package.ListTrending(context.Background(), trendingPeriod, trendingLimit)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		if err != nil {
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(pkgs, func() {
+			if len(pkgs) == 0 {
+				fmt.Printf("No packages pulled in the last %s\n", trendingPeriod)
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "NAME\tVERSION\tPULLS THIS %s\tDESCRIPTION\n", strings.ToUpper(trendingPeriod))
+			for _, p := range pkgs {
+				description := ""
+				if p.ShortDescription != nil {
+					description = truncate(strings.Join(strings.Fields(*p.ShortDescription), " "), 50)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", p.Name, p.Version, p.RecentPulls, description)
+			}
+			w.Flush()
+		})
+	},
+}
+
+func init() {
+	trendingCmd.Flags().StringVarP(&trendingPeriod, "period", "p", helpers.StatsPeriodWeek, "Count pulls over the last day, week or month")
+	trendingCmd.Flags().IntVarP(&trendingLimit, "limit", "l", helpers.DefaultSearchLimit, "Most results to show")
+	Root.AddCommand(trendingCmd)
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var triggerTags string
+
+var triggerCmd = &cobra.Command{
+	Use:   "trigger",
+	Short: "Manage the triggers publishing a package when its image is pushed",
+	Long: `Triggers are URLs that publish a new version of a package when a tag is pushed to
+its image. Add the URL as a webhook of the image's Docker Hub repository, or of
+the GitHub repository or org its GHCR package belongs to with the package event.
+Each tag matching the trigger's --tags is published as a version of the same
+name, copying the latest version, by the user who added the trigger. Signed
+packages can't be published by triggers.`,
+}
+
+var triggerListCmd = &cobra.Command{
+	Use:   "list [package]",
+	Short: "Lists the triggers of a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		triggers, resp, err := client.Package.ListTriggers(context.Background(), args[0])
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 401:
+			exit1("Login with `cr login` first")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(triggers, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTAGS\tCREATED BY\tLAST TAG")
+			for _, t := range triggers {
+				createdBy, lastTag := "-", "never triggered"
+				if t.CreatedBy != nil {
+					createdBy = *t.CreatedBy
+				}
+				if t.LastTag != nil {
+					lastTag = *t.LastTag
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", t.ID, t.Tags, createdBy, lastTag)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var triggerAddCmd = &cobra.Command{
+	Use:   "add [package]",
+	Short: "Adds a trigger to a package, its URL is only shown once",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+		if err := helpers.ValidTriggerTags(triggerTags); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		trigger, resp, err := client.Package.CreateTrigger(context.Background(), name, triggerTags)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+		case 401:
+			exit1("Login with `cr login` first")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(trigger, func() {
+			fmt.Println(trigger.URL)
+		})
+		helpers.Infof("Added trigger %d to %s, add the URL above as a webhook of its image, it won't be shown again", trigger.ID, name)
+	},
+}
+
+var triggerRemoveCmd = &cobra.Command{
+	Use:   "remove [package] [id]",
+	Short: "Removes a trigger from a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			exit1(fmt.Sprintf("Trigger id \"%s\" is invalid", args[1]))
+		}
+
+		client := newClient()
+		resp, err := client.Package.DeleteTrigger(context.Background(), name, id)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			helpers.Infof("Removed trigger %d from %s", id, name)
+		case 401:
+			exit1("Login with `cr login` first")
+		case 404:
+			exit1(fmt.Sprintf("Trigger %d of %s not found", id, name))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+func init() {
+	triggerAddCmd.Flags().StringVar(&triggerTags, "tags", helpers.DefaultTriggerTags, "Only publish tags matching this glob, such as v* or 1.*")
+	triggerListCmd.ValidArgsFunction = completeRegistry
+	triggerAddCmd.ValidArgsFunction = completeRegistry
+	triggerRemoveCmd.ValidArgsFunction = completeRegistry
+	triggerCmd.AddCommand(triggerListCmd)
+	triggerCmd.AddCommand(triggerAddCmd)
+	triggerCmd.AddCommand(triggerRemoveCmd)
+	Root.AddCommand(triggerCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/client"
+)
+
+var (
+	// otpCode is the --otp flag of commands that may need a two-factor code,
+	// newClient sends it with every request
+	otpCode          string
+	twoFactorPublish bool
+)
+
+var twoFactorCmd = &cobra.Command{
+	Use:   "2fa",
+	Short: "Manage two-factor authentication",
+	Long: `Two-factor authentication asks for a code from an authenticator app, such as
+Google Authenticator or 1Password, whenever you login. With --publish
+publishing and yanking need a fresh code too, so a leaked token can't publish
+on its own. Pass codes with --otp, or enter them when asked.`,
+}
+
+var twoFactorEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Turns two-factor authentication on",
+	Long: `Turns two-factor authentication on, showing a secret to add to your
+authenticator app and asking for a code of it. Once it's on, enable changes
+whether publishing and yanking need a code.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.Interactive() || !isTerminal(os.Stdin) {
+			exit1("Turning two-factor authentication on needs a terminal to enter a code at")
+		}
+
+		client := newClient()
+		ctx := context.Background()
+		setup, resp, err := client.Account.StartTwoFactor(ctx)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+			fmt.Printf("Add this account to your authenticator app, with its secret or URI:\n\n")
+			fmt.Printf("  Secret: %s\n  URI:    %s\n\n", setup.Secret, setup.URI)
+		case 409:
+			// Already on, a code changes whether publishing needs one
+		default:
+			checkAccountResponse(resp, err)
+		}
+
+		code := prompt("Code from your authenticator app", "")
+		resp, err = client.Account.EnableTwoFactor(ctx, code, twoFactorPublish)
+		if resp != nil && resp.StatusCode == 400 {
+			exit1("The code is wrong or was already used, try again with the next one")
+		}
+		checkAccountResponse(resp, err)
+		if twoFactorPublish {
+			helpers.Infof("Two-factor authentication is on for logging in, publishing and yanking")
+		} else {
+			helpers.Infof("Two-factor authentication is on for logging in")
+		}
+	},
+}
+
+var twoFactorDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Turns two-factor authentication off",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+
+		client := newClient()
+		ctx := context.Background()
+		resp, err := client.Account.DisableTwoFactor(ctx)
+		if otpRetry(client, resp) {
+			resp, err = client.Account.DisableTwoFactor(ctx)
+		}
+		if resp != nil && resp.StatusCode == 404 {
+			exit1("Two-factor authentication is already off")
+		}
+		checkAccountResponse(resp, err)
+		helpers.Infof("Two-factor authentication is off")
+	},
+}
+
+// addOTPFlag adds --otp to a command that may need a two-factor code
+func addOTPFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&otpCode, "otp", "", "Two-factor code from your authenticator app")
+}
+
+// otpRetry reports whether a request refused for want of a two-factor code
+// should be sent again, after asking for one. It exits when the code given was
+// wrong or there's no terminal to ask at.
+func otpRetry(c *client.Client, resp *http.Response) bool {
+	if !client.OTPRequired(resp) {
+		return false
+	}
+	if c.OTP != "" {
+		exit1("The two-factor code is wrong or was already used, try again with the next one")
+	}
+	if !helpers.Interactive() || !isTerminal(os.Stdin) {
+		exit1("A two-factor code is required, pass one with --otp")
+	}
+	c.OTP = prompt("Two-factor code", "")
+	return true
+}
+
+func init() {
+	twoFactorEnableCmd.Flags().BoolVar(&twoFactorPublish, "publish", false, "Require a fresh code to publish and yank too")
+	addOTPFlag(twoFactorDisableCmd)
+	twoFactorCmd.AddCommand(twoFactorEnableCmd)
+	twoFactorCmd.AddCommand(twoFactorDisableCmd)
+	accountCmd.AddCommand(twoFactorCmd)
+}
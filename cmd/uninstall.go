@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall [package]",
+	Short: "Removes an installed tool and its shim",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+		// Packages installed before the state file existed only have a config
+		_, statErr := os.Stat(helpers.PackageConfigPath(args[0]))
+		if _, ok := state.Packages[args[0]]; !ok && os.IsNotExist(statErr) {
+			exit1(fmt.Sprintf("Package %s isn't installed", args[0]))
+		}
+
+		if err = helpers.RemovePackageFiles(args[0]); err != nil {
+			exit1(err.Error())
+		}
+		state.Remove(args[0])
+		if err = state.Save(); err != nil {
+			exit1(err.Error())
+		}
+		lock, err := helpers.LoadLock(helpers.LockPath())
+		if err != nil {
+			exit1(err.Error())
+		}
+		if _, ok := lock.Packages[args[0]]; ok {
+			delete(lock.Packages, args[0])
+			if err = lock.Save(helpers.LockPath()); err != nil {
+				exit1(err.Error())
+			}
+		}
+		helpers.Infof("Uninstalled %s", args[0])
+	},
+}
+
+func init() {
+	uninstallCmd.ValidArgsFunction = completeInstalled
+	Root.AddCommand(uninstallCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var upgradeAll bool
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [package]",
+	Short: "Upgrades installed packages to their latest version",
+	Long: `Upgrades an installed package, or every one with --all, to its latest version
+on Crackle. The new image is pulled and the package's config and shim are
+rewritten.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (upgradeAll && len(args) != 0) || (!upgradeAll && len(args) != 1) {
+			exit1(cmd.UsageString())
+		}
+		state, err := helpers.LoadState()
+		if err != nil {
+			exit1(err.Error())
+		}
+
+		names := args
+		if upgradeAll {
+			for _, p := range state.Installed() {
+				names = append(names, p.Name)
+			}
+		} else if _, ok := state.Packages[args[0]]; !ok {
+			exit1(fmt.Sprintf("Package %s isn't installed, install it with `cr install %s`", args[0], args[0]))
+		}
+
+		client := newClient()
+		ctx := context.Background()
+
+		upgraded := 0
+		for _, name := range names {
+			installed := state.Packages[name]
+			pkg := getPackageVersion(ctx, client, name, "")
+			if pkg.Version == installed.Version {
+				continue
+			}
+
+			pt, err := helpers.PackageToPackageToml(pkg)
+			if err != nil {
+				exit1(err.Error())
+			}
+			if err = helpers.PullImageContext(ctx, pt.Repository); err != nil {
+				exit1(fmt.Sprintf("Pulling %s: %s", pt.Repository, err))
+			}
+			if err = installPackage(ctx, client, state, pkg); err != nil {
+				exit1(err.Error())
+			}
+			// Save after every package so a later failure doesn't lose earlier upgrades
+			if err = state.Save(); err != nil {
+				exit1(err.Error())
+			}
+			helpers.Infof("Upgraded %s %s -> %s", name, installed.Version, pkg.Version)
+			upgraded++
+		}
+		if upgraded == 0 {
+			helpers.Infof("All packages are up to date")
+		}
+	},
+}
+
+func init() {
+	upgradeCmd.ValidArgsFunction = completeInstalled
+	upgradeCmd.Flags().BoolVarP(&upgradeAll, "all", "a", false, "Upgrade every installed package")
+	Root.AddCommand(upgradeCmd)
+}
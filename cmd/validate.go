@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var (
+	validateFormat string
+	validateStrict bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "Validates and lints a package manifest for CI",
+	Long: `Validates and lints a package manifest, exiting 1 when it couldn't be published.
+
+With --strict warnings fail validation too, --format json prints the result as
+json for CI pipelines. Path defaults to the current directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit1(cmd.UsageString())
+		}
+		if validateFormat != "text" && validateFormat != "json" {
+			exit1(fmt.Sprintf("Unknown format \"%s\", use text or json", validateFormat))
+		}
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		v := helpers.ValidateManifestContext(context.Background(), path)
+		if validateFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(v); err != nil {
+				exit1(err.Error())
+			}
+		} else {
+			for _, e := range v.Errors {
+				fmt.Printf("error: %s\n", e)
+			}
+			for _, w := range v.Warnings {
+				fmt.Println(w)
+			}
+			if v.Passed(validateStrict) {
+				fmt.Printf("%s is valid\n", v.Path)
+			}
+		}
+
+		if !v.Passed(validateStrict) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Output format, text or json")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Fail on warnings as well as errors")
+	Root.AddCommand(validateCmd)
+}
@@ -3,24 +3,23 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	"github.com/sunshinekitty/cr/pkg/crackle"
 )
 
-const (
-	version = "0.0.0"
-)
+// devVersion is the version of builds that weren't given one, such as go get
+const devVersion = "dev"
+
+// version is the release cr was built as, set by building releases with
+// -ldflags "-X github.com/sunshinekitty/cr/cmd.version=1.2.0"
+var version = devVersion
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version of client and configured endpoint",
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("Client: %s\n", version)
-		client := crackle.NewClient(nil)
-		client.BaseURL, _ = url.Parse(viper.GetString("crackle.api"))
+		client := newClient()
 		ctx := context.Background()
 		server, resp, _ := client.Version.Server(ctx)
 		switch resp.StatusCode {
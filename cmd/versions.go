@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions [package]",
+	Short: "Lists every published version of a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		versions, resp, err := client.Package.ListVersions(context.Background(), args[0])
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 404:
+			exit1(fmt.Sprintf("Package %s not found", args[0]))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(versions, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tPUBLISHED\tYANKED")
+			for _, v := range versions {
+				yanked := ""
+				if v.Yanked {
+					yanked = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", v.Version, date(v.CreatedAt), yanked)
+			}
+			w.Flush()
+		})
+	},
+}
+
+func init() {
+	versionsCmd.ValidArgsFunction = completeRegistry
+	Root.AddCommand(versionsCmd)
+}
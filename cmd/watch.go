@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// watchDebounce is how long files have to stop changing before the package is restarted
+const watchDebounce = 300 * time.Millisecond
+
+var (
+	watchPaths     []string
+	watchOverrides helpers.RunOverrides
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [package] [-- args...]",
+	Short: "Runs a package and restarts it whenever files change",
+	Long: `Runs a package like cr run and restarts its container whenever files change,
+for packaged dev servers, test runners and linters.
+
+The local directories the package mounts as volumes are watched, or the paths
+given with --path. Version control directories and node_modules are ignored.
+Stop watching with Ctrl-C.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var extraArgs []string
+		if dash := cmd.ArgsLenAtDash(); dash != -1 {
+			args, extraArgs = args[:dash], args[dash:]
+		}
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		pkg := helpers.ResolveAlias(args[0])
+		if !helpers.ValidPackageName(pkg) {
+			exit1("Invalid package")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		pt := loadRunnable(ctx, pkg, watchOverrides, viper.GetBool("run.verify_signatures"))
+		if err := helpers.ApplyPullPolicyContext(ctx, pt.Repository, helpers.PullMissing); err != nil {
+			exit1(err.Error())
+		}
+
+		paths := watchPaths
+		if len(paths) == 0 {
+			paths = helpers.WatchPaths(pt)
+		}
+		if len(paths) == 0 {
+			exit1(fmt.Sprintf("Package %s mounts no local directories, give the paths to watch with --path", pkg))
+		}
+		changes, err := helpers.WatchChangesContext(ctx, paths, watchDebounce)
+		if err != nil {
+			exit1(err.Error())
+		}
+		helpers.Infof("Watching %s for changes", helpers.ShellJoin(paths))
+
+		name, dockerArgs := helpers.PackageTomlToWatchArgs(pt, extraArgs...)
+		for {
+			c, err := helpers.StartCmd(name, dockerArgs)
+			if err != nil {
+				exit1(err.Error())
+			}
+			exited := make(chan error, 1)
+			go func() { exited <- c.Wait() }()
+
+			select {
+			case path := <-changes:
+				helpers.Infof("%s changed, restarting %s", path, pkg)
+				stopWatchContainer(pkg)
+				<-exited
+			case err = <-exited:
+				if err != nil {
+					helpers.Warnf("%s exited: %s", pkg, err)
+				}
+				helpers.Infof("Waiting for changes to restart %s", pkg)
+				select {
+				case <-changes:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				stopWatchContainer(pkg)
+				<-exited
+				return
+			}
+		}
+	},
+}
+
+// stopWatchContainer stops and removes the container cr watch runs a package
+// in, so it can be started again under the same name
+func stopWatchContainer(pkg string) {
+	// The interrupt that stopped watching has already canceled any other context
+	ctx := context.Background()
+	container := helpers.WatchContainerName(pkg)
+	if _, err := helpers.DockerOutputContext(ctx, "stop", "--time", "2", container); err != nil {
+		helpers.Debugf("stopping %s: %s", container, err)
+	}
+	// --rm may not have removed it yet by the time docker run exits
+	helpers.DockerOutputContext(ctx, "rm", "--force", container)
+}
+
+func init() {
+	watchCmd.ValidArgsFunction = completeInstalled
+	watchCmd.Flags().StringArrayVar(&watchPaths, "path", nil, "Watch this path instead of the package's volumes, can be repeated")
+	watchCmd.Flags().StringArrayVarP(&watchOverrides.Ports, "publish", "p", nil, "Publish a container port to the host (local:container)")
+	watchCmd.Flags().StringArrayVarP(&watchOverrides.Volumes, "volume", "v", nil, "Bind mount a volume (local:container)")
+	watchCmd.Flags().StringArrayVarP(&watchOverrides.Env, "env", "e", nil, "Set an environment variable (KEY=value, or KEY to pass it through)")
+	Root.AddCommand(watchCmd)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var webhookEvents []string
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Manage the webhooks of a package",
+	Long: `Webhooks are URLs the registry POSTs a json event to when a package is published,
+yanked, unyanked, deprecated or undeprecated. Each call carries the event in
+X-Crackle-Event and an HMAC-SHA256 of its body, keyed by the webhook's secret,
+in X-Crackle-Signature as sha256=<hex>.`,
+}
+
+var webhookListCmd = &cobra.Command{
+	Use:   "list [package]",
+	Short: "Lists the webhooks of a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		if !helpers.ValidPackageName(args[0]) {
+			exit1("Invalid package")
+		}
+
+		client := newClient()
+		webhooks, resp, err := client.Package.ListWebhooks(context.Background(), args[0])
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 401:
+			exit1("Login with `cr login` first")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(webhooks, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tURL\tEVENTS\tLAST STATUS")
+			for _, h := range webhooks {
+				events := "all"
+				if len(h.Events) > 0 {
+					events = strings.Join(h.Events, ",")
+				}
+				status := "never called"
+				if h.LastStatus != nil && *h.LastStatus == 0 {
+					status = "unreachable"
+				} else if h.LastStatus != nil {
+					status = strconv.Itoa(*h.LastStatus)
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", h.ID, h.URL, events, status)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var webhookAddCmd = &cobra.Command{
+	Use:   "add [package] [url]",
+	Short: "Adds a webhook to a package, its secret is only shown once",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit1(cmd.UsageString())
+		}
+		name, hookURL := args[0], args[1]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+		if err := helpers.ValidWebhook(hookURL, webhookEvents); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		webhook, resp, err := client.Package.CreateWebhook(context.Background(), name, hookURL, webhookEvents)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 201:
+		case 401:
+			exit1("Login with `cr login` first")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		render(webhook, func() {
+			fmt.Println(webhook.Secret)
+		})
+		helpers.Infof("Added webhook %d to %s, the secret above signs its calls and won't be shown again", webhook.ID, name)
+	},
+}
+
+var webhookRemoveCmd = &cobra.Command{
+	Use:   "remove [package] [id]",
+	Short: "Removes a webhook from a package",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit1(cmd.UsageString())
+		}
+		name := args[0]
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			exit1(fmt.Sprintf("Webhook id \"%s\" is invalid", args[1]))
+		}
+
+		client := newClient()
+		resp, err := client.Package.DeleteWebhook(context.Background(), name, id)
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			helpers.Infof("Removed webhook %d from %s", id, name)
+		case 401:
+			exit1("Login with `cr login` first")
+		case 404:
+			exit1(fmt.Sprintf("Webhook %d of %s not found", id, name))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+func init() {
+	webhookAddCmd.Flags().StringSliceVar(&webhookEvents, "event", nil, "Only call the webhook for these events, publish, yank, unyank, deprecate or undeprecate")
+	webhookListCmd.ValidArgsFunction = completeRegistry
+	webhookAddCmd.ValidArgsFunction = completeRegistry
+	webhookRemoveCmd.ValidArgsFunction = completeRegistry
+	webhookCmd.AddCommand(webhookListCmd)
+	webhookCmd.AddCommand(webhookAddCmd)
+	webhookCmd.AddCommand(webhookRemoveCmd)
+	Root.AddCommand(webhookCmd)
+}
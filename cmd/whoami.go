@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Shows who you're logged in to Crackle as",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit1(cmd.UsageString())
+		}
+		if _, err := helpers.LoadCredentials(); err != nil {
+			exit1(err.Error())
+		}
+
+		client := newClient()
+		identity, resp, err := client.Auth.WhoAmI(context.Background())
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 200:
+		case 401:
+			exit1("Your token is invalid or revoked, login again with `cr login`")
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+
+		fmt.Printf("Logged in to %s as %s\n", viper.GetString("crackle.api"), identity.Username)
+		fmt.Printf("Packages: %d\n", identity.Packages)
+		fmt.Printf("Token scopes: %s\n", strings.Join(identity.Scopes, ", "))
+		if identity.Email != "" {
+			fmt.Printf("Email: %s\n", identity.Email)
+			if len(identity.Notifications) == 0 {
+				fmt.Println("Notifications: none")
+			} else {
+				fmt.Printf("Notifications: %s\n", strings.Join(identity.Notifications, ", "))
+			}
+		}
+		switch {
+		case identity.TwoFactorPublish:
+			fmt.Println("Two-factor authentication: login, publish")
+		case identity.TwoFactor:
+			fmt.Println("Two-factor authentication: login")
+		}
+		if identity.SigningKey != "" {
+			fmt.Printf("Signing key: %s\n", helpers.KeyFingerprint(identity.SigningKey))
+		}
+		if identity.PendingEmail != "" {
+			fmt.Printf("Unverified email: %s, verify it with cr account verify\n", identity.PendingEmail)
+		}
+	},
+}
+
+func init() {
+	Root.AddCommand(whoamiCmd)
+}
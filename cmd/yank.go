@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+var yankUndo bool
+
+var yankCmd = &cobra.Command{
+	Use:   "yank [package]@[version]",
+	Short: "Stops a published version being installed by default",
+	Long: `Yanks a published version of a package. Yanked versions are skipped when the
+latest version is looked up but can still be installed by version, so anyone
+pinned to it keeps working. --undo reverses a yank.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit1(cmd.UsageString())
+		}
+		name, version := helpers.SplitPackageVersion(args[0])
+		if !helpers.ValidPackageName(name) {
+			exit1("Invalid package")
+		}
+		if version == "" {
+			exit1("Provide the version to yank as [package]@[version]")
+		}
+
+		client := newClient()
+		resp, err := client.Package.YankVersion(context.Background(), name, version, !yankUndo)
+		if otpRetry(client, resp) {
+			resp, err = client.Package.YankVersion(context.Background(), name, version, !yankUndo)
+		}
+		if resp == nil {
+			exit1(err.Error())
+		}
+		switch resp.StatusCode {
+		case 204:
+			if yankUndo {
+				helpers.Infof("Restored %s@%s", name, version)
+			} else {
+				helpers.Infof("Yanked %s@%s", name, version)
+			}
+		case 404:
+			exit1(fmt.Sprintf("Package %s version %s not found", name, version))
+		default:
+			exit1(fmt.Sprintf("%v: %s", resp.StatusCode, err))
+		}
+	},
+}
+
+func init() {
+	yankCmd.Flags().BoolVar(&yankUndo, "undo", false, "Restore a yanked version")
+	addOTPFlag(yankCmd)
+	Root.AddCommand(yankCmd)
+}
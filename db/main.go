@@ -5,27 +5,52 @@ import (
 	"log"
 
 	"github.com/sunshinekitty/cr/handlers"
+	"github.com/sunshinekitty/cr/helpers"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/spf13/viper"
 )
 
-// InitDB initializes all database pointers in the project
+// InitDB initializes all database pointers in the project, connecting to
+// database.url when it's set (by cr server --db) or else the database described
+// by the rest of [database]
 func InitDB() {
-	if viper.GetString("database.driver") == "psql" {
-		var err error
-		handlers.DB, err = sqlx.Connect("postgres",
-			fmt.Sprintf("user=%s password=%s dbname=%s sslmode=disable",
-				viper.GetString("database.user"),
-				viper.GetString("database.pass"),
-				viper.GetString("database.db")))
-		if err != nil {
-			log.Fatalln(err)
-		}
+	driver, source, err := dataSource()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	handlers.DB, err = sqlx.Connect(driver, source)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	handlers.Dialect = dialect(driver)
 
-		handlers.DB.SetMaxOpenConns(viper.GetInt("database.MaxConnections"))
-	} else {
-		log.Fatalln("Invalid database '", viper.GetString("database.driver"), "' specified")
+	handlers.DB.SetMaxOpenConns(viper.GetInt("database.MaxConnections"))
+}
+
+// dialect returns the Dialect of the database a driver connects to
+func dialect(driver string) helpers.Dialect {
+	if driver == helpers.DriverSQLite {
+		return helpers.SQLiteDialect{}
+	}
+	return helpers.PostgresDialect{}
+}
+
+// dataSource returns the driver and data source of the configured database
+func dataSource() (driver string, source string, err error) {
+	if url := viper.GetString("database.url"); url != "" {
+		return helpers.ParseDatabaseURL(url)
+	}
+	if viper.GetString("database.driver") != "psql" {
+		return "", "", fmt.Errorf("Invalid database '%s' specified", viper.GetString("database.driver"))
+	}
+	source = fmt.Sprintf("user=%s password=%s dbname=%s sslmode=disable",
+		viper.GetString("database.user"),
+		viper.GetString("database.pass"),
+		viper.GetString("database.db"))
+	if host := viper.GetString("database.host"); host != "" {
+		source += " host=" + host
 	}
+	return helpers.DriverPostgres, source, nil
 }
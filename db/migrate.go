@@ -0,0 +1,143 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/sunshinekitty/cr/handlers"
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// migrationLock is the Postgres advisory lock held while a migration runs, so
+// registries starting together don't migrate at once
+const migrationLock = 3813
+
+// migrationFiles are the schema migrations built into cr
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationDirs are the directories of migrationFiles holding the migrations of
+// each driver. SQLite databases start from the schema Postgres had when SQLite
+// was added rather than replaying its history.
+var migrationDirs = map[string]string{
+	helpers.DriverPostgres: "migrations",
+	helpers.DriverSQLite:   "migrations/sqlite",
+}
+
+// Migrations returns the schema migrations built into cr for the database
+// handlers.DB is, oldest first
+func Migrations() ([]helpers.Migration, error) {
+	fsys, err := fs.Sub(migrationFiles, migrationDirs[handlers.Dialect.Driver()])
+	if err != nil {
+		return nil, err
+	}
+	return helpers.ParseMigrations(fsys)
+}
+
+// SchemaVersion returns the version of the last migration run against d, 0
+// before any has. Dirty is true when a migration failed part way, which
+// migrations run by cr never leave behind. schema_migrations is laid out the
+// way mattes/migrate keeps it, so databases it migrated carry on from there.
+func SchemaVersion(d *sqlx.DB) (version int64, dirty bool, err error) {
+	if _, err = d.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)"); err != nil {
+		return 0, false, err
+	}
+	return schemaVersion(d)
+}
+
+// schemaVersion reads schema_migrations through q
+func schemaVersion(q sqlx.Queryer) (version int64, dirty bool, err error) {
+	row := q.QueryRowx("SELECT version, dirty FROM schema_migrations LIMIT 1")
+	if err = row.Scan(&version, &dirty); err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// setSchemaVersion records the version the schema is at, 0 clears it
+func setSchemaVersion(tx *sqlx.Tx, version int64) error {
+	if _, err := tx.Exec("DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	_, err := tx.Exec("INSERT INTO schema_migrations(version, dirty) VALUES($1, false)", version)
+	return err
+}
+
+// Migrate runs the steps of a migration plan against d in order, calling done
+// after each. Every step runs in its own transaction with the schema version it
+// sets, so a failed step changes nothing and later runs start over from it.
+func Migrate(d *sqlx.DB, steps []helpers.MigrationStep, done func(helpers.MigrationStep)) error {
+	if _, _, err := SchemaVersion(d); err != nil {
+		return err
+	}
+	for _, step := range steps {
+		if err := migrateStep(d, step); err != nil {
+			return fmt.Errorf("migration %d_%s: %s", step.Version, step.Name, err)
+		}
+		done(step)
+	}
+	return nil
+}
+
+// migrateStep runs a single step, refusing when the schema was changed by
+// someone else since the plan was made
+func migrateStep(d *sqlx.DB, step helpers.MigrationStep) error {
+	tx, err := d.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// SQLite transactions already lock the whole database when they begin
+	if handlers.Dialect.Driver() == helpers.DriverPostgres {
+		if _, err = tx.Exec("SELECT pg_advisory_xact_lock($1)", migrationLock); err != nil {
+			return err
+		}
+	}
+	version, dirty, err := schemaVersion(tx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("the schema is dirty at version %d, fix it by hand then run cr server migrate --force", version)
+	}
+	if version != step.Before {
+		return fmt.Errorf("expected the schema at version %d but it's at %d, run cr server migrate again", step.Before, version)
+	}
+
+	query := step.UpSQL
+	if !step.Up {
+		query = step.DownSQL
+	}
+	if _, err = tx.Exec(query); err != nil {
+		return err
+	}
+	if err = setSchemaVersion(tx, step.After); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ForceSchemaVersion records version as the schema's without running any
+// migration, for a schema migrated by hand or left dirty
+func ForceSchemaVersion(d *sqlx.DB, version int64) error {
+	if _, _, err := SchemaVersion(d); err != nil {
+		return err
+	}
+	tx, err := d.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = setSchemaVersion(tx, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
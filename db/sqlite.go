@@ -0,0 +1,73 @@
+//go:build cgo
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"math"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// sqlitePlaceholder matches the $1 style placeholders of the registry's queries
+var sqlitePlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+func init() {
+	sql.Register(helpers.DriverSQLite, &sqliteDriver{sqlite3.SQLiteDriver{ConnectHook: sqliteConnect}})
+	sqlx.BindDriver(helpers.DriverSQLite, sqlx.QUESTION)
+}
+
+// sqliteConnect adds the functions of Postgres the registry's queries use to a
+// new SQLite connection
+func sqliteConnect(c *sqlite3.SQLiteConn) error {
+	return c.RegisterFunc("power", math.Pow, true)
+}
+
+// sqliteQuery rewrites the placeholders of query as ?1, ?2 and so on. SQLite
+// numbers $1 and $2 in the order they first appear rather than by their
+// number, so $2 before $1 would be bound to the first argument.
+func sqliteQuery(query string) string {
+	return sqlitePlaceholder.ReplaceAllString(query, "?$1")
+}
+
+// sqliteDriver is SQLite taking the registry's queries as they're written for
+// Postgres
+type sqliteDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+// Open opens a connection to a SQLite database
+func (d *sqliteDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.SQLiteDriver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{c.(*sqlite3.SQLiteConn)}, nil
+}
+
+// sqliteConn is a SQLite connection rewriting the placeholders of its queries
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.SQLiteConn.Prepare(sqliteQuery(query))
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.SQLiteConn.PrepareContext(ctx, sqliteQuery(query))
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.SQLiteConn.ExecContext(ctx, sqliteQuery(query), args)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.SQLiteConn.QueryContext(ctx, sqliteQuery(query), args)
+}
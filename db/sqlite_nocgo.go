@@ -0,0 +1,27 @@
+//go:build !cgo
+
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// errSQLiteCgo is returned opening a SQLite database in a cr built without cgo,
+// which SQLite is compiled with
+var errSQLiteCgo = errors.New("SQLite databases need cr built with cgo, build it with CGO_ENABLED=1 or use Postgres")
+
+func init() {
+	sql.Register(helpers.DriverSQLite, sqliteDriver{})
+}
+
+// sqliteDriver fails to open any SQLite database
+type sqliteDriver struct{}
+
+// Open returns errSQLiteCgo
+func (sqliteDriver) Open(name string) (driver.Conn, error) {
+	return nil, errSQLiteCgo
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// reservedOrder pages reserved names alphabetically
+var reservedOrder = []helpers.PageKey{{Column: "name"}}
+
+// reserveNameQuery reserves a package name, or updates why it's reserved
+const reserveNameQuery = `INSERT INTO reserved_names(name, reason, reserved_by) VALUES($1, $2, $3)
+						  ON CONFLICT (name) DO UPDATE SET reason=$2, reserved_by=$3`
+
+// RequireRegistryAdmin is middleware rejecting requests by users who aren't
+// admins of the registry, it follows RequireAuth
+func RequireRegistryAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !helpers.RegistryAdmin(authUsername(c)) {
+			return echo.NewHTTPError(http.StatusForbidden, "Only registry admins can moderate the registry")
+		}
+		return next(c)
+	}
+}
+
+// SuspendUser keeps a user from using their API tokens or logging in until
+// UnsuspendUser, their packages stay published
+func SuspendUser(c echo.Context) error {
+	m := new(models.Moderation)
+	if err := c.Bind(m); err != nil {
+		return err
+	}
+	if err := helpers.ValidModerationReason(m.Reason, false); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// Params
+	username := c.Param("username")
+
+	if username == authUsername(c) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Admins can't suspend themselves")
+	}
+
+	// Query
+	res, err := DB.Exec("UPDATE users SET suspended_at="+Dialect.Now()+", suspended_reason=$1 WHERE username=$2", m.Reason, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if updatedRows, _ := res.RowsAffected(); updatedRows == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("User %s not found", username))
+	}
+	audit(c, helpers.AuditUserSuspend, "", fmt.Sprintf("%s: %s", username, m.Reason))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnsuspendUser undoes SuspendUser
+func UnsuspendUser(c echo.Context) error {
+	// Params
+	username := c.Param("username")
+
+	// Query
+	res, err := DB.Exec("UPDATE users SET suspended_at=NULL, suspended_reason=NULL WHERE username=$1", username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if updatedRows, _ := res.RowsAffected(); updatedRows == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("User %s not found", username))
+	}
+	audit(c, helpers.AuditUserUnsuspend, "", username)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemovePackage deletes every version of a Package whatever its owners think,
+// such as when it's malicious. The reason query param is required, with the
+// reserve query param set its name is reserved so it can't be published again.
+// Its open reports are resolved as removed.
+func RemovePackage(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	reason := c.QueryParam("reason")
+	reserve := c.QueryParam("reserve") == "true"
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := helpers.ValidModerationReason(reason, false); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Query
+	tx, err := DB.Beginx()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	defer tx.Rollback()
+	objects, err := deletePackageRows(tx, name)
+	if err != nil {
+		return err
+	}
+	if reserve {
+		if _, err = tx.Exec(reserveNameQuery, name, reason, authUsername(c)); err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+	}
+	_, err = tx.Exec(`UPDATE reports SET status=$1, resolved_by=$2, resolved_at=`+Dialect.Now()+`, resolution=$3
+					  WHERE name=$4 AND status=$5`, helpers.ReportRemoved, authUsername(c), reason, name, helpers.ReportOpen)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	deleteObjects(objects)
+	invalidateCache(name)
+	audit(c, helpers.AuditPackageRemove, name, reason)
+	if reserve {
+		audit(c, helpers.AuditNameReserve, name, reason)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReadReservedNames returns the package names reserved by admins, alphabetically
+func ReadReservedNames(c echo.Context) error {
+	limit, after, err := pageParams(c, reservedOrder, helpers.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	cond, args := pageAfter(reservedOrder, after, nil)
+	args = append(args, limit+1)
+
+	// Query
+	query := fmt.Sprintf(`SELECT name, reason, reserved_by, created_at FROM reserved_names
+						  WHERE %s ORDER BY %s LIMIT $%d`, cond, helpers.PageOrder(reservedOrder), len(args))
+	reserved := models.ReservedNames{Reserved: []models.ReservedName{}}
+	if err = DB.Select(&reserved.Reserved, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(reserved.Reserved) > limit {
+		reserved.Reserved = reserved.Reserved[:limit]
+		reserved.Next = helpers.EncodePageToken(reserved.Reserved[limit-1].Name)
+	}
+
+	return c.JSON(http.StatusOK, reserved)
+}
+
+// ReserveName keeps anyone from publishing a package name, whether or not it's
+// been published before. Reserving a reserved name again updates its reason.
+func ReserveName(c echo.Context) error {
+	m := new(models.Moderation)
+	if err := c.Bind(m); err != nil {
+		return err
+	}
+	if err := helpers.ValidModerationReason(m.Reason, true); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusBadRequest, helpers.ErrInvalidPackageName.Error())
+	}
+	var reason *string
+	if m.Reason != "" {
+		reason = &m.Reason
+	}
+
+	// Query
+	if _, err := DB.Exec(reserveNameQuery, name, reason, authUsername(c)); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	audit(c, helpers.AuditNameReserve, name, m.Reason)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnreserveName undoes ReserveName
+func UnreserveName(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	// Query
+	res, err := DB.Exec("DELETE FROM reserved_names WHERE name=$1", name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if deletedRows, _ := res.RowsAffected(); deletedRows == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Name %s isn't reserved", name))
+	}
+	audit(c, helpers.AuditNameUnreserve, name, "")
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requireUnreserved returns a 403 error when admins reserved a package name
+func requireUnreserved(name string) error {
+	var reason sql.NullString
+	err := DB.Get(&reason, "SELECT reason FROM reserved_names WHERE name=$1", name)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if reason.Valid {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Name %s is reserved by the registry: %s", name, reason.String))
+	}
+	return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Name %s is reserved by the registry", name))
+}
+
+// suspendedError returns the 403 error for a request by a suspended user
+func suspendedError(username string, reason sql.NullString) error {
+	return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Account %s is suspended: %s", username, reason.String))
+}
+
+// userSuspension returns whether username is suspended and why
+func userSuspension(username string) (bool, sql.NullString, error) {
+	var row struct {
+		Suspended bool
+		Reason    sql.NullString
+	}
+	err := DB.Get(&row, "SELECT suspended_at IS NOT NULL AS suspended, suspended_reason AS reason FROM users WHERE username=$1", username)
+	if err == sql.ErrNoRows {
+		return false, row.Reason, nil
+	}
+	return row.Suspended, row.Reason, err
+}
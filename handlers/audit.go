@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// auditOrder pages the audit log newest first
+var auditOrder = []helpers.PageKey{{Column: "id", Desc: true}}
+
+// audit records a change made by the authenticated user to the audit log, name
+// is the package changed if any. Failing to record it is only logged, the
+// change has already been made.
+func audit(c echo.Context, action string, name string, detail string) {
+	auditAs(c, authUsername(c), action, name, detail)
+}
+
+// auditAs is audit for changes made before the request is authenticated, such
+// as logging in
+func auditAs(c echo.Context, actor string, action string, name string, detail string) {
+	var namePtr, detailPtr *string
+	if name != "" {
+		namePtr = &name
+	}
+	if detail != "" {
+		detailPtr = &detail
+	}
+	_, err := DB.Exec("INSERT INTO audit_log(name, action, actor, ip, detail) VALUES($1, $2, $3, $4, $5)",
+		namePtr, action, actor, clientIP(c), detailPtr)
+	if err != nil {
+		log.Errorf("couldn't audit %s of %s by %s: %s", action, name, actor, err)
+	}
+}
+
+// ReadPackageAudit returns the audit log of a Package to its owners and the
+// registry's admins, newest first, filtered by the actor and action query params
+func ReadPackageAudit(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if !helpers.RegistryAdmin(authUsername(c)) {
+		if err := requirePublishedOwner(name, authUsername(c)); err != nil {
+			return err
+		}
+	}
+
+	return readAuditLog(c, []string{"name = $1"}, []interface{}{name})
+}
+
+// ReadAuditLog returns the audit log of the whole registry to its admins, newest
+// first, filtered by the package, actor and action query params
+func ReadAuditLog(c echo.Context) error {
+	if !helpers.RegistryAdmin(authUsername(c)) {
+		return echo.NewHTTPError(http.StatusForbidden, "Only registry admins can read the audit log, package owners can read their package's")
+	}
+
+	// Params
+	where := []string{"TRUE"}
+	var args []interface{}
+	if name := c.QueryParam("package"); name != "" {
+		args = append(args, name)
+		where = append(where, fmt.Sprintf("name = $%d", len(args)))
+	}
+
+	return readAuditLog(c, where, args)
+}
+
+// readAuditLog responds with a page of the audit log entries matching where and
+// the actor and action query params
+func readAuditLog(c echo.Context, where []string, args []interface{}) error {
+	if actor := c.QueryParam("actor"); actor != "" {
+		args = append(args, actor)
+		where = append(where, fmt.Sprintf("actor = $%d", len(args)))
+	}
+	if action := c.QueryParam("action"); action != "" {
+		if err := helpers.ValidAuditAction(action); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		args = append(args, action)
+		where = append(where, fmt.Sprintf("action = $%d", len(args)))
+	}
+	limit, after, err := pageParams(c, auditOrder, helpers.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	cond, args := pageAfter(auditOrder, after, args)
+	args = append(args, limit+1)
+
+	// Query
+	query := fmt.Sprintf(`SELECT id, name, action, actor, ip, detail, created_at FROM audit_log
+						  WHERE %s AND %s ORDER BY %s LIMIT $%d`,
+		strings.Join(where, " AND "), cond, helpers.PageOrder(auditOrder), len(args))
+	entries := models.AuditLog{Entry: []models.AuditEntry{}}
+	if err = DB.Select(&entries.Entry, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(entries.Entry) > limit {
+		entries.Entry = entries.Entry[:limit]
+		entries.Next = helpers.EncodePageToken(strconv.Itoa(entries.Entry[limit-1].ID))
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
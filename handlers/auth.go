@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// usernameKey is the context key RequireAuth stores the authenticated username under
+	usernameKey = "username"
+	// scopesKey is the context key RequireAuth stores the token's scopes under
+	scopesKey = "scopes"
+)
+
+// Login exchanges a username and password for an API token
+func Login(c echo.Context) error {
+	l := new(models.Login)
+	if err := c.Bind(l); err != nil {
+		return err
+	}
+
+	// Query
+	var password string
+	err := DB.Get(&password, "SELECT password FROM users WHERE username=$1", l.Username)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	locked := 0
+	if err == nil {
+		if locked, err = lockedFor(l.Username); err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+	}
+	// Unknown users, locked accounts and wrong passwords aren't told apart,
+	// a locked account's password isn't checked until it's unlocked
+	if err == sql.ErrNoRows || locked > 0 || bcrypt.CompareHashAndPassword([]byte(password), []byte(l.Password)) != nil {
+		if err == nil && locked == 0 {
+			recordAuthFailure(l.Username)
+		}
+		return echo.NewHTTPError(http.StatusUnauthorized, "Username or password is incorrect")
+	}
+
+	return issueLoginToken(c, l.Username)
+}
+
+// issueLoginToken responds with a new API token for a user who just logged in,
+// unless they're suspended or didn't send a TOTP code with two-factor
+// authentication on
+func issueLoginToken(c echo.Context, username string) error {
+	suspended, reason, err := userSuspension(username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if suspended {
+		return suspendedError(username, reason)
+	}
+	if err = requireOTP(c, username, false); err != nil {
+		return err
+	}
+	resetAuthFailures(username)
+	token, err := helpers.NewToken()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	scopes, err := json.Marshal(helpers.DefaultTokenScopes)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	var id int
+	err = DB.Get(&id, "INSERT INTO tokens(token_hash, username, scopes) VALUES($1, $2, $3) RETURNING id", helpers.HashToken(token), username, string(scopes))
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	auditAs(c, username, helpers.AuditTokenCreate, "", tokenAuditDetail(id, "login", helpers.DefaultTokenScopes))
+	_, err = DB.Exec("UPDATE users SET last_login="+Dialect.Now()+" WHERE username=$1", username)
+	if err != nil {
+		log.Error(err)
+	}
+
+	return c.JSON(http.StatusCreated, models.Token{Username: username, Token: token})
+}
+
+// Logout revokes the API token the request was made with
+func Logout(c echo.Context) error {
+	token := helpers.BearerToken(c.Request().Header.Get("Authorization"))
+	_, err := DB.Exec("DELETE FROM tokens WHERE token_hash=$1", helpers.HashToken(token))
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// WhoAmI returns the Identity of the API token the request was made with
+func WhoAmI(c echo.Context) error {
+	identity := models.Identity{Username: authUsername(c), Scopes: authScopes(c), Notifications: []string{}}
+
+	// Query
+	if err := DB.Get(&identity.Packages, "SELECT count(*) FROM package_owners WHERE username=$1", identity.Username); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	var account struct {
+		Email            sql.NullString
+		PendingEmail     sql.NullString `db:"pending_email"`
+		Notifications    types.JSONText
+		TwoFactor        bool           `db:"two_factor"`
+		TwoFactorPublish bool           `db:"totp_publish"`
+		SigningKey       sql.NullString `db:"signing_key"`
+	}
+	err := DB.Get(&account, `SELECT email, notifications, totp_secret IS NOT NULL AS two_factor, totp_publish, signing_key,
+							 (SELECT email FROM email_verifications WHERE email_verifications.username=users.username) AS pending_email
+							 FROM users WHERE username=$1`, identity.Username)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if err == nil {
+		identity.Email, identity.PendingEmail = account.Email.String, account.PendingEmail.String
+		identity.TwoFactor, identity.TwoFactorPublish = account.TwoFactor, account.TwoFactorPublish
+		identity.SigningKey = account.SigningKey.String
+		if err = account.Notifications.Unmarshal(&identity.Notifications); err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+	}
+
+	return c.JSON(http.StatusOK, identity)
+}
+
+// RequireAuth is middleware rejecting requests without a valid API token, the
+// token's username is available to handlers through authUsername
+func RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := helpers.BearerToken(c.Request().Header.Get("Authorization"))
+		if token == "" {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Login with `cr login` first")
+		}
+
+		var row struct {
+			Username        string
+			Scopes          types.JSONText
+			Suspended       bool
+			SuspendedReason sql.NullString `db:"suspended_reason"`
+		}
+		err := DB.Get(&row, `UPDATE tokens SET last_used=`+Dialect.Now()+` WHERE token_hash=$1
+							 RETURNING username, scopes,
+							 EXISTS(SELECT 1 FROM users WHERE users.username=tokens.username AND suspended_at IS NOT NULL) AS suspended,
+							 (SELECT suspended_reason FROM users WHERE users.username=tokens.username) AS suspended_reason`,
+			helpers.HashToken(token))
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Token is invalid or revoked, login with `cr login` again")
+		}
+		if err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		if row.Suspended {
+			return suspendedError(row.Username, row.SuspendedReason)
+		}
+		scopes := []string{}
+		if err = row.Scopes.Unmarshal(&scopes); err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+
+		c.Set(usernameKey, row.Username)
+		c.Set(scopesKey, scopes)
+		return next(c)
+	}
+}
+
+// RequireScope returns middleware rejecting requests whose API token doesn't
+// have scope, it follows RequireAuth. Package scopes are checked against the
+// name param of the route.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := requireScope(c, scope, packageParam(c)); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// requireScope returns a 403 error unless the request's API token has scope on
+// the package name
+func requireScope(c echo.Context, scope string, name string) error {
+	if !helpers.HasScope(authScopes(c), scope, name) {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Token doesn't have the %s scope, create one that does with `cr token create`", scope))
+	}
+	return nil
+}
+
+// authUsername returns the username RequireAuth authenticated the request as
+func authUsername(c echo.Context) string {
+	username, _ := c.Get(usernameKey).(string)
+	return username
+}
+
+// authScopes returns the scopes of the API token RequireAuth authenticated the request with
+func authScopes(c echo.Context) []string {
+	scopes, _ := c.Get(scopesKey).([]string)
+	return scopes
+}
+
+// requireOwner returns a 403 error unless a package name is unpublished or
+// username is one of its owners, or an owner or publisher of its org
+func requireOwner(name string, username string) error {
+	org, err := packageOrg(name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if org != "" {
+		role, err := orgRole(org, username)
+		if err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		if helpers.OrgRoleCanPublish(role) {
+			return nil
+		}
+	}
+
+	owners, err := packageOwners(name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	for _, owner := range owners {
+		if owner == username {
+			return nil
+		}
+	}
+	if len(owners) != 0 {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Package %s is owned by %s", name, strings.Join(owners, ", ")))
+	}
+	if org != "" {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Package %s is owned by the %s org", name, org))
+	}
+
+	// A published package whose owners were all deleted isn't up for grabs
+	var published bool
+	if err = DB.Get(&published, "SELECT EXISTS(SELECT 1 FROM packages WHERE name=$1)", name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if published {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Package %s has no owners", name))
+	}
+	return nil
+}
+
+// packageOwners returns the usernames owning a package name, oldest first, none
+// when it hasn't been published
+func packageOwners(name string) ([]string, error) {
+	owners := []string{}
+	err := DB.Select(&owners, "SELECT username FROM package_owners WHERE name=$1 ORDER BY added_at, username", name)
+	return owners, err
+}
@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/labstack/echo"
+
+	"github.com/sunshinekitty/cr/handlers"
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/server"
+)
+
+func TestRequireScope(t *testing.T) {
+	e := server.New()
+	owner := newUser(t, "scopes", helpers.DefaultTokenScopes...)
+	publish(t, e, owner, "scoped", "1.0.0", false)
+
+	reader := newUser(t, "scopes-reader", helpers.ScopeRead)
+	if rec := request(e, "POST", "/api/package/", reader, `{"Name": "unscoped", "Version": "1.0.0", "Repository": "crackle/unscoped"}`); rec.Code != 403 {
+		t.Errorf("A read token shouldn't publish, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := request(e, "PUT", "/api/package/scoped/deprecate", reader, `{"Message": "gone"}`); rec.Code != 403 {
+		t.Errorf("A read token shouldn't deprecate, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := request(e, "GET", "/api/tokens", reader, ""); rec.Code != 200 {
+		t.Errorf("A read token should read, got %d %s", rec.Code, rec.Body)
+	}
+
+	// A package scope only publishes that package, and isn't admin
+	packageOnly := newUser(t, "scopes-package", helpers.ScopePackagePrefix+"scoped")
+	if _, err := handlers.DB.Exec("INSERT INTO package_owners(name, username) VALUES('scoped', 'scopes-package')"); err != nil {
+		t.Fatal(err)
+	}
+	publish(t, e, packageOnly, "scoped", "1.0.1", false)
+	if rec := request(e, "POST", "/api/package/", packageOnly, `{"Name": "other", "Version": "1.0.0", "Repository": "crackle/other"}`); rec.Code != 403 {
+		t.Errorf("A package scope shouldn't publish other packages, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := request(e, "POST", "/api/tokens", packageOnly, `{"Name": "more", "Scopes": ["admin"]}`); rec.Code != 403 {
+		t.Errorf("A package scope shouldn't create tokens, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	newUser(t, "lockout")
+	login := func(e *echo.Echo, password string) (int, string) {
+		rec := request(e, "POST", "/api/login", "", fmt.Sprintf(`{"username": "lockout", "password": %q}`, password))
+		return rec.Code, rec.Body.String()
+	}
+
+	// Rate limits are per server, so each round of guesses gets a new one
+	e := server.New()
+	for i := 0; i < 9; i++ {
+		login(e, "wrong")
+	}
+	if code, body := login(e, testPassword); code != 201 {
+		t.Fatalf("9 wrong passwords shouldn't lock the account, got %d %s", code, body)
+	}
+
+	e = server.New()
+	for i := 0; i < 10; i++ {
+		login(e, "wrong")
+	}
+	code, body := login(e, testPassword)
+	unknown := request(e, "POST", "/api/login", "", `{"username": "nobody", "password": "wrong"}`)
+	if code != 401 || code != unknown.Code || body != unknown.Body.String() {
+		t.Errorf("10 wrong passwords should lock the account like an unknown user, got %d %s", code, body)
+	}
+	var locked bool
+	if err := handlers.DB.Get(&locked, "SELECT locked_until > "+handlers.Dialect.Now()+" FROM users WHERE username='lockout'"); err != nil || !locked {
+		t.Errorf("The account should be locked, got %v %v", locked, err)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+const (
+	// badgeMaxAge is how long badges are cached by browsers and READMEs' image proxies
+	badgeMaxAge = "max-age=300"
+	// badgeNotFound is the message of badges of packages that aren't found
+	badgeNotFound = "not found"
+)
+
+// badge responds with an SVG badge. Badges are embedded as images, which don't
+// show error pages, so a package that isn't found still gets one.
+func badge(c echo.Context, label string, message string, color string) error {
+	c.Response().Header().Set("Cache-Control", badgeMaxAge)
+	return c.Blob(http.StatusOK, "image/svg+xml;charset=utf-8", helpers.Badge(label, message, color))
+}
+
+// ReadVersionBadge returns a badge of the latest version of a public Package
+func ReadVersionBadge(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return badge(c, "crackle", badgeNotFound, helpers.BadgeGrey)
+	}
+
+	// Query, private packages aren't found as badges are read without a token
+	var p struct {
+		Version    string
+		Deprecated *string
+	}
+	err := DB.Get(&p, `SELECT version, deprecated FROM packages WHERE name=$1 AND NOT yanked AND NOT private
+					   ORDER BY created_at DESC LIMIT 1`, name)
+	if err == sql.ErrNoRows {
+		return badge(c, "crackle", badgeNotFound, helpers.BadgeGrey)
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if p.Deprecated != nil {
+		return badge(c, "crackle", p.Version+" deprecated", helpers.BadgeRed)
+	}
+
+	return badge(c, "crackle", p.Version, helpers.VersionBadgeColor(p.Version))
+}
+
+// ReadPullsBadge returns a badge of how often every version of a public Package
+// was pulled
+func ReadPullsBadge(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return badge(c, "pulls", badgeNotFound, helpers.BadgeGrey)
+	}
+
+	// Query
+	var pulls sql.NullInt64
+	err := DB.Get(&pulls, "SELECT sum(pulls) FROM packages WHERE name=$1 AND NOT private", name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !pulls.Valid {
+		return badge(c, "pulls", badgeNotFound, helpers.BadgeGrey)
+	}
+
+	return badge(c, "pulls", helpers.BadgeCount(int(pulls.Int64)), helpers.BadgeGreen)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+const (
+	// defaultCacheTTL is how long reads are cached when cache.ttl isn't set
+	defaultCacheTTL = time.Minute
+	// cacheHeader says whether a read was answered from the cache
+	cacheHeader = "X-Crackle-Cache"
+	// searchCacheKey is the Redis hash search results are cached in
+	searchCacheKey = "crackle:search"
+)
+
+var (
+	redisMu     sync.Mutex
+	redisURL    string
+	redisClient *helpers.RedisClient
+)
+
+// cacheClient returns a client of the Redis server in cache.redis, nil when it
+// isn't set. The client is made again when server.toml changes it.
+func cacheClient() *helpers.RedisClient {
+	redisMu.Lock()
+	defer redisMu.Unlock()
+	u := viper.GetString("cache.redis")
+	if u == redisURL {
+		return redisClient
+	}
+	redisURL, redisClient = u, nil
+	if u == "" {
+		return nil
+	}
+	client, err := helpers.NewRedisClient(u)
+	if err != nil {
+		log.Errorf("not caching reads: %s", err)
+		return nil
+	}
+	redisClient = client
+	return redisClient
+}
+
+// cacheTTL returns how long reads are cached
+func cacheTTL() time.Duration {
+	if ttl := viper.GetDuration("cache.ttl"); ttl > 0 {
+		return ttl
+	}
+	return defaultCacheTTL
+}
+
+// packageCacheKey returns the Redis hash a package's reads are cached in
+func packageCacheKey(name string) string {
+	return "crackle:package:" + name
+}
+
+// Cache is middleware answering reads of public packages and anonymous
+// searches from Redis when cache.redis is set in server.toml, for cache.ttl.
+// Reads of private packages and searches by users, who can see private
+// packages, are never cached. Every change to a package drops its cached reads
+// and every cached search, see invalidateCache.
+func Cache(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		client := cacheClient()
+		if client == nil {
+			return next(c)
+		}
+		name := packageParam(c)
+		key := packageCacheKey(name)
+		if name == "" {
+			if authUsername(c) != "" {
+				return next(c)
+			}
+			key = searchCacheKey
+		}
+		field := c.Request().URL.RequestURI()
+
+		// Entries are prefixed with when they expire, the hash they're in only
+		// expires once none has been added for a while
+		now := time.Now()
+		cached, ok, err := client.HGet(key, field)
+		if err != nil {
+			log.Warnf("couldn't read the cache: %s", err)
+		}
+		if ok {
+			if i := bytes.IndexByte(cached, '\n'); i != -1 {
+				expires, _ := strconv.ParseInt(string(cached[:i]), 10, 64)
+				if now.Unix() < expires {
+					c.Response().Header().Set(cacheHeader, "hit")
+					return c.JSONBlob(http.StatusOK, cached[i+1:])
+				}
+			}
+		}
+
+		body := new(bytes.Buffer)
+		writer := c.Response().Writer
+		c.Response().Writer = &teeResponseWriter{ResponseWriter: writer, w: io.MultiWriter(writer, body)}
+		c.Response().Header().Set(cacheHeader, "miss")
+		err = next(c)
+		c.Response().Writer = writer
+		if err != nil || c.Response().Status != http.StatusOK {
+			return err
+		}
+		if name != "" {
+			var private bool
+			if dbErr := DB.Get(&private, "SELECT EXISTS(SELECT 1 FROM packages WHERE name=$1 AND private)", name); dbErr != nil || private {
+				return nil
+			}
+		}
+		ttl := cacheTTL()
+		entry := append([]byte(strconv.FormatInt(now.Add(ttl).Unix(), 10)+"\n"), body.Bytes()...)
+		if err = client.HSet(key, field, entry, ttl); err != nil {
+			log.Warnf("couldn't write the cache: %s", err)
+		}
+		return nil
+	}
+}
+
+// invalidateCache drops the cached reads of a package and every cached search,
+// after it changed
+func invalidateCache(name string) {
+	client := cacheClient()
+	if client == nil {
+		return
+	}
+	if err := client.Del(packageCacheKey(name), searchCacheKey); err != nil {
+		log.Warnf("couldn't drop cached reads of %s: %s", name, err)
+	}
+}
+
+// teeResponseWriter is a response writer keeping a copy of what's written
+type teeResponseWriter struct {
+	http.ResponseWriter
+	w io.Writer
+}
+
+func (t *teeResponseWriter) Write(b []byte) (int, error) {
+	return t.w.Write(b)
+}
@@ -0,0 +1,151 @@
+package handlers_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/server"
+)
+
+// fakeRedis serves HGET, HSET, PEXPIRE and DEL from a map, returning its address
+func fakeRedis(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	var mu sync.Mutex
+	hashes := map[string]map[string]string{}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					args, err := readCommand(r)
+					if err != nil {
+						return
+					}
+					mu.Lock()
+					switch args[0] {
+					case "HGET":
+						if v, ok := hashes[args[1]][args[2]]; ok {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+						} else {
+							fmt.Fprint(conn, "$-1\r\n")
+						}
+					case "HSET":
+						if hashes[args[1]] == nil {
+							hashes[args[1]] = map[string]string{}
+						}
+						hashes[args[1]][args[2]] = args[3]
+						fmt.Fprint(conn, ":1\r\n")
+					case "PEXPIRE":
+						fmt.Fprint(conn, ":1\r\n")
+					case "DEL":
+						for _, k := range args[1:] {
+							delete(hashes, k)
+						}
+						fmt.Fprintf(conn, ":%d\r\n", len(args)-1)
+					default:
+						fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
+
+func TestCache(t *testing.T) {
+	addr := fakeRedis(t)
+	viper.Set("cache.redis", "redis://"+addr)
+	t.Cleanup(func() { viper.Set("cache.redis", "") })
+	redis := &helpers.RedisClient{Addr: addr}
+	cached := func(key string, field string) bool {
+		t.Helper()
+		_, ok, err := redis.HGet(key, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	e := server.New()
+	owner := newUser(t, "cache-owner", helpers.DefaultTokenScopes...)
+	publish(t, e, owner, "cached", "1.0.0", false)
+	publish(t, e, owner, "uncached", "1.0.0", true)
+
+	for i, want := range []string{"miss", "hit"} {
+		if rec := request(e, "GET", "/api/package/cached", "", ""); rec.Code != 200 || rec.Header().Get("X-Crackle-Cache") != want {
+			t.Errorf("Read %d of a public package should be a %s, got %d %q", i+1, want, rec.Code, rec.Header().Get("X-Crackle-Cache"))
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request(e, "GET", "/api/package/uncached", owner, ""); rec.Code != 200 || rec.Header().Get("X-Crackle-Cache") == "hit" {
+			t.Errorf("A private package shouldn't be read from the cache, got %d %q", rec.Code, rec.Header().Get("X-Crackle-Cache"))
+		}
+	}
+	if cached("crackle:package:uncached", "/api/package/uncached") {
+		t.Error("A private package shouldn't be cached")
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request(e, "GET", "/api/search?q=cached", owner, ""); rec.Code != 200 || rec.Header().Get("X-Crackle-Cache") != "" {
+			t.Errorf("A search by a user shouldn't use the cache, got %d %q", rec.Code, rec.Header().Get("X-Crackle-Cache"))
+		}
+	}
+	if cached("crackle:search", "/api/search?q=cached") {
+		t.Error("A search by a user shouldn't be cached")
+	}
+	request(e, "GET", "/api/search?q=cached", "", "")
+	if !cached("crackle:search", "/api/search?q=cached") {
+		t.Error("An anonymous search should be cached")
+	}
+
+	// Publishing drops what's cached of the package and every search
+	publish(t, e, owner, "cached", "1.0.1", false)
+	if cached("crackle:package:cached", "/api/package/cached") || cached("crackle:search", "/api/search?q=cached") {
+		t.Error("Publishing should drop the cached reads")
+	}
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// collectionRow is a collection as it's stored, its packages a json list of names
+type collectionRow struct {
+	Slug        string
+	Title       string
+	Description *string
+	Packages    types.JSONText
+	UpdatedBy   *string `db:"updated_by"`
+	UpdatedAt   string  `db:"updated_at"`
+}
+
+// collection returns the Collection a row holds
+func (row collectionRow) collection() (models.Collection, error) {
+	c := models.Collection{
+		Slug:        row.Slug,
+		Title:       row.Title,
+		Description: row.Description,
+		Packages:    []string{},
+		UpdatedBy:   row.UpdatedBy,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	err := json.Unmarshal(row.Packages, &c.Packages)
+	return c, err
+}
+
+// missingNames returns the names of want that aren't in have, in want's order
+func missingNames(want []string, have []string) []string {
+	found := make(map[string]bool)
+	for _, name := range have {
+		found[name] = true
+	}
+	var missing []string
+	for _, name := range want {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// categoryExists reports whether slug is a category of the registry's taxonomy
+func categoryExists(slug string) (bool, error) {
+	var exists bool
+	err := DB.Get(&exists, "SELECT EXISTS(SELECT 1 FROM categories WHERE slug=$1)", slug)
+	return exists, err
+}
+
+// ReadCategories returns every category, alphabetically, with how many of the
+// packages the user can see are in each
+func ReadCategories(c echo.Context) error {
+	visible, args := visibleCondition(authUsername(c), nil)
+
+	// Query
+	query := fmt.Sprintf(`SELECT slug, title, description, created_at, count(pc.name) AS packages FROM categories
+						  LEFT JOIN package_categories pc ON pc.category = slug
+						  AND pc.name IN (SELECT name FROM packages WHERE NOT yanked AND %s)
+						  GROUP BY slug ORDER BY slug`, visible)
+	categories := models.Categories{Category: []models.Category{}}
+	if err := DB.Select(&categories.Category, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
+
+// SetCategory adds a category to the registry's taxonomy, or changes the title
+// and description of one
+func SetCategory(c echo.Context) error {
+	category := new(models.Category)
+	if err := c.Bind(category); err != nil {
+		return err
+	}
+	// Params
+	category.Slug = c.Param("slug")
+
+	if err := helpers.ValidCategory(category); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Query
+	_, err := DB.Exec(`INSERT INTO categories(slug, title, description) VALUES($1, $2, $3)
+					   ON CONFLICT (slug) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description`,
+		category.Slug, category.Title, category.Description)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	audit(c, helpers.AuditCategorySet, "", fmt.Sprintf("%s: %s", category.Slug, category.Title))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteCategory removes a category from the registry's taxonomy, the packages
+// in it are taken out of it
+func DeleteCategory(c echo.Context) error {
+	// Params
+	slug := c.Param("slug")
+
+	// Query
+	res, err := DB.Exec("DELETE FROM categories WHERE slug=$1", slug)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if deleted, _ := res.RowsAffected(); deleted == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Category %s not found", slug))
+	}
+	audit(c, helpers.AuditCategoryRemove, "", slug)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReadPackageCategories returns the categories a Package is in
+func ReadPackageCategories(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
+
+	// Query
+	categories := models.PackageCategories{Categories: []string{}}
+	err := DB.Select(&categories.Categories, "SELECT category FROM package_categories WHERE name=$1 ORDER BY category", name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
+
+// SetPackageCategories puts a published Package in the given categories of the
+// registry's taxonomy, replacing those it was in
+func SetPackageCategories(c echo.Context) error {
+	categories := new(models.PackageCategories)
+	if err := c.Bind(categories); err != nil {
+		return err
+	}
+	if err := helpers.ValidPackageCategories(categories.Categories); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requirePublishedOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query
+	var known []string
+	query := "SELECT slug FROM categories WHERE " + Dialect.In("slug", "$1")
+	if err := DB.Select(&known, query, Dialect.List(categories.Categories)); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if missing := missingNames(categories.Categories, known); len(missing) != 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("No category %s, see cr browse", strings.Join(missing, ", ")))
+	}
+
+	tx, err := DB.Beginx()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	defer tx.Rollback()
+	if _, err = tx.Exec("DELETE FROM package_categories WHERE name=$1", name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	for _, slug := range categories.Categories {
+		if _, err = tx.Exec("INSERT INTO package_categories(name, category) VALUES($1, $2)", name, slug); err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	invalidateCache(name)
+	audit(c, helpers.AuditCategorize, name, strings.Join(categories.Categories, ", "))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReadCollections returns every collection, alphabetically, with the names of
+// the packages they list
+func ReadCollections(c echo.Context) error {
+	// Query
+	var rows []collectionRow
+	if err := DB.Select(&rows, "SELECT * FROM collections ORDER BY slug"); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	collections := models.Collections{Collection: []models.Collection{}}
+	for _, row := range rows {
+		collection, err := row.collection()
+		if err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		collections.Collection = append(collections.Collection, collection)
+	}
+
+	return c.JSON(http.StatusOK, collections)
+}
+
+// ReadCollection returns a collection with the latest version of each package
+// it lists that the user can see, in its order
+func ReadCollection(c echo.Context) error {
+	// Params
+	slug := c.Param("slug")
+
+	// Query
+	var row collectionRow
+	err := DB.Get(&row, "SELECT * FROM collections WHERE slug=$1", slug)
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Collection %s not found", slug))
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	collection, err := row.collection()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	visible, args := visibleCondition(authUsername(c), []interface{}{Dialect.List(collection.Packages)})
+	var latest []models.Package
+	err = DB.Select(&latest, fmt.Sprintf(`SELECT * FROM (%s) latest WHERE %s`,
+		Dialect.Latest("NOT yanked AND "+Dialect.In("name", "$1")), visible), args...)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	byName := make(map[string]models.Package)
+	for _, p := range latest {
+		byName[p.Name] = p
+	}
+	collection.Package = []models.Package{}
+	for _, name := range collection.Packages {
+		if p, ok := byName[name]; ok {
+			signPackageObjects(&p)
+			collection.Package = append(collection.Package, p)
+		}
+	}
+
+	return c.JSON(http.StatusOK, collection)
+}
+
+// SetCollection adds a collection, or replaces the title, description and
+// packages of one. Only published public packages can be listed.
+func SetCollection(c echo.Context) error {
+	collection := new(models.Collection)
+	if err := c.Bind(collection); err != nil {
+		return err
+	}
+	// Params
+	collection.Slug = c.Param("slug")
+
+	if collection.Packages == nil {
+		collection.Packages = []string{}
+	}
+	if err := helpers.ValidCollection(collection); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Query
+	var public []string
+	err := DB.Select(&public, "SELECT DISTINCT name FROM packages WHERE "+Dialect.In("name", "$1")+" AND NOT private AND NOT yanked",
+		Dialect.List(collection.Packages))
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if missing := missingNames(collection.Packages, public); len(missing) != 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s aren't published public packages", strings.Join(missing, ", ")))
+	}
+	packages, err := json.Marshal(collection.Packages)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	_, err = DB.Exec(`INSERT INTO collections(slug, title, description, packages, updated_by) VALUES($1, $2, $3, $4, $5)
+					  ON CONFLICT (slug) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description,
+					  packages = EXCLUDED.packages, updated_by = EXCLUDED.updated_by, updated_at = `+Dialect.Now(),
+		collection.Slug, collection.Title, collection.Description, types.JSONText(packages), authUsername(c))
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	audit(c, helpers.AuditCollectionSet, "", fmt.Sprintf("%s: %s", collection.Slug, strings.Join(collection.Packages, ", ")))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteCollection removes a collection, the packages it listed stay
+func DeleteCollection(c echo.Context) error {
+	// Params
+	slug := c.Param("slug")
+
+	// Query
+	res, err := DB.Exec("DELETE FROM collections WHERE slug=$1", slug)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if deleted, _ := res.RowsAffected(); deleted == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Collection %s not found", slug))
+	}
+	audit(c, helpers.AuditCollectionRemove, "", slug)
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// trendingRange maps a stats period to how many days back pulls are counted for trending
+var trendingRange = map[string]int{
+	helpers.StatsPeriodDay:   1,
+	helpers.StatsPeriodWeek:  7,
+	helpers.StatsPeriodMonth: 30,
+}
+
+// latestPackages is the condition of the packages whose latest version is
+// listed, those that aren't yanked or deprecated
+const latestPackages = "NOT yanked AND deprecated IS NULL"
+
+var (
+	// trendingOrder are the columns trending packages are ordered and paged by
+	trendingOrder = []helpers.PageKey{{Column: "recent.pulls", Desc: true, Numeric: true}, {Column: "name"}}
+	// recentOrder are the columns recent packages are ordered and paged by
+	recentOrder = []helpers.PageKey{{Column: "created_at", Desc: true}, {Column: "name"}}
+)
+
+// ReadTrending returns the latest version of the packages pulled most over a recent period
+func ReadTrending(c echo.Context) error {
+	// Params
+	period := c.QueryParam("period")
+	if period == "" {
+		period = helpers.StatsPeriodWeek
+	}
+	if !helpers.ValidStatsPeriod(period) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Period \"%s\" is invalid, use day, week or month", period))
+	}
+	limit, after, err := pageParams(c, trendingOrder, helpers.DefaultSearchLimit)
+	if err != nil {
+		return err
+	}
+	visible, args := visibleCondition(authUsername(c), nil)
+	cond, args := pageAfter(trendingOrder, after, args)
+	args = append(args, limit+1)
+
+	// Query, the range comes from the map above, never from the request
+	query := fmt.Sprintf(`SELECT latest.*, recent.pulls AS recent_pulls FROM (
+							  SELECT name, sum(unique_pulls) AS pulls FROM package_pulls
+							  WHERE day > %s GROUP BY name
+						  ) recent JOIN (%s) latest USING (name)
+						  WHERE %s AND %s ORDER BY %s LIMIT $%d`,
+		Dialect.DaysAgo(trendingRange[period]), Dialect.Latest(latestPackages), visible, cond, helpers.PageOrder(trendingOrder), len(args))
+	results := models.TrendingPackages{Period: period, Package: []models.TrendingPackage{}}
+	if err := DB.Select(&results.Package, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(results.Package) > limit {
+		results.Package = results.Package[:limit]
+		last := results.Package[limit-1]
+		results.Next = helpers.EncodePageToken(strconv.Itoa(last.RecentPulls), last.Name)
+	}
+	for i := range results.Package {
+		signPackageObjects(&results.Package[i].Package)
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
+
+// ReadRecent returns the latest version of the most recently published packages
+func ReadRecent(c echo.Context) error {
+	// Params
+	limit, after, err := pageParams(c, recentOrder, helpers.DefaultSearchLimit)
+	if err != nil {
+		return err
+	}
+	visible, args := visibleCondition(authUsername(c), nil)
+	cond, args := pageAfter(recentOrder, after, args)
+	args = append(args, limit+1)
+
+	// Query
+	query := fmt.Sprintf(`SELECT * FROM (%s) latest WHERE %s AND %s ORDER BY %s LIMIT $%d`,
+		Dialect.Latest(latestPackages), visible, cond, helpers.PageOrder(recentOrder), len(args))
+	results := models.Packages{Package: []models.Package{}}
+	if err := DB.Select(&results.Package, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(results.Package) > limit {
+		results.Package = results.Package[:limit]
+		results.Next = helpers.EncodePageToken(packageKeyValues(results.Package[limit-1], recentOrder)...)
+	}
+	for i := range results.Package {
+		signPackageObjects(&results.Package[i])
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// emailCodeExpiry is how long a code emailed to verify an address works for
+	emailCodeExpiry = 24 * time.Hour
+	// mailTimeout is how long sending an email can take
+	mailTimeout = 30 * time.Second
+)
+
+var (
+	// mailClient makes requests to email APIs such as SendGrid's
+	mailClient = &http.Client{Timeout: mailTimeout}
+	// subscriptionOrder pages subscriptions alphabetically
+	subscriptionOrder = []helpers.PageKey{{Column: "name"}}
+)
+
+// mailer returns the sender configured under [email] in server.toml, ok is
+// false when the registry doesn't send email
+func mailer() (m helpers.Mailer, ok bool) {
+	from := viper.GetString("email.from")
+	switch viper.GetString("email.provider") {
+	case "smtp":
+		return &helpers.SMTPMailer{
+			Addr:     viper.GetString("email.smtp_addr"),
+			Username: viper.GetString("email.smtp_username"),
+			Password: viper.GetString("email.smtp_password"),
+			From:     from,
+		}, true
+	case "sendgrid":
+		return &helpers.SendgridMailer{APIKey: viper.GetString("email.sendgrid_api_key"), From: from, Client: mailClient}, true
+	}
+	return nil, false
+}
+
+// sendMail sends an email in the background so the request that caused it
+// isn't held up, failing to is only logged
+func sendMail(m helpers.Mail) {
+	mailer, ok := mailer()
+	if !ok {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+		if err := mailer.Send(ctx, m); err != nil {
+			log.Warnf("email to %s failed: %s", m.To, err)
+		}
+	}()
+}
+
+// SetEmail emails a code to an address the authenticated user wants to use,
+// it's only theirs once VerifyEmail is sent the code
+func SetEmail(c echo.Context) error {
+	r := new(models.EmailRequest)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := helpers.ValidEmail(r.Email); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if _, ok := mailer(); !ok {
+		return echo.NewHTTPError(http.StatusNotImplemented, "Email isn't enabled on this registry")
+	}
+
+	if err := startEmailVerification(authUsername(c), r.Email); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// startEmailVerification emails a code verifying email belongs to username,
+// replacing any code sent before
+func startEmailVerification(username string, email string) error {
+	code, err := helpers.NewToken()
+	if err != nil {
+		return err
+	}
+	_, err = DB.Exec(`INSERT INTO email_verifications(username, email, code_hash) VALUES($1, $2, $3)
+					  ON CONFLICT (username) DO UPDATE SET email=$2, code_hash=$3, created_at=`+Dialect.Now(),
+		username, email, helpers.HashToken(code))
+	if err != nil {
+		return err
+	}
+	sendMail(helpers.Mail{
+		To:      email,
+		Subject: "Verify your email for Crackle",
+		Body: fmt.Sprintf(`Hi %s,
+
+Verify this is your email address by running:
+
+    cr account verify %s
+
+The code works for %s. If you didn't ask for it, ignore this email.
+`, username, code, emailCodeExpiry),
+	})
+	return nil
+}
+
+// VerifyEmail makes the address a code was emailed to by SetEmail the
+// authenticated user's
+func VerifyEmail(c echo.Context) error {
+	v := new(models.EmailVerification)
+	if err := c.Bind(v); err != nil {
+		return err
+	}
+	username := authUsername(c)
+
+	// Query
+	var email string
+	query := fmt.Sprintf(`DELETE FROM email_verifications
+						  WHERE username=$1 AND code_hash=$2 AND created_at > %s
+						  RETURNING email`, Dialect.Ago(int(emailCodeExpiry.Seconds())))
+	err := DB.Get(&email, query, username, helpers.HashToken(v.Code))
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusBadRequest, "Verification code is wrong or expired, request another with `cr account email`")
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if _, err = DB.Exec("UPDATE users SET email=$1 WHERE username=$2", email, username); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	audit(c, helpers.AuditEmailVerify, "", email)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetNotifications chooses the kinds of email the authenticated user is sent,
+// none turns them all off
+func SetNotifications(c echo.Context) error {
+	n := new(models.NotificationSettings)
+	if err := c.Bind(n); err != nil {
+		return err
+	}
+	if err := helpers.ValidNotifications(n.Notifications); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if n.Notifications == nil {
+		n.Notifications = []string{}
+	}
+	notifications, err := json.Marshal(n.Notifications)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	// Query
+	if _, err = DB.Exec("UPDATE users SET notifications=$1 WHERE username=$2", string(notifications), authUsername(c)); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReadSubscriptions returns the packages the authenticated user is subscribed
+// to, alphabetically
+func ReadSubscriptions(c echo.Context) error {
+	limit, after, err := pageParams(c, subscriptionOrder, helpers.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	cond, args := pageAfter(subscriptionOrder, after, []interface{}{authUsername(c)})
+	args = append(args, limit+1)
+
+	// Query
+	query := fmt.Sprintf(`SELECT name, created_at FROM package_subscriptions
+						  WHERE username=$1 AND %s ORDER BY %s LIMIT $%d`, cond, helpers.PageOrder(subscriptionOrder), len(args))
+	subscriptions := models.Subscriptions{Subscription: []models.Subscription{}}
+	if err = DB.Select(&subscriptions.Subscription, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(subscriptions.Subscription) > limit {
+		subscriptions.Subscription = subscriptions.Subscription[:limit]
+		subscriptions.Next = helpers.EncodePageToken(subscriptions.Subscription[limit-1].Name)
+	}
+
+	return c.JSON(http.StatusOK, subscriptions)
+}
+
+// Subscribe emails the authenticated user whenever a version of a Package is
+// published, as long as they verified an email and chose publish notifications
+func Subscribe(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
+
+	// Query
+	var published bool
+	if err := DB.Get(&published, "SELECT EXISTS(SELECT 1 FROM packages WHERE name=$1)", name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !published {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	_, err := DB.Exec("INSERT INTO package_subscriptions(username, name) VALUES($1, $2) ON CONFLICT DO NOTHING", authUsername(c), name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Unsubscribe undoes Subscribe
+func Unsubscribe(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	// Query
+	res, err := DB.Exec("DELETE FROM package_subscriptions WHERE username=$1 AND name=$2", authUsername(c), name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if deletedRows, _ := res.RowsAffected(); deletedRows == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("You aren't subscribed to %s", name))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// notifySubscribers emails the users subscribed to a package who can see it
+// that a version was published, other than its publisher. It's run in the
+// background like sendWebhooks.
+func notifySubscribers(c echo.Context, name string, version string) {
+	if _, ok := mailer(); !ok {
+		return
+	}
+	publisher := authUsername(c)
+	go func() {
+		var emails []string
+		err := DB.Select(&emails, `SELECT users.email FROM package_subscriptions JOIN users USING (username)
+								   WHERE package_subscriptions.name=$1 AND users.username<>$2 AND users.email IS NOT NULL
+								   AND `+Dialect.Contains("users.notifications", "$3")+` AND users.suspended_at IS NULL
+								   AND (NOT EXISTS(SELECT 1 FROM packages WHERE name=$1 AND private) OR users.username IN (
+										SELECT username FROM package_owners WHERE name=$1
+										UNION SELECT org_members.username FROM package_orgs JOIN org_members USING (org)
+										WHERE package_orgs.name=$1))`, name, publisher, helpers.NotifyPublish)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		for _, email := range emails {
+			sendMail(helpers.Mail{
+				To:      email,
+				Subject: fmt.Sprintf("%s %s was published on Crackle", name, version),
+				Body: fmt.Sprintf(`%s published %s %s, install it with:
+
+    cr install %s@%s
+
+You're subscribed to %s. Stop these emails with cr subscribe --undo %s, or
+turn off publish notifications with cr account notify.
+`, publisher, name, version, name, version, name, name),
+			})
+		}
+	}()
+}
+
+// notifyTransfer emails the user a package is offered to, unless they turned
+// invite notifications off
+func notifyTransfer(t *models.Transfer) {
+	if _, ok := mailer(); !ok {
+		return
+	}
+	var email string
+	err := DB.Get(&email, "SELECT email FROM users WHERE username=$1 AND email IS NOT NULL AND "+Dialect.Contains("notifications", "$2"),
+		t.To, helpers.NotifyInvite)
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	sendMail(helpers.Mail{
+		To:      email,
+		Subject: fmt.Sprintf("%s offered you %s on Crackle", t.From, t.Name),
+		Body: fmt.Sprintf(`%s wants to hand %s over to you, you'd become its only owner.
+
+Accept it with:
+
+    cr transfer --accept %s
+
+or decline it with cr transfer --cancel %s.
+`, t.From, t.Name, t.Name, t.Name),
+	})
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+const (
+	// defaultFederationTTL is how long answers from upstream registries are
+	// cached when federation.ttl isn't set
+	defaultFederationTTL = 5 * time.Minute
+	// federationCacheExpiry is how long cached answers are kept to fall back on
+	// when every upstream is unreachable
+	federationCacheExpiry = 24 * time.Hour
+	// maxFederatedBody is the largest answer taken from an upstream registry
+	maxFederatedBody = 10 << 20
+	// upstreamHeader names the upstream registry a federated answer came from
+	upstreamHeader = "X-Crackle-Upstream"
+)
+
+// federationClient makes requests to upstream registries
+var federationClient = &http.Client{Timeout: 30 * time.Second}
+
+// federatedAnswer is an upstream registry's answer to a read, as it's cached
+type federatedAnswer struct {
+	Upstream string
+	Status   int
+	Body     string
+	Fresh    bool
+}
+
+// FederationUpstreams returns the registries reads of packages this one
+// doesn't have are proxied to in order, from federation.upstreams in server.toml
+func FederationUpstreams() []string {
+	return viper.GetStringSlice("federation.upstreams")
+}
+
+// federationTTL returns how long answers from upstream registries are cached
+func federationTTL() time.Duration {
+	if ttl := viper.GetDuration("federation.ttl"); ttl > 0 {
+		return ttl
+	}
+	return defaultFederationTTL
+}
+
+// Federate is middleware answering reads of packages this registry doesn't
+// have from the first upstream registry that does, like npm's registry
+// fallback. Answers, including that no upstream has a package, are cached for
+// federation.ttl. Packages published here always win over upstream ones.
+func Federate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		upstreams := FederationUpstreams()
+		if he, ok := err.(*echo.HTTPError); len(upstreams) == 0 || !ok || he.Code != http.StatusNotFound {
+			return err
+		}
+		name := packageParam(c)
+		if !helpers.ValidPackageName(name) {
+			return err
+		}
+		var local bool
+		if dbErr := DB.Get(&local, "SELECT EXISTS(SELECT 1 FROM packages WHERE name=$1)", name); dbErr != nil {
+			log.Error(dbErr)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		if local {
+			return err
+		}
+
+		answer, fedErr := federatedRead(upstreams, upstreamPath(c, name))
+		if fedErr != nil {
+			log.Warnf("couldn't read %s from upstream registries: %s", name, fedErr)
+			return echo.NewHTTPError(http.StatusBadGateway, "Couldn't reach this registry's upstream registries")
+		}
+		if answer.Status == http.StatusNotFound {
+			return err
+		}
+		c.Response().Header().Set(upstreamHeader, answer.Upstream)
+		return c.Blob(answer.Status, echo.MIMEApplicationJSONCharsetUTF8, []byte(answer.Body))
+	}
+}
+
+// upstreamPath returns the path of a package read relative to an API's URL,
+// with its query
+func upstreamPath(c echo.Context, name string) string {
+	namespace, short := helpers.SplitPackageName(name)
+	path := "package/" + url.PathEscape(short)
+	if namespace != "" {
+		path = fmt.Sprintf("ns/%s/package/%s", url.PathEscape(namespace), url.PathEscape(short))
+	}
+	// Routes are registered under the package's name param
+	route := c.Path()
+	if i := strings.Index(route, ":name"); i != -1 {
+		path += route[i+len(":name"):]
+	}
+	if query := c.Request().URL.RawQuery; query != "" {
+		path += "?" + query
+	}
+	return path
+}
+
+// federatedRead returns the cached answer to a read from upstream registries,
+// asking them again once it's older than federation.ttl. A stale answer is
+// used when none of them can be reached.
+func federatedRead(upstreams []string, path string) (*federatedAnswer, error) {
+	cached := new(federatedAnswer)
+	query := fmt.Sprintf(`SELECT upstream, status, body, fetched_at > %s AS fresh
+						  FROM federation_cache WHERE path=$1`, Dialect.Ago(int(federationTTL().Seconds())))
+	err := DB.Get(cached, query, path)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if err == nil && cached.Fresh {
+		return cached, nil
+	}
+
+	answer, fetchErr := fetchUpstreams(upstreams, path)
+	if fetchErr != nil {
+		if err == nil {
+			log.Warnf("serving stale %s: %s", path, fetchErr)
+			return cached, nil
+		}
+		return nil, fetchErr
+	}
+	_, err = DB.Exec(`INSERT INTO federation_cache(path, upstream, status, body) VALUES($1, $2, $3, $4)
+					  ON CONFLICT (path) DO UPDATE SET upstream=$2, status=$3, body=$4, fetched_at=`+Dialect.Now(),
+		path, answer.Upstream, answer.Status, answer.Body)
+	if err != nil {
+		return nil, err
+	}
+	expired := "DELETE FROM federation_cache WHERE fetched_at < " + Dialect.Ago(int(federationCacheExpiry.Seconds()))
+	if _, err = DB.Exec(expired); err != nil {
+		log.Error(err)
+	}
+	return answer, nil
+}
+
+// fetchUpstreams asks each upstream registry in turn for path, returning the
+// first answer that isn't a 404 or a server error. It's a 404 when every
+// upstream answered one, an error is only returned when one couldn't be
+// reached and none had it.
+func fetchUpstreams(upstreams []string, path string) (*federatedAnswer, error) {
+	rel, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	var failed error
+	for _, upstream := range upstreams {
+		base, err := helpers.APIURL(upstream)
+		if err != nil {
+			failed = err
+			continue
+		}
+		status, body, err := getUpstream(base.ResolveReference(rel).String())
+		switch {
+		case err != nil:
+			failed = fmt.Errorf("%s: %s", upstream, err)
+		case status == http.StatusNotFound:
+		case status < http.StatusInternalServerError:
+			return &federatedAnswer{Upstream: upstream, Status: status, Body: body}, nil
+		default:
+			failed = fmt.Errorf("%s: %d", upstream, status)
+		}
+	}
+	if failed != nil {
+		return nil, failed
+	}
+	return &federatedAnswer{Status: http.StatusNotFound}, nil
+}
+
+// getUpstream reads a JSON answer from an upstream registry, without the
+// client's token
+func getUpstream(u string) (int, string, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Accept", echo.MIMEApplicationJSON)
+	resp, err := federationClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxFederatedBody+1))
+	if err != nil {
+		return 0, "", err
+	}
+	if len(body) > maxFederatedBody {
+		return 0, "", errors.New("answer is too large")
+	}
+	return resp.StatusCode, string(body), nil
+}
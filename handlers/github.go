@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// githubOTPWait is how long a GitHub login refused for want of a TOTP code can
+// be retried with one, GitHub only exchanges a device code once
+const githubOTPWait = 10 * time.Minute
+
+var (
+	// githubURL is where GitHub's OAuth device flow is served
+	githubURL = "https://github.com"
+	// githubAPIURL is where GitHub's REST API is served
+	githubAPIURL = "https://api.github.com"
+	// githubClient makes requests to GitHub
+	githubClient = &http.Client{Timeout: 10 * time.Second}
+	// githubOTPLogins are the users of GitHub logins waiting for a TOTP code, by
+	// device code
+	githubOTPLogins = struct {
+		sync.Mutex
+		m map[string]githubOTPLogin
+	}{m: make(map[string]githubOTPLogin)}
+)
+
+// githubOTPLogin is a GitHub login waiting for a TOTP code until expires
+type githubOTPLogin struct {
+	username string
+	expires  time.Time
+}
+
+// githubAccessToken is GitHub's answer to polling for an access token, Error
+// is set until the user has authorized cr
+type githubAccessToken struct {
+	AccessToken string `json:"access_token"`
+	Error       string `json:"error"`
+	Interval    int    `json:"interval"`
+}
+
+// githubUser is the GitHub account an access token belongs to
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	// Email is the account's public email, if it has one
+	Email *string `json:"email"`
+}
+
+// githubClientID returns the client id of the registry's GitHub OAuth app, or a
+// 501 error when GitHub login isn't set up
+func githubClientID() (string, error) {
+	clientID := viper.GetString("github.client_id")
+	if clientID == "" {
+		return "", echo.NewHTTPError(http.StatusNotImplemented, "GitHub login isn't enabled on this registry")
+	}
+	return clientID, nil
+}
+
+// githubPost posts form to a GitHub OAuth endpoint, decoding its json answer into v
+func githubPost(path string, form url.Values, v interface{}) error {
+	req, err := http.NewRequest("POST", githubURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := githubClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("POST %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// StartGithubLogin begins a GitHub device login, the user authorizes it in a
+// browser while the client polls GithubLogin
+func StartGithubLogin(c echo.Context) error {
+	clientID, err := githubClientID()
+	if err != nil {
+		return err
+	}
+
+	device := new(models.GithubDevice)
+	if err = githubPost("/login/device/code", url.Values{"client_id": {clientID}}, device); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusBadGateway, "Couldn't reach GitHub")
+	}
+
+	return c.JSON(http.StatusOK, device)
+}
+
+// GithubLogin exchanges an authorized GitHub device login for an API token,
+// creating a user named after the GitHub account the first time. While the user
+// hasn't authorized it yet a 202 is returned with the interval to poll at.
+func GithubLogin(c echo.Context) error {
+	clientID, err := githubClientID()
+	if err != nil {
+		return err
+	}
+	d := new(models.GithubDevice)
+	if err = c.Bind(d); err != nil {
+		return err
+	}
+	if d.DeviceCode == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "A device_code is required")
+	}
+	if username, ok := takeGithubOTPLogin(d.DeviceCode); ok {
+		return issueGithubLoginToken(c, d.DeviceCode, username)
+	}
+
+	form := url.Values{
+		"client_id":   {clientID},
+		"device_code": {d.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+	token := new(githubAccessToken)
+	if err = githubPost("/login/oauth/access_token", form, token); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusBadGateway, "Couldn't reach GitHub")
+	}
+	switch token.Error {
+	case "":
+	case "authorization_pending", "slow_down":
+		return c.JSON(http.StatusAccepted, models.GithubDevice{DeviceCode: d.DeviceCode, Interval: token.Interval})
+	case "expired_token":
+		return echo.NewHTTPError(http.StatusGone, "The GitHub login expired, try again")
+	case "access_denied":
+		return echo.NewHTTPError(http.StatusUnauthorized, "The GitHub login was denied")
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("GitHub login failed: %s", token.Error))
+	}
+
+	user, err := fetchGithubUser(token.AccessToken)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusBadGateway, "Couldn't reach GitHub")
+	}
+	username, err := githubUsername(user)
+	if err != nil {
+		return err
+	}
+
+	return issueGithubLoginToken(c, d.DeviceCode, username)
+}
+
+// issueGithubLoginToken is issueLoginToken for a GitHub login, remembering it
+// for githubOTPWait when it's refused for want of a TOTP code so it can be
+// retried with one
+func issueGithubLoginToken(c echo.Context, deviceCode string, username string) error {
+	err := issueLoginToken(c, username)
+	if err != nil && c.Response().Header().Get(helpers.OTPHeader) == helpers.OTPRequired {
+		githubOTPLogins.Lock()
+		githubOTPLogins.m[deviceCode] = githubOTPLogin{username: username, expires: time.Now().Add(githubOTPWait)}
+		githubOTPLogins.Unlock()
+	}
+	return err
+}
+
+// takeGithubOTPLogin returns the user of a GitHub login waiting for a TOTP
+// code, forgetting it. Expired logins are dropped along the way.
+func takeGithubOTPLogin(deviceCode string) (string, bool) {
+	githubOTPLogins.Lock()
+	defer githubOTPLogins.Unlock()
+	now := time.Now()
+	for code, login := range githubOTPLogins.m {
+		if now.After(login.expires) {
+			delete(githubOTPLogins.m, code)
+		}
+	}
+	login, ok := githubOTPLogins.m[deviceCode]
+	delete(githubOTPLogins.m, deviceCode)
+	return login.username, ok
+}
+
+// fetchGithubUser returns the GitHub account an access token belongs to
+func fetchGithubUser(accessToken string) (*githubUser, error) {
+	req, err := http.NewRequest("GET", githubAPIURL+"/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := githubClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET /user: %s", resp.Status)
+	}
+	user := new(githubUser)
+	if err = json.NewDecoder(resp.Body).Decode(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// githubUsername returns the username of the user linked to a GitHub account,
+// creating one named after its login the first time it's seen and emailing its
+// public email a verification code. A username
+// already taken by a password user is never linked to a GitHub account.
+func githubUsername(user *githubUser) (string, error) {
+	var username string
+	err := DB.Get(&username, "SELECT username FROM users WHERE github_id=$1", user.ID)
+	if err == nil {
+		return username, nil
+	}
+	if err != sql.ErrNoRows {
+		log.Error(err)
+		return "", echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	// GitHub users have no password, so can't login with one
+	result, err := DB.Exec(`INSERT INTO users(name, username, password, created_at, last_login, provider, github_id)
+							VALUES($1, $1, '', `+Dialect.Now()+`, `+Dialect.Now()+`, 'github', $2) ON CONFLICT DO NOTHING`,
+		user.Login, user.ID)
+	if err != nil {
+		log.Error(err)
+		return "", echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if created, _ := result.RowsAffected(); created == 0 {
+		return "", echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Username %s is already taken, login with its password instead", user.Login))
+	}
+	// New users are sent a code to verify their public GitHub email with
+	if _, ok := mailer(); ok && user.Email != nil && helpers.ValidEmail(*user.Email) == nil {
+		if err = startEmailVerification(user.Login, *user.Email); err != nil {
+			log.Error(err)
+		}
+	}
+	return user.Login, nil
+}
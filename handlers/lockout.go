@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultLockoutMaxFailures is how many passwords and two-factor codes in a
+	// row can be wrong before an account is locked, unless lockout.max_failures
+	// in server.toml says otherwise
+	defaultLockoutMaxFailures = 10
+	// defaultLockoutDuration is how long an account stays locked when
+	// lockout.duration isn't set
+	defaultLockoutDuration = 15 * time.Minute
+)
+
+// lockoutMaxFailures returns the failures in a row locking an account, 0 never does
+func lockoutMaxFailures() int {
+	if viper.IsSet("lockout.max_failures") {
+		return viper.GetInt("lockout.max_failures")
+	}
+	return defaultLockoutMaxFailures
+}
+
+// lockoutDuration returns how long a locked account stays locked
+func lockoutDuration() time.Duration {
+	if d := viper.GetDuration("lockout.duration"); d > 0 {
+		return d
+	}
+	return defaultLockoutDuration
+}
+
+// lockedFor returns the seconds username stays locked for getting its password
+// or two-factor code wrong too often, 0 if it isn't locked
+func lockedFor(username string) (int, error) {
+	var seconds float64
+	query := fmt.Sprintf("SELECT %s FROM users WHERE username=$1 AND locked_until > %s", Dialect.SecondsUntil("locked_until"), Dialect.Now())
+	err := DB.Get(&seconds, query, username)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Ceil(seconds)), nil
+}
+
+// requireUnlocked returns a 429 error while username is locked, nothing is
+// checked until then so guessing can't go on in the meantime. Only users who
+// are already known get it, logins refuse a locked account like a wrong
+// password so usernames can't be told apart.
+func requireUnlocked(c echo.Context, username string) error {
+	retry, err := lockedFor(username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if retry == 0 {
+		return nil
+	}
+	c.Response().Header().Set("Retry-After", fmt.Sprint(retry))
+	return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf("Too many wrong passwords or two-factor codes, %s is locked for %d more seconds", username, retry))
+}
+
+// recordAuthFailure counts a wrong password or two-factor code of username,
+// locking it for lockout.duration once lockout.max_failures are wrong in a row.
+// Failing to count it is only logged.
+func recordAuthFailure(username string) {
+	max := lockoutMaxFailures()
+	if max <= 0 {
+		return
+	}
+	query := fmt.Sprintf(`UPDATE users SET
+						  locked_until = CASE WHEN failed_logins + 1 >= $2 THEN %s ELSE locked_until END,
+						  failed_logins = CASE WHEN failed_logins + 1 >= $2 THEN 0 ELSE failed_logins + 1 END
+						  WHERE username=$1`, Dialect.Later(int(lockoutDuration().Seconds())))
+	_, err := DB.Exec(query, username, max)
+	if err != nil {
+		log.Errorf("couldn't count a failed login of %s: %s", username, err)
+	}
+}
+
+// resetAuthFailures forgets the wrong passwords and codes of username once it
+// gets one right
+func resetAuthFailures(username string) {
+	if _, err := DB.Exec("UPDATE users SET failed_logins=0 WHERE username=$1 AND failed_logins<>0", username); err != nil {
+		log.Errorf("couldn't reset failed logins of %s: %s", username, err)
+	}
+}
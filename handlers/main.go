@@ -2,7 +2,13 @@ package handlers
 
 import (
 	"github.com/jmoiron/sqlx"
+
+	"github.com/sunshinekitty/cr/helpers"
 )
 
 // DB points to our global DB object
 var DB *sqlx.DB
+
+// Dialect writes the SQL of queries that differs between the databases DB can
+// be, Postgres unless it's connected to another
+var Dialect helpers.Dialect = helpers.PostgresDialect{}
@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sunshinekitty/cr/db"
+	"github.com/sunshinekitty/cr/handlers"
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// testPassword is the password of every user made by newUser
+const testPassword = "correct horse"
+
+// TestMain runs the handler tests against a SQLite registry migrated to the
+// latest schema, made afresh for each run
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "cr-handlers")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	code, err := runTests(m, filepath.Join(dir, "cr.db"))
+	os.RemoveAll(dir)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+// runTests migrates the SQLite database at path then runs m
+func runTests(m *testing.M, path string) (int, error) {
+	viper.Set("database.url", "sqlite:"+path)
+	db.InitDB()
+	defer handlers.DB.Close()
+	migrations, err := db.Migrations()
+	if err != nil {
+		return 0, err
+	}
+	steps, err := helpers.MigrationPlan(migrations, 0, helpers.LatestMigration(migrations))
+	if err != nil {
+		return 0, err
+	}
+	if err = db.Migrate(handlers.DB, steps, func(helpers.MigrationStep) {}); err != nil {
+		return 0, err
+	}
+	return m.Run(), nil
+}
+
+// newUser adds a user with testPassword, returning an API token of theirs with
+// scopes
+func newUser(t *testing.T, username string, scopes ...string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = handlers.DB.Exec(`INSERT INTO users(name, username, password, created_at, last_login, provider)
+							   VALUES($1, $1, $2, `+handlers.Dialect.Now()+`, `+handlers.Dialect.Now()+`, 'password')`,
+		username, string(hash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := helpers.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(scopes)
+	_, err = handlers.DB.Exec("INSERT INTO tokens(token_hash, username, scopes) VALUES($1, $2, $3)", helpers.HashToken(token), username, string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// request sends a request to e, authenticated with token unless it's "", with
+// headers given as pairs of names and values
+func request(e *echo.Echo, method string, path string, token string, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// publish publishes version of name by the user of token
+func publish(t *testing.T, e *echo.Echo, token string, name string, version string, private bool) {
+	t.Helper()
+	body := fmt.Sprintf(`{"Name": %q, "Version": %q, "Repository": "crackle/%s", "Private": %t}`, name, version, name, private)
+	if rec := request(e, "POST", "/api/package/", token, body); rec.Code != 201 {
+		t.Fatalf("Publishing %s %s should work, got %d %s", name, version, rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// readyTimeout is how long Readyz waits on the database
+const readyTimeout = 2 * time.Second
+
+var (
+	httpRequests = helpers.NewCounterVec("crackle_http_requests_total",
+		"Requests answered by method, route and status.", "method", "route", "status")
+	httpDuration = helpers.NewHistogramVec("crackle_http_request_duration_seconds",
+		"Time taken to answer requests by method and route.", helpers.LatencyBuckets, "method", "route")
+	publishes = helpers.NewCounterVec("crackle_publishes_total",
+		"Package versions published.")
+)
+
+// Metrics is middleware counting requests and timing them by the route they
+// matched, for ReadMetrics
+func Metrics(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+		// Routes rather than paths, so every package doesn't get its own series
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Response().Status
+		if err != nil {
+			status = http.StatusInternalServerError
+			if he, ok := err.(*echo.HTTPError); ok {
+				status = he.Code
+			}
+		}
+		method := c.Request().Method
+		httpRequests.Inc(method, route, strconv.Itoa(status))
+		httpDuration.Observe(time.Since(start).Seconds(), method, route)
+		return err
+	}
+}
+
+// ReadMetrics returns request rates and latencies, database connection pool
+// stats and publish counts in the Prometheus text format. When metrics.token is
+// set in server.toml it has to be sent as a bearer token.
+func ReadMetrics(c echo.Context) error {
+	if token := viper.GetString("metrics.token"); token != "" {
+		sent := helpers.BearerToken(c.Request().Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			return echo.NewHTTPError(http.StatusUnauthorized)
+		}
+	}
+
+	stats := DB.Stats()
+	pool := []helpers.Metric{
+		helpers.GaugeFunc{Name: "crackle_db_connections_open", Help: "Connections open to the database.",
+			Value: func() float64 { return float64(stats.OpenConnections) }},
+		helpers.GaugeFunc{Name: "crackle_db_connections_in_use", Help: "Connections to the database in use.",
+			Value: func() float64 { return float64(stats.InUse) }},
+		helpers.GaugeFunc{Name: "crackle_db_connections_idle", Help: "Idle connections to the database.",
+			Value: func() float64 { return float64(stats.Idle) }},
+		helpers.CounterFunc{Name: "crackle_db_connections_waited_total", Help: "Connections to the database waited for.",
+			Value: func() float64 { return float64(stats.WaitCount) }},
+		helpers.CounterFunc{Name: "crackle_db_connections_wait_seconds_total", Help: "Time spent waiting for connections to the database.",
+			Value: func() float64 { return stats.WaitDuration.Seconds() }},
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, helpers.MetricsContentType)
+	c.Response().WriteHeader(http.StatusOK)
+	return helpers.WriteMetrics(c.Response(), append([]helpers.Metric{httpRequests, httpDuration, publishes}, pool...)...)
+}
+
+// Healthz answers as long as the server is up, for liveness checks
+func Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz answers when the server can reach its database, for readiness checks
+// taking it out of a load balancer until then
+func Readyz(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readyTimeout)
+	defer cancel()
+	if err := DB.PingContext(ctx); err != nil {
+		log.Warnf("not ready: %s", err)
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "unavailable"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+	"github.com/sunshinekitty/cr/client"
+)
+
+// defaultMirrorInterval is how often a mirror syncs when mirror.interval isn't set
+const defaultMirrorInterval = 15 * time.Minute
+
+// insertMirroredPackage inserts a version copied from the upstream registry,
+// keeping when it was published there so the latest version stays the latest,
+// and its signature so it can still be verified
+const insertMirroredPackage = `INSERT INTO packages(command_start, created_at, deprecated, description_key, env, homepage,
+											   icon, icon_key, keywords, long_description, name, owner, ports,
+											   readme_key, repository, short_description, signature, signing_key,
+											   test_command, version, volumes, yanked)
+							   VALUES(:command_start, :created_at, :deprecated, :description_key, :env, :homepage,
+									  :icon, :icon_key, :keywords, :long_description, :name, :owner, :ports,
+									  :readme_key, :repository, :short_description, :signature, :signing_key,
+									  :test_command, :version, :volumes, :yanked)`
+
+// MirrorUpstream returns the registry this one mirrors, from mirror.upstream in
+// server.toml, empty when it isn't a mirror
+func MirrorUpstream() string {
+	return viper.GetString("mirror.upstream")
+}
+
+// MirrorReadOnly is middleware refusing changes to a mirror, they're made on
+// its upstream and synced from there. Pulls are still counted.
+func MirrorReadOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		upstream := MirrorUpstream()
+		switch {
+		case upstream == "", c.Request().Method == "GET", c.Request().Method == "HEAD":
+		case strings.HasSuffix(c.Path(), "/versions/:version/pulls"):
+		default:
+			return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("This registry is a read only mirror of %s, make changes there", upstream))
+		}
+		return next(c)
+	}
+}
+
+// RunMirror syncs from the upstream registry every mirror.interval until ctx is
+// done, failed syncs are logged and tried again at the next interval
+func RunMirror(ctx context.Context, upstream string) error {
+	baseURL, err := helpers.APIURL(upstream)
+	if err != nil {
+		return err
+	}
+	registry := client.NewClient(&http.Client{Timeout: time.Minute})
+	registry.BaseURL = baseURL
+
+	for {
+		start := time.Now()
+		if synced, err := SyncMirror(ctx, registry); err != nil {
+			log.Errorf("mirror sync from %s failed: %s", upstream, err)
+		} else {
+			log.Infof("mirror synced %d new versions from %s in %s", synced, upstream, time.Since(start))
+		}
+
+		interval := viper.GetDuration("mirror.interval")
+		if interval <= 0 {
+			interval = defaultMirrorInterval
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// SyncMirror copies the metadata of every public package on upstream, returning
+// how many versions were new. Versions it doesn't have yet are inserted, and
+// the yanked and deprecated state of every package follows upstream's. Pulls,
+// owners and orgs are left to each registry.
+func SyncMirror(ctx context.Context, upstream *client.Client) (int, error) {
+	pkgs, _, err := upstream.Package.SearchPackages(ctx, &client.SearchOptions{Sort: helpers.SearchSortName})
+	if err != nil {
+		return 0, err
+	}
+	synced := 0
+	for _, latest := range pkgs {
+		n, err := syncMirrorPackage(ctx, upstream, &latest.Package)
+		synced += n
+		if err != nil {
+			return synced, fmt.Errorf("%s: %s", latest.Name, err)
+		}
+	}
+	return synced, nil
+}
+
+// syncMirrorPackage copies the versions of a package from upstream given its
+// latest version, returning how many were new
+func syncMirrorPackage(ctx context.Context, upstream *client.Client, latest *models.Package) (int, error) {
+	versions, _, err := upstream.Package.ListVersions(ctx, latest.Name)
+	if err != nil {
+		return 0, err
+	}
+	var have []string
+	if err = DB.Select(&have, "SELECT version FROM published_versions WHERE name=$1", latest.Name); err != nil {
+		return 0, err
+	}
+	known := make(map[string]bool, len(have))
+	for _, v := range have {
+		known[v] = true
+	}
+
+	// Only changed packages drop their cached reads, most don't change between syncs
+	synced, changed := 0, false
+	defer func() {
+		if synced > 0 || changed {
+			invalidateCache(latest.Name)
+		}
+	}()
+	for _, v := range versions {
+		if known[v.Version] {
+			res, err := DB.Exec("UPDATE packages SET yanked=$3 WHERE name=$1 AND version=$2 AND yanked<>$3", latest.Name, v.Version, v.Yanked)
+			if err != nil {
+				return synced, err
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				changed = true
+			}
+			continue
+		}
+		p, _, err := upstream.Package.GetPackageVersion(ctx, latest.Name, v.Version)
+		if err != nil {
+			return synced, err
+		}
+		if err = insertMirrored(p); err != nil {
+			return synced, err
+		}
+		synced++
+	}
+
+	res, err := DB.Exec("UPDATE packages SET deprecated=$2 WHERE name=$1 AND deprecated IS DISTINCT FROM $2", latest.Name, latest.Deprecated)
+	if err != nil {
+		return synced, err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		changed = true
+	}
+	return synced, nil
+}
+
+// insertMirrored inserts a version copied from upstream, recording it as
+// published so it's never published again here
+func insertMirrored(p *models.Package) error {
+	tx, err := DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec("INSERT INTO published_versions(name, version, published_at) VALUES($1, $2, $3)", p.Name, p.Version, p.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err = storePackageObjects(p); err != nil {
+		return err
+	}
+	if _, err = tx.NamedExec(insertMirroredPackage, p); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// orgMemberOrder are the columns members of an Org are ordered and paged by
+var orgMemberOrder = []helpers.PageKey{{Column: "added_at"}, {Column: "username"}}
+
+// CreateOrg creates an Org, the authenticated user becomes its first owner
+func CreateOrg(c echo.Context) error {
+	o := new(models.Org)
+	if err := c.Bind(o); err != nil {
+		return err
+	}
+	if !helpers.ValidOrgName(o.Name) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Org name \"%s\" is invalid, org names follow the rules of package names", o.Name))
+	}
+
+	// Orgs and users share a namespace so owners can't be confused
+	var taken bool
+	err := DB.Get(&taken, "SELECT EXISTS(SELECT 1 FROM users WHERE username=$1) OR EXISTS(SELECT 1 FROM orgs WHERE name=$1)", o.Name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if taken {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Name %s is already taken", o.Name))
+	}
+
+	tx, err := DB.Beginx()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	defer tx.Rollback()
+	if err = tx.Get(&o.CreatedAt, "INSERT INTO orgs(name) VALUES($1) RETURNING created_at", o.Name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	_, err = tx.Exec("INSERT INTO org_members(org, username, role) VALUES($1, $2, $3)", o.Name, authUsername(c), helpers.OrgRoleOwner)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditOrgCreate, "", o.Name)
+
+	return c.JSON(http.StatusCreated, o)
+}
+
+// ReadOrgMembers returns the members of an Org, oldest first
+func ReadOrgMembers(c echo.Context) error {
+	// Params
+	org := c.Param("org")
+
+	if !helpers.ValidOrgName(org) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	limit, after, err := pageParams(c, orgMemberOrder, helpers.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	cond, args := pageAfter(orgMemberOrder, after, []interface{}{org})
+	args = append(args, limit+1)
+
+	// Query
+	members := models.OrgMembers{Member: []models.OrgMember{}}
+	query := fmt.Sprintf("SELECT username, role, added_at FROM org_members WHERE org=$1 AND %s ORDER BY %s LIMIT $%d",
+		cond, helpers.PageOrder(orgMemberOrder), len(args))
+	if err = DB.Select(&members.Member, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	// An org always has an owner, so no members means no org
+	if len(members.Member) == 0 && after == nil {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if len(members.Member) > limit {
+		members.Member = members.Member[:limit]
+		last := members.Member[limit-1]
+		members.Next = helpers.EncodePageToken(last.AddedAt, last.Username)
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// SetOrgMember adds a user to an Org with a role, or changes their role
+func SetOrgMember(c echo.Context) error {
+	// Params
+	org := c.Param("org")
+	username := c.Param("username")
+
+	m := new(models.OrgMember)
+	if err := c.Bind(m); err != nil {
+		return err
+	}
+	if !helpers.ValidOrgRole(m.Role) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Role \"%s\" is invalid, use owner, publisher or reader", m.Role))
+	}
+	if err := requireOrgOwner(org, authUsername(c)); err != nil {
+		return err
+	}
+	if m.Role != helpers.OrgRoleOwner {
+		if err := requireAnotherOrgOwner(org, username); err != nil {
+			return err
+		}
+	}
+
+	var exists bool
+	if err := DB.Get(&exists, "SELECT EXISTS(SELECT 1 FROM users WHERE username=$1)", username); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("User %s not found", username))
+	}
+
+	_, err := DB.Exec(`INSERT INTO org_members(org, username, role) VALUES($1, $2, $3)
+					   ON CONFLICT (org, username) DO UPDATE SET role=EXCLUDED.role`, org, username, m.Role)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditOrgMemberSet, "", fmt.Sprintf("%s %s %s", org, username, m.Role))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveOrgMember removes a user from an Org, members can remove themselves.
+// The last owner can't be removed.
+func RemoveOrgMember(c echo.Context) error {
+	// Params
+	org := c.Param("org")
+	username := c.Param("username")
+
+	if username != authUsername(c) {
+		if err := requireOrgOwner(org, authUsername(c)); err != nil {
+			return err
+		}
+	}
+	if err := requireAnotherOrgOwner(org, username); err != nil {
+		return err
+	}
+
+	result, err := DB.Exec("DELETE FROM org_members WHERE org=$1 AND username=$2", org, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if removed, _ := result.RowsAffected(); removed == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("%s isn't a member of %s", username, org))
+	}
+
+	audit(c, helpers.AuditOrgMemberRemove, "", fmt.Sprintf("%s %s", org, username))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requireOrgOwner returns a 403 error unless username is an owner of org, or a
+// 404 when there's no such org
+func requireOrgOwner(org string, username string) error {
+	role, err := orgRole(org, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if role == helpers.OrgRoleOwner {
+		return nil
+	}
+	var exists bool
+	if err = DB.Get(&exists, "SELECT EXISTS(SELECT 1 FROM orgs WHERE name=$1)", org); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Org %s not found", org))
+	}
+	return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Only owners of %s can manage its members", org))
+}
+
+// requireAnotherOrgOwner returns a 409 error when username is the only owner
+// of org, so it always keeps one
+func requireAnotherOrgOwner(org string, username string) error {
+	var others int
+	err := DB.Get(&others, "SELECT count(*) FROM org_members WHERE org=$1 AND role=$2 AND username<>$3", org, helpers.OrgRoleOwner, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if others == 0 {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("%s is the only owner of %s, an org always keeps one", username, org))
+	}
+	return nil
+}
+
+// requirePackageOrg checks a Package being published matches the org it belongs
+// to, which is filled in when it doesn't say. A package can only join an org on
+// its first publish, by a member allowed to publish to it.
+func requirePackageOrg(p *models.Package) error {
+	current, err := packageOrg(p.Name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	switch {
+	case p.Org == nil && current != "":
+		p.Org = &current
+		return nil
+	case p.Org == nil || *p.Org == current:
+		return nil
+	case current != "":
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Package %s belongs to the %s org", p.Name, current))
+	}
+
+	var published bool
+	if err = DB.Get(&published, "SELECT EXISTS(SELECT 1 FROM packages WHERE name=$1)", p.Name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if published {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Package %s doesn't belong to an org, it can only join one when first published", p.Name))
+	}
+	role, err := orgRole(*p.Org, p.Owner)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !helpers.OrgRoleCanPublish(role) {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Only owners and publishers of %s can publish to it", *p.Org))
+	}
+	return nil
+}
+
+// orgRole returns the role of username in org, "" when they aren't a member
+func orgRole(org string, username string) (string, error) {
+	var role string
+	err := DB.Get(&role, "SELECT role FROM org_members WHERE org=$1 AND username=$2", org, username)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// packageOrg returns the org a package name belongs to, "" when it doesn't
+func packageOrg(name string) (string, error) {
+	var org string
+	err := DB.Get(&org, "SELECT org FROM package_orgs WHERE name=$1", name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return org, err
+}
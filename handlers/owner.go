@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// ownerOrder are the columns owners of a Package are ordered and paged by
+var ownerOrder = []helpers.PageKey{{Column: "added_at"}, {Column: "username"}}
+
+// ReadPackageOwners returns the owners of a Package, oldest first
+func ReadPackageOwners(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
+
+	limit, after, err := pageParams(c, ownerOrder, helpers.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	cond, args := pageAfter(ownerOrder, after, []interface{}{name})
+	args = append(args, limit+1)
+
+	// Query
+	owners := models.Owners{Owner: []models.Owner{}}
+	query := fmt.Sprintf("SELECT username, added_at FROM package_owners WHERE name=$1 AND %s ORDER BY %s LIMIT $%d",
+		cond, helpers.PageOrder(ownerOrder), len(args))
+	if err = DB.Select(&owners.Owner, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(owners.Owner) == 0 && after == nil {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if len(owners.Owner) > limit {
+		owners.Owner = owners.Owner[:limit]
+		last := owners.Owner[limit-1]
+		owners.Next = helpers.EncodePageToken(last.AddedAt, last.Username)
+	}
+
+	return c.JSON(http.StatusOK, owners)
+}
+
+// AddPackageOwner lets another user publish and manage a Package
+func AddPackageOwner(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	username := c.Param("username")
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requirePublishedOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query
+	var exists bool
+	if err := DB.Get(&exists, "SELECT EXISTS(SELECT 1 FROM users WHERE username=$1)", username); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("User %s not found", username))
+	}
+	_, err := DB.Exec("INSERT INTO package_owners(name, username) VALUES($1, $2) ON CONFLICT DO NOTHING", name, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditOwnerAdd, name, username)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemovePackageOwner undoes AddPackageOwner, a Package always keeps one owner
+func RemovePackageOwner(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	username := c.Param("username")
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requirePublishedOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query, the count is checked in the same statement so two owners can't
+	// remove each other at once
+	res, err := DB.Exec(`DELETE FROM package_owners WHERE name=$1 AND username=$2
+						 AND (SELECT count(*) FROM package_owners WHERE name=$1) > 1`, name, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if deletedRows, _ := res.RowsAffected(); deletedRows == 0 {
+		owners, err := packageOwners(name)
+		if err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		for _, owner := range owners {
+			if owner == username {
+				return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("%s is the last owner of %s", username, name))
+			}
+		}
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("%s isn't an owner of %s", username, name))
+	}
+
+	audit(c, helpers.AuditOwnerRemove, name, username)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requirePublishedOwner is requireOwner for changes that only make sense once
+// a package is published, unpublished packages are a 404 rather than allowed
+func requirePublishedOwner(name string, username string) error {
+	owners, err := packageOwners(name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(owners) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	return requireOwner(name, username)
+}
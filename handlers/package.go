@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo"
 	"github.com/labstack/gommon/log"
 	_ "github.com/lib/pq"
+	"github.com/spf13/viper"
 
 	"github.com/sunshinekitty/cr/helpers"
 	"github.com/sunshinekitty/cr/models"
@@ -19,29 +21,116 @@ func CreatePackage(c echo.Context) error {
 	if err := c.Bind(p); err != nil {
 		return err
 	}
+	p.Owner = authUsername(c)
+	// Pulls are only counted by the registry
+	p.Pulls = 0
 
 	if err := helpers.ValidPackage(p); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest)
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// The name is only known from the body, so package scopes are checked here
+	if err := requireScope(c, helpers.ScopePublish, p.Name); err != nil {
+		return err
+	}
+	return publishPackage(c, p)
+}
+
+// publishPackage publishes a valid Package as a new version by its Owner,
+// answering with the published Package
+func publishPackage(c echo.Context, p *models.Package) error {
+	if p.TestCommand == nil && viper.GetBool("require_test_command") {
+		return echo.NewHTTPError(http.StatusBadRequest, "Packages published here need a test_command, check it passes with cr test")
+	}
+
+	if err := requireUnreserved(p.Name); err != nil {
+		return err
+	}
+	if err := requireOwner(p.Name, p.Owner); err != nil {
+		return err
+	}
+	// The signature is of the manifest as published, before the org is filled in
+	if err := requireSignature(p); err != nil {
+		return err
+	}
+	signedOrg := p.Org
+	if err := requireNamespace(p); err != nil {
+		return err
+	}
+	if err := requirePackageOrg(p); err != nil {
+		return err
+	}
+	if p.Signature != nil && signedOrg == nil && p.Org != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Signed packages of the %s org have to set org = \"%s\" in their manifest", *p.Org, *p.Org))
 	}
 
-	// TODO: check for conflict here
-	foundPackage, err := selectPackage(p.Name, p.Version)
-	if foundPackage.Name != "" {
-		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Package %s:%s already exists", foundPackage.Name, foundPackage.Version))
+	// Deprecation is package wide, new versions stay deprecated until undone
+	p.Deprecated = nil
+	err := DB.Get(&p.Deprecated, "SELECT deprecated FROM packages WHERE name=$1 ORDER BY created_at DESC LIMIT 1", p.Name)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
 
-	query := `INSERT INTO packages(command_start, homepage, long_description, 
-								   name, owner, pulls, ports, repository, 
-								   short_description, version, volumes) 
-			  VALUES(:command_start, :homepage, :long_description, :name, 
-					 :owner, :pulls, :ports, :repository, :short_description, 
-					 :version, :volumes)`
+	query := `INSERT INTO packages(command_start, deprecated, description_key, env, homepage, icon, icon_key, keywords, 
+								   long_description, name, owner, pulls, ports, private, readme_key, 
+								   repository, short_description, signature, signing_key, test_command, version, volumes) 
+			  VALUES(:command_start, :deprecated, :description_key, :env, :homepage, :icon, :icon_key, :keywords, 
+					 :long_description, :name, :owner, :pulls, :ports, :private, :readme_key, :repository, 
+					 :short_description, :signature, :signing_key, :test_command, :version, :volumes)`
 
-	_, err = DB.NamedExec(query, p)
+	tx, err := DB.Beginx()
 	if err != nil {
 		log.Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
+	defer tx.Rollback()
+	// Versions are immutable, one published before can't be published again even
+	// once it's deleted
+	res, err := tx.Exec("INSERT INTO published_versions(name, version) VALUES($1, $2) ON CONFLICT DO NOTHING", p.Name, p.Version)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if inserted, _ := res.RowsAffected(); inserted == 0 {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("%s %s was already published and can't be changed, publish a new version", p.Name, p.Version))
+	}
+	// Only once the version is known to be new, so published objects are never overwritten
+	if err = storePackageObjects(p); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusBadGateway, "Couldn't store the package in object storage")
+	}
+	_, err = tx.NamedExec(query, p)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	// Privacy is package wide, the latest publish decides it for every version
+	if _, err = tx.Exec("UPDATE packages SET private=$2 WHERE name=$1", p.Name, p.Private); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	// The first publisher becomes the package's owner, unless it's published
+	// under an org whose members own it instead
+	if p.Org == nil {
+		_, err = tx.Exec("INSERT INTO package_owners(name, username) VALUES($1, $2) ON CONFLICT DO NOTHING", p.Name, p.Owner)
+	} else {
+		_, err = tx.Exec("INSERT INTO package_orgs(name, org) VALUES($1, $2) ON CONFLICT DO NOTHING", p.Name, *p.Org)
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	publishes.Inc()
+	invalidateCache(p.Name)
+	sendWebhooks(c, helpers.WebhookEventPublish, p.Name, p.Version, nil)
+	notifySubscribers(c, p.Name, p.Version)
+	audit(c, helpers.AuditPublish, p.Name, p.Version)
+	p.Namespace, _ = helpers.SplitPackageName(p.Name)
+	signPackageObjects(p)
 
 	return c.JSON(http.StatusCreated, p)
 }
@@ -49,12 +138,15 @@ func CreatePackage(c echo.Context) error {
 // ReadPackage returns a Package by name
 func ReadPackage(c echo.Context) error {
 	// Params
-	name := c.Param("name")
+	name := packageParam(c)
 	version := c.QueryParam("version")
 
 	if !helpers.ValidPackageName(name) {
 		return echo.NewHTTPError(http.StatusNotFound)
 	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
 
 	// Query/unpack
 	foundPackage, err := selectPackage(name, version)
@@ -65,14 +157,73 @@ func ReadPackage(c echo.Context) error {
 		log.Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
+	org, err := packageOrg(name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if org != "" {
+		foundPackage.Org = &org
+	}
+	foundPackage.Namespace, _ = helpers.SplitPackageName(foundPackage.Name)
+	signPackageObjects(&foundPackage)
 
 	return c.JSON(http.StatusOK, foundPackage)
 }
 
+// ReadPackageReadme returns a Package's long description rendered as sanitized
+// HTML and as text for a terminal, so clients needn't render Markdown themselves
+func ReadPackageReadme(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	version := c.QueryParam("version")
+	color := c.QueryParam("color") == "true"
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
+
+	// Query
+	foundPackage, err := selectPackage(name, version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	description := ""
+	if foundPackage.LongDescription != nil {
+		description = *foundPackage.LongDescription
+	}
+	// Only an excerpt is kept in the database once it's in object storage
+	if store, ok := objectStore(); ok && foundPackage.DescriptionKey != nil {
+		b, err := getObject(store, *foundPackage.DescriptionKey)
+		if err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusBadGateway, "Couldn't fetch the long description from object storage")
+		}
+		description = string(b)
+	}
+	if description == "" {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("%s has no long description", name))
+	}
+
+	return c.JSON(http.StatusOK, models.Readme{
+		Name:    foundPackage.Name,
+		Version: foundPackage.Version,
+		HTML:    helpers.RenderMarkdownHTML(description),
+		Text:    helpers.RenderMarkdown(description, color),
+	})
+}
+
 // UpdatePackage updates a Package by name
 func UpdatePackage(c echo.Context) error {
 	// Params
-	name := c.Param("name")
+	name := packageParam(c)
 	//version := c.QueryParam("version")
 
 	if !helpers.ValidPackageName(name) {
@@ -85,24 +236,253 @@ func UpdatePackage(c echo.Context) error {
 // DeletePackage deletes a Package by name
 func DeletePackage(c echo.Context) error {
 	// Params
-	name := c.Param("name")
+	name := packageParam(c)
 	//version := c.QueryParam("version")
 
 	if !helpers.ValidPackageName(name) {
 		return echo.NewHTTPError(http.StatusNotFound)
 	}
 
+	if err := requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
 	// Query
-	delete := `DELETE FROM packages WHERE name=$1`
-	res, err := DB.Exec(delete, name)
+	tx, err := DB.Beginx()
 	if err != nil {
 		log.Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
+	defer tx.Rollback()
+	objects, err := deletePackageRows(tx, name)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	deleteObjects(objects)
+	invalidateCache(name)
+	audit(c, helpers.AuditDelete, name, "")
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// deletePackageRows deletes every version of a package name along with its
+// owners, org, transfers, subscriptions, categories, webhooks and triggers in
+// tx, returning the keys of the objects to delete once tx is committed. A 404
+// error is returned when it isn't published.
+func deletePackageRows(tx *sqlx.Tx, name string) ([]string, error) {
+	var objects []string
+	err := tx.Select(&objects, `SELECT key FROM (
+								   SELECT description_key AS key FROM packages WHERE name=$1
+								   UNION ALL SELECT readme_key FROM packages WHERE name=$1
+								   UNION ALL SELECT icon_key FROM packages WHERE name=$1
+							   ) objects WHERE key IS NOT NULL`, name)
+	if err != nil {
+		log.Error(err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	delete := `DELETE FROM packages WHERE name=$1`
+	res, err := tx.Exec(delete, name)
+	if err != nil {
+		log.Error(err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError)
+	}
 	deletedRows, _ := res.RowsAffected()
 	if deletedRows == 0 {
+		return nil, echo.NewHTTPError(http.StatusNotFound)
+	}
+	// A deleted package's name can be published by anyone again, nothing of the
+	// package before can be left to act on or for whoever publishes it next
+	for _, table := range []string{"package_owners", "package_orgs", "package_transfers", "package_subscriptions",
+		"package_categories", "webhooks", "triggers"} {
+		if _, err = tx.Exec("DELETE FROM "+table+" WHERE name=$1", name); err != nil {
+			log.Error(err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError)
+		}
+	}
+	return objects, nil
+}
+
+// versionOrder are the columns versions of a Package are ordered and paged by
+var versionOrder = []helpers.PageKey{{Column: "created_at", Desc: true}, {Column: "version"}}
+
+// ReadPackageVersions returns every published version of a Package, newest first
+func ReadPackageVersions(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
+
+	limit, after, err := pageParams(c, versionOrder, helpers.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	cond, args := pageAfter(versionOrder, after, []interface{}{name})
+	args = append(args, limit+1)
+
+	// Query
+	versions := models.PackageVersions{Version: []models.PackageVersion{}}
+	query := fmt.Sprintf("SELECT version, created_at, yanked FROM packages WHERE name=$1 AND %s ORDER BY %s LIMIT $%d",
+		cond, helpers.PageOrder(versionOrder), len(args))
+	if err = DB.Select(&versions.Version, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	// Only the first page being empty means there's no such package
+	if len(versions.Version) == 0 && after == nil {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if len(versions.Version) > limit {
+		versions.Version = versions.Version[:limit]
+		last := versions.Version[limit-1]
+		versions.Next = helpers.EncodePageToken(last.CreatedAt, last.Version)
+	}
+
+	return c.JSON(http.StatusOK, versions)
+}
+
+// ResolvePackageVersion returns the newest version of a Package in a range such
+// as ^1.2, so clients needn't fetch every version to pick one. Yanked versions
+// are never picked.
+func ResolvePackageVersion(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	versionRange := c.QueryParam("range")
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	r, err := helpers.ParseVersionRange(versionRange)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err = requireVisible(c, name); err != nil {
+		return err
+	}
+
+	// Query
+	var versions []models.PackageVersion
+	if err = DB.Select(&versions, "SELECT version, created_at, yanked FROM packages WHERE name=$1", name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(versions) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	candidates := make(map[string]models.PackageVersion)
+	var unyanked []string
+	for _, v := range versions {
+		if !v.Yanked {
+			candidates[v.Version] = v
+			unyanked = append(unyanked, v.Version)
+		}
+	}
+	best, ok := r.BestVersion(unyanked)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No version of %s matches %s", name, versionRange))
+	}
+
+	return c.JSON(http.StatusOK, candidates[best])
+}
+
+// YankPackageVersion marks a version of a Package as yanked
+func YankPackageVersion(c echo.Context) error {
+	return setYanked(c, true)
+}
+
+// UnyankPackageVersion undoes YankPackageVersion
+func UnyankPackageVersion(c echo.Context) error {
+	return setYanked(c, false)
+}
+
+func setYanked(c echo.Context, yanked bool) error {
+	// Params
+	name := packageParam(c)
+	version := c.Param("version")
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query
+	res, err := DB.Exec("UPDATE packages SET yanked=$1 WHERE name=$2 AND version=$3", yanked, name, version)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	updatedRows, _ := res.RowsAffected()
+	if updatedRows == 0 {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	event, action := helpers.WebhookEventYank, helpers.AuditYank
+	if !yanked {
+		event, action = helpers.WebhookEventUnyank, helpers.AuditUnyank
+	}
+	invalidateCache(name)
+	sendWebhooks(c, event, name, version, nil)
+	audit(c, action, name, version)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeprecatePackage marks every version of a Package as deprecated with a message
+func DeprecatePackage(c echo.Context) error {
+	d := new(models.Deprecation)
+	if err := c.Bind(d); err != nil {
+		return err
+	}
+	if err := helpers.ValidDeprecation(d.Message); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return setDeprecated(c, &d.Message)
+}
+
+// UndeprecatePackage undoes DeprecatePackage
+func UndeprecatePackage(c echo.Context) error {
+	return setDeprecated(c, nil)
+}
+
+func setDeprecated(c echo.Context, message *string) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
 		return echo.NewHTTPError(http.StatusNotFound)
 	}
+	if err := requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query
+	res, err := DB.Exec("UPDATE packages SET deprecated=$1 WHERE name=$2", message, name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	updatedRows, _ := res.RowsAffected()
+	if updatedRows == 0 {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	event, action, detail := helpers.WebhookEventDeprecate, helpers.AuditDeprecate, ""
+	if message == nil {
+		event, action = helpers.WebhookEventUndeprecate, helpers.AuditUndeprecate
+	} else {
+		detail = *message
+	}
+	invalidateCache(name)
+	sendWebhooks(c, event, name, "", message)
+	audit(c, action, name, detail)
 
 	return c.NoContent(http.StatusNoContent)
 }
@@ -111,10 +491,41 @@ func selectPackage(packageName string, version string) (models.Package, error) {
 	var err error
 	foundPackage := models.Package{}
 	if version == "" {
-		err = DB.Get(&foundPackage, "SELECT * FROM packages WHERE name=$1 ORDER BY created_at DESC LIMIT 1", packageName)
+		// Yanked versions are only returned when asked for by version
+		err = DB.Get(&foundPackage, "SELECT * FROM packages WHERE name=$1 AND NOT yanked ORDER BY created_at DESC LIMIT 1", packageName)
 	} else {
 		log.Error(version)
 		err = DB.Get(&foundPackage, "SELECT * FROM packages WHERE name=$1 AND version=$2 ORDER BY created_at DESC LIMIT 1", packageName, version)
 	}
 	return foundPackage, err
 }
+
+// packageParam returns the name of the package a route is for, namespaced
+// packages are routed with their namespace as a param of its own
+func packageParam(c echo.Context) string {
+	return helpers.QualifyPackageName(c.Param("namespace"), c.Param("name"))
+}
+
+// requireNamespace checks a package in a namespace is published to its
+// publisher's own namespace or to an org's, packages in an org's namespace
+// belong to that org
+func requireNamespace(p *models.Package) error {
+	namespace, _ := helpers.SplitPackageName(p.Name)
+	switch {
+	case namespace == "" || namespace == p.Owner && p.Org == nil:
+		return nil
+	case p.Org != nil && *p.Org != namespace:
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Packages in the %s namespace can't belong to another org", namespace))
+	}
+
+	var isOrg bool
+	if err := DB.Get(&isOrg, "SELECT EXISTS(SELECT 1 FROM orgs WHERE name=$1)", namespace); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !isOrg {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Only %s can publish to the %s namespace", namespace, namespace))
+	}
+	p.Org = &namespace
+	return nil
+}
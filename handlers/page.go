@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// pageParams reads the limit and next query params of a list ordered by keys,
+// limit defaults to def. after holds the position to continue from, it's nil
+// on the first page.
+func pageParams(c echo.Context, keys []helpers.PageKey, def int) (limit int, after []string, err error) {
+	limit = def
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > helpers.MaxPageSize {
+			return 0, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Limit should be between 1 and %d", helpers.MaxPageSize))
+		}
+	}
+	if next := c.QueryParam("next"); next != "" {
+		if after, err = helpers.DecodePageToken(next, len(keys)); err != nil {
+			return 0, nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	return limit, after, nil
+}
+
+// pageAfter returns a WHERE condition for rows after the position of a next
+// token, or an always true one on the first page
+func pageAfter(keys []helpers.PageKey, after []string, args []interface{}) (string, []interface{}) {
+	if after == nil {
+		return "TRUE", args
+	}
+	return helpers.PageCondition(keys, after, args)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// OptionalAuth is middleware authenticating requests that send an API token
+// like RequireAuth, requests without one carry on anonymously. Read endpoints
+// use it to show private packages to those allowed to see them.
+func OptionalAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	auth := RequireAuth(next)
+	return func(c echo.Context) error {
+		if helpers.BearerToken(c.Request().Header.Get("Authorization")) == "" {
+			return next(c)
+		}
+		return auth(c)
+	}
+}
+
+// visibleCondition returns the condition on rows of packages that username can
+// see with its args appended: public packages, and private ones they own or
+// are a member of the org of. Anonymous requests only see public packages.
+func visibleCondition(username string, args []interface{}) (string, []interface{}) {
+	if username == "" {
+		return "NOT private", args
+	}
+	args = append(args, username)
+	return fmt.Sprintf(`(NOT private OR name IN (
+							SELECT name FROM package_owners WHERE username = $%[1]d
+							UNION SELECT package_orgs.name FROM package_orgs JOIN org_members USING (org)
+							WHERE org_members.username = $%[1]d))`, len(args)), args
+}
+
+// requireVisible returns a 404 error when name is a private package the
+// request's user can't see, the same as when it doesn't exist
+func requireVisible(c echo.Context, name string) error {
+	cond, args := visibleCondition(authUsername(c), []interface{}{name})
+	var hidden bool
+	err := DB.Get(&hidden, fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM packages WHERE name=$1 AND NOT %s)", cond), args...)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if hidden {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/server"
+)
+
+func TestRequireVisible(t *testing.T) {
+	e := server.New()
+	owner := newUser(t, "private-owner", helpers.DefaultTokenScopes...)
+	stranger := newUser(t, "private-stranger", helpers.DefaultTokenScopes...)
+	publish(t, e, owner, "hidden", "1.0.0", true)
+
+	missing := request(e, "GET", "/api/package/nothere/stats", stranger, "")
+	for _, path := range []string{"/api/package/hidden", "/api/package/hidden/stats", "/api/package/hidden/versions"} {
+		if rec := request(e, "GET", path, owner, ""); rec.Code != 200 {
+			t.Errorf("The owner should see %s, got %d %s", path, rec.Code, rec.Body)
+		}
+		// Hidden the same way as a package that doesn't exist
+		for _, token := range []string{"", stranger} {
+			if rec := request(e, "GET", path, token, ""); rec.Code != 404 || rec.Body.String() != missing.Body.String() {
+				t.Errorf("%s should be hidden from users who aren't owners, got %d %s", path, rec.Code, rec.Body)
+			}
+		}
+	}
+	if rec := request(e, "GET", "/api/search?q=hidden", stranger, ""); rec.Code != 200 || rec.Body.String() != `{"Package":[]}`+"\n" {
+		t.Errorf("Searches should leave out private packages of others, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// rateLimitDefaults are the requests a minute allowed for each rate limit group
+// unless rate_limit.<group> in server.toml says otherwise
+var rateLimitDefaults = map[string]struct{ perToken, perIP int }{
+	"publish": {perToken: 30, perIP: 60},
+	"search":  {perToken: 120, perIP: 240},
+	// Logins and requests checking a two-factor code, which can be guessed
+	"login": {perToken: 10, perIP: 20},
+}
+
+// RateLimit returns middleware limiting requests to the routes of group, set
+// with per_token, per_ip and window under rate_limit.<group> in server.toml.
+// Every request counts against its IP and, when it sends one, its API token
+// too, a limit of 0 turns it off. Responses say where the tightest limit stands
+// with RateLimit headers, refused requests get a 429 with Retry-After.
+func RateLimit(group string) echo.MiddlewareFunc {
+	limiter := helpers.NewRateLimiter()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// Read on every request so a reloaded server.toml applies
+			config := "rate_limit." + group
+			window := viper.GetDuration(config + ".window")
+			if window <= 0 {
+				window = time.Minute
+			}
+			now := time.Now()
+
+			// A made up token still counts against the IP, so it can't dodge the limit
+			var limits []helpers.RateLimit
+			if limit := rateLimitPerIP(config, group); limit > 0 {
+				limits = append(limits, limiter.Allow("ip:"+clientIP(c), limit, window, now))
+			}
+			token := helpers.BearerToken(c.Request().Header.Get("Authorization"))
+			if limit := rateLimitPerToken(config, group); limit > 0 && token != "" {
+				limits = append(limits, limiter.Allow("token:"+helpers.HashToken(token), limit, window, now))
+			}
+			if len(limits) == 0 {
+				return next(c)
+			}
+			r := limits[0]
+			for _, l := range limits[1:] {
+				if !l.Allowed || r.Allowed && l.Remaining < r.Remaining {
+					r = l
+				}
+			}
+
+			header := c.Response().Header()
+			header.Set(helpers.RateLimitLimitHeader, fmt.Sprint(r.Limit))
+			header.Set(helpers.RateLimitRemainingHeader, fmt.Sprint(r.Remaining))
+			header.Set(helpers.RateLimitResetHeader, r.ResetSeconds())
+			if !r.Allowed {
+				header.Set("Retry-After", r.ResetSeconds())
+				return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf("Too many requests, try again in %s seconds", r.ResetSeconds()))
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimitPerToken returns the requests a window each token is allowed
+func rateLimitPerToken(config string, group string) int {
+	if viper.IsSet(config + ".per_token") {
+		return viper.GetInt(config + ".per_token")
+	}
+	return rateLimitDefaults[group].perToken
+}
+
+// rateLimitPerIP returns the requests a window each IP is allowed
+func rateLimitPerIP(config string, group string) int {
+	if viper.IsSet(config + ".per_ip") {
+		return viper.GetInt(config + ".per_ip")
+	}
+	return rateLimitDefaults[group].perIP
+}
+
+// clientIP returns the IP of the client making a request, forwarding headers
+// are only believed from the trusted_proxies of server.toml
+func clientIP(c echo.Context) string {
+	return helpers.ClientIP(c.Request(), viper.GetStringSlice(helpers.TrustedProxiesKey))
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// reportOrder pages reports oldest first, the order admins work through them
+var reportOrder = []helpers.PageKey{{Column: "id"}}
+
+// ReportPackage flags a Package, or one of its versions, to the registry's
+// admins. A user can only have one open report of each package.
+func ReportPackage(c echo.Context) error {
+	r := new(models.Report)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := helpers.ValidReportReason(r.Reason); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
+	version := ""
+	if r.Version != nil {
+		version = *r.Version
+	}
+	if _, err := selectPackage(name, version); err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound)
+	} else if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	// Query
+	username := authUsername(c)
+	err := DB.QueryRow(`INSERT INTO reports(name, version, reason, reporter) VALUES($1, $2, $3, $4)
+					    ON CONFLICT (name, reporter) WHERE status = 'open' DO NOTHING
+					    RETURNING id, status, created_at`, name, r.Version, r.Reason, username).Scan(&r.ID, &r.Status, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("You already reported %s, an admin will look at it", name))
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	r.Package, r.Reporter = name, &username
+
+	// Not in the package's own log, so its owners can't see who reported it
+	audit(c, helpers.AuditPackageReport, "", fmt.Sprintf("%s: %d", name, r.ID))
+
+	return c.JSON(http.StatusCreated, r)
+}
+
+// ReadReports returns reports of packages, oldest first, only those with the
+// status query param when it's given
+func ReadReports(c echo.Context) error {
+	// Params
+	var where []string
+	var args []interface{}
+	if status := c.QueryParam("status"); status != "" {
+		if err := helpers.ValidReportStatus(status); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		args = append(args, status)
+		where = append(where, fmt.Sprintf("status = $%d", len(args)))
+	}
+	limit, after, err := pageParams(c, reportOrder, helpers.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	cond, args := pageAfter(reportOrder, after, args)
+	where = append(where, cond)
+	args = append(args, limit+1)
+
+	// Query
+	query := fmt.Sprintf(`SELECT id, name, version, reason, reporter, status, created_at, resolved_by, resolved_at, resolution
+						  FROM reports WHERE %s ORDER BY %s LIMIT $%d`,
+		strings.Join(where, " AND "), helpers.PageOrder(reportOrder), len(args))
+	reports := models.Reports{Report: []models.Report{}}
+	if err = DB.Select(&reports.Report, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(reports.Report) > limit {
+		reports.Report = reports.Report[:limit]
+		reports.Next = helpers.EncodePageToken(strconv.Itoa(reports.Report[limit-1].ID))
+	}
+
+	return c.JSON(http.StatusOK, reports)
+}
+
+// ResolveReport dismisses a report, or opens it again. Reports are marked
+// removed by RemovePackage, which removes the package too.
+func ResolveReport(c echo.Context) error {
+	res := new(models.ReportResolution)
+	if err := c.Bind(res); err != nil {
+		return err
+	}
+	if err := helpers.ValidReportStatus(res.Status); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if res.Status == helpers.ReportRemoved {
+		return echo.NewHTTPError(http.StatusBadRequest, "Remove the package to resolve its reports as removed")
+	}
+	if err := helpers.ValidModerationReason(res.Reason, true); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// Params
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	var resolution *string
+	if res.Reason != "" {
+		resolution = &res.Reason
+	}
+
+	// Query
+	var name string
+	if res.Status == helpers.ReportOpen {
+		err = DB.Get(&name, `UPDATE reports SET status=$1, resolved_by=NULL, resolved_at=NULL, resolution=NULL
+							 WHERE id=$2 RETURNING name`, res.Status, id)
+	} else {
+		err = DB.Get(&name, `UPDATE reports SET status=$1, resolved_by=$2, resolved_at=`+Dialect.Now()+`, resolution=$3
+							 WHERE id=$4 RETURNING name`, res.Status, authUsername(c), resolution, id)
+	}
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Report %d not found", id))
+	}
+	if err != nil {
+		// Reopening a report whose reporter has another open one of the package
+		if Dialect.UniqueViolation(err) {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("The reporter of %d has another open report", id))
+		}
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	audit(c, helpers.AuditReportResolve, "", fmt.Sprintf("%s: %d %s", name, id, res.Status))
+
+	return c.NoContent(http.StatusNoContent)
+}
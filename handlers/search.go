@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// searchOrder maps a search sort to the columns results are ordered and paged by
+var searchOrder = map[string][]helpers.PageKey{
+	helpers.SearchSortRelevance: {{Column: "rank", Desc: true, Numeric: true}, {Column: "name"}},
+	helpers.SearchSortPulls:     {{Column: "pulls", Desc: true}, {Column: "name"}},
+	helpers.SearchSortUpdated:   {{Column: "updated_at", Desc: true}, {Column: "name"}},
+	helpers.SearchSortName:      {{Column: "name"}},
+}
+
+// packageKeyValues returns the values of a Package for keys, the position a
+// page of packages ordered by them ends at
+func packageKeyValues(p models.Package, keys []helpers.PageKey) []string {
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		switch k.Column {
+		case "pulls":
+			values[i] = strconv.Itoa(p.Pulls)
+		case "updated_at":
+			values[i] = p.UpdatedAt
+		case "created_at":
+			values[i] = p.CreatedAt
+		case "name":
+			values[i] = p.Name
+		}
+	}
+	return values
+}
+
+// searchKeyValues returns the values of a SearchResult for keys, like
+// packageKeyValues but also ordered by rank
+func searchKeyValues(r models.SearchResult, keys []helpers.PageKey) []string {
+	values := packageKeyValues(r.Package, keys)
+	for i, k := range keys {
+		if k.Column == "rank" {
+			values[i] = strconv.FormatFloat(r.Rank, 'f', -1, 64)
+		}
+	}
+	return values
+}
+
+// searchWeights returns the weights of server.toml's [search], the defaults for
+// those it doesn't set
+func searchWeights() helpers.SearchWeights {
+	w := helpers.DefaultSearchWeights
+	for key, weight := range map[string]*float64{
+		"search.text_weight":       &w.Text,
+		"search.exact_name_weight": &w.ExactName,
+		"search.pulls_weight":      &w.Pulls,
+		"search.recency_weight":    &w.Recency,
+	} {
+		if viper.IsSet(key) {
+			*weight = viper.GetFloat64(key)
+		}
+	}
+	if viper.IsSet("search.pulls_midpoint") {
+		w.PullsMidpoint = viper.GetInt("search.pulls_midpoint")
+	}
+	if viper.IsSet("search.recency_half_life") {
+		w.RecencyHalfLife = viper.GetInt("search.recency_half_life")
+	}
+	return w
+}
+
+// SearchPackages returns the latest version of every Package matching a query.
+// The query is matched against the full text of names, keywords and descriptions,
+// which rank in that order, or as part of a name, see Dialect.TextSearch. Matches
+// are ranked by searchWeights, so a package named the query and popular ones
+// come first.
+func SearchPackages(c echo.Context) error {
+	// Params
+	query := strings.TrimSpace(c.QueryParam("q"))
+	owner := c.QueryParam("owner")
+	keyword := c.QueryParam("keyword")
+	category := c.QueryParam("category")
+	sort := c.QueryParam("sort")
+
+	if sort == "" {
+		sort = helpers.SearchSortRelevance
+	}
+	if !helpers.ValidSearchSort(sort) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Sort \"%s\" is invalid, use relevance, pulls, updated or name", sort))
+	}
+	// Without a query everything is as relevant
+	if sort == helpers.SearchSortRelevance && query == "" {
+		sort = helpers.SearchSortPulls
+	}
+	keys := searchOrder[sort]
+	limit, after, err := pageParams(c, keys, helpers.DefaultSearchLimit)
+	if err != nil {
+		return err
+	}
+
+	visible, args := visibleCondition(authUsername(c), nil)
+	where := []string{visible}
+	rank := Dialect.Rank("0")
+	if query != "" {
+		args = append(args, query, "%"+helpers.EscapeLike(query)+"%")
+		q := len(args) - 1
+		match, text := Dialect.TextSearch(fmt.Sprintf("$%d", q), fmt.Sprintf("$%d", q+1))
+		where = append(where, match)
+		// Popularity counts the pulls of every version, rounding keeps ranks exact in next tokens
+		pulls := "(SELECT sum(p.pulls) FROM packages p WHERE p.name = latest.name)"
+		rank = Dialect.Rank(searchWeights().RankSQL(text, Dialect.Text(fmt.Sprintf("$%d", q)), pulls, Dialect.DaysSince("updated_at")))
+	}
+	if owner != "" {
+		args = append(args, owner)
+		where = append(where, fmt.Sprintf("name IN (SELECT name FROM package_owners WHERE username = $%d)", len(args)))
+	}
+	if keyword != "" {
+		args = append(args, keyword)
+		where = append(where, Dialect.Contains("keywords", fmt.Sprintf("$%d", len(args))))
+	}
+	if category != "" {
+		exists, err := categoryExists(category)
+		if err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		if !exists {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Category %s not found", category))
+		}
+		args = append(args, category)
+		where = append(where, fmt.Sprintf("name IN (SELECT name FROM package_categories WHERE category = $%d)", len(args)))
+	}
+	filter := "WHERE " + strings.Join(where, " AND ")
+	cond, args := pageAfter(keys, after, args)
+	// One more than the limit shows whether there's another page
+	args = append(args, limit+1)
+
+	// Query, only the latest version of each package is matched
+	search := fmt.Sprintf(`SELECT * FROM (
+								SELECT latest.*, %s AS rank FROM (%s) latest %s
+							) results WHERE %s ORDER BY %s LIMIT $%d`,
+		rank, Dialect.Latest("NOT yanked"), filter, cond, helpers.PageOrder(keys), len(args))
+	results := models.SearchResults{Package: []models.SearchResult{}}
+	if err := DB.Select(&results.Package, search, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(results.Package) > limit {
+		results.Package = results.Package[:limit]
+		results.Next = helpers.EncodePageToken(searchKeyValues(results.Package[limit-1], keys)...)
+	}
+	for i := range results.Package {
+		signPackageObjects(&results.Package[i].Package)
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// SetSigningKey registers the public key the authenticated user signs packages
+// with, replacing any before it. Versions signed before keep the key they
+// were signed with.
+func SetSigningKey(c echo.Context) error {
+	k := new(models.SigningKey)
+	if err := c.Bind(k); err != nil {
+		return err
+	}
+	if err := helpers.ValidSigningKey(k.PublicKey); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Signing key must be a base64 ed25519 public key")
+	}
+
+	// Query
+	if _, err := DB.Exec("UPDATE users SET signing_key=$1 WHERE username=$2", k.PublicKey, authUsername(c)); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	audit(c, helpers.AuditSigningKeySet, "", helpers.KeyFingerprint(k.PublicKey))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveSigningKey forgets the authenticated user's signing key, they can't
+// publish signed packages until they register another
+func RemoveSigningKey(c echo.Context) error {
+	// Query
+	res, err := DB.Exec("UPDATE users SET signing_key=NULL WHERE username=$1 AND signing_key IS NOT NULL", authUsername(c))
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if updatedRows, _ := res.RowsAffected(); updatedRows == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "You haven't registered a signing key")
+	}
+	audit(c, helpers.AuditSigningKeyRemove, "", "")
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requireSignature checks a signed package's signature was made by its
+// publisher's registered key and records the key with it, unsigned packages
+// are published as they are
+func requireSignature(p *models.Package) error {
+	p.SigningKey = nil
+	if p.Signature == nil {
+		return nil
+	}
+
+	var key sql.NullString
+	err := DB.Get(&key, "SELECT signing_key FROM users WHERE username=$1", p.Owner)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !key.Valid {
+		return echo.NewHTTPError(http.StatusBadRequest, "Register your signing key with `cr key register` before publishing signed packages")
+	}
+	pt, err := helpers.PackageToPackageToml(p)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if err = helpers.VerifyManifest(key.String, *p.Signature, pt); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "The package's signature wasn't made by your registered signing key")
+	}
+	p.SigningKey = &key.String
+	return nil
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// defaultStatsRange maps a stats period to how far back pull counts are returned when
+// the request doesn't give a range
+var defaultStatsRange = map[string]string{
+	helpers.StatsPeriodDay:   "30d",
+	helpers.StatsPeriodWeek:  "12w",
+	helpers.StatsPeriodMonth: "365d",
+}
+
+// RecordPull counts a pull of a Package version. Every pull is counted, but a
+// client pulling a package again the same day isn't counted as unique, nor in
+// the package's total.
+func RecordPull(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	version := c.Param("version")
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
+	client := pullClient(c)
+
+	// Query
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	defer tx.Rollback()
+	var found bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM packages WHERE name=$1 AND version=$2)", name, version).Scan(&found)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !found {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	res, err := tx.Exec(`INSERT INTO package_pull_clients(name, day, client) VALUES($1, `+Dialect.Today()+`, $2)
+						 ON CONFLICT DO NOTHING`, name, client)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	unique := 0
+	if inserted, _ := res.RowsAffected(); inserted > 0 {
+		unique = 1
+	}
+	_, err = tx.Exec(`INSERT INTO package_pulls(name, day, pulls, unique_pulls) VALUES($1, `+Dialect.Today()+`, 1, $2)
+					  ON CONFLICT (name, day) DO UPDATE SET pulls = package_pulls.pulls + 1,
+					  unique_pulls = package_pulls.unique_pulls + $2`, name, unique)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if unique > 0 {
+		_, err = tx.Exec("UPDATE packages SET pulls = pulls + 1 WHERE name=$1 AND version=$2", name, version)
+		if err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+	}
+	// Clients are only needed to deduplicate today's pulls
+	if _, err = tx.Exec("DELETE FROM package_pull_clients WHERE name=$1 AND day < "+Dialect.Today(), name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// pullClient returns who's pulling, hashed so it isn't kept. Clients send the id
+// of their machine, those that don't are told apart by address and user agent.
+func pullClient(c echo.Context) string {
+	req := c.Request()
+	if id := req.Header.Get(helpers.PullClientHeader); id != "" {
+		return helpers.HashToken(id)
+	}
+	return helpers.HashToken(clientIP(c) + " " + req.UserAgent())
+}
+
+// ReadPackageStats returns the pulls of a Package grouped by day, week or month
+// over a range such as 30d. Every period in the range is returned, those without
+// pulls as zero, so the series can be drawn as it is.
+func ReadPackageStats(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	period := c.QueryParam("period")
+	statsRange := c.QueryParam("range")
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireVisible(c, name); err != nil {
+		return err
+	}
+	if period == "" {
+		period = helpers.StatsPeriodDay
+	}
+	if !helpers.ValidStatsPeriod(period) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Period \"%s\" is invalid, use day, week or month", period))
+	}
+	if statsRange == "" {
+		statsRange = defaultStatsRange[period]
+	}
+	days, err := helpers.ParseStatsRange(statsRange)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Query
+	stats := models.PackageStats{Name: name, Period: period, Range: statsRange, Pulls: []models.PullCount{}}
+	var total sql.NullInt64
+	if err := DB.Get(&total, "SELECT sum(pulls) FROM packages WHERE name=$1", name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !total.Valid {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	stats.Total = int(total.Int64)
+
+	// period was checked above so never comes from the request as it is, periods
+	// are whole even when the range starts partway through the first
+	if err := DB.Select(&stats.Pulls, Dialect.PullSeries(period), name, days); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
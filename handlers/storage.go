@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// defaultObjectURLExpiry is how long signed URLs last when storage.url_expiry isn't set
+	defaultObjectURLExpiry = time.Hour
+	// descriptionExcerptLength is how much of a long description kept in object
+	// storage stays in the database, for search to match
+	descriptionExcerptLength = 1000
+	// maxIconSize is the largest icon copied into object storage
+	maxIconSize = 1 << 20
+)
+
+var (
+	// storageClient makes requests to object storage
+	storageClient = &http.Client{Timeout: 30 * time.Second}
+	// iconClient fetches icons to copy, it only connects to public addresses so
+	// publishers can't reach the registry's own network through it
+	iconClient = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: publicAddressOnly,
+		}).DialContext},
+	}
+	// errPrivateAddress is thrown when a host the registry connects to for a
+	// publisher, an icon's or a webhook's, resolves to a private address
+	errPrivateAddress = errors.New("host isn't a public address")
+)
+
+// objectStore returns the object storage configured under [storage] in
+// server.toml, ok is false when there isn't any and everything is kept in the
+// database
+func objectStore() (store *helpers.ObjectStore, ok bool) {
+	endpoint, err := url.Parse(viper.GetString("storage.endpoint"))
+	if err != nil || endpoint.Host == "" || viper.GetString("storage.bucket") == "" {
+		return nil, false
+	}
+	region := viper.GetString("storage.region")
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &helpers.ObjectStore{
+		Endpoint:  endpoint,
+		Bucket:    viper.GetString("storage.bucket"),
+		Region:    region,
+		AccessKey: viper.GetString("storage.access_key"),
+		SecretKey: viper.GetString("storage.secret_key"),
+		PathStyle: viper.GetBool("storage.path_style"),
+	}, true
+}
+
+// objectURLExpiry returns how long signed URLs last
+func objectURLExpiry() time.Duration {
+	if expiry := viper.GetDuration("storage.url_expiry"); expiry > 0 {
+		return expiry
+	}
+	return defaultObjectURLExpiry
+}
+
+// packageObjectKey returns the key of one of a package version's objects
+func packageObjectKey(p *models.Package, object string) string {
+	return fmt.Sprintf("packages/%s/%s/%s", p.Name, p.Version, object)
+}
+
+// storePackageObjects moves a package's long description, its rendering and
+// a copy of its icon into object storage when there is any. An icon that can't
+// be copied is only linked to. Objects the package already has a key for, as a
+// version published by a trigger shares those of the one it's copied from, are
+// kept.
+func storePackageObjects(p *models.Package) error {
+	store, ok := objectStore()
+	if !ok {
+		return nil
+	}
+	if p.LongDescription != nil && p.DescriptionKey == nil {
+		description := *p.LongDescription
+		key := packageObjectKey(p, "description.md")
+		if err := putObject(store, key, "text/markdown; charset=utf-8", []byte(description)); err != nil {
+			return err
+		}
+		p.DescriptionKey = &key
+
+		readmeKey := packageObjectKey(p, "readme.txt")
+		if err := putObject(store, readmeKey, "text/plain; charset=utf-8", []byte(helpers.RenderMarkdown(description, false))); err != nil {
+			return err
+		}
+		p.ReadmeKey = &readmeKey
+
+		if len(description) > descriptionExcerptLength {
+			n := descriptionExcerptLength
+			for n > 0 && !utf8.RuneStart(description[n]) {
+				n--
+			}
+			excerpt := description[:n]
+			p.LongDescription = &excerpt
+		}
+	}
+
+	if p.Icon != nil && p.IconKey == nil {
+		icon, contentType, err := fetchIcon(*p.Icon)
+		if err != nil {
+			log.Warnf("couldn't copy icon of %s %s: %s", p.Name, p.Version, err)
+			return nil
+		}
+		key := packageObjectKey(p, "icon")
+		if err = putObject(store, key, contentType, icon); err != nil {
+			return err
+		}
+		p.IconKey = &key
+	}
+	return nil
+}
+
+// signPackageObjects fills in signed URLs of a package's objects, its long
+// description is then only served from its URL
+func signPackageObjects(p *models.Package) {
+	store, ok := objectStore()
+	if !ok {
+		return
+	}
+	now, expiry := time.Now(), objectURLExpiry()
+	if p.DescriptionKey != nil {
+		p.LongDescription = nil
+		p.LongDescriptionURL = store.PresignGet(*p.DescriptionKey, now, expiry)
+	}
+	if p.ReadmeKey != nil {
+		p.ReadmeURL = store.PresignGet(*p.ReadmeKey, now, expiry)
+	}
+	if p.IconKey != nil {
+		p.IconURL = store.PresignGet(*p.IconKey, now, expiry)
+	}
+}
+
+// deleteObjects deletes objects of deleted packages, failures are only logged
+func deleteObjects(keys []string) {
+	store, ok := objectStore()
+	if !ok {
+		return
+	}
+	for _, key := range keys {
+		if err := objectRequest(store, "DELETE", key, "", nil); err != nil {
+			log.Warnf("couldn't delete object %s: %s", key, err)
+		}
+	}
+}
+
+// putObject uploads an object
+func putObject(store *helpers.ObjectStore, key string, contentType string, body []byte) error {
+	return objectRequest(store, "PUT", key, contentType, body)
+}
+
+// getObject downloads an object
+func getObject(store *helpers.ObjectStore, key string) ([]byte, error) {
+	return objectResponse(store, "GET", key, "", nil)
+}
+
+// objectRequest sends a signed request for an object
+func objectRequest(store *helpers.ObjectStore, method string, key string, contentType string, body []byte) error {
+	_, err := objectResponse(store, method, key, contentType, body)
+	return err
+}
+
+// objectResponse sends a signed request for an object and returns the body of
+// the response
+func objectResponse(store *helpers.ObjectStore, method string, key string, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, store.ObjectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	hash := sha256.Sum256(body)
+	store.Sign(req, hex.EncodeToString(hash[:]), time.Now())
+	resp, err := storageClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s: %s", method, key, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// fetchIcon downloads an icon to copy, refusing anything but a small image
+func fetchIcon(iconURL string) (icon []byte, contentType string, err error) {
+	resp, err := iconClient.Get(iconURL)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET %s: %s", iconURL, resp.Status)
+	}
+	contentType = resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("%s isn't an image but %q", iconURL, contentType)
+	}
+	icon, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxIconSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(icon) > maxIconSize {
+		return nil, "", fmt.Errorf("%s is larger than %s", iconURL, helpers.HumanSize(maxIconSize))
+	}
+	return icon, contentType, nil
+}
+
+// publicAddressOnly refuses connections to loopback, private and link local
+// addresses, it's checked after host names are resolved
+func publicAddressOnly(network string, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !helpers.PublicIP(net.ParseIP(host)) {
+		return errPrivateAddress
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// ReadTokens returns the API tokens of the authenticated user, newest first.
+// Only their hashes are stored so the tokens themselves aren't included.
+func ReadTokens(c echo.Context) error {
+	username := authUsername(c)
+
+	// Query
+	var rows []struct {
+		ID        int
+		Name      *string
+		Scopes    types.JSONText
+		CreatedAt string  `db:"created_at"`
+		LastUsed  *string `db:"last_used"`
+	}
+	err := DB.Select(&rows, `SELECT id, name, scopes, created_at, last_used FROM tokens
+							 WHERE username=$1 ORDER BY created_at DESC, id DESC`, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	tokens := models.Tokens{Token: []models.Token{}}
+	for _, row := range rows {
+		t := models.Token{ID: row.ID, Username: username, CreatedAt: row.CreatedAt, LastUsed: row.LastUsed}
+		if row.Name != nil {
+			t.Name = *row.Name
+		}
+		if err = row.Scopes.Unmarshal(&t.Scopes); err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		tokens.Token = append(tokens.Token, t)
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// CreateToken issues the authenticated user a new API token with the requested
+// scopes, the only time the token is shown
+func CreateToken(c echo.Context) error {
+	r := new(models.TokenRequest)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if len(r.Scopes) == 0 {
+		r.Scopes = helpers.DefaultCreatedTokenScopes
+	}
+	if err := helpers.ValidTokenRequest(r.Name, r.Scopes); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	token, err := helpers.NewToken()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	scopes, err := json.Marshal(r.Scopes)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	var name *string
+	if r.Name != "" {
+		name = &r.Name
+	}
+	t := models.Token{Username: authUsername(c), Token: token, Name: r.Name, Scopes: r.Scopes}
+	err = DB.QueryRow(`INSERT INTO tokens(token_hash, username, name, scopes) VALUES($1, $2, $3, $4)
+					   RETURNING id, created_at`, helpers.HashToken(token), t.Username, name, string(scopes)).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditTokenCreate, "", tokenAuditDetail(t.ID, t.Name, t.Scopes))
+
+	return c.JSON(http.StatusCreated, t)
+}
+
+// RevokeToken revokes one of the authenticated user's API tokens by its id
+func RevokeToken(c echo.Context) error {
+	// Params
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+
+	// Query
+	var revoked int
+	err = DB.Get(&revoked, "DELETE FROM tokens WHERE id=$1 AND username=$2 RETURNING id", id, authUsername(c))
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditTokenRevoke, "", strconv.Itoa(id))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// tokenAuditDetail describes an issued token in the audit log, never the token itself
+func tokenAuditDetail(id int, name string, scopes []string) string {
+	return strings.TrimSpace(fmt.Sprintf("%d %s %s", id, name, strings.Join(scopes, ",")))
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// transferOrder are the columns transfers are ordered and paged by
+var transferOrder = []helpers.PageKey{{Column: "created_at", Desc: true}, {Column: "name"}}
+
+// ReadTransfers returns the pending transfers offered to or by the
+// authenticated user, newest first
+func ReadTransfers(c echo.Context) error {
+	username := authUsername(c)
+	limit, after, err := pageParams(c, transferOrder, helpers.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	cond, args := pageAfter(transferOrder, after, []interface{}{username})
+	args = append(args, limit+1)
+
+	// Query
+	transfers := models.Transfers{Transfer: []models.Transfer{}}
+	query := fmt.Sprintf(`SELECT name, from_user, to_user, created_at FROM package_transfers
+						  WHERE (from_user=$1 OR to_user=$1) AND %s ORDER BY %s LIMIT $%d`,
+		cond, helpers.PageOrder(transferOrder), len(args))
+	if err = DB.Select(&transfers.Transfer, query, args...); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if len(transfers.Transfer) > limit {
+		transfers.Transfer = transfers.Transfer[:limit]
+		last := transfers.Transfer[limit-1]
+		transfers.Next = helpers.EncodePageToken(last.CreatedAt, last.Name)
+	}
+
+	return c.JSON(http.StatusOK, transfers)
+}
+
+// OfferTransfer offers a Package to another user, replacing any transfer
+// already pending. Nothing changes until they accept.
+func OfferTransfer(c echo.Context) error {
+	t := new(models.Transfer)
+	if err := c.Bind(t); err != nil {
+		return err
+	}
+	// Params
+	t.Name = packageParam(c)
+	t.From = authUsername(c)
+
+	if !helpers.ValidPackageName(t.Name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requirePublishedOwner(t.Name, t.From); err != nil {
+		return err
+	}
+	if t.To == t.From {
+		return echo.NewHTTPError(http.StatusBadRequest, "Packages can't be transferred to yourself")
+	}
+
+	// Query
+	var exists bool
+	if err := DB.Get(&exists, "SELECT EXISTS(SELECT 1 FROM users WHERE username=$1)", t.To); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("User %s not found", t.To))
+	}
+	_, err := DB.Exec(`INSERT INTO package_transfers(name, from_user, to_user) VALUES($1, $2, $3)
+					   ON CONFLICT (name) DO UPDATE SET from_user=$2, to_user=$3, created_at=`+Dialect.Now(), t.Name, t.From, t.To)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditTransferOffer, t.Name, t.To)
+	notifyTransfer(t)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CancelTransfer withdraws a pending transfer, it can be cancelled by an owner
+// of the Package or declined by the user it was offered to
+func CancelTransfer(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	username := authUsername(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+
+	// Query
+	var to string
+	err := DB.Get(&to, "SELECT to_user FROM package_transfers WHERE name=$1", name)
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Package %s has no pending transfer", name))
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if to != username {
+		if err = requireOwner(name, username); err != nil {
+			return err
+		}
+	}
+	if _, err = DB.Exec("DELETE FROM package_transfers WHERE name=$1", name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditTransferCancel, name, to)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AcceptTransfer makes the user a pending transfer was offered to the only
+// owner of the Package
+func AcceptTransfer(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	username := authUsername(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+
+	// Query
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	defer tx.Rollback()
+	var to string
+	err = tx.QueryRow("SELECT to_user FROM package_transfers WHERE name=$1"+Dialect.ForUpdate(), name).Scan(&to)
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Package %s has no pending transfer", name))
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if to != username {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Package %s wasn't offered to %s", name, username))
+	}
+	if _, err = tx.Exec("DELETE FROM package_owners WHERE name=$1", name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if _, err = tx.Exec("INSERT INTO package_owners(name, username) VALUES($1, $2)", name, username); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if _, err = tx.Exec("UPDATE packages SET owner=$1 WHERE name=$2", username, name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if _, err = tx.Exec("DELETE FROM package_transfers WHERE name=$1", name); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	invalidateCache(name)
+	audit(c, helpers.AuditTransferAccept, name, username)
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// maxTriggerBody is the largest webhook body a trigger reads
+const maxTriggerBody = 1 << 20
+
+// triggerRow is a trigger as it's stored
+type triggerRow struct {
+	ID            int
+	Name          string
+	TokenHash     string `db:"token_hash"`
+	Tags          string
+	CreatedBy     *string `db:"created_by"`
+	CreatedAt     string  `db:"created_at"`
+	LastTag       *string `db:"last_tag"`
+	LastTriggered *string `db:"last_triggered"`
+}
+
+// ReadTriggers returns the triggers of a Package, oldest first, without their URLs
+func ReadTriggers(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query
+	var rows []triggerRow
+	err := DB.Select(&rows, "SELECT * FROM triggers WHERE name=$1 ORDER BY id", name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	triggers := models.Triggers{Trigger: []models.Trigger{}}
+	for _, row := range rows {
+		triggers.Trigger = append(triggers.Trigger, models.Trigger{
+			ID:            row.ID,
+			Tags:          row.Tags,
+			CreatedBy:     row.CreatedBy,
+			CreatedAt:     row.CreatedAt,
+			LastTag:       row.LastTag,
+			LastTriggered: row.LastTriggered,
+		})
+	}
+
+	return c.JSON(http.StatusOK, triggers)
+}
+
+// CreateTrigger adds a URL to a published Package that Docker Hub or GHCR
+// webhooks call when a tag of its image is pushed, the response holds the URL
+func CreateTrigger(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	t := new(models.Trigger)
+	if err := c.Bind(t); err != nil {
+		return err
+	}
+	if t.Tags == "" {
+		t.Tags = helpers.DefaultTriggerTags
+	}
+	if err := helpers.ValidTriggerTags(t.Tags); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+	// A trigger publishes without a two-factor code, which publishing needs of some
+	if err := requireNoPublishOTP(authUsername(c), "Triggers can't publish for you while two-factor authentication is on for publishing, publish with cr publish --otp"); err != nil {
+		return err
+	}
+	// A new version copies the latest, so there has to be one
+	if _, err := selectPackage(name, ""); err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Publish %s before adding a trigger to it", name))
+	} else if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	token, err := helpers.NewToken()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	username := authUsername(c)
+	t.CreatedBy = &username
+	err = DB.QueryRow(`INSERT INTO triggers(name, token_hash, tags, created_by) VALUES($1, $2, $3, $4)
+					   RETURNING id, created_at`, name, helpers.HashToken(token), t.Tags, username).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	t.URL = fmt.Sprintf("%s://%s/api/triggers/%s", c.Scheme(), c.Request().Host, token)
+
+	audit(c, helpers.AuditTriggerCreate, name, t.Tags)
+
+	return c.JSON(http.StatusCreated, t)
+}
+
+// DeleteTrigger removes one of a Package's triggers, its URL stops publishing
+func DeleteTrigger(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if !helpers.ValidPackageName(name) || err != nil {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err = requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query
+	var deleted int
+	err = DB.Get(&deleted, "DELETE FROM triggers WHERE id=$1 AND name=$2 RETURNING id", id, name)
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditTriggerDelete, name, strconv.Itoa(id))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PublishFromTrigger is called by Docker Hub or GHCR webhooks with a trigger's
+// secret in its URL, neither can sign their calls with it. A tag pushed to the
+// package's image that the trigger wants is published as a new version, copying
+// the latest, by the user who added the trigger. Pushes that aren't published
+// are answered with why.
+func PublishFromTrigger(c echo.Context) error {
+	// Params
+	token := c.Param("token")
+
+	// Query
+	var t triggerRow
+	err := DB.Get(&t, "SELECT * FROM triggers WHERE token_hash=$1", helpers.HashToken(token))
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request().Body, maxTriggerBody))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	push, err := helpers.ParseImagePush(c.Request().Header.Get(helpers.GitHubEventHeader), body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if push == nil {
+		return c.JSON(http.StatusOK, models.TriggerResult{Message: "Not a tag pushed to a container image, nothing published"})
+	}
+	if !helpers.TriggerWants(t.Tags, push.Tag) {
+		return c.JSON(http.StatusOK, models.TriggerResult{Message: fmt.Sprintf("Tag %s doesn't match %s, nothing published", push.Tag, t.Tags)})
+	}
+
+	// The trigger publishes for the user who added it, as long as they still can
+	if t.CreatedBy == nil {
+		return echo.NewHTTPError(http.StatusForbidden, "The user who added this trigger no longer exists, add it again")
+	}
+	username := *t.CreatedBy
+	suspended, reason, err := userSuspension(username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if suspended {
+		return suspendedError(username, reason)
+	}
+	// Two-factor authentication may have been turned on for publishing since
+	if err = requireNoPublishOTP(username, fmt.Sprintf("%s turned two-factor authentication on for publishing, triggers can't publish for them", username)); err != nil {
+		return err
+	}
+
+	p, err := selectPackage(t.Name, "")
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("%s has no version to copy", t.Name))
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !helpers.SameImage(p.Repository, push.Image) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s isn't the image of %s, %s is", push.Image, t.Name, p.Repository))
+	}
+	// Only the publisher can sign a version, the registry can't sign a copy
+	if p.Signature != nil {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("%s is signed, publish its versions with cr publish", t.Name))
+	}
+	var published bool
+	err = DB.Get(&published, "SELECT EXISTS(SELECT 1 FROM published_versions WHERE name=$1 AND version=$2)", t.Name, push.Tag)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if published {
+		return c.JSON(http.StatusOK, models.TriggerResult{Message: fmt.Sprintf("%s %s is already published", t.Name, push.Tag)})
+	}
+
+	org, err := packageOrg(t.Name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if org != "" {
+		p.Org = &org
+	}
+	p.Version, p.Owner, p.Pulls, p.Yanked = push.Tag, username, 0, false
+	if err = helpers.ValidPackage(&p); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Audited and sent to webhooks as published by the trigger's user
+	c.Set(usernameKey, username)
+	if err = publishPackage(c, &p); err != nil {
+		return err
+	}
+	if _, err = DB.Exec("UPDATE triggers SET last_tag=$1, last_triggered="+Dialect.Now()+" WHERE id=$2", push.Tag, t.ID); err != nil {
+		log.Error(err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// totpIssuer names the registry in authenticator apps
+const totpIssuer = "Crackle"
+
+// twoFactorRow is a user's two-factor authentication as it's stored
+type twoFactorRow struct {
+	Secret   sql.NullString `db:"totp_secret"`
+	Pending  sql.NullString `db:"totp_pending"`
+	LastStep int64          `db:"totp_last_step"`
+	Publish  bool           `db:"totp_publish"`
+}
+
+// userTwoFactor returns username's two-factor authentication, off for unknown users
+func userTwoFactor(username string) (*twoFactorRow, error) {
+	row := new(twoFactorRow)
+	err := DB.Get(row, "SELECT totp_secret, totp_pending, totp_last_step, totp_publish FROM users WHERE username=$1", username)
+	if err == sql.ErrNoRows {
+		return row, nil
+	}
+	return row, err
+}
+
+// useTOTP checks code is a fresh TOTP code of secret for username, recording
+// it as used so it can't be used again
+func useTOTP(username string, secret string, lastStep int64, code string) (bool, error) {
+	step, ok := helpers.VerifyTOTP(secret, code, time.Now(), lastStep)
+	if !ok {
+		return false, nil
+	}
+	// Two requests racing with the same code can't both use it
+	res, err := DB.Exec("UPDATE users SET totp_last_step=$1 WHERE username=$2 AND totp_last_step < $1", step, username)
+	if err != nil {
+		return false, err
+	}
+	updatedRows, _ := res.RowsAffected()
+	return updatedRows == 1, nil
+}
+
+// otpRequiredError returns the 401 error refusing a request without a fresh
+// TOTP code, clients tell it apart from a bad token by its OTPHeader
+func otpRequiredError(c echo.Context, message string) error {
+	c.Response().Header().Set(helpers.OTPHeader, helpers.OTPRequired)
+	return echo.NewHTTPError(http.StatusUnauthorized, message)
+}
+
+// requireOTP returns a 401 error unless two-factor authentication is off for
+// username or the request's OTPHeader holds a fresh code. publish only requires
+// it of users who turned it on for publishing.
+func requireOTP(c echo.Context, username string, publish bool) error {
+	tf, err := userTwoFactor(username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !tf.Secret.Valid || publish && !tf.Publish {
+		return nil
+	}
+	code := c.Request().Header.Get(helpers.OTPHeader)
+	if code == "" {
+		return otpRequiredError(c, "A two-factor code is required, pass one with --otp")
+	}
+	if err = requireUnlocked(c, username); err != nil {
+		return err
+	}
+	ok, err := useTOTP(username, tf.Secret.String, tf.LastStep, code)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !ok {
+		recordAuthFailure(username)
+		return otpRequiredError(c, "The two-factor code is wrong or was already used, wait for the next one")
+	}
+	resetAuthFailures(username)
+	return nil
+}
+
+// requireNoPublishOTP returns a 403 error when username turned two-factor
+// authentication on for publishing, so nothing publishes for them without a
+// code, such as a trigger
+func requireNoPublishOTP(username string, message string) error {
+	tf, err := userTwoFactor(username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if tf.Secret.Valid && tf.Publish {
+		return echo.NewHTTPError(http.StatusForbidden, message)
+	}
+	return nil
+}
+
+// RequirePublishOTP is middleware rejecting publishes and yanks without a
+// fresh TOTP code by users who turned two-factor authentication on for them,
+// it follows RequireAuth
+func RequirePublishOTP(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := requireOTP(c, authUsername(c), true); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+// StartTwoFactor creates a TOTP secret for the authenticated user to add to an
+// authenticator app, two-factor authentication is only on once EnableTwoFactor
+// is sent a code of it
+func StartTwoFactor(c echo.Context) error {
+	username := authUsername(c)
+	tf, err := userTwoFactor(username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if tf.Secret.Valid {
+		return echo.NewHTTPError(http.StatusConflict, "Two-factor authentication is already on")
+	}
+
+	secret, err := helpers.NewTOTPSecret()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if _, err = DB.Exec("UPDATE users SET totp_pending=$1 WHERE username=$2", secret, username); err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusCreated, models.TwoFactorSetup{Secret: secret, URI: helpers.TOTPURI(totpIssuer, username, secret)})
+}
+
+// EnableTwoFactor turns two-factor authentication on with a code of the secret
+// StartTwoFactor created, once it's on a code of it changes whether publishing
+// and yanking need a fresh code
+func EnableTwoFactor(c echo.Context) error {
+	r := new(models.TwoFactorRequest)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	username := authUsername(c)
+	tf, err := userTwoFactor(username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	secret := tf.Secret
+	if !secret.Valid {
+		secret = tf.Pending
+	}
+	if !secret.Valid {
+		return echo.NewHTTPError(http.StatusBadRequest, "Set up two-factor authentication with `cr account 2fa enable` first")
+	}
+
+	if err = requireUnlocked(c, username); err != nil {
+		return err
+	}
+	ok, err := useTOTP(username, secret.String, tf.LastStep, r.Code)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !ok {
+		recordAuthFailure(username)
+		return echo.NewHTTPError(http.StatusBadRequest, "The two-factor code is wrong or was already used, wait for the next one")
+	}
+	resetAuthFailures(username)
+	_, err = DB.Exec("UPDATE users SET totp_secret=$1, totp_pending=NULL, totp_publish=$2 WHERE username=$3", secret.String, r.Publish, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	detail := "login"
+	if r.Publish {
+		detail = "login, publish"
+	}
+	audit(c, helpers.AuditTwoFactorEnable, "", detail)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DisableTwoFactor turns the authenticated user's two-factor authentication
+// off, which needs a fresh code
+func DisableTwoFactor(c echo.Context) error {
+	username := authUsername(c)
+	tf, err := userTwoFactor(username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !tf.Secret.Valid {
+		return echo.NewHTTPError(http.StatusNotFound, "Two-factor authentication is already off")
+	}
+	if err = requireOTP(c, username, false); err != nil {
+		return err
+	}
+
+	_, err = DB.Exec(`UPDATE users SET totp_secret=NULL, totp_pending=NULL, totp_last_step=0, totp_publish=false
+					  WHERE username=$1`, username)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	audit(c, helpers.AuditTwoFactorDisable, "", "")
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sunshinekitty/cr/handlers"
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/server"
+)
+
+func TestRequirePublishOTP(t *testing.T) {
+	e := server.New()
+	token := newUser(t, "two-factor", helpers.DefaultTokenScopes...)
+	secret, err := helpers.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = handlers.DB.Exec("UPDATE users SET totp_secret=$1, totp_publish=true WHERE username='two-factor'", secret); err != nil {
+		t.Fatal(err)
+	}
+	body := `{"Name": "otp", "Version": "1.0.0", "Repository": "crackle/otp"}`
+
+	rec := request(e, "POST", "/api/package/", token, body)
+	if rec.Code != 401 || rec.Header().Get(helpers.OTPHeader) != helpers.OTPRequired {
+		t.Errorf("Publishing without a code should ask for one, got %d %s", rec.Code, rec.Body)
+	}
+	code, err := helpers.TOTPCode(secret, helpers.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec = request(e, "POST", "/api/package/", token, body, helpers.OTPHeader, code); rec.Code != 201 {
+		t.Fatalf("Publishing with a fresh code should work, got %d %s", rec.Code, rec.Body)
+	}
+	body = `{"Name": "otp", "Version": "1.0.1", "Repository": "crackle/otp"}`
+	rec = request(e, "POST", "/api/package/", token, body, helpers.OTPHeader, code)
+	if rec.Code != 401 || rec.Header().Get(helpers.OTPHeader) != helpers.OTPRequired {
+		t.Errorf("A code shouldn't publish twice, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// webhookClient makes calls to webhooks, a slow receiver can't hold one up for
+// long. It only connects to public addresses, checked when connecting so a
+// host resolving to another address since the webhook was created can't get
+// around it, and doesn't follow redirects.
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{DialContext: (&net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: publicAddressOnly,
+	}).DialContext},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// webhookRow is a webhook as it's stored
+type webhookRow struct {
+	ID           int
+	Name         string
+	URL          string
+	Secret       string
+	Events       types.JSONText
+	CreatedBy    *string `db:"created_by"`
+	CreatedAt    string  `db:"created_at"`
+	LastStatus   *int    `db:"last_status"`
+	LastDelivery *string `db:"last_delivery"`
+}
+
+// ReadWebhooks returns the webhooks of a Package, oldest first, without their secrets
+func ReadWebhooks(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err := requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query
+	var rows []webhookRow
+	err := DB.Select(&rows, "SELECT * FROM webhooks WHERE name=$1 ORDER BY id", name)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	webhooks := models.Webhooks{Webhook: []models.Webhook{}}
+	for _, row := range rows {
+		w := models.Webhook{
+			ID:           row.ID,
+			URL:          row.URL,
+			CreatedBy:    row.CreatedBy,
+			CreatedAt:    row.CreatedAt,
+			LastStatus:   row.LastStatus,
+			LastDelivery: row.LastDelivery,
+		}
+		if err = row.Events.Unmarshal(&w.Events); err != nil {
+			log.Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		webhooks.Webhook = append(webhooks.Webhook, w)
+	}
+
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook registers a URL to call when a Package changes, the response
+// holds the secret calls are signed with
+func CreateWebhook(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+
+	if !helpers.ValidPackageName(name) {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	w := new(models.Webhook)
+	if err := c.Bind(w); err != nil {
+		return err
+	}
+	if err := helpers.ValidWebhook(w.URL, w.Events); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := helpers.ValidWebhookHost(c.Request().Context(), w.URL); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	secret, err := helpers.NewToken()
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if w.Events == nil {
+		w.Events = []string{}
+	}
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	username := authUsername(c)
+	w.Secret, w.CreatedBy = secret, &username
+	err = DB.QueryRow(`INSERT INTO webhooks(name, url, secret, events, created_by) VALUES($1, $2, $3, $4, $5)
+					   RETURNING id, created_at`, name, w.URL, secret, string(events), username).Scan(&w.ID, &w.CreatedAt)
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditWebhookCreate, name, w.URL)
+
+	return c.JSON(http.StatusCreated, w)
+}
+
+// DeleteWebhook stops calling one of a Package's webhooks
+func DeleteWebhook(c echo.Context) error {
+	// Params
+	name := packageParam(c)
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if !helpers.ValidPackageName(name) || err != nil {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err = requireOwner(name, authUsername(c)); err != nil {
+		return err
+	}
+
+	// Query
+	var deleted int
+	err = DB.Get(&deleted, "DELETE FROM webhooks WHERE id=$1 AND name=$2 RETURNING id", id, name)
+	if err == sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	if err != nil {
+		log.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	audit(c, helpers.AuditWebhookDelete, name, strconv.Itoa(id))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// sendWebhooks calls every webhook of a package subscribed to an event, it's
+// run in the background so the request that caused the event isn't held up
+func sendWebhooks(c echo.Context, event string, name string, version string, message *string) {
+	e := models.WebhookEvent{
+		Event:     event,
+		Package:   name,
+		Version:   version,
+		Message:   message,
+		Sender:    authUsername(c),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	go func() {
+		var rows []webhookRow
+		if err := DB.Select(&rows, "SELECT * FROM webhooks WHERE name=$1", e.Package); err != nil {
+			log.Error(err)
+			return
+		}
+		body, err := json.Marshal(e)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		for _, row := range rows {
+			var events []string
+			if err = row.Events.Unmarshal(&events); err != nil {
+				log.Error(err)
+				continue
+			}
+			if helpers.WebhookWants(events, e.Event) {
+				go deliverWebhook(row, e.Event, body)
+			}
+		}
+	}()
+}
+
+// deliverWebhook calls a webhook with a signed body, recording the status it
+// answered with or 0 when it couldn't be reached
+func deliverWebhook(w webhookRow, event string, body []byte) {
+	status := 0
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "Crackle-Webhook")
+		req.Header.Set(helpers.WebhookEventHeader, event)
+		req.Header.Set(helpers.WebhookSignatureHeader, helpers.SignWebhook(w.Secret, body))
+		var resp *http.Response
+		if resp, err = webhookClient.Do(req); err == nil {
+			status = resp.StatusCode
+			resp.Body.Close()
+		}
+	}
+	if err != nil {
+		log.Warnf("webhook %d to %s failed: %s", w.ID, w.URL, err)
+	}
+	if _, err = DB.Exec("UPDATE webhooks SET last_status=$1, last_delivery="+Dialect.Now()+" WHERE id=$2", status, w.ID); err != nil {
+		log.Error(err)
+	}
+}
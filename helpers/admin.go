@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrEmptyModerationReason is thrown when an admin suspends a user or removes
+	// a package without saying why
+	ErrEmptyModerationReason = errors.New("A reason is required")
+	// ErrLongModerationReason is thrown when the reason for a moderation is too long
+	ErrLongModerationReason = errors.New("reason is too long (>200 chars)")
+)
+
+// ValidModerationReason validates why an admin suspended a user, removed a
+// package or reserved a name, required unless optional
+func ValidModerationReason(reason string, optional bool) error {
+	if strings.TrimSpace(reason) == "" {
+		if optional {
+			return nil
+		}
+		return ErrEmptyModerationReason
+	}
+	return validText("reason", &reason, 200, ErrLongModerationReason)
+}
@@ -0,0 +1,21 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidModerationReason(t *testing.T) {
+	if err := ValidModerationReason("Ships a cryptominer", false); err != nil {
+		t.Errorf("Reason should be valid, got %s", err)
+	}
+	if err := ValidModerationReason(" ", false); err != ErrEmptyModerationReason {
+		t.Errorf("Blank reason should be required, got %v", err)
+	}
+	if err := ValidModerationReason("", true); err != nil {
+		t.Errorf("Optional reason can be empty, got %s", err)
+	}
+	if err := ValidModerationReason(strings.Repeat("a", 201), true); err != ErrLongModerationReason {
+		t.Errorf("Long reason should be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// AliasesKey is the client config table aliases are kept in
+const AliasesKey = "aliases"
+
+// ErrInvalidAlias is thrown when an alias isn't given as name=package or
+// either side isn't a valid package name
+var ErrInvalidAlias = errors.New("alias is invalid")
+
+// Alias represents another name a package can be run by
+type Alias struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+}
+
+// ParseAlias parses an alias given as name=package
+func ParseAlias(s string) (*Alias, error) {
+	i := strings.Index(s, "=")
+	if i == -1 {
+		ErrInvalidAlias = fmt.Errorf("alias \"%s\" should be given as name=package", s)
+		return nil, ErrInvalidAlias
+	}
+	a := &Alias{Name: s[:i], Package: s[i+1:]}
+	if err := ValidAlias(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ValidAlias checks both an alias's name and package are valid package names,
+// and that it doesn't point at itself
+func ValidAlias(a *Alias) error {
+	switch {
+	case !ValidShortPackageName(a.Name):
+		ErrInvalidAlias = fmt.Errorf("alias name \"%s\" is invalid, it follows the rules of package names", a.Name)
+		return ErrInvalidAlias
+	case !ValidPackageName(a.Package):
+		ErrInvalidAlias = fmt.Errorf("package \"%s\" of alias %s is invalid", a.Package, a.Name)
+		return ErrInvalidAlias
+	case a.Name == a.Package:
+		ErrInvalidAlias = fmt.Errorf("alias %s can't point at itself", a.Name)
+		return ErrInvalidAlias
+	}
+	return nil
+}
+
+// Aliases returns the aliases in the client config sorted by name
+func Aliases() []Alias {
+	var aliases []Alias
+	for name, pkg := range viper.GetStringMapString(AliasesKey) {
+		aliases = append(aliases, Alias{Name: name, Package: pkg})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases
+}
+
+// ResolveAlias returns the package an alias points at, names that aren't an
+// alias are returned as they are. Aliases of aliases aren't followed.
+func ResolveAlias(name string) string {
+	if pkg := viper.GetStringMapString(AliasesKey)[name]; pkg != "" {
+		return pkg
+	}
+	return name
+}
+
+// SetAlias writes an alias to the client config, an empty package removes it
+func SetAlias(name string, pkg string) error {
+	if pkg == "" {
+		return SetClientConfig(AliasesKey+"."+name, nil)
+	}
+	return SetClientConfig(AliasesKey+"."+name, pkg)
+}
@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseAlias(t *testing.T) {
+	a, err := ParseAlias("pg=postgres-13")
+	if err != nil || a.Name != "pg" || a.Package != "postgres-13" {
+		t.Errorf("Alias pg=postgres-13 should parse, got %+v %v", a, err)
+	}
+	for _, s := range []string{"pg", "pg=", "=postgres", "PG=postgres", "pg=pg"} {
+		if _, err := ParseAlias(s); err == nil {
+			t.Errorf("Alias %q should be invalid", s)
+		}
+	}
+}
+
+func TestResolveAlias(t *testing.T) {
+	viper.Set(AliasesKey, map[string]interface{}{"pg": "postgres-13"})
+	defer viper.Set(AliasesKey, nil)
+
+	if pkg := ResolveAlias("pg"); pkg != "postgres-13" {
+		t.Error("Alias pg should resolve to postgres-13, got", pkg)
+	}
+	if pkg := ResolveAlias("redis"); pkg != "redis" {
+		t.Error("Names that aren't an alias should be returned as they are, got", pkg)
+	}
+	if aliases := Aliases(); len(aliases) != 1 || aliases[0].Name != "pg" {
+		t.Errorf("Aliases should be listed, got %+v", aliases)
+	}
+}
@@ -0,0 +1,121 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// AuditPublish is recorded when a version of a package is published
+	AuditPublish = "publish"
+	// AuditDelete is recorded when a package is deleted
+	AuditDelete = "delete"
+	// AuditYank is recorded when a version of a package is yanked
+	AuditYank = "yank"
+	// AuditUnyank is recorded when a yanked version of a package is restored
+	AuditUnyank = "unyank"
+	// AuditDeprecate is recorded when a package is deprecated
+	AuditDeprecate = "deprecate"
+	// AuditUndeprecate is recorded when a package's deprecation is undone
+	AuditUndeprecate = "undeprecate"
+	// AuditOwnerAdd is recorded when a user is made an owner of a package
+	AuditOwnerAdd = "owner.add"
+	// AuditOwnerRemove is recorded when an owner of a package is removed
+	AuditOwnerRemove = "owner.remove"
+	// AuditTransferOffer is recorded when a package is offered to another user
+	AuditTransferOffer = "transfer.offer"
+	// AuditTransferCancel is recorded when an offered transfer is cancelled
+	AuditTransferCancel = "transfer.cancel"
+	// AuditTransferAccept is recorded when a user accepts a package offered to them
+	AuditTransferAccept = "transfer.accept"
+	// AuditWebhookCreate is recorded when a webhook is added to a package
+	AuditWebhookCreate = "webhook.create"
+	// AuditWebhookDelete is recorded when a webhook is removed from a package
+	AuditWebhookDelete = "webhook.delete"
+	// AuditTriggerCreate is recorded when a trigger is added to a package
+	AuditTriggerCreate = "trigger.create"
+	// AuditTriggerDelete is recorded when a trigger is removed from a package
+	AuditTriggerDelete = "trigger.delete"
+	// AuditTokenCreate is recorded when an API token is issued, by logging in
+	// or cr token create
+	AuditTokenCreate = "token.create"
+	// AuditTokenRevoke is recorded when an API token is revoked
+	AuditTokenRevoke = "token.revoke"
+	// AuditOrgCreate is recorded when an org is created
+	AuditOrgCreate = "org.create"
+	// AuditOrgMemberSet is recorded when a user joins an org or their role changes
+	AuditOrgMemberSet = "org.member.set"
+	// AuditOrgMemberRemove is recorded when a user leaves an org
+	AuditOrgMemberRemove = "org.member.remove"
+	// AuditUserSuspend is recorded when an admin suspends a user
+	AuditUserSuspend = "user.suspend"
+	// AuditUserUnsuspend is recorded when an admin lifts a user's suspension
+	AuditUserUnsuspend = "user.unsuspend"
+	// AuditPackageRemove is recorded when an admin removes a package
+	AuditPackageRemove = "package.remove"
+	// AuditPackageReport is recorded when a user reports a package to the
+	// registry's admins, it's only in the registry's log so owners can't see who
+	AuditPackageReport = "package.report"
+	// AuditReportResolve is recorded when an admin dismisses or reopens a report
+	AuditReportResolve = "report.resolve"
+	// AuditCategorize is recorded when a package's categories are changed
+	AuditCategorize = "categorize"
+	// AuditCategorySet is recorded when an admin adds or changes a category
+	AuditCategorySet = "category.set"
+	// AuditCategoryRemove is recorded when an admin removes a category
+	AuditCategoryRemove = "category.remove"
+	// AuditCollectionSet is recorded when an admin adds or changes a collection
+	AuditCollectionSet = "collection.set"
+	// AuditCollectionRemove is recorded when an admin removes a collection
+	AuditCollectionRemove = "collection.remove"
+	// AuditNameReserve is recorded when an admin reserves a package name
+	AuditNameReserve = "name.reserve"
+	// AuditNameUnreserve is recorded when an admin frees a reserved package name
+	AuditNameUnreserve = "name.unreserve"
+	// AuditEmailVerify is recorded when a user verifies their email address
+	AuditEmailVerify = "email.verify"
+	// AuditTwoFactorEnable is recorded when a user turns two-factor
+	// authentication on or changes what it's required for
+	AuditTwoFactorEnable = "2fa.enable"
+	// AuditTwoFactorDisable is recorded when a user turns two-factor authentication off
+	AuditTwoFactorDisable = "2fa.disable"
+	// AuditSigningKeySet is recorded when a user registers the key they sign packages with
+	AuditSigningKeySet = "signing_key.set"
+	// AuditSigningKeyRemove is recorded when a user removes their signing key
+	AuditSigningKeyRemove = "signing_key.remove"
+)
+
+// AuditActions are every action the audit log records
+var AuditActions = []string{
+	AuditPublish, AuditDelete, AuditYank, AuditUnyank, AuditDeprecate, AuditUndeprecate,
+	AuditOwnerAdd, AuditOwnerRemove, AuditTransferOffer, AuditTransferCancel, AuditTransferAccept,
+	AuditWebhookCreate, AuditWebhookDelete, AuditTriggerCreate, AuditTriggerDelete, AuditTokenCreate, AuditTokenRevoke,
+	AuditOrgCreate, AuditOrgMemberSet, AuditOrgMemberRemove,
+	AuditUserSuspend, AuditUserUnsuspend, AuditPackageRemove, AuditNameReserve, AuditNameUnreserve,
+	AuditPackageReport, AuditReportResolve,
+	AuditCategorize, AuditCategorySet, AuditCategoryRemove, AuditCollectionSet, AuditCollectionRemove,
+	AuditEmailVerify, AuditTwoFactorEnable, AuditTwoFactorDisable, AuditSigningKeySet, AuditSigningKeyRemove,
+}
+
+// ValidAuditAction validates an action to filter the audit log by
+func ValidAuditAction(action string) error {
+	for _, a := range AuditActions {
+		if a == action {
+			return nil
+		}
+	}
+	return fmt.Errorf("Action \"%s\" is invalid, use one of %s", action, strings.Join(AuditActions, ", "))
+}
+
+// RegistryAdmin returns true when a user is listed under admins in
+// server.toml, admins can read the whole audit log and moderate the registry
+func RegistryAdmin(username string) bool {
+	for _, admin := range viper.GetStringSlice("admins") {
+		if username != "" && admin == username {
+			return true
+		}
+	}
+	return false
+}
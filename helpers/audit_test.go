@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidAuditAction(t *testing.T) {
+	for _, a := range []string{AuditPublish, AuditOwnerAdd, AuditTokenCreate} {
+		if err := ValidAuditAction(a); err != nil {
+			t.Errorf("Action %s should be valid, got %s", a, err)
+		}
+	}
+	for _, a := range []string{"", "pull", "owner"} {
+		if err := ValidAuditAction(a); err == nil {
+			t.Errorf("Action \"%s\" should be invalid", a)
+		}
+	}
+}
+
+func TestRegistryAdmin(t *testing.T) {
+	viper.Set("admins", []string{"alice"})
+	defer viper.Set("admins", nil)
+
+	if !RegistryAdmin("alice") {
+		t.Error("alice should be an admin")
+	}
+	if RegistryAdmin("bob") || RegistryAdmin("") {
+		t.Error("Only users listed under admins should be admins")
+	}
+}
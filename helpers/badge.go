@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strconv"
+)
+
+const (
+	// BadgeBlue is the color of version badges
+	BadgeBlue = "#007ec6"
+	// BadgeOrange is the color of version badges of prereleases
+	BadgeOrange = "#fe7d37"
+	// BadgeGreen is the color of pull badges
+	BadgeGreen = "#4c1"
+	// BadgeRed is the color of badges of deprecated packages
+	BadgeRed = "#e05d44"
+	// BadgeGrey is the color of badges of packages that aren't found
+	BadgeGrey = "#9f9f9f"
+
+	// badgePadding is the space left and right of each half's text
+	badgePadding = 6
+)
+
+// Badge renders a shields style SVG badge with label on a grey left half and
+// message on a right half of color
+func Badge(label string, message string, color string) []byte {
+	lw, mw := badgeTextWidth(label)+2*badgePadding, badgeTextWidth(message)+2*badgePadding
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, lw+mw, label, message)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, message)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, lw+mw)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/>`, lw, lw, mw, color)
+	fmt.Fprintf(&b, `<rect width="%d" height="20" fill="url(#s)"/></g>`, lw+mw)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	for _, half := range []struct {
+		x    int
+		text string
+	}{{lw / 2, label}, {lw + mw/2, message}} {
+		fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%[1]d" y="14">%[2]s</text>`, half.x, half.text)
+	}
+	b.WriteString(`</g></svg>`)
+	return b.Bytes()
+}
+
+// badgeTextWidth estimates the width in pixels of text in 11px Verdana
+func badgeTextWidth(text string) int {
+	width := 0.0
+	for _, r := range text {
+		switch {
+		case r == 'i' || r == 'l' || r == 'j' || r == 'I' || r == '.' || r == ',' || r == ':' || r == ';' || r == '\'' || r == '|' || r == '!':
+			width += 3.5
+		case r == 'f' || r == 't' || r == 'r' || r == ' ' || r == '-' || r == '(' || r == ')':
+			width += 4.5
+		case r == 'm' || r == 'w' || r == 'M' || r == 'W':
+			width += 10.5
+		case r >= 'A' && r <= 'Z':
+			width += 7.5
+		default:
+			width += 7
+		}
+	}
+	return int(width + 0.5)
+}
+
+// BadgeCount formats a count for a badge, such as 999, 12.3k or 4M
+func BadgeCount(n int) string {
+	for _, unit := range []struct {
+		size   float64
+		suffix string
+	}{{1e9, "B"}, {1e6, "M"}, {1e3, "k"}} {
+		if f := float64(n); f >= unit.size {
+			f /= unit.size
+			if f >= 100 {
+				return strconv.Itoa(int(f)) + unit.suffix
+			}
+			// Cut to one decimal, without a trailing .0, so 999999 isn't 1000k
+			return strconv.FormatFloat(float64(int(f*10))/10, 'f', -1, 64) + unit.suffix
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+// VersionBadgeColor returns the color of a version's badge, prereleases such
+// as 2.0.0-rc.1 stand out
+func VersionBadgeColor(version string) string {
+	if v, ok := parseSemver(version); ok && v.pre != "" {
+		return BadgeOrange
+	}
+	return BadgeBlue
+}
@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBadge(t *testing.T) {
+	svg := string(Badge("crackle", "1.2.3", BadgeBlue))
+	for _, part := range []string{`<svg xmlns="http://www.w3.org/2000/svg"`, `aria-label="crackle: 1.2.3"`, `fill="#007ec6"`, `>1.2.3</text>`} {
+		if !strings.Contains(svg, part) {
+			t.Errorf("Badge should contain %s, got %s", part, svg)
+		}
+	}
+	if svg = string(Badge("pulls", "<script>", BadgeGreen)); strings.Contains(svg, "<script>") {
+		t.Errorf("Badge text should be escaped, got %s", svg)
+	}
+	if badgeTextWidth("mmmm") <= badgeTextWidth("iiii") {
+		t.Error("Wide letters should be wider than narrow ones")
+	}
+}
+
+func TestBadgeCount(t *testing.T) {
+	tests := map[int]string{
+		0:          "0",
+		999:        "999",
+		1000:       "1k",
+		12345:      "12.3k",
+		999999:     "999k",
+		4000000:    "4M",
+		1250000000: "1.2B",
+	}
+	for n, expected := range tests {
+		if count := BadgeCount(n); count != expected {
+			t.Errorf("Expected %d to be %s, got %s", n, expected, count)
+		}
+	}
+}
+
+func TestVersionBadgeColor(t *testing.T) {
+	if VersionBadgeColor("1.2.3") != BadgeBlue || VersionBadgeColor("latest") != BadgeBlue {
+		t.Error("Releases and tags should be blue")
+	}
+	if VersionBadgeColor("2.0.0-rc.1") != BadgeOrange {
+		t.Error("Prereleases should be orange")
+	}
+}
@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrInvalidBrowserURL is thrown when a URL to open isn't a http(s) URL
+var ErrInvalidBrowserURL = errors.New("only http(s) URLs can be opened")
+
+// PackagePageURL returns the page of a package on the Crackle website at web
+func PackagePageURL(web string, name string) string {
+	return fmt.Sprintf("%s/package/%s", strings.TrimRight(web, "/"), name)
+}
+
+// BrowserCmd returns the command and args that open url in the default browser
+func BrowserCmd(url string) (string, []string) {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
+
+// OpenURLContext opens url in the default browser, only http(s) URLs are opened
+// so a package can't point it at a local file or program
+func OpenURLContext(ctx context.Context, url string) error {
+	if !ValidURL(url) {
+		ErrInvalidBrowserURL = fmt.Errorf("\"%s\" can't be opened, only http(s) URLs can", url)
+		return ErrInvalidBrowserURL
+	}
+	name, args := BrowserCmd(url)
+	defer logCommand(name, args)()
+	return exec.CommandContext(ctx, name, args...).Run()
+}
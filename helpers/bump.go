@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// BumpMajor increments the first number of a version and resets the rest
+	BumpMajor = "major"
+	// BumpMinor increments the second number of a version and resets the patch
+	BumpMinor = "minor"
+	// BumpPatch increments the third number of a version
+	BumpPatch = "patch"
+)
+
+var bumpVersion = match(`^(v?)(\d+)\.(\d+)\.(\d+)$`)
+
+// ValidBumpPart returns true for a part of a version BumpVersion can increment
+func ValidBumpPart(part string) bool {
+	switch part {
+	case BumpMajor, BumpMinor, BumpPatch:
+		return true
+	}
+	return false
+}
+
+// BumpVersion increments the major, minor or patch number of a version such as
+// 1.2.3, a leading v is kept. Versions that aren't three dotted numbers can't be
+// bumped.
+func BumpVersion(version string, part string) (string, error) {
+	m := bumpVersion.FindStringSubmatch(version)
+	if m == nil {
+		return "", fmt.Errorf("Version \"%s\" can't be bumped, it should look like 1.2.3", version)
+	}
+	var n [3]int
+	for i := range n {
+		n[i], _ = strconv.Atoi(m[i+2])
+	}
+	switch part {
+	case BumpMajor:
+		n = [3]int{n[0] + 1, 0, 0}
+	case BumpMinor:
+		n = [3]int{n[0], n[1] + 1, 0}
+	case BumpPatch:
+		n[2]++
+	default:
+		return "", fmt.Errorf("Unknown version part \"%s\", use major, minor or patch", part)
+	}
+	return fmt.Sprintf("%s%d.%d.%d", m[1], n[0], n[1], n[2]), nil
+}
+
+// BumpPackageToml increments the version tagged on the repository of pt, the
+// bumped repository is returned and pt is left unchanged
+func BumpPackageToml(pt *models.PackageToml, part string) (string, error) {
+	ref, err := ParseReference(pt.Repository)
+	if err != nil {
+		return "", err
+	}
+	if ref.Digest != "" {
+		return "", ErrRepositoryDigest
+	}
+	if ref.Tag == "" {
+		return "", fmt.Errorf("Repository \"%s\" has no version tag to bump", pt.Repository)
+	}
+	if ref.Tag, err = BumpVersion(ref.Tag, part); err != nil {
+		return "", err
+	}
+	return ref.String(), nil
+}
+
+// BumpManifestFile rewrites the repository of the manifest at path with its
+// version bumped, returning the old and new versions. Only the repository value
+// is replaced so comments and formatting are kept, whatever the manifest format.
+func BumpManifestFile(path string, part string) (string, string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	pt, err := DecodePackageToml(bytes.NewReader(b), DetectFormat(path, b))
+	if err != nil {
+		return "", "", err
+	}
+	repository, err := BumpPackageToml(pt, part)
+	if err != nil {
+		return "", "", err
+	}
+	if n := bytes.Count(b, []byte(pt.Repository)); n != 1 {
+		return "", "", fmt.Errorf("Repository \"%s\" appears %d times in %s, bump it by hand", pt.Repository, n, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", err
+	}
+	b = bytes.Replace(b, []byte(pt.Repository), []byte(repository), 1)
+	if err = ioutil.WriteFile(path, b, info.Mode()); err != nil {
+		return "", "", err
+	}
+	from, _ := ParseReference(pt.Repository)
+	to, _ := ParseReference(repository)
+	return from.Tag, to.Tag, nil
+}
@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		version, part, expected string
+	}{
+		{"1.2.3", BumpPatch, "1.2.4"},
+		{"1.2.3", BumpMinor, "1.3.0"},
+		{"1.2.3", BumpMajor, "2.0.0"},
+		{"v0.9.9", BumpPatch, "v0.9.10"},
+	}
+	for _, test := range tests {
+		bumped, err := BumpVersion(test.version, test.part)
+		if err != nil {
+			t.Errorf("BumpVersion(%q, %q) returned error: %s", test.version, test.part, err)
+		} else if bumped != test.expected {
+			t.Errorf("BumpVersion(%q, %q) should be %q, got %q", test.version, test.part, test.expected, bumped)
+		}
+	}
+	for _, version := range []string{"latest", "1.2", "1.2.3-rc1"} {
+		if _, err := BumpVersion(version, BumpPatch); err == nil {
+			t.Errorf("BumpVersion(%q) should have failed", version)
+		}
+	}
+	if _, err := BumpVersion("1.2.3", "build"); err == nil {
+		t.Error("BumpVersion with part \"build\" should have failed")
+	}
+}
+
+func TestBumpManifestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cr-bump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cr.yaml")
+	manifest := "# keep me\npackage: testing\nrepository: \"myreg:5000/team/testing:1.4.2\"\n"
+	if err = ioutil.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	from, to, err := BumpManifestFile(path, BumpMinor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != "1.4.2" || to != "1.5.0" {
+		t.Errorf("Bump should go from 1.4.2 to 1.5.0, got %s to %s", from, to)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# keep me\npackage: testing\nrepository: \"myreg:5000/team/testing:1.5.0\"\n"
+	if string(b) != expected {
+		t.Errorf("Bumped manifest should be %q, got %q", expected, b)
+	}
+}
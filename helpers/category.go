@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// MaxPackageCategories is the most categories a package can be in
+	MaxPackageCategories = 3
+	// MaxCollectionPackages is the most packages a collection can list
+	MaxCollectionPackages = 100
+)
+
+var categorySlug = match(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+var (
+	// ErrInvalidCategory is thrown when a category slug isn't lower case letters,
+	// numbers and dashes
+	ErrInvalidCategory = errors.New("category is invalid")
+	// ErrInvalidCollection is thrown when a collection slug isn't lower case
+	// letters, numbers and dashes
+	ErrInvalidCollection = errors.New("collection is invalid")
+	// ErrEmptyCategoryTitle is thrown when a category or collection has no title
+	ErrEmptyCategoryTitle = errors.New("A title is required")
+	// ErrLongCategoryTitle is thrown when the title of a category or collection is too long
+	ErrLongCategoryTitle = errors.New("title is too long (>100 chars)")
+	// ErrLongCategoryDescription is thrown when the description of a category or collection is too long
+	ErrLongCategoryDescription = errors.New("description is too long (>200 chars)")
+)
+
+// ValidCategorySlug validates the slug of a category or collection, such as dev-tools
+func ValidCategorySlug(slug string) bool {
+	return categorySlug.MatchString(slug)
+}
+
+// ValidCategory validates a category of the registry's taxonomy
+func ValidCategory(c *models.Category) error {
+	if !ValidCategorySlug(c.Slug) {
+		return fmt.Errorf("%w: \"%s\", use lower case letters, numbers and dashes", ErrInvalidCategory, c.Slug)
+	}
+	return validTitled(c.Title, c.Description)
+}
+
+// ValidCollection validates a collection of packages, each can only be listed once
+func ValidCollection(c *models.Collection) error {
+	if !ValidCategorySlug(c.Slug) {
+		return fmt.Errorf("%w: \"%s\", use lower case letters, numbers and dashes", ErrInvalidCollection, c.Slug)
+	}
+	if err := validTitled(c.Title, c.Description); err != nil {
+		return err
+	}
+	if len(c.Packages) > MaxCollectionPackages {
+		return fmt.Errorf("Collection has %d packages, at most %d are allowed", len(c.Packages), MaxCollectionPackages)
+	}
+	seen := make(map[string]bool)
+	for _, name := range c.Packages {
+		if !ValidPackageName(name) {
+			return fmt.Errorf("Package \"%s\" is invalid", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("%s is listed more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// ValidPackageCategories validates the slugs of the categories a package is put
+// in, whether they exist is up to the registry
+func ValidPackageCategories(categories []string) error {
+	if len(categories) > MaxPackageCategories {
+		return fmt.Errorf("Package is in %d categories, at most %d are allowed", len(categories), MaxPackageCategories)
+	}
+	seen := make(map[string]bool)
+	for _, slug := range categories {
+		if !ValidCategorySlug(slug) {
+			return fmt.Errorf("%w: \"%s\", use lower case letters, numbers and dashes", ErrInvalidCategory, slug)
+		}
+		if seen[slug] {
+			return fmt.Errorf("%s is listed more than once", slug)
+		}
+		seen[slug] = true
+	}
+	return nil
+}
+
+// validTitled validates the title and description shared by categories and collections
+func validTitled(title string, description *string) error {
+	if strings.TrimSpace(title) == "" {
+		return ErrEmptyCategoryTitle
+	}
+	if err := validText("title", &title, 100, ErrLongCategoryTitle); err != nil {
+		return err
+	}
+	return validText("description", description, 200, ErrLongCategoryDescription)
+}
@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestValidCategory(t *testing.T) {
+	description := "Servers and clients of databases"
+	if err := ValidCategory(&models.Category{Slug: "databases", Title: "Databases", Description: &description}); err != nil {
+		t.Errorf("Category should be valid, got %s", err)
+	}
+	for _, slug := range []string{"", "Dev Tools", "-tools", "dev_tools", strings.Repeat("a", 51)} {
+		if err := ValidCategory(&models.Category{Slug: slug, Title: "Tools"}); !errors.Is(err, ErrInvalidCategory) {
+			t.Errorf("%s should be an invalid slug, got %v", slug, err)
+		}
+	}
+	if ErrInvalidCategory.Error() != "category is invalid" {
+		t.Error("Validating categories shouldn't change ErrInvalidCategory, got", ErrInvalidCategory)
+	}
+	if err := ValidCategory(&models.Category{Slug: "databases", Title: " "}); err != ErrEmptyCategoryTitle {
+		t.Errorf("A blank title should be ErrEmptyCategoryTitle, got %v", err)
+	}
+	long := strings.Repeat("a", 201)
+	if err := ValidCategory(&models.Category{Slug: "databases", Title: "Databases", Description: &long}); err != ErrLongCategoryDescription {
+		t.Errorf("A long description should be ErrLongCategoryDescription, got %v", err)
+	}
+}
+
+func TestValidCollection(t *testing.T) {
+	c := &models.Collection{Slug: "dev-tools", Title: "Dev tools", Packages: []string{"jq", "alice/tool"}}
+	if err := ValidCollection(c); err != nil {
+		t.Errorf("Collection should be valid, got %s", err)
+	}
+	if err := ValidCollection(&models.Collection{Slug: "Dev Tools", Title: "Dev tools"}); !errors.Is(err, ErrInvalidCollection) {
+		t.Error("An invalid slug should return ErrInvalidCollection, got", err)
+	}
+	c.Packages = []string{"jq", "jq"}
+	if err := ValidCollection(c); err == nil {
+		t.Error("A package listed twice should be invalid")
+	}
+	c.Packages = []string{"Not A Package"}
+	if err := ValidCollection(c); err == nil {
+		t.Error("An invalid package name should be invalid")
+	}
+	c.Packages = make([]string, MaxCollectionPackages+1)
+	if err := ValidCollection(c); err == nil {
+		t.Error("Too many packages should be invalid")
+	}
+}
+
+func TestValidPackageCategories(t *testing.T) {
+	if err := ValidPackageCategories([]string{"databases", "dev-tools"}); err != nil {
+		t.Errorf("Categories should be valid, got %s", err)
+	}
+	if err := ValidPackageCategories(nil); err != nil {
+		t.Errorf("No categories should be valid, got %s", err)
+	}
+	for _, categories := range [][]string{{"a", "b", "c", "d"}, {"databases", "databases"}, {"Databases"}} {
+		if err := ValidPackageCategories(categories); err == nil {
+			t.Errorf("%v should be invalid", categories)
+		}
+	}
+}
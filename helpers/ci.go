@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"os"
+	"strconv"
+	"sync"
+)
+
+// CIEnv is the environment variable turning on CI mode when set to a true
+// value such as 1
+const CIEnv = "CR_CI"
+
+var (
+	interactiveMu sync.Mutex
+	interactive   = true
+)
+
+// SetInteractive sets whether cr is used by a person at a terminal. When it
+// isn't, output has no colors, nothing is prompted for and containers aren't
+// given a terminal.
+func SetInteractive(enabled bool) {
+	interactiveMu.Lock()
+	defer interactiveMu.Unlock()
+	interactive = enabled
+}
+
+// Interactive returns true unless SetInteractive turned interactive use off
+func Interactive() bool {
+	interactiveMu.Lock()
+	defer interactiveMu.Unlock()
+	return interactive
+}
+
+// CIFromEnv returns true when CR_CI is set to a true value, anything that
+// doesn't parse as a bool leaves CI mode off
+func CIFromEnv() bool {
+	ci, err := strconv.ParseBool(os.Getenv(CIEnv))
+	return err == nil && ci
+}
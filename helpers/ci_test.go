@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestCIFromEnv(t *testing.T) {
+	defer os.Setenv(CIEnv, os.Getenv(CIEnv))
+	tests := map[string]bool{"1": true, "true": true, "0": false, "": false, "yes please": false}
+	for value, expected := range tests {
+		os.Setenv(CIEnv, value)
+		if ci := CIFromEnv(); ci != expected {
+			t.Errorf("CIFromEnv with %s=%q should be %t, got %t", CIEnv, value, expected, ci)
+		}
+	}
+}
+
+func TestPackageTomlToArgsNotInteractive(t *testing.T) {
+	defer SetInteractive(true)
+	pt := &models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing:latest"}
+	hasTTY := func() bool {
+		_, args := PackageTomlToArgs(pt)
+		for _, a := range args {
+			if a == "-t" {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasTTY() {
+		t.Error("Interactive runs should be given a terminal with -t")
+	}
+	SetInteractive(false)
+	if hasTTY() {
+		t.Error("Runs that aren't interactive shouldn't be given a terminal")
+	}
+}
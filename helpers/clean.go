@@ -0,0 +1,226 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// imageFormat is the docker images --format template parsed by ListImagesContext
+const imageFormat = `{{.ID}}\t{{.Repository}}\t{{.Tag}}\t{{.Digest}}\t{{.CreatedAt}}\t{{.Size}}`
+
+// dockerTimeLayout is how docker's --format templates print CreatedAt
+const dockerTimeLayout = "2006-01-02 15:04:05 -0700 MST"
+
+var (
+	// ErrInvalidSize is thrown when a size printed by docker can't be parsed
+	ErrInvalidSize = errors.New("size is invalid")
+	// ErrInvalidAge is thrown when an age such as 30d can't be parsed
+	ErrInvalidAge = errors.New("age is invalid")
+
+	// dockerSizeUnits are the decimal units docker prints sizes in
+	dockerSizeUnits = map[string]float64{"B": 1, "kB": 1e3, "KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12}
+	dockerSize      = match(`^([0-9.]+)\s*([kKMGT]?B)$`)
+)
+
+// Image represents a docker image
+type Image struct {
+	ID         string
+	Repository string
+	Tag        string
+	Digest     string
+	CreatedAt  time.Time
+	Size       int64
+}
+
+// Reference returns how an Image is removed, by tag so other tags of the same
+// image are left alone, or by ID when it isn't tagged
+func (i Image) Reference() string {
+	if i.Repository == "<none>" || i.Tag == "<none>" {
+		return i.ID
+	}
+	return i.Repository + ":" + i.Tag
+}
+
+// ListImagesContext lists the local images of a repository such as
+// sunshinekitty/testing, every tag and untagged image of it is listed
+func ListImagesContext(ctx context.Context, repository string) ([]Image, error) {
+	out, err := DockerOutputContext(ctx, "images", "--digests", "--format", imageFormat, repository)
+	if err != nil {
+		return nil, err
+	}
+	return parseImages(out), nil
+}
+
+// parseImages parses the output of docker images formatted with imageFormat
+func parseImages(out string) []Image {
+	var images []Image
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 {
+			continue
+		}
+		// Unparsable times and sizes are left zero rather than losing the image
+		createdAt, _ := parseDockerTime(fields[4])
+		size, _ := ParseDockerSize(fields[5])
+		images = append(images, Image{
+			ID:         fields[0],
+			Repository: fields[1],
+			Tag:        fields[2],
+			Digest:     fields[3],
+			CreatedAt:  createdAt,
+			Size:       size,
+		})
+	}
+	return images
+}
+
+// parseDockerTime parses a CreatedAt printed by docker's --format templates
+func parseDockerTime(s string) (time.Time, error) {
+	return time.Parse(dockerTimeLayout, strings.TrimSpace(s))
+}
+
+// ParseDockerSize parses a size such as 5.57MB as printed by docker, in bytes
+func ParseDockerSize(s string) (int64, error) {
+	m := dockerSize.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		ErrInvalidSize = fmt.Errorf("size \"%s\" is invalid", s)
+		return 0, ErrInvalidSize
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		ErrInvalidSize = fmt.Errorf("size \"%s\" is invalid", s)
+		return 0, ErrInvalidSize
+	}
+	return int64(n * dockerSizeUnits[m[2]]), nil
+}
+
+// FormatSize formats a number of bytes the way docker does, such as 5.57MB
+func FormatSize(n int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	size, i := float64(n), 0
+	for size >= 1000 && i < len(units)-1 {
+		size /= 1000
+		i++
+	}
+	s := strings.TrimRight(strings.TrimRight(strconv.FormatFloat(size, 'f', 2, 64), "0"), ".")
+	return s + units[i]
+}
+
+// ParseAge parses an age such as 12h, 30d or 2w, on top of time.ParseDuration
+// it understands days and weeks
+func ParseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n := strings.TrimSuffix(s, suffix); n != s {
+			days, err := strconv.Atoi(n)
+			if err != nil || days < 0 {
+				ErrInvalidAge = fmt.Errorf("age \"%s\" is invalid, use a duration such as 12h, 30d or 2w", s)
+				return 0, ErrInvalidAge
+			}
+			return time.Duration(days) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		ErrInvalidAge = fmt.Errorf("age \"%s\" is invalid, use a duration such as 12h, 30d or 2w", s)
+		return 0, ErrInvalidAge
+	}
+	return d, nil
+}
+
+// NormalizeImage returns the reference docker lists an image under, Docker Hub
+// images lose their registry and library/ prefix and images without a tag or
+// digest are tagged latest
+func NormalizeImage(image string) string {
+	r, err := ParseReference(image)
+	if err != nil {
+		return image
+	}
+	if r.Registry == "docker.io" || r.Registry == "index.docker.io" {
+		r.Registry = ""
+	}
+	if r.Registry == "" {
+		r.Repository = strings.TrimPrefix(r.Repository, "library/")
+	}
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = "latest"
+	}
+	return r.String()
+}
+
+// ImageRepository returns the repository docker lists an image under, without
+// its tag or digest
+func ImageRepository(image string) string {
+	r, err := ParseReference(NormalizeImage(image))
+	if err != nil {
+		return image
+	}
+	return r.Name()
+}
+
+// CleanPlan represents the containers and images cr clean removes
+type CleanPlan struct {
+	Containers []Container
+	Images     []Image
+}
+
+// Size returns the most space removing a CleanPlan's images frees, images
+// sharing layers free less
+func (p *CleanPlan) Size() int64 {
+	var size int64
+	for _, i := range p.Images {
+		size += i.Size
+	}
+	return size
+}
+
+// PlanClean picks the stopped containers and images cr clean removes. Images
+// used by running containers are always kept, images in installed are kept
+// unless all is set. Only containers and images created before are picked.
+func PlanClean(stopped []Container, images []Image, running []string, installed []string, all bool, before time.Time) *CleanPlan {
+	keep := make(map[string]bool)
+	keepImage := func(image string) {
+		keep[NormalizeImage(image)] = true
+		// docker lists images pulled by digest without their tag
+		if r, err := ParseReference(NormalizeImage(image)); err == nil && r.Digest != "" {
+			keep[r.Name()+"@"+r.Digest] = true
+		}
+	}
+	for _, image := range running {
+		keepImage(image)
+	}
+	if !all {
+		for _, image := range installed {
+			keepImage(image)
+		}
+	}
+
+	plan := &CleanPlan{}
+	for _, c := range stopped {
+		if c.CreatedAt.Before(before) {
+			plan.Containers = append(plan.Containers, c)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, i := range images {
+		tagged := i.Reference() != i.ID
+		if seen[i.Reference()] || !i.CreatedAt.Before(before) || keep[i.ID] {
+			continue
+		}
+		if tagged && keep[NormalizeImage(i.Reference())] {
+			continue
+		}
+		if i.Digest != "" && i.Digest != "<none>" && keep[i.Repository+"@"+i.Digest] {
+			continue
+		}
+		seen[i.Reference()] = true
+		plan.Images = append(plan.Images, i)
+	}
+	sort.Slice(plan.Images, func(a, b int) bool { return plan.Images[a].Reference() < plan.Images[b].Reference() })
+	return plan
+}
@@ -0,0 +1,134 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// digest is the digest images are pinned to in the clean tests
+const digest = "sha256:4a5b6c7d8e9f4a5b6c7d8e9f4a5b6c7d"
+
+func TestParseImages(t *testing.T) {
+	out := "4f2a\tsunshinekitty/testing\t1.0\t<none>\t2017-10-09 12:30:00 +0000 UTC\t5.57MB\n" +
+		"9c1b\t<none>\t<none>\tsha256:abc\t2017-10-01 09:30:00 +0000 UTC\t1.2GB\n"
+	expected := []Image{
+		{"4f2a", "sunshinekitty/testing", "1.0", "<none>", time.Date(2017, 10, 9, 12, 30, 0, 0, time.UTC), 5570000},
+		{"9c1b", "<none>", "<none>", "sha256:abc", time.Date(2017, 10, 1, 9, 30, 0, 0, time.UTC), 1200000000},
+	}
+	if images := parseImages(out); !reflect.DeepEqual(images, expected) {
+		t.Errorf("Images should be parsed, got %+v", images)
+	}
+	if expected[0].Reference() != "sunshinekitty/testing:1.0" || expected[1].Reference() != "9c1b" {
+		t.Error("Tagged images should be referenced by tag and untagged by ID")
+	}
+}
+
+func TestDockerSize(t *testing.T) {
+	tests := []struct {
+		size  string
+		bytes int64
+	}{
+		{"0B", 0},
+		{"512B", 512},
+		{"10kB", 10000},
+		{"5.57MB", 5570000},
+		{"1.2GB", 1200000000},
+	}
+	for _, test := range tests {
+		if n, err := ParseDockerSize(test.size); err != nil || n != test.bytes {
+			t.Errorf("Size %q should be %d bytes, got %d %v", test.size, test.bytes, n, err)
+		}
+		if s := FormatSize(test.bytes); s != test.size {
+			t.Errorf("%d bytes should format as %q, got %q", test.bytes, test.size, s)
+		}
+	}
+	if _, err := ParseDockerSize("big"); err == nil {
+		t.Error("Size \"big\" should be invalid")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"12h": 12 * time.Hour,
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+	}
+	for age, expected := range tests {
+		if d, err := ParseAge(age); err != nil || d != expected {
+			t.Errorf("Age %q should be %s, got %s %v", age, expected, d, err)
+		}
+	}
+	for _, age := range []string{"", "d", "-1d", "soon"} {
+		if _, err := ParseAge(age); err == nil {
+			t.Errorf("Age %q should be invalid", age)
+		}
+	}
+}
+
+func TestNormalizeImage(t *testing.T) {
+	tests := map[string]string{
+		"sunshinekitty/testing":           "sunshinekitty/testing:latest",
+		"docker.io/library/alpine:3.6":    "alpine:3.6",
+		"myreg:5000/team/img:1.0":         "myreg:5000/team/img:1.0",
+		"sunshinekitty/testing@" + digest: "sunshinekitty/testing@" + digest,
+	}
+	for image, expected := range tests {
+		if normalized := NormalizeImage(image); normalized != expected {
+			t.Errorf("Image %q should normalize to %q, got %q", image, expected, normalized)
+		}
+	}
+	if r := ImageRepository("docker.io/library/alpine:3.6"); r != "alpine" {
+		t.Error("Repository of docker.io/library/alpine:3.6 should be alpine, got", r)
+	}
+}
+
+func TestPlanClean(t *testing.T) {
+	now := time.Date(2017, 10, 9, 0, 0, 0, 0, time.UTC)
+	old, recent := now.Add(-48*time.Hour), now.Add(-time.Hour)
+	stopped := []Container{
+		{ID: "c1", Package: "testing", CreatedAt: old},
+		{ID: "c2", Package: "testing", CreatedAt: recent},
+	}
+	images := []Image{
+		{ID: "i1", Repository: "sunshinekitty/testing", Tag: "1.0", CreatedAt: old, Size: 100},
+		{ID: "i2", Repository: "sunshinekitty/testing", Tag: "2.0", CreatedAt: old, Size: 200},
+		{ID: "i3", Repository: "<none>", Tag: "<none>", CreatedAt: old, Size: 300},
+		{ID: "i4", Repository: "sunshinekitty/webserver", Tag: "latest", CreatedAt: old, Size: 400},
+		{ID: "i5", Repository: "sunshinekitty/pinned", Tag: "<none>", Digest: digest, CreatedAt: recent, Size: 500},
+	}
+	running := []string{"sunshinekitty/webserver"}
+	installed := []string{"sunshinekitty/testing:2.0", "sunshinekitty/pinned:1.0@" + digest}
+
+	plan := PlanClean(stopped, images, running, installed, false, now)
+	if len(plan.Containers) != 2 {
+		t.Errorf("Every stopped container should be removed, got %+v", plan.Containers)
+	}
+	if refs := imageReferences(plan.Images); !reflect.DeepEqual(refs, []string{"i3", "sunshinekitty/testing:1.0"}) {
+		t.Errorf("Only unused images should be removed, got %q", refs)
+	}
+	if plan.Size() != 400 {
+		t.Error("Plan should reclaim 400 bytes, got", plan.Size())
+	}
+
+	plan = PlanClean(stopped, images, running, installed, true, now)
+	if refs := imageReferences(plan.Images); !reflect.DeepEqual(refs, []string{"i3", "i5", "sunshinekitty/testing:1.0", "sunshinekitty/testing:2.0"}) {
+		t.Errorf("All should remove installed images but not running ones, got %q", refs)
+	}
+
+	plan = PlanClean(stopped, images, running, installed, true, now.Add(-24*time.Hour))
+	if len(plan.Containers) != 1 || plan.Containers[0].ID != "c1" {
+		t.Errorf("Only containers older than the age should be removed, got %+v", plan.Containers)
+	}
+	if refs := imageReferences(plan.Images); !reflect.DeepEqual(refs, []string{"i3", "sunshinekitty/testing:1.0", "sunshinekitty/testing:2.0"}) {
+		t.Errorf("Only images older than the age should be removed, got %q", refs)
+	}
+}
+
+func imageReferences(images []Image) []string {
+	var refs []string
+	for _, i := range images {
+		refs = append(refs, i.Reference())
+	}
+	return refs
+}
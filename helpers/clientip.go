@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxiesKey is the server.toml key of the proxies whose forwarding
+// headers are believed, IPs or CIDRs such as 10.0.0.0/8
+const TrustedProxiesKey = "trusted_proxies"
+
+// ClientIP returns the IP a request came from. That's the address it was
+// received from unless it's one of trustedProxies, whose X-Forwarded-For is
+// then followed back to the first address that isn't a trusted proxy, or their
+// X-Real-IP used when they don't send one. Anyone else can send those headers,
+// so they're never believed from other addresses.
+func ClientIP(r *http.Request, trustedProxies []string) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	trusted := parseTrustedProxies(trustedProxies)
+	if !proxyTrusted(trusted, ip) {
+		return ip
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				// What's before an address that isn't one can't be believed either
+				return ip
+			}
+			ip = hop
+			if !proxyTrusted(trusted, hop) {
+				return hop
+			}
+		}
+		return ip
+	}
+	if real := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(real) != nil {
+		return real
+	}
+	return ip
+}
+
+// ValidTrustedProxy validates a trusted proxy, an IP or a CIDR
+func ValidTrustedProxy(s string) bool {
+	return len(parseTrustedProxies([]string{s})) == 1
+}
+
+// parseTrustedProxies returns the networks of IPs and CIDRs, those that are
+// neither are left out
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, network, err := net.ParseCIDR(p); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// proxyTrusted reports whether ip is in one of the trusted networks
+func proxyTrusted(trusted []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
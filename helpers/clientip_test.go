@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies := []string{"10.0.0.0/8", "192.0.2.1"}
+	tests := []struct {
+		remote    string
+		forwarded string
+		real      string
+		ip        string
+	}{
+		// Headers from anyone but a trusted proxy are ignored
+		{"203.0.113.9:5000", "198.51.100.1", "198.51.100.2", "203.0.113.9"},
+		{"203.0.113.9:5000", "", "", "203.0.113.9"},
+		{"192.0.2.1:443", "198.51.100.1", "", "198.51.100.1"},
+		{"10.1.2.3:443", "", "198.51.100.2", "198.51.100.2"},
+		// A client can put anything first, only the trusted proxies' hops count
+		{"10.1.2.3:443", "1.1.1.1, 198.51.100.1, 10.0.0.5", "", "198.51.100.1"},
+		{"10.1.2.3:443", "10.0.0.6, 10.0.0.5", "", "10.0.0.6"},
+		{"10.1.2.3:443", "198.51.100.1, not-an-ip", "", "10.1.2.3"},
+		{"10.1.2.3:443", "", "not-an-ip", "10.1.2.3"},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remote
+		if test.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", test.forwarded)
+		}
+		if test.real != "" {
+			r.Header.Set("X-Real-IP", test.real)
+		}
+		if ip := ClientIP(r, proxies); ip != test.ip {
+			t.Errorf("%s forwarding %q and %q should be %s, got %s", test.remote, test.forwarded, test.real, test.ip, ip)
+		}
+	}
+
+	for _, proxy := range []string{"10.0.0.1", "10.0.0.0/8", "::1", "fd00::/8"} {
+		if !ValidTrustedProxy(proxy) {
+			t.Errorf("%s should be a valid trusted proxy", proxy)
+		}
+	}
+	for _, proxy := range []string{"", "proxy.example.com", "10.0.0.0/33"} {
+		if ValidTrustedProxy(proxy) {
+			t.Errorf("%s should be an invalid trusted proxy", proxy)
+		}
+	}
+}
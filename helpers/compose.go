@@ -0,0 +1,272 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// ErrComposeService is thrown when a compose file's service can't be picked or converted
+var ErrComposeService = errors.New("compose service is invalid")
+
+// ComposeVersion is the docker-compose file format version exported packages use
+const ComposeVersion = "3"
+
+// ComposeFile represents a docker-compose file
+type ComposeFile struct {
+	Version  string                    `yaml:"version"`
+	Services map[string]ComposeService `yaml:"services"`
+}
+
+// ComposeService represents a single service of a docker-compose file
+type ComposeService struct {
+	Image       string            `yaml:"image"`
+	Command     []string          `yaml:"command,omitempty"`
+	Ports       []string          `yaml:"ports,omitempty"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	TTY         bool              `yaml:"tty,omitempty"`
+}
+
+// PackageToCompose converts a Package to a docker-compose v3 file with a single
+// service named after the package, it runs the same as `cr run` would
+func PackageToCompose(p *models.Package) (*ComposeFile, error) {
+	pt, err := PackageToPackageToml(p)
+	if err != nil {
+		return nil, err
+	}
+
+	service := ComposeService{
+		Image: pt.Repository,
+		TTY:   true,
+	}
+	if pt.CommandStart != nil {
+		service.Command, err = ShellSplit(*pt.CommandStart)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, port := range pt.Ports {
+		service.Ports = append(service.Ports, fmt.Sprintf("%s:%s", port.Local, port.Container))
+	}
+	for _, volume := range pt.Volumes {
+		service.Volumes = append(service.Volumes, fmt.Sprintf("%s:%s", volume.Local, volume.Container))
+	}
+	if len(pt.Env) > 0 {
+		service.Environment = map[string]string(pt.Env)
+	}
+
+	return &ComposeFile{
+		Version:  ComposeVersion,
+		Services: map[string]ComposeService{p.Name: service},
+	}, nil
+}
+
+// EncodeCompose writes a docker-compose file as yaml to w
+func EncodeCompose(w io.Writer, c *ComposeFile) error {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// composeImport represents a docker-compose file being imported, fields that can
+// be written more than one way are decoded loosely and converted by hand
+type composeImport struct {
+	Services map[string]composeImportService `yaml:"services"`
+}
+
+// composeImportService represents a single service of a docker-compose file being imported
+type composeImportService struct {
+	Image       string        `yaml:"image"`
+	Command     interface{}   `yaml:"command"`
+	Ports       []interface{} `yaml:"ports"`
+	Volumes     []interface{} `yaml:"volumes"`
+	Environment interface{}   `yaml:"environment"`
+}
+
+// ComposeToPackageToml reads a docker-compose file from r and converts one of its
+// services to a PackageToml named after it. service may be empty when the file
+// only has one service. Environment variables without a value are passed through
+// from the host by compose, a manifest can't do that so they're left out.
+func ComposeToPackageToml(r io.Reader, service string) (*models.PackageToml, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var c composeImport
+	if err = yaml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	if service == "" {
+		if len(c.Services) != 1 {
+			names := make([]string, 0, len(c.Services))
+			for name := range c.Services {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			ErrComposeService = fmt.Errorf("Compose file has %d services (%v), pick one to import", len(c.Services), strings.Join(names, ", "))
+			return nil, ErrComposeService
+		}
+		for name := range c.Services {
+			service = name
+		}
+	}
+	s, ok := c.Services[service]
+	if !ok {
+		ErrComposeService = fmt.Errorf("Compose file has no service \"%v\"", service)
+		return nil, ErrComposeService
+	}
+	return composeServiceToPackageToml(service, s)
+}
+
+// composeServiceToPackageToml converts a single compose service to a PackageToml
+func composeServiceToPackageToml(name string, s composeImportService) (*models.PackageToml, error) {
+	if s.Image == "" {
+		ErrComposeService = fmt.Errorf("Compose service \"%v\" has no image, services that build one can't be imported", name)
+		return nil, ErrComposeService
+	}
+	pt := &models.PackageToml{
+		Package:    name,
+		Repository: s.Image,
+	}
+
+	switch command := s.Command.(type) {
+	case nil:
+	case string:
+		pt.CommandStart = &command
+	case []interface{}:
+		args := make([]string, len(command))
+		for i, a := range command {
+			args[i] = fmt.Sprint(a)
+		}
+		cmdStart := ShellJoin(args)
+		pt.CommandStart = &cmdStart
+	default:
+		ErrComposeService = fmt.Errorf("Compose service \"%v\" command is invalid", name)
+		return nil, ErrComposeService
+	}
+
+	for _, p := range s.Ports {
+		port, err := composePort(p)
+		if err != nil {
+			return nil, err
+		}
+		pt.Ports = append(pt.Ports, port)
+	}
+
+	for _, v := range s.Volumes {
+		volume, err := composeVolume(v)
+		if err != nil {
+			return nil, err
+		}
+		pt.Volumes = append(pt.Volumes, volume)
+	}
+
+	env, err := composeEnvironment(s.Environment)
+	if err != nil {
+		return nil, err
+	}
+	if len(env) > 0 {
+		pt.Env = env
+	}
+
+	return pt, nil
+}
+
+// composePort converts a compose port, either "[ip:]local:container[/protocol]",
+// a lone container port published on the same local port or the long syntax
+func composePort(p interface{}) (models.Port, error) {
+	switch port := p.(type) {
+	case int:
+		s := strconv.Itoa(port)
+		return models.Port{Local: s, Container: s}, nil
+	case string:
+		spec := port
+		if i := strings.Index(spec, "/"); i != -1 {
+			spec = spec[:i]
+		}
+		parts := strings.Split(spec, ":")
+		if len(parts) == 1 {
+			parts = append(parts, parts[0])
+		}
+		local, container := parts[len(parts)-2], parts[len(parts)-1]
+		if ValidPort(local) && ValidPort(container) {
+			return models.Port{Local: local, Container: container}, nil
+		}
+	case map[interface{}]interface{}:
+		target, published := fmt.Sprint(port["target"]), fmt.Sprint(port["published"])
+		if port["published"] == nil {
+			published = target
+		}
+		if ValidPort(published) && ValidPort(target) {
+			return models.Port{Local: published, Container: target}, nil
+		}
+	}
+	ErrInvalidPort = fmt.Errorf("Compose port \"%v\" is invalid, port ranges aren't supported", p)
+	return models.Port{}, ErrInvalidPort
+}
+
+// composeVolume converts a compose volume, either "local:container[:mode]" or the
+// long syntax. cr mounts are always read-write so the mode is dropped.
+func composeVolume(v interface{}) (models.Volume, error) {
+	switch volume := v.(type) {
+	case string:
+		spec := volume
+		drive := ""
+		if IsWindowsPath(spec) {
+			drive, spec = spec[:2], spec[2:]
+		}
+		if parts := strings.Split(spec, ":"); len(parts) == 3 {
+			spec = parts[0] + ":" + parts[1]
+		}
+		if vol, err := ParseVolume(drive + spec); err == nil {
+			return vol, nil
+		}
+	case map[interface{}]interface{}:
+		source, target := fmt.Sprint(volume["source"]), fmt.Sprint(volume["target"])
+		vol := models.Volume{Local: source, Container: target}
+		if volume["source"] != nil && volume["target"] != nil && ValidVolume(vol) {
+			return vol, nil
+		}
+	}
+	ErrInvalidVolume = fmt.Errorf("Compose volume \"%v\" is invalid, volumes need both a local and container path", v)
+	return models.Volume{}, ErrInvalidVolume
+}
+
+// composeEnvironment converts compose environment variables, either a list of
+// "KEY=value" or a map of keys to values
+func composeEnvironment(e interface{}) (models.Env, error) {
+	env := make(models.Env)
+	switch environment := e.(type) {
+	case nil:
+	case []interface{}:
+		for _, kv := range environment {
+			parts := strings.SplitN(fmt.Sprint(kv), "=", 2)
+			if len(parts) == 2 {
+				env[parts[0]] = parts[1]
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, v := range environment {
+			if v != nil {
+				env[fmt.Sprint(k)] = fmt.Sprint(v)
+			}
+		}
+	default:
+		ErrInvalidEnv = fmt.Errorf("Compose environment \"%v\" is invalid", e)
+		return nil, ErrInvalidEnv
+	}
+	return env, nil
+}
@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx/types"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestPackageToCompose(t *testing.T) {
+	cmdStart := "npm run 'dev server'"
+	ports := types.JSONText(`[{"local":"8080","container":"80"}]`)
+	volumes := types.JSONText(`[{"local":"/tmp","container":"/data"}]`)
+	env := types.JSONText(`{"DEBUG":"1"}`)
+	p := &models.Package{
+		CommandStart: &cmdStart,
+		Env:          &env,
+		Name:         "testing",
+		Ports:        &ports,
+		Repository:   "sunshinekitty/testing",
+		Version:      "1.0",
+		Volumes:      &volumes,
+	}
+
+	c, err := PackageToCompose(p)
+	if err != nil {
+		t.Fatal("Package should convert to compose, got", err)
+	}
+	if c.Version != ComposeVersion {
+		t.Errorf("Compose version should be %s, got %s", ComposeVersion, c.Version)
+	}
+	service, ok := c.Services["testing"]
+	if !ok || len(c.Services) != 1 {
+		t.Fatalf("Compose should have a single service named after the package, got %+v", c.Services)
+	}
+	expected := ComposeService{
+		Image:       "sunshinekitty/testing:1.0",
+		Command:     []string{"npm", "run", "dev server"},
+		Ports:       []string{"8080:80"},
+		Volumes:     []string{"/tmp:/data"},
+		Environment: map[string]string{"DEBUG": "1"},
+		TTY:         true,
+	}
+	if !reflect.DeepEqual(service, expected) {
+		t.Errorf("Compose service should be %+v, got %+v", expected, service)
+	}
+
+	bare, err := PackageToCompose(&models.Package{Name: "bare", Repository: "redis", Version: "latest"})
+	if err != nil {
+		t.Fatal("Package without ports, volumes or env should convert to compose, got", err)
+	}
+	if s := bare.Services["bare"]; s.Command != nil || s.Ports != nil || s.Volumes != nil || s.Environment != nil {
+		t.Errorf("Empty fields should be left out of the compose service, got %+v", s)
+	}
+}
+
+func TestComposeServiceToPackageToml(t *testing.T) {
+	s := composeImportService{
+		Image:   "nginx:1.13",
+		Command: []interface{}{"nginx", "-g", "daemon off;"},
+		Ports: []interface{}{
+			"8080:80",
+			"127.0.0.1:8443:443/tcp",
+			9000,
+			map[interface{}]interface{}{"target": 53, "published": 5353, "protocol": "udp"},
+		},
+		Volumes: []interface{}{
+			"./html:/usr/share/nginx/html:ro",
+			`C:\logs:/var/log/nginx`,
+			map[interface{}]interface{}{"type": "bind", "source": "/etc/nginx", "target": "/etc/nginx"},
+		},
+		Environment: []interface{}{"A=1", "B=x=y", "FROM_HOST"},
+	}
+
+	pt, err := composeServiceToPackageToml("web", s)
+	if err != nil {
+		t.Fatal("Compose service should convert, got", err)
+	}
+	if pt.Package != "web" || pt.Repository != "nginx:1.13" {
+		t.Errorf("Package should be named after the service and use its image, got %s %s", pt.Package, pt.Repository)
+	}
+	if pt.CommandStart == nil || *pt.CommandStart != "nginx -g 'daemon off;'" {
+		t.Errorf("Command should be joined in to command_start, got %v", pt.CommandStart)
+	}
+	expectedPorts := models.Ports{
+		{Local: "8080", Container: "80"},
+		{Local: "8443", Container: "443"},
+		{Local: "9000", Container: "9000"},
+		{Local: "5353", Container: "53"},
+	}
+	if !reflect.DeepEqual(pt.Ports, expectedPorts) {
+		t.Errorf("Ports should be %+v, got %+v", expectedPorts, pt.Ports)
+	}
+	expectedVolumes := models.Volumes{
+		{Local: "./html", Container: "/usr/share/nginx/html"},
+		{Local: `C:\logs`, Container: "/var/log/nginx"},
+		{Local: "/etc/nginx", Container: "/etc/nginx"},
+	}
+	if !reflect.DeepEqual(pt.Volumes, expectedVolumes) {
+		t.Errorf("Volumes should be %+v, got %+v", expectedVolumes, pt.Volumes)
+	}
+	if !reflect.DeepEqual(pt.Env, models.Env{"A": "1", "B": "x=y"}) {
+		t.Errorf("Env should leave out variables without a value, got %+v", pt.Env)
+	}
+
+	s.Environment = map[interface{}]interface{}{"PORT": 80, "DEBUG": true, "FROM_HOST": nil}
+	pt, err = composeServiceToPackageToml("web", s)
+	if err != nil {
+		t.Fatal("Compose service with a map environment should convert, got", err)
+	}
+	if !reflect.DeepEqual(pt.Env, models.Env{"PORT": "80", "DEBUG": "true"}) {
+		t.Errorf("Env map should be converted to strings, got %+v", pt.Env)
+	}
+
+	if _, err = composeServiceToPackageToml("web", composeImportService{}); err != ErrComposeService {
+		t.Error("Service without an image should return ErrComposeService, got", err)
+	}
+	if _, err = composeServiceToPackageToml("web", composeImportService{Image: "nginx", Ports: []interface{}{"8000-8010:80"}}); err != ErrInvalidPort {
+		t.Error("Port range should return ErrInvalidPort, got", err)
+	}
+	if _, err = composeServiceToPackageToml("web", composeImportService{Image: "nginx", Volumes: []interface{}{"/data"}}); err != ErrInvalidVolume {
+		t.Error("Anonymous volume should return ErrInvalidVolume, got", err)
+	}
+}
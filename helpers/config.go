@@ -2,64 +2,201 @@ package helpers
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/user"
+	"path/filepath"
 )
 
 // EnsureConfigDirs ensures necessary Crackle config dirs are setup
 func EnsureConfigDirs() error {
-	home := ConfigDir()
-	if _, err := os.Stat(home); os.IsNotExist(err) {
-		err = os.Mkdir(home, 0755)
-		if err != nil {
+	for _, dir := range []string{ConfigDir(), filepath.Join(DataDir(), "packages"), BinDir()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
 			return err
 		}
 	}
-	homePackages := fmt.Sprintf("%s/packages", home)
-	if _, err := os.Stat(homePackages); os.IsNotExist(err) {
-		err = os.Mkdir(homePackages, 0755)
+	return nil
+}
+
+// ConfigDir returns the directory the client config, credentials and signing
+// key are kept in, $XDG_CONFIG_HOME/cr or ~/.config/cr
+func ConfigDir() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// DataDir returns the directory installed packages, their shims, state and
+// pins are kept in, $XDG_DATA_HOME/cr or ~/.local/share/cr
+func DataDir() string {
+	return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// CacheDir returns the directory files cr can fetch again are kept in,
+// $XDG_CACHE_HOME/cr or ~/.cache/cr
+func CacheDir() string {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// xdgDir returns cr's directory under the XDG base directory in env, or under
+// def in the home directory when it's unset
+func xdgDir(env string, def string) string {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, "cr")
+	}
+	return filepath.Join(homeDir(), def, "cr")
+}
+
+// LegacyDir returns where cr kept everything before XDG base directories,
+// ~/.cr or $XDG_CONFIG_HOME/.cr
+func LegacyDir() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, ".cr")
+	}
+	return filepath.Join(homeDir(), ".cr")
+}
+
+// homeDir returns the user's home directory, $HOME when it's set
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	usr, _ := user.Current()
+	return usr.HomeDir
+}
+
+// legacyData and legacyCache are the entries of LegacyDir that move to DataDir
+// and CacheDir, anything else is config
+var (
+	legacyData  = []string{"packages", "bin", "state.json", LockFileName, "client_id"}
+	legacyCache = []string{"acme"}
+)
+
+// MigrateLegacyDir moves the files in LegacyDir to ConfigDir, DataDir and
+// CacheDir, returning how many it moved. Files already in their new place are
+// left, and LegacyDir is only removed once it's empty.
+func MigrateLegacyDir() (int, error) {
+	legacy := LegacyDir()
+	entries, err := ioutil.ReadDir(legacy)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, entry := range entries {
+		dir := ConfigDir()
+		if containsString(legacyData, entry.Name()) {
+			dir = DataDir()
+		} else if containsString(legacyCache, entry.Name()) {
+			dir = CacheDir()
+		}
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return moved, err
+		}
+		n, err := migrateEntry(filepath.Join(legacy, entry.Name()), filepath.Join(dir, entry.Name()))
+		moved += n
 		if err != nil {
-			return err
+			return moved, err
 		}
 	}
-	homeBin := fmt.Sprintf("%s/bin", home)
-	if _, err := os.Stat(homeBin); os.IsNotExist(err) {
-		err = os.Mkdir(homeBin, 0755)
+	if err = os.Remove(legacy); err != nil {
+		Debugf("keeping %s: %s", legacy, err)
+	}
+	return moved, nil
+}
+
+// migrateEntry moves the file or directory from to to, returning how many it
+// moved. A directory that's already there gets the entries it doesn't have.
+func migrateEntry(from string, to string) (int, error) {
+	existing, err := os.Lstat(to)
+	if os.IsNotExist(err) {
+		return 1, os.Rename(from, to)
+	}
+	if err != nil {
+		return 0, err
+	}
+	entries, err := ioutil.ReadDir(from)
+	if !existing.IsDir() || err != nil {
+		Debugf("not moving %s, %s already exists", from, to)
+		return 0, nil
+	}
+
+	moved := 0
+	for _, entry := range entries {
+		n, err := migrateEntry(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name()))
+		moved += n
 		if err != nil {
-			return err
+			return moved, err
 		}
 	}
-	return nil
+	os.Remove(from)
+	return moved, nil
 }
 
-// ConfigDir returns the location Crackle Home should be set to
-func ConfigDir() string {
-	xdg := os.Getenv("XDG_CONFIG_HOME")
-	usr, _ := user.Current()
-	home := fmt.Sprintf("%s/.cr", usr.HomeDir)
-	if xdg != "" {
-		home = fmt.Sprintf("%s/.cr", xdg)
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
 	}
-	return home
+	return false
+}
+
+// BinDir returns the directory package shims are written to
+func BinDir() string {
+	return filepath.Join(DataDir(), "bin")
+}
+
+// ShimPath returns the location of a package's shim
+func ShimPath(packageName string) string {
+	return fmt.Sprintf("%s/%s", BinDir(), PackageFileName(packageName))
+}
+
+// PackageConfigPath returns the location of a package's downloaded config
+func PackageConfigPath(packageName string) string {
+	return fmt.Sprintf("%s/packages/%s.toml", DataDir(), PackageFileName(packageName))
 }
 
 // CreatePackageFiles returns a file handler for config file and creates
 // a file in bin based on package name that is executable
 func CreatePackageFiles(packageName string) (*os.File, error) {
-	bPath := fmt.Sprintf("%s/bin/%s", ConfigDir(), packageName)
-	b, err := os.OpenFile(bPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
-	if err != nil {
+	if err := WriteShim(packageName); err != nil {
 		return nil, err
 	}
-	_, err = b.Write([]byte(fmt.Sprintf("cr exec %s", packageName)))
-	if err != nil {
-		return nil, err
+	return os.Create(PackageConfigPath(packageName))
+}
+
+// WriteShim writes an executable shim to BinDir that runs a package with cr,
+// passing on its arguments, so installed packages can be run by name
+func WriteShim(packageName string) error {
+	bPath := ShimPath(packageName)
+	shim := fmt.Sprintf("#!/bin/sh\nexec cr run %s -- \"$@\"\n", ShellQuote(packageName))
+	if err := ioutil.WriteFile(bPath, []byte(shim), 0755); err != nil {
+		return err
 	}
-	b.Close()
-	err = os.Chmod(bPath, 0755)
-	if err != nil {
-		return nil, err
+	// WriteFile doesn't change the mode of an existing file
+	return os.Chmod(bPath, 0755)
+}
+
+// RemovePackageFiles removes a package's shim and config, files that are
+// already gone are ignored
+func RemovePackageFiles(packageName string) error {
+	for _, p := range []string{ShimPath(packageName), PackageConfigPath(packageName)} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// BinDirOnPath returns true when BinDir is in the PATH environment variable
+func BinDirOnPath() bool {
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.Clean(dir) == filepath.Clean(BinDir()) {
+			return true
+		}
 	}
-	pPath := fmt.Sprintf("%s/packages/%s.toml", ConfigDir(), packageName)
-	return os.Create(pPath)
+	return false
 }
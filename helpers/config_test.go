@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tempConfigDir points ConfigDir, DataDir and CacheDir at a new temporary
+// directory with the OS keychain turned off, the returned func puts them back
+func tempConfigDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "cr-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	envs := []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME"}
+	xdg := make(map[string]string)
+	for _, env := range envs {
+		xdg[env] = os.Getenv(env)
+		os.Setenv(env, filepath.Join(dir, env))
+	}
+	keychainEnabled = false
+	if err = EnsureConfigDirs(); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		keychainEnabled = true
+		for _, env := range envs {
+			os.Setenv(env, xdg[env])
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestWriteShim(t *testing.T) {
+	defer tempConfigDir(t)()
+
+	if err := WriteShim("testing"); err != nil {
+		t.Fatal("Shim should be written, got", err)
+	}
+	info, err := os.Stat(ShimPath("testing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		t.Error("Shim should be executable, got mode", info.Mode())
+	}
+	b, _ := ioutil.ReadFile(ShimPath("testing"))
+	if string(b) != "#!/bin/sh\nexec cr run testing -- \"$@\"\n" {
+		t.Errorf("Shim should run the package with its arguments, got %q", b)
+	}
+
+	if err = RemovePackageFiles("testing"); err != nil {
+		t.Fatal("Package files should be removed, got", err)
+	}
+	if _, err = os.Stat(ShimPath("testing")); !os.IsNotExist(err) {
+		t.Error("Shim should be removed")
+	}
+	if err = RemovePackageFiles("testing"); err != nil {
+		t.Error("Removing package files twice shouldn't fail, got", err)
+	}
+}
+
+func TestXDGDirs(t *testing.T) {
+	defer tempConfigDir(t)()
+
+	if !strings.HasSuffix(ConfigDir(), "XDG_CONFIG_HOME/cr") || !strings.HasSuffix(DataDir(), "XDG_DATA_HOME/cr") ||
+		!strings.HasSuffix(CacheDir(), "XDG_CACHE_HOME/cr") {
+		t.Errorf("Dirs should be under their XDG base directory, got %s %s %s", ConfigDir(), DataDir(), CacheDir())
+	}
+	data := os.Getenv("XDG_DATA_HOME")
+	os.Setenv("XDG_DATA_HOME", "relative")
+	if DataDir() != filepath.Join(homeDir(), ".local", "share", "cr") {
+		t.Error("A relative XDG base directory should be ignored, got", DataDir())
+	}
+	os.Setenv("XDG_DATA_HOME", data)
+}
+
+func TestMigrateLegacyDir(t *testing.T) {
+	defer tempConfigDir(t)()
+	legacy := LegacyDir()
+	for _, f := range []string{"client.toml", "state.json", "bin/testing", "acme/example.com"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(legacy, f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(legacy, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Already in its new place, so it's left
+	if err := ioutil.WriteFile(filepath.Join(ConfigDir(), "client.toml"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := MigrateLegacyDir()
+	if err != nil || moved != 3 {
+		t.Fatalf("3 entries should be moved, got %d %v", moved, err)
+	}
+	for _, f := range []string{StatePath(), ShimPath("testing"), filepath.Join(CacheDir(), "acme", "example.com")} {
+		if _, err = os.Stat(f); err != nil {
+			t.Errorf("%s should be moved, got %s", f, err)
+		}
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(ConfigDir(), "client.toml")); string(b) != "new" {
+		t.Error("A file already in its new place shouldn't be replaced")
+	}
+	if _, err = os.Stat(filepath.Join(legacy, "client.toml")); err != nil {
+		t.Error("A file that wasn't moved should be kept")
+	}
+
+	if moved, err = MigrateLegacyDir(); err != nil || moved != 0 {
+		t.Errorf("Nothing more should be moved, got %d %v", moved, err)
+	}
+}
@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// PackageLabel is the docker label holding the name of the package a container runs
+const PackageLabel = "cr.package"
+
+// containerFormat is the docker ps --format template parsed by ListContainersContext
+const containerFormat = `{{.ID}}\t{{.Label "` + PackageLabel + `"}}\t{{.Image}}\t{{.Status}}\t{{.Names}}\t{{.CreatedAt}}`
+
+// ErrNotRunning is thrown when no container is running a package
+var ErrNotRunning = errors.New("package isn't running")
+
+// Container represents a container started by cr
+type Container struct {
+	ID        string
+	Package   string
+	Image     string
+	Status    string
+	Name      string
+	CreatedAt time.Time
+}
+
+// ContainerName returns the name of the container a package is run detached in
+func ContainerName(packageName string) string {
+	return "cr-" + PackageFileName(packageName)
+}
+
+// packageLabelArgs returns the docker run flags labelling a container with its package
+func packageLabelArgs(packageName string) []string {
+	return []string{"--label", fmt.Sprintf("%s=%s", PackageLabel, packageName)}
+}
+
+// PackageTomlToDetachedArgs is PackageTomlToArgs for running a package in the
+// background, in a container named with ContainerName
+func PackageTomlToDetachedArgs(pt *models.PackageToml, extra ...string) (string, []string) {
+	flags := append([]string{"-d", "--rm", "--name", ContainerName(pt.Package)}, packageLabelArgs(pt.Package)...)
+	args := dockerRunArgs(pt, flags...)
+	return DockerCmd(append(args[1:], extra...)...)
+}
+
+// ListContainersContext lists the running containers started by cr, newest
+// first. When packageName isn't empty only containers running it are listed.
+func ListContainersContext(ctx context.Context, packageName string) ([]Container, error) {
+	filter := "label=" + PackageLabel
+	if packageName != "" {
+		filter += "=" + packageName
+	}
+	out, err := DockerOutputContext(ctx, "ps", "--filter", filter, "--format", containerFormat)
+	if err != nil {
+		return nil, err
+	}
+	return parseContainers(out), nil
+}
+
+// ListStoppedContainersContext lists the containers started by cr that have
+// exited but weren't removed, newest first
+func ListStoppedContainersContext(ctx context.Context) ([]Container, error) {
+	out, err := DockerOutputContext(ctx, "ps", "--all", "--filter", "label="+PackageLabel,
+		"--filter", "status=exited", "--filter", "status=created", "--format", containerFormat)
+	if err != nil {
+		return nil, err
+	}
+	return parseContainers(out), nil
+}
+
+// parseContainers parses the output of docker ps formatted with containerFormat
+func parseContainers(out string) []Container {
+	var containers []Container
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 {
+			continue
+		}
+		// An unparsable time is left zero rather than losing the container
+		createdAt, _ := parseDockerTime(fields[5])
+		containers = append(containers, Container{
+			ID:        fields[0],
+			Package:   fields[1],
+			Image:     fields[2],
+			Status:    fields[3],
+			Name:      fields[4],
+			CreatedAt: createdAt,
+		})
+	}
+	return containers
+}
+
+// FindContainerContext returns the newest running container of a package, if
+// there's none ErrNotRunning is returned
+func FindContainerContext(ctx context.Context, packageName string) (*Container, error) {
+	containers, err := ListContainersContext(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, ErrNotRunning
+	}
+	return &containers[0], nil
+}
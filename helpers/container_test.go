@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestParseContainers(t *testing.T) {
+	out := "4f2a\ttesting\tsunshinekitty/testing:1.0\tUp 2 minutes\tcr-testing\t2017-10-09 12:30:00 +0000 UTC\n" +
+		"9c1b\twebserver\tsunshinekitty/webserver:1.0\tUp 3 hours\tgallant_hopper\t2017-10-09 09:30:00 +0000 UTC\n"
+	expected := []Container{
+		{"4f2a", "testing", "sunshinekitty/testing:1.0", "Up 2 minutes", "cr-testing", time.Date(2017, 10, 9, 12, 30, 0, 0, time.UTC)},
+		{"9c1b", "webserver", "sunshinekitty/webserver:1.0", "Up 3 hours", "gallant_hopper", time.Date(2017, 10, 9, 9, 30, 0, 0, time.UTC)},
+	}
+	if containers := parseContainers(out); !reflect.DeepEqual(containers, expected) {
+		t.Errorf("Containers should be parsed, got %+v", containers)
+	}
+	if containers := parseContainers(""); len(containers) != 0 {
+		t.Errorf("No containers should be parsed from empty output, got %+v", containers)
+	}
+}
+
+func TestPackageTomlToDetachedArgs(t *testing.T) {
+	pt := &models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing:1.0"}
+	_, args := PackageTomlToDetachedArgs(pt, "--verbose")
+	expected := []string{"run", "-d", "--rm", "--name", "cr-testing", "--label", "cr.package=testing", "--", "sunshinekitty/testing:1.0", "--verbose"}
+	if !reflect.DeepEqual(args[len(args)-len(expected):], expected) {
+		t.Errorf("Detached run should be named and labelled, got %q", args)
+	}
+}
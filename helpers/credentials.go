@@ -0,0 +1,132 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// TokenEnv is the environment variable holding an API token to use in place
+	// of logging in, such as one created with `cr token create` for CI
+	TokenEnv = "CR_TOKEN"
+	// AuthTokenEnv is another name for TokenEnv, TokenEnv wins when both are set
+	AuthTokenEnv = "CR_AUTH_TOKEN"
+)
+
+// ErrNotLoggedIn is thrown when there are no credentials for the configured Crackle endpoint
+var ErrNotLoggedIn = errors.New("not logged in, login with `cr login`")
+
+// Credentials represents the API token of a user logged in to a Crackle endpoint
+type Credentials struct {
+	Username string `json:"username"`
+	Token    string `json:"token,omitempty"`
+	// Keychain is true when the token is held in the OS keychain rather than the credentials file
+	Keychain bool `json:"keychain,omitempty"`
+}
+
+// CredentialsPath returns the location of the credentials file
+func CredentialsPath() string {
+	return fmt.Sprintf("%s/credentials.json", ConfigDir())
+}
+
+// LoadCredentials returns the credentials for the configured Crackle endpoint,
+// ErrNotLoggedIn is returned when there aren't any. A token in TokenEnv or
+// AuthTokenEnv, then one of the registry in use in the client config, is used
+// over them.
+func LoadCredentials() (*Credentials, error) {
+	for _, env := range []string{TokenEnv, AuthTokenEnv} {
+		if token := os.Getenv(env); token != "" {
+			return &Credentials{Token: token}, nil
+		}
+	}
+	if token := registryToken(); token != "" {
+		return &Credentials{Token: token}, nil
+	}
+	all, err := loadAllCredentials()
+	if err != nil {
+		return nil, err
+	}
+	api := viper.GetString("crackle.api")
+	c, ok := all[api]
+	if !ok {
+		return nil, ErrNotLoggedIn
+	}
+	if c.Keychain {
+		if c.Token, err = keychainGet(api); err != nil {
+			return nil, ErrNotLoggedIn
+		}
+	}
+	return c, nil
+}
+
+// SaveCredentials stores credentials for the configured Crackle endpoint. The
+// token goes in the OS keychain when there is one, otherwise in the credentials
+// file which only its owner can read.
+func SaveCredentials(c *Credentials) error {
+	all, err := loadAllCredentials()
+	if err != nil {
+		return err
+	}
+	api := viper.GetString("crackle.api")
+	stored := &Credentials{Username: c.Username, Token: c.Token}
+	if err = keychainSet(api, c.Token); err == nil {
+		stored.Token = ""
+		stored.Keychain = true
+	} else {
+		Debugf("keeping token in %s, keychain unavailable: %s", CredentialsPath(), err)
+	}
+	all[api] = stored
+	return saveAllCredentials(all)
+}
+
+// DeleteCredentials removes the credentials for the configured Crackle endpoint
+func DeleteCredentials() error {
+	all, err := loadAllCredentials()
+	if err != nil {
+		return err
+	}
+	api := viper.GetString("crackle.api")
+	if c, ok := all[api]; ok && c.Keychain {
+		keychainDelete(api)
+	}
+	delete(all, api)
+	return saveAllCredentials(all)
+}
+
+// loadAllCredentials reads the credentials file, which holds credentials keyed
+// by Crackle endpoint
+func loadAllCredentials() (map[string]*Credentials, error) {
+	all := make(map[string]*Credentials)
+	b, err := ioutil.ReadFile(CredentialsPath())
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &all); err != nil {
+		return nil, fmt.Errorf("%s: %s", CredentialsPath(), err)
+	}
+	return all, nil
+}
+
+// saveAllCredentials writes the credentials file readable only by its owner
+func saveAllCredentials(all map[string]*Credentials) error {
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = EnsureConfigDirs(); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(CredentialsPath(), b, 0600); err != nil {
+		return err
+	}
+	// WriteFile doesn't change the mode of an existing file
+	return os.Chmod(CredentialsPath(), 0600)
+}
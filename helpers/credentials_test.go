@@ -0,0 +1,50 @@
+package helpers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCredentials(t *testing.T) {
+	defer tempConfigDir(t)()
+	viper.Set("crackle.api", "https://one.example.com/api/")
+	defer viper.Set("crackle.api", nil)
+
+	if _, err := LoadCredentials(); err != ErrNotLoggedIn {
+		t.Error("Loading credentials before logging in should return ErrNotLoggedIn, got", err)
+	}
+
+	if err := SaveCredentials(&Credentials{Username: "tester", Token: "secret"}); err != nil {
+		t.Fatal("Credentials should save, got", err)
+	}
+	info, err := os.Stat(CredentialsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Error("Credentials file should only be readable by its owner, got mode", info.Mode())
+	}
+
+	c, err := LoadCredentials()
+	if err != nil {
+		t.Fatal("Saved credentials should load, got", err)
+	}
+	if c.Username != "tester" || c.Token != "secret" {
+		t.Errorf("Loaded credentials should match saved, got %+v", c)
+	}
+
+	viper.Set("crackle.api", "https://two.example.com/api/")
+	if _, err = LoadCredentials(); err != ErrNotLoggedIn {
+		t.Error("Credentials should be kept per endpoint, got", err)
+	}
+
+	viper.Set("crackle.api", "https://one.example.com/api/")
+	if err = DeleteCredentials(); err != nil {
+		t.Fatal("Credentials should delete, got", err)
+	}
+	if _, err = LoadCredentials(); err != ErrNotLoggedIn {
+		t.Error("Loading deleted credentials should return ErrNotLoggedIn, got", err)
+	}
+}
@@ -0,0 +1,222 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// DiffAdded marks a field or entry that only exists in the newer package
+	DiffAdded = "added"
+	// DiffRemoved marks a field or entry that only exists in the older package
+	DiffRemoved = "removed"
+	// DiffChanged marks a field whose value differs between packages
+	DiffChanged = "changed"
+)
+
+// PackageDiff represents a single field level difference between two packages.
+// Ports and volumes are compared entry by entry as "local:container" strings,
+// environment variables as "KEY=value" strings.
+type PackageDiff struct {
+	Field  string `json:"field"`
+	Change string `json:"change"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// String formats a PackageDiff in the style of a unified diff line
+func (d PackageDiff) String() string {
+	switch d.Change {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", d.Field, d.New)
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", d.Field, d.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", d.Field, d.Old, d.New)
+	}
+}
+
+// DiffPackages returns the field level differences going from package a to package b
+func DiffPackages(a, b *models.Package) ([]PackageDiff, error) {
+	var diffs []PackageDiff
+
+	diffs = appendStringDiff(diffs, "repository", a.Repository, b.Repository)
+	diffs = appendStringDiff(diffs, "version", a.Version, b.Version)
+	diffs = appendOptionalDiff(diffs, "command_start", a.CommandStart, b.CommandStart)
+	diffs = appendOptionalDiff(diffs, "test_command", a.TestCommand, b.TestCommand)
+	diffs = appendOptionalDiff(diffs, "org", a.Org, b.Org)
+	diffs = appendStringDiff(diffs, "private", fmt.Sprint(a.Private), fmt.Sprint(b.Private))
+	diffs = appendOptionalDiff(diffs, "homepage", a.Homepage, b.Homepage)
+	diffs = appendOptionalDiff(diffs, "icon", a.Icon, b.Icon)
+	diffs = appendOptionalDiff(diffs, "short_description", a.ShortDescription, b.ShortDescription)
+	diffs = appendOptionalDiff(diffs, "long_description", a.LongDescription, b.LongDescription)
+
+	aPorts, err := packagePorts(a)
+	if err != nil {
+		return nil, err
+	}
+	bPorts, err := packagePorts(b)
+	if err != nil {
+		return nil, err
+	}
+	diffs = appendSetDiff(diffs, "port", portStrings(aPorts), portStrings(bPorts))
+
+	aVolumes, err := packageVolumes(a)
+	if err != nil {
+		return nil, err
+	}
+	bVolumes, err := packageVolumes(b)
+	if err != nil {
+		return nil, err
+	}
+	diffs = appendSetDiff(diffs, "volume", volumeStrings(aVolumes), volumeStrings(bVolumes))
+
+	aEnv, err := packageEnv(a)
+	if err != nil {
+		return nil, err
+	}
+	bEnv, err := packageEnv(b)
+	if err != nil {
+		return nil, err
+	}
+	diffs = appendSetDiff(diffs, "env", envStrings(aEnv), envStrings(bEnv))
+
+	aKeywords, err := packageKeywords(a)
+	if err != nil {
+		return nil, err
+	}
+	bKeywords, err := packageKeywords(b)
+	if err != nil {
+		return nil, err
+	}
+	diffs = appendSetDiff(diffs, "keyword", aKeywords, bKeywords)
+
+	return diffs, nil
+}
+
+func appendStringDiff(diffs []PackageDiff, field, a, b string) []PackageDiff {
+	if a != b {
+		diffs = append(diffs, PackageDiff{Field: field, Change: DiffChanged, Old: a, New: b})
+	}
+	return diffs
+}
+
+func appendOptionalDiff(diffs []PackageDiff, field string, a, b *string) []PackageDiff {
+	switch {
+	case a == nil && b == nil:
+		return diffs
+	case a == nil:
+		return append(diffs, PackageDiff{Field: field, Change: DiffAdded, New: *b})
+	case b == nil:
+		return append(diffs, PackageDiff{Field: field, Change: DiffRemoved, Old: *a})
+	default:
+		return appendStringDiff(diffs, field, *a, *b)
+	}
+}
+
+func appendSetDiff(diffs []PackageDiff, field string, a, b []string) []PackageDiff {
+	inA := make(map[string]bool)
+	for _, s := range a {
+		inA[s] = true
+	}
+	inB := make(map[string]bool)
+	for _, s := range b {
+		inB[s] = true
+	}
+	for _, s := range a {
+		if !inB[s] {
+			diffs = append(diffs, PackageDiff{Field: field, Change: DiffRemoved, Old: s})
+		}
+	}
+	for _, s := range b {
+		if !inA[s] {
+			diffs = append(diffs, PackageDiff{Field: field, Change: DiffAdded, New: s})
+		}
+	}
+	return diffs
+}
+
+func portStrings(ports models.Ports) []string {
+	s := make([]string, 0, len(ports))
+	for _, p := range ports {
+		s = append(s, fmt.Sprintf("%s:%s", p.Local, p.Container))
+	}
+	sort.Strings(s)
+	return s
+}
+
+func volumeStrings(volumes models.Volumes) []string {
+	s := make([]string, 0, len(volumes))
+	for _, v := range volumes {
+		s = append(s, fmt.Sprintf("%s:%s", v.Local, v.Container))
+	}
+	sort.Strings(s)
+	return s
+}
+
+func envStrings(env models.Env) []string {
+	s := make([]string, 0, len(env))
+	for _, k := range env.Keys() {
+		s = append(s, fmt.Sprintf("%s=%s", k, env[k]))
+	}
+	return s
+}
+
+// packagePorts decodes the raw json ports of a Package
+func packagePorts(p *models.Package) (models.Ports, error) {
+	ports := make(models.Ports, 0)
+	if p.Ports != nil {
+		if err := json.Unmarshal(*p.Ports, &ports); err != nil {
+			return nil, err
+		}
+	}
+	if ports == nil {
+		ports = make(models.Ports, 0)
+	}
+	return ports, nil
+}
+
+// packageVolumes decodes the raw json volumes of a Package
+func packageVolumes(p *models.Package) (models.Volumes, error) {
+	volumes := make(models.Volumes, 0)
+	if p.Volumes != nil {
+		if err := json.Unmarshal(*p.Volumes, &volumes); err != nil {
+			return nil, err
+		}
+	}
+	if volumes == nil {
+		volumes = make(models.Volumes, 0)
+	}
+	return volumes, nil
+}
+
+// packageEnv decodes the raw json environment variables of a Package
+func packageEnv(p *models.Package) (models.Env, error) {
+	env := make(models.Env)
+	if p.Env != nil {
+		if err := json.Unmarshal(*p.Env, &env); err != nil {
+			return nil, err
+		}
+	}
+	if env == nil {
+		env = make(models.Env)
+	}
+	return env, nil
+}
+
+// packageKeywords decodes the raw json keywords of a Package
+func packageKeywords(p *models.Package) ([]string, error) {
+	keywords := make([]string, 0)
+	if p.Keywords != nil {
+		if err := json.Unmarshal(*p.Keywords, &keywords); err != nil {
+			return nil, err
+		}
+	}
+	if keywords == nil {
+		keywords = make([]string, 0)
+	}
+	return keywords, nil
+}
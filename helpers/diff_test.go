@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx/types"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestDiffPackages(t *testing.T) {
+	cmdStart := "start.sh"
+	newCmdStart := "run.sh"
+	homepage := "https://example.com"
+	portsA := types.JSONText(`[{"local":"8080","container":"80"}]`)
+	portsB := types.JSONText(`[{"local":"8080","container":"80"},{"local":"8443","container":"443"}]`)
+	volumesA := types.JSONText(`[{"local":"/tmp","container":"/data"}]`)
+	keywordsB := types.JSONText(`["testing"]`)
+	a := &models.Package{
+		Name:         "testing",
+		Repository:   "sunshinekitty/testing",
+		Version:      "1.0.0",
+		CommandStart: &cmdStart,
+		Homepage:     &homepage,
+		Ports:        &portsA,
+		Volumes:      &volumesA,
+	}
+	b := &models.Package{
+		Name:         "testing",
+		Repository:   "sunshinekitty/testing",
+		Version:      "1.1.0",
+		CommandStart: &newCmdStart,
+		Keywords:     &keywordsB,
+		Ports:        &portsB,
+		Private:      true,
+	}
+
+	diffs, err := DiffPackages(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PackageDiff{
+		{Field: "version", Change: DiffChanged, Old: "1.0.0", New: "1.1.0"},
+		{Field: "command_start", Change: DiffChanged, Old: "start.sh", New: "run.sh"},
+		{Field: "private", Change: DiffChanged, Old: "false", New: "true"},
+		{Field: "homepage", Change: DiffRemoved, Old: "https://example.com"},
+		{Field: "port", Change: DiffAdded, New: "8443:443"},
+		{Field: "volume", Change: DiffRemoved, Old: "/tmp:/data"},
+		{Field: "keyword", Change: DiffAdded, New: "testing"},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Diffs should be %v, got %v", expected, diffs)
+	}
+
+	diffs, err = DiffPackages(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Error("Identical packages should have no diffs, got", diffs)
+	}
+}
+
+func TestPackageDiffString(t *testing.T) {
+	if s := (PackageDiff{Field: "port", Change: DiffAdded, New: "80:80"}).String(); s != "+ port: 80:80" {
+		t.Error("Added diff should be \"+ port: 80:80\", got", s)
+	}
+	if s := (PackageDiff{Field: "version", Change: DiffChanged, Old: "1", New: "2"}).String(); s != "~ version: 1 -> 2" {
+		t.Error("Changed diff should be \"~ version: 1 -> 2\", got", s)
+	}
+}
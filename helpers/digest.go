@@ -0,0 +1,168 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// dockerHubAPI is the host of Docker Hub's registry API
+const dockerHubAPI = "registry-1.docker.io"
+
+// manifestAccept are the manifest types asked for when resolving a digest,
+// lists come first so a digest pins the image for every platform
+var manifestAccept = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// ErrNoDigest is thrown when a registry doesn't return the digest of an image
+var ErrNoDigest = errors.New("registry didn't return a digest")
+
+// registryAPI returns the base URL of the registry API serving a Reference and
+// the repository's path on it
+func registryAPI(ref *Reference) (string, string) {
+	registry := normalizeRegistry(ref.Registry)
+	if registry == "" || registry == DockerHub {
+		repository := ref.Repository
+		// Official images live under library/ on Docker Hub
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+		return "https://" + dockerHubAPI, repository
+	}
+	if strings.HasPrefix(registry, "localhost") {
+		return "http://" + registry, ref.Repository
+	}
+	return "https://" + registry, ref.Repository
+}
+
+// ResolveDigestContext resolves an image's tag to the digest it currently points
+// at by asking its registry, images without a tag resolve latest. Registries
+// that need a token get an anonymous one.
+func ResolveDigestContext(ctx context.Context, image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	tag := ref.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+	base, repository := registryAPI(ref)
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", base, repository, tag)
+
+	resp, err := headManifestContext(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := registryTokenContext(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", err
+		}
+		if resp, err = headManifestContext(ctx, manifestURL, token); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("resolving %s: %s", image, resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if !referenceDigest.MatchString(digest) {
+		ErrNoDigest = fmt.Errorf("registry didn't return a digest for %s", image)
+		return "", ErrNoDigest
+	}
+	return digest, nil
+}
+
+// headManifestContext asks a registry for a manifest's headers
+func headManifestContext(ctx context.Context, manifestURL string, token string) (*http.Response, error) {
+	req, err := http.NewRequest("HEAD", manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestAccept, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// registryTokenContext fetches an anonymous token for the Bearer challenge a
+// registry answered with
+func registryTokenContext(ctx context.Context, challenge string) (string, error) {
+	params := parseChallenge(challenge)
+	if params["realm"] == "" {
+		return "", fmt.Errorf("registry needs authentication: %s", challenge)
+	}
+	q := url.Values{}
+	for _, k := range []string{"service", "scope"} {
+		if params[k] != "" {
+			q.Set(k, params[k])
+		}
+	}
+	b, err := httpGetContext(ctx, params["realm"]+"?"+q.Encode())
+	if err != nil {
+		return "", err
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.Unmarshal(b, &token); err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		return token.AccessToken, nil
+	}
+	return token.Token, nil
+}
+
+// parseChallenge parses the params of a WWW-Authenticate Bearer challenge such
+// as Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return params
+	}
+	rest := strings.TrimSpace(challenge[len("bearer "):])
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq == -1 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end == -1 {
+				break
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else if comma := strings.Index(rest, ","); comma != -1 {
+			value, rest = rest[:comma], rest[comma:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[key] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return params
+}
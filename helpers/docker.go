@@ -0,0 +1,198 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinDockerVersion is the oldest docker cr's commands work with, --rm and -d
+// couldn't be used together before it
+const MinDockerVersion = "1.13"
+
+const (
+	// PullAlways pulls the image before every run so a pushed tag is picked up
+	PullAlways = "always"
+	// PullMissing only pulls images docker doesn't have
+	PullMissing = "missing"
+	// PullNever never pulls, running fails when the image isn't there
+	PullNever = "never"
+)
+
+// ErrImageNotFound is thrown when an image isn't there and can't be pulled
+var ErrImageNotFound = errors.New("image not found locally")
+
+// ValidPullPolicy returns true for PullAlways, PullMissing and PullNever
+func ValidPullPolicy(policy string) bool {
+	switch policy {
+	case PullAlways, PullMissing, PullNever:
+		return true
+	}
+	return false
+}
+
+// ApplyPullPolicyContext gets an image ready to run under a pull policy. docker
+// run's own --pull is newer than MinDockerVersion so the image is pulled, or
+// checked for, before running.
+func ApplyPullPolicyContext(ctx context.Context, image string, policy string) error {
+	switch policy {
+	case PullAlways:
+		return PullImageContext(ctx, image)
+	case PullMissing:
+		exists, err := ImageExistsContext(ctx, image)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return PullImageContext(ctx, image)
+		}
+	case PullNever:
+		exists, err := ImageExistsContext(ctx, image)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			ErrImageNotFound = fmt.Errorf("image %s not found locally, pull it with `docker pull %s`", image, image)
+			return ErrImageNotFound
+		}
+	}
+	return nil
+}
+
+// ImageExistsContext returns true when docker has image locally
+func ImageExistsContext(ctx context.Context, image string) (bool, error) {
+	out, err := DockerOutputContext(ctx, "images", "--quiet", image)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// DockerCmd returns the command and args to run docker with args
+func DockerCmd(args ...string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		// There's no /usr/bin/env to find docker with on Windows
+		return "docker", args
+	}
+	return "/usr/bin/env", append([]string{"docker"}, args...)
+}
+
+// PullImageContext pulls a docker image showing the progress of each layer on
+// stderr, nothing is shown with --quiet. The docker API is used when the daemon
+// is on a unix socket, otherwise or when the API refuses the pull, such as for
+// images needing registry credentials, it falls back to docker pull.
+func PullImageContext(ctx context.Context, image string) error {
+	quiet := !LogEnabled(LogInfo)
+	progress := func(pullMessage) {}
+	if !quiet {
+		progress = pullProgressPrinter(os.Stderr, Interactive())
+	}
+	err := pullImageAPIContext(ctx, image, progress)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	Debugf("pulling %s through the docker API failed, using docker pull: %s", image, err)
+	if quiet {
+		_, err = DockerOutputContext(ctx, "pull", "--", image)
+		return err
+	}
+	name, args := DockerCmd("pull", "--", image)
+	return RunCmdContext(ctx, name, args)
+}
+
+// DockerOutputContext runs docker with args and returns what it printed, when it
+// fails the error holds what docker printed to stderr
+func DockerOutputContext(ctx context.Context, args ...string) (string, error) {
+	name, dockerArgs := DockerCmd(args...)
+	defer logCommand(name, dockerArgs)()
+	var stderr bytes.Buffer
+	c := exec.CommandContext(ctx, name, dockerArgs...)
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("docker %s: %s", args[0], msg)
+		}
+	}
+	return string(out), err
+}
+
+// DockerVersionContext returns the version of the docker client, and of the
+// daemon when it can be reached. err is set when the daemon can't be reached.
+func DockerVersionContext(ctx context.Context) (client string, server string, err error) {
+	// docker version fails as a whole when the daemon is down, so ask separately
+	out, err := DockerOutputContext(ctx, "version", "--format", "{{.Client.Version}}")
+	client = strings.TrimSpace(out)
+	if client == "" {
+		return "", "", err
+	}
+	out, err = DockerOutputContext(ctx, "version", "--format", "{{.Server.Version}}")
+	return client, strings.TrimSpace(out), err
+}
+
+// DockerVersionAtLeast returns true when a docker version such as 17.09.0-ce is
+// at least min, only the major and minor versions are compared
+func DockerVersionAtLeast(version string, min string) bool {
+	v, m := majorMinor(version), majorMinor(min)
+	if v[0] != m[0] {
+		return v[0] > m[0]
+	}
+	return v[1] >= m[1]
+}
+
+// majorMinor parses the major and minor numbers of a version, missing or invalid
+// numbers are 0
+func majorMinor(version string) [2]int {
+	var mm [2]int
+	for i, part := range strings.SplitN(version, ".", 3) {
+		if i > 1 {
+			break
+		}
+		mm[i], _ = strconv.Atoi(strings.TrimLeft(part, "v"))
+	}
+	return mm
+}
+
+// logCommand logs a command about to be run, the returned func logs how long it
+// took once it's finished
+func logCommand(name string, args []string) func() {
+	if !LogEnabled(LogDebug) {
+		return func() {}
+	}
+	command := ShellJoin(append([]string{name}, args...))
+	Debugf("running %s", command)
+	start := time.Now()
+	return func() {
+		Debugf("ran %s in %s", command, time.Since(start))
+	}
+}
+
+// RunCmdContext runs a command such as the one built by PackageTomlToCmd attached
+// to the terminal. The command is killed if ctx is canceled or times out before
+// it exits.
+func RunCmdContext(ctx context.Context, name string, args []string) error {
+	defer logCommand(name, args)()
+	c := exec.CommandContext(ctx, name, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// StartCmd starts a command such as the one built by PackageTomlToCmd attached
+// to the terminal, without waiting for it to exit
+func StartCmd(name string, args []string) (*exec.Cmd, error) {
+	Debugf("running %s", ShellJoin(append([]string{name}, args...)))
+	c := exec.Command(name, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c, c.Start()
+}
@@ -0,0 +1,34 @@
+package helpers
+
+import "testing"
+
+func TestDockerVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"1.13.1", true},
+		{"1.13", true},
+		{"17.09.0-ce", true},
+		{"20.10.7", true},
+		{"1.12.6", false},
+		{"1.9.1", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if DockerVersionAtLeast(test.version, MinDockerVersion) != test.expected {
+			t.Errorf("DockerVersionAtLeast(%q, %q) should be %v", test.version, MinDockerVersion, test.expected)
+		}
+	}
+}
+
+func TestValidPullPolicy(t *testing.T) {
+	for _, policy := range []string{PullAlways, PullMissing, PullNever} {
+		if !ValidPullPolicy(policy) {
+			t.Errorf("Pull policy %q should be valid", policy)
+		}
+	}
+	if ValidPullPolicy("sometimes") {
+		t.Error("Pull policy \"sometimes\" should be invalid")
+	}
+}
@@ -0,0 +1,203 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const (
+	// NotifyPublish emails a user when a version of a package they subscribed
+	// to is published
+	NotifyPublish = "publish"
+	// NotifyInvite emails a user when a package is offered to them
+	NotifyInvite = "invite"
+
+	// maxEmailLength is the longest address an email can be sent to
+	maxEmailLength = 254
+	// sendgridURL is where SendGrid's mail API is served
+	sendgridURL = "https://api.sendgrid.com/v3/mail/send"
+)
+
+// Notifications are every kind of email a user can choose to be sent
+var Notifications = []string{NotifyPublish, NotifyInvite}
+
+var (
+	// ErrInvalidEmail is thrown when an email address can't be parsed
+	ErrInvalidEmail = errors.New("email address is invalid")
+	// ErrInvalidNotification is thrown when a user chooses an unknown notification
+	ErrInvalidNotification = errors.New("notification is invalid")
+)
+
+// Mail is a plain text email to a single recipient
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails for the registry, such as through SMTP or SendGrid
+type Mailer interface {
+	Send(ctx context.Context, m Mail) error
+}
+
+// SMTPMailer sends emails through an SMTP server, authenticating with PLAIN
+// auth when Username is set
+type SMTPMailer struct {
+	// Addr is the host:port of the server, STARTTLS is used when it offers it
+	Addr     string
+	Username string
+	Password string
+	// From is the sender, such as "Crackle <noreply@crackle.pm>"
+	From string
+}
+
+// Send sends m through the SMTP server, ctx is only checked before connecting
+func (s *SMTPMailer) Send(ctx context.Context, m Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("email from address: %s", err)
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		host := s.Addr
+		if i := strings.LastIndex(host, ":"); i != -1 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	return smtp.SendMail(s.Addr, auth, from.Address, []string{m.To}, MailMessage(s.From, m, time.Now()))
+}
+
+// SendgridMailer sends emails through SendGrid's v3 mail API
+type SendgridMailer struct {
+	APIKey string
+	From   string
+	// URL overrides where the API is served, for tests
+	URL    string
+	Client *http.Client
+}
+
+// sendgridMail is the body of a request to SendGrid's mail API
+type sendgridMail struct {
+	Personalizations []sendgridPersonalization `json:"personalizations"`
+	From             sendgridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendgridContent         `json:"content"`
+}
+
+type sendgridPersonalization struct {
+	To []sendgridAddress `json:"to"`
+}
+
+type sendgridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendgridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Send sends m through SendGrid, which answers 202 once it's queued
+func (s *SendgridMailer) Send(ctx context.Context, m Mail) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("email from address: %s", err)
+	}
+	body, err := json.Marshal(sendgridMail{
+		Personalizations: []sendgridPersonalization{{To: []sendgridAddress{{Email: m.To}}}},
+		From:             sendgridAddress{Email: from.Address, Name: from.Name},
+		Subject:          m.Subject,
+		Content:          []sendgridContent{{Type: "text/plain", Value: m.Body}},
+	})
+	if err != nil {
+		return err
+	}
+	u, client := s.URL, s.Client
+	if u == "" {
+		u = sendgridURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest("POST", u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("sendgrid: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// MailMessage renders m as a message from from sent at date, with CRLF line
+// endings and a quoted-printable body as SMTP expects
+func MailMessage(from string, m Mail, date time.Time) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", from)
+	header("To", m.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+	w := quotedprintable.NewWriter(&b)
+	w.Write([]byte(strings.Replace(m.Body, "\n", "\r\n", -1)))
+	w.Close()
+	return b.Bytes()
+}
+
+// ValidEmail validates an email address users can verify, a bare address
+// without a display name
+func ValidEmail(email string) error {
+	a, err := mail.ParseAddress(email)
+	if err != nil || a.Address != email || len(email) > maxEmailLength {
+		return fmt.Errorf("%w: \"%s\"", ErrInvalidEmail, email)
+	}
+	return nil
+}
+
+// ValidNotifications validates the kinds of email a user chooses to be sent
+func ValidNotifications(notifications []string) error {
+	for _, n := range notifications {
+		if !Notified(Notifications, n) {
+			return fmt.Errorf("%w: \"%s\", use %s", ErrInvalidNotification, n, strings.Join(Notifications, ", "))
+		}
+	}
+	return nil
+}
+
+// Notified reports whether a user who chose notifications is sent notification
+func Notified(notifications []string, notification string) bool {
+	for _, n := range notifications {
+		if n == notification {
+			return true
+		}
+	}
+	return false
+}
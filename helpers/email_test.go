@@ -0,0 +1,88 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidEmail(t *testing.T) {
+	if err := ValidEmail("alice@example.com"); err != nil {
+		t.Errorf("Email should be valid, got %s", err)
+	}
+	for _, e := range []string{"", "alice", "Alice <alice@example.com>", strings.Repeat("a", 250) + "@b.io"} {
+		if err := ValidEmail(e); !errors.Is(err, ErrInvalidEmail) {
+			t.Errorf("Email \"%s\" should return ErrInvalidEmail, got %v", e, err)
+		}
+	}
+	if ErrInvalidEmail.Error() != "email address is invalid" {
+		t.Error("Validating emails shouldn't change ErrInvalidEmail, got", ErrInvalidEmail)
+	}
+}
+
+func TestValidNotifications(t *testing.T) {
+	if err := ValidNotifications([]string{NotifyPublish, NotifyInvite}); err != nil {
+		t.Errorf("Notifications should be valid, got %s", err)
+	}
+	if err := ValidNotifications(nil); err != nil {
+		t.Errorf("No notifications should be valid, got %s", err)
+	}
+	if err := ValidNotifications([]string{"yank"}); !errors.Is(err, ErrInvalidNotification) || !strings.Contains(err.Error(), "yank") {
+		t.Error("Unknown notification should return ErrInvalidNotification naming it, got", err)
+	}
+	if ErrInvalidNotification.Error() != "notification is invalid" {
+		t.Error("Validating notifications shouldn't change ErrInvalidNotification, got", ErrInvalidNotification)
+	}
+}
+
+func TestMailMessage(t *testing.T) {
+	date := time.Date(2017, 10, 23, 12, 0, 0, 0, time.UTC)
+	m := Mail{To: "bob@example.com", Subject: "Grüße", Body: "line one\nline two"}
+	msg := string(MailMessage("Crackle <noreply@crackle.pm>", m, date))
+
+	for _, want := range []string{
+		"From: Crackle <noreply@crackle.pm>\r\n",
+		"To: bob@example.com\r\n",
+		"Subject: =?utf-8?q?Gr=C3=BC=C3=9Fe?=\r\n",
+		"Date: Mon, 23 Oct 2017 12:00:00 +0000\r\n",
+		"\r\n\r\nline one\r\nline two",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Message should contain %q, got %q", want, msg)
+		}
+	}
+}
+
+func TestSendgridMailer(t *testing.T) {
+	var got sendgridMail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	s := &SendgridMailer{APIKey: "key", From: "Crackle <noreply@crackle.pm>", URL: server.URL}
+	if err := s.Send(context.Background(), Mail{To: "bob@example.com", Subject: "Hi", Body: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.From.Email != "noreply@crackle.pm" || got.From.Name != "Crackle" {
+		t.Errorf("Mail should be from Crackle <noreply@crackle.pm>, got %+v", got.From)
+	}
+	if len(got.Personalizations) != 1 || got.Personalizations[0].To[0].Email != "bob@example.com" {
+		t.Errorf("Mail should be to bob@example.com, got %+v", got.Personalizations)
+	}
+
+	s.APIKey = "wrong"
+	if err := s.Send(context.Background(), Mail{To: "bob@example.com"}); err == nil {
+		t.Error("Send should fail when SendGrid refuses it")
+	}
+}
@@ -0,0 +1,242 @@
+// Package k8s converts Crackle packages to Kubernetes manifests
+package k8s
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+)
+
+// Metadata represents the metadata of a Kubernetes object
+type Metadata struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// ContainerPort represents a port exposed by a container
+type ContainerPort struct {
+	ContainerPort int `yaml:"containerPort"`
+}
+
+// EnvVar represents an environment variable set in a container
+type EnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// VolumeMount represents a volume mounted in to a container
+type VolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+}
+
+// Container represents a container of a pod
+type Container struct {
+	Name         string          `yaml:"name"`
+	Image        string          `yaml:"image"`
+	Args         []string        `yaml:"args,omitempty"`
+	Ports        []ContainerPort `yaml:"ports,omitempty"`
+	Env          []EnvVar        `yaml:"env,omitempty"`
+	VolumeMounts []VolumeMount   `yaml:"volumeMounts,omitempty"`
+	TTY          bool            `yaml:"tty,omitempty"`
+}
+
+// HostPathVolumeSource represents a directory on the node mounted in to a pod
+type HostPathVolumeSource struct {
+	Path string `yaml:"path"`
+}
+
+// Volume represents a volume of a pod, package volumes become hostPath volumes
+// which should be swapped for a PersistentVolumeClaim outside of a single node
+type Volume struct {
+	Name     string                `yaml:"name"`
+	HostPath *HostPathVolumeSource `yaml:"hostPath,omitempty"`
+}
+
+// PodSpec represents the spec of a pod
+type PodSpec struct {
+	Containers    []Container `yaml:"containers"`
+	Volumes       []Volume    `yaml:"volumes,omitempty"`
+	RestartPolicy string      `yaml:"restartPolicy,omitempty"`
+}
+
+// PodTemplateSpec represents the pod template of a Deployment or Job
+type PodTemplateSpec struct {
+	Metadata Metadata `yaml:"metadata"`
+	Spec     PodSpec  `yaml:"spec"`
+}
+
+// LabelSelector represents a selector matching pods by their labels
+type LabelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+// DeploymentSpec represents the spec of a Deployment
+type DeploymentSpec struct {
+	Replicas int             `yaml:"replicas"`
+	Selector LabelSelector   `yaml:"selector"`
+	Template PodTemplateSpec `yaml:"template"`
+}
+
+// Deployment represents a Kubernetes Deployment
+type Deployment struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   Metadata       `yaml:"metadata"`
+	Spec       DeploymentSpec `yaml:"spec"`
+}
+
+// JobSpec represents the spec of a Job
+type JobSpec struct {
+	Template PodTemplateSpec `yaml:"template"`
+}
+
+// Job represents a Kubernetes Job
+type Job struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       JobSpec  `yaml:"spec"`
+}
+
+// ServicePort represents a port of a Service
+type ServicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort int    `yaml:"targetPort"`
+}
+
+// ServiceSpec represents the spec of a Service
+type ServiceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []ServicePort     `yaml:"ports"`
+}
+
+// Service represents a Kubernetes Service
+type Service struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   Metadata    `yaml:"metadata"`
+	Spec       ServiceSpec `yaml:"spec"`
+}
+
+// FromPackage converts a Package to Kubernetes objects. Packages with ports are
+// treated as services and become a Deployment and a Service with the local ports
+// as Service ports, packages without ports are treated as tools and become a Job.
+func FromPackage(p *models.Package) ([]interface{}, error) {
+	pt, err := helpers.PackageToPackageToml(p)
+	if err != nil {
+		return nil, err
+	}
+
+	name := Name(p.Name)
+	labels := map[string]string{"app": name}
+
+	container := Container{
+		Name:  name,
+		Image: pt.Repository,
+		TTY:   true,
+	}
+	if pt.CommandStart != nil {
+		container.Args, err = helpers.ShellSplit(*pt.CommandStart)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range pt.Env.Keys() {
+		container.Env = append(container.Env, EnvVar{Name: k, Value: pt.Env[k]})
+	}
+
+	var volumes []Volume
+	for i, v := range pt.Volumes {
+		volumeName := fmt.Sprintf("volume-%d", i)
+		container.VolumeMounts = append(container.VolumeMounts, VolumeMount{Name: volumeName, MountPath: v.Container})
+		volumes = append(volumes, Volume{Name: volumeName, HostPath: &HostPathVolumeSource{Path: v.Local}})
+	}
+
+	var servicePorts []ServicePort
+	for _, port := range pt.Ports {
+		local, err := strconv.Atoi(port.Local)
+		if err != nil {
+			return nil, err
+		}
+		target, err := strconv.Atoi(port.Container)
+		if err != nil {
+			return nil, err
+		}
+		container.Ports = append(container.Ports, ContainerPort{ContainerPort: target})
+		servicePorts = append(servicePorts, ServicePort{Name: fmt.Sprintf("port-%d", local), Port: local, TargetPort: target})
+	}
+
+	template := PodTemplateSpec{
+		Metadata: Metadata{Name: name, Labels: labels},
+		Spec: PodSpec{
+			Containers: []Container{container},
+			Volumes:    volumes,
+		},
+	}
+
+	if len(servicePorts) == 0 {
+		template.Spec.RestartPolicy = "Never"
+		return []interface{}{
+			&Job{
+				APIVersion: "batch/v1",
+				Kind:       "Job",
+				Metadata:   Metadata{Name: name, Labels: labels},
+				Spec:       JobSpec{Template: template},
+			},
+		}, nil
+	}
+
+	return []interface{}{
+		&Deployment{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Metadata:   Metadata{Name: name, Labels: labels},
+			Spec: DeploymentSpec{
+				Replicas: 1,
+				Selector: LabelSelector{MatchLabels: labels},
+				Template: template,
+			},
+		},
+		&Service{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Metadata:   Metadata{Name: name, Labels: labels},
+			Spec: ServiceSpec{
+				Selector: labels,
+				Ports:    servicePorts,
+			},
+		},
+	}, nil
+}
+
+// Name converts a package name to a valid Kubernetes object name
+func Name(n string) string {
+	return strings.Replace(n, "_", "-", -1)
+}
+
+// Encode writes Kubernetes objects to w as a multi document yaml stream
+func Encode(w io.Writer, objects []interface{}) error {
+	for i, o := range objects {
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		b, err := yaml.Marshal(o)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx/types"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestFromPackageService(t *testing.T) {
+	cmdStart := "nginx -g 'daemon off;'"
+	ports := types.JSONText(`[{"local":"8080","container":"80"}]`)
+	volumes := types.JSONText(`[{"local":"/srv/www","container":"/usr/share/nginx/html"}]`)
+	env := types.JSONText(`{"B":"2","A":"1"}`)
+	p := &models.Package{
+		CommandStart: &cmdStart,
+		Env:          &env,
+		Name:         "my_web",
+		Ports:        &ports,
+		Repository:   "nginx",
+		Version:      "1.13",
+		Volumes:      &volumes,
+	}
+
+	objects, err := FromPackage(p)
+	if err != nil {
+		t.Fatal("Package should convert, got", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("Package with ports should become a Deployment and Service, got %d objects", len(objects))
+	}
+	deployment, ok := objects[0].(*Deployment)
+	if !ok {
+		t.Fatalf("First object should be a Deployment, got %T", objects[0])
+	}
+	service, ok := objects[1].(*Service)
+	if !ok {
+		t.Fatalf("Second object should be a Service, got %T", objects[1])
+	}
+
+	if deployment.Metadata.Name != "my-web" || service.Metadata.Name != "my-web" {
+		t.Errorf("Object names should be valid Kubernetes names, got %s and %s", deployment.Metadata.Name, service.Metadata.Name)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.Image != "nginx:1.13" {
+		t.Error("Container image should be nginx:1.13, got", container.Image)
+	}
+	if !reflect.DeepEqual(container.Args, []string{"nginx", "-g", "daemon off;"}) {
+		t.Errorf("Container args should be split from command_start, got %q", container.Args)
+	}
+	if !reflect.DeepEqual(container.Env, []EnvVar{{"A", "1"}, {"B", "2"}}) {
+		t.Errorf("Container env should be sorted, got %+v", container.Env)
+	}
+	if !reflect.DeepEqual(container.Ports, []ContainerPort{{80}}) {
+		t.Errorf("Container ports should be the package's container ports, got %+v", container.Ports)
+	}
+	if !reflect.DeepEqual(service.Spec.Ports, []ServicePort{{Name: "port-8080", Port: 8080, TargetPort: 80}}) {
+		t.Errorf("Service ports should map local ports to container ports, got %+v", service.Spec.Ports)
+	}
+	volume := deployment.Spec.Template.Spec.Volumes[0]
+	if volume.HostPath == nil || volume.HostPath.Path != "/srv/www" || container.VolumeMounts[0].Name != volume.Name {
+		t.Errorf("Volumes should become hostPath volumes mounted in to the container, got %+v", volume)
+	}
+	if !reflect.DeepEqual(service.Spec.Selector, deployment.Spec.Template.Metadata.Labels) {
+		t.Error("Service should select the Deployment's pods")
+	}
+}
+
+func TestFromPackageJob(t *testing.T) {
+	objects, err := FromPackage(&models.Package{Name: "tool", Repository: "alpine", Version: "3.6"})
+	if err != nil {
+		t.Fatal("Package should convert, got", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("Package without ports should become a single Job, got %d objects", len(objects))
+	}
+	job, ok := objects[0].(*Job)
+	if !ok {
+		t.Fatalf("Object should be a Job, got %T", objects[0])
+	}
+	if job.Spec.Template.Spec.RestartPolicy != "Never" {
+		t.Error("Job pods should never restart, got", job.Spec.Template.Spec.RestartPolicy)
+	}
+}
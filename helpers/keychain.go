@@ -0,0 +1,102 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keychainService is the service name tokens are stored under in the OS keychain
+const keychainService = "cr"
+
+var (
+	// keychainEnabled allows the OS keychain to be used, tests turn it off
+	keychainEnabled = true
+
+	// errNoKeychain is returned when there's no OS keychain to use
+	errNoKeychain = errors.New("no OS keychain available")
+)
+
+// keychainSet stores secret in the OS keychain under account, replacing any
+// secret already stored. Secrets are never passed as arguments where they
+// would be visible to other users.
+func keychainSet(account string, secret string) error {
+	switch keychainTool() {
+	case "security":
+		if strings.ContainsAny(account+secret, "\"\\\n") {
+			return errNoKeychain
+		}
+		// security -i reads the command from stdin, keeping the secret out of ps
+		command := fmt.Sprintf("add-generic-password -U -s %s -a \"%s\" -w \"%s\"\n", keychainService, account, secret)
+		return runKeychain(command, "security", "-i")
+	case "secret-tool":
+		return runKeychain(secret, "secret-tool", "store", "--label", "cr ("+account+")", "service", keychainService, "account", account)
+	}
+	return errNoKeychain
+}
+
+// keychainGet returns the secret stored in the OS keychain under account
+func keychainGet(account string) (string, error) {
+	var c *exec.Cmd
+	switch keychainTool() {
+	case "security":
+		c = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", account, "-w")
+	case "secret-tool":
+		c = exec.Command("secret-tool", "lookup", "service", keychainService, "account", account)
+	default:
+		return "", errNoKeychain
+	}
+	out, err := c.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// keychainDelete removes the secret stored in the OS keychain under account
+func keychainDelete(account string) error {
+	switch keychainTool() {
+	case "security":
+		return exec.Command("security", "delete-generic-password", "-s", keychainService, "-a", account).Run()
+	case "secret-tool":
+		return exec.Command("secret-tool", "clear", "service", keychainService, "account", account).Run()
+	}
+	return errNoKeychain
+}
+
+// keychainTool returns the command line tool used to reach the OS keychain, or
+// "" when there isn't one
+func keychainTool() string {
+	if !keychainEnabled {
+		return ""
+	}
+	tool := ""
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "linux", "freebsd", "openbsd":
+		tool = "secret-tool"
+	}
+	if tool == "" {
+		return ""
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return ""
+	}
+	return tool
+}
+
+// runKeychain runs a keychain tool with stdin as its input
+func runKeychain(stdin string, name string, args ...string) error {
+	c := exec.Command(name, args...)
+	c.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s: %s %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
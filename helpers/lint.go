@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// SeverityInfo marks a lint warning that is only a suggestion
+	SeverityInfo = "info"
+	// SeverityWarning marks a lint warning that is likely a problem
+	SeverityWarning = "warning"
+)
+
+// broadVolumes are host paths that give a container far more than it needs
+var broadVolumes = []string{"/", "/etc", "/home", "/root", "/usr", "/var", "~", "$HOME"}
+
+// dockerSocket is the host docker socket, mounting it is equivalent to privileged mode
+const dockerSocket = "/var/run/docker.sock"
+
+// LintWarning represents a non-fatal problem with a package config
+type LintWarning struct {
+	Field    string `json:"field"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// String formats a LintWarning as "severity: field: message"
+func (w LintWarning) String() string {
+	return fmt.Sprintf("%s: %s: %s", w.Severity, w.Field, w.Message)
+}
+
+// LintPackageToml returns warnings for a PackageToml object. Unlike ValidPackageToml
+// the package can still be published, these point out likely mistakes and
+// missing metadata.
+func LintPackageToml(pt *models.PackageToml) []LintWarning {
+	var warnings []LintWarning
+
+	if pt.ShortDescription == nil || len(strings.TrimSpace(*pt.ShortDescription)) == 0 {
+		warnings = append(warnings, LintWarning{"short_description", SeverityWarning,
+			"missing, packages without one are hard to find in search"})
+	}
+	if pt.Homepage == nil || len(strings.TrimSpace(*pt.Homepage)) == 0 {
+		warnings = append(warnings, LintWarning{"homepage", SeverityInfo,
+			"missing, users won't know where to find documentation"})
+	}
+
+	if ref, err := ParseReference(pt.Repository); err == nil {
+		switch ref.Tag {
+		case "":
+			warnings = append(warnings, LintWarning{"repository", SeverityWarning,
+				fmt.Sprintf("\"%s\" has no tag so runs the mutable latest tag, pin a version", pt.Repository)})
+		case "latest":
+			warnings = append(warnings, LintWarning{"repository", SeverityWarning,
+				fmt.Sprintf("\"%s\" uses the mutable latest tag, pin a version", pt.Repository)})
+		}
+	}
+
+	for _, v := range pt.Volumes {
+		local := path.Clean(v.Local)
+		if local == dockerSocket {
+			warnings = append(warnings, LintWarning{"volume", SeverityWarning,
+				fmt.Sprintf("\"%s\" mounts the docker socket, giving the container privileged control of the host", v.Local)})
+			continue
+		}
+		if IsWindowsPath(v.Local) && len(strings.TrimRight(v.Local, `\/`)) == 2 {
+			local = "/"
+		}
+		for _, broad := range broadVolumes {
+			if local == broad {
+				warnings = append(warnings, LintWarning{"volume", SeverityWarning,
+					fmt.Sprintf("\"%s\" is an overly broad mount, mount only the directory the package needs", v.Local)})
+				break
+			}
+		}
+	}
+
+	return warnings
+}
+
+// Validation represents the result of validating and linting a manifest
+type Validation struct {
+	Path     string        `json:"path"`
+	Package  string        `json:"package,omitempty"`
+	Valid    bool          `json:"valid"`
+	Errors   []string      `json:"errors"`
+	Warnings []LintWarning `json:"warnings"`
+}
+
+// Passed returns true when the manifest is valid, with strict any warning of
+// SeverityWarning fails it too
+func (v *Validation) Passed(strict bool) bool {
+	if !v.Valid {
+		return false
+	}
+	if strict {
+		for _, w := range v.Warnings {
+			if w.Severity == SeverityWarning {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidateManifestContext loads the manifest at path, a file or a directory
+// holding one, validates it and lints it. Problems are reported in the returned
+// Validation rather than as an error.
+func ValidateManifestContext(ctx context.Context, path string) *Validation {
+	v := &Validation{Path: path, Errors: []string{}, Warnings: []LintWarning{}}
+	p, err := ManifestPath(path)
+	if err != nil {
+		v.Errors = append(v.Errors, err.Error())
+		return v
+	}
+	v.Path = p
+	pt, err := ConfigFileToPackageTomlContext(ctx, p)
+	if err != nil {
+		v.Errors = append(v.Errors, err.Error())
+		return v
+	}
+	v.Package = pt.Package
+	if err = ValidPackageToml(pt); err != nil {
+		v.Errors = append(v.Errors, err.Error())
+	}
+	v.Valid = len(v.Errors) == 0
+	v.Warnings = append(v.Warnings, LintPackageToml(pt)...)
+	return v
+}
@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func lintFields(warnings []LintWarning) map[string]int {
+	fields := make(map[string]int)
+	for _, w := range warnings {
+		fields[w.Field]++
+	}
+	return fields
+}
+
+func TestLintPackageToml(t *testing.T) {
+	short := "A testing package"
+	homepage := "https://example.com"
+	pt := &models.PackageToml{
+		Package:          "testing",
+		Repository:       "sunshinekitty/testing:1.0.0",
+		ShortDescription: &short,
+		Homepage:         &homepage,
+		Volumes:          models.Volumes{{Local: "/tmp/data", Container: "/data"}},
+	}
+	if warnings := LintPackageToml(pt); len(warnings) != 0 {
+		t.Error("Complete package should have no warnings, got", warnings)
+	}
+
+	pt = &models.PackageToml{
+		Package:    "testing",
+		Repository: "myreg:5000/testing",
+		Volumes: models.Volumes{
+			{Local: "/var/run/docker.sock", Container: "/var/run/docker.sock"},
+			{Local: "/etc/", Container: "/host/etc"},
+			{Local: `C:\`, Container: "/host"},
+		},
+	}
+	fields := lintFields(LintPackageToml(pt))
+	if fields["short_description"] != 1 {
+		t.Error("Missing short description should warn")
+	}
+	if fields["homepage"] != 1 {
+		t.Error("Missing homepage should warn")
+	}
+	if fields["repository"] != 1 {
+		t.Error("Untagged repository should warn")
+	}
+	if fields["volume"] != 3 {
+		t.Error("Docker socket and broad volumes should warn")
+	}
+
+	pt.Repository = "sunshinekitty/testing:latest"
+	if lintFields(LintPackageToml(pt))["repository"] != 1 {
+		t.Error("Latest tag should warn")
+	}
+}
+
+func TestValidateManifestContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cr-validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	v := ValidateManifestContext(context.Background(), dir)
+	if v.Valid || len(v.Errors) != 1 || v.Passed(false) {
+		t.Errorf("Directory without a manifest should be invalid, got %+v", v)
+	}
+
+	manifest := filepath.Join(dir, "cr.json")
+	err = ioutil.WriteFile(manifest, []byte(`{"package": "testing", "repository": "sunshinekitty/testing"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v = ValidateManifestContext(context.Background(), dir)
+	if !v.Valid || len(v.Errors) != 0 || v.Path != manifest || v.Package != "testing" {
+		t.Errorf("Valid manifest should pass validation, got %+v", v)
+	}
+	if len(v.Warnings) == 0 {
+		t.Error("Manifest without a tag or description should have warnings")
+	}
+	if !v.Passed(false) || v.Passed(true) {
+		t.Error("Manifest with warnings should only fail strict validation")
+	}
+
+	err = ioutil.WriteFile(manifest, []byte(`{"package": "Not Valid", "repository": "sunshinekitty/testing"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v = ValidateManifestContext(context.Background(), manifest)
+	if v.Valid || len(v.Errors) != 1 {
+		t.Errorf("Invalid package name should fail validation, got %+v", v)
+	}
+}
@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LockFileName is the name of a lock file
+const LockFileName = "cr.lock"
+
+// LockedPackage represents a package pinned to an image digest
+type LockedPackage struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Repository string `json:"repository"`
+	Digest     string `json:"digest"`
+}
+
+// Lock represents pinned packages, it's kept as json
+type Lock struct {
+	Packages map[string]LockedPackage `json:"packages"`
+}
+
+// LockPath returns the location of the lock file pinning installed packages
+func LockPath() string {
+	return filepath.Join(DataDir(), LockFileName)
+}
+
+// LoadLock reads the lock file at path, a missing lock file is an empty Lock
+func LoadLock(path string) (*Lock, error) {
+	l := &Lock{Packages: make(map[string]LockedPackage)}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, l); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if l.Packages == nil {
+		l.Packages = make(map[string]LockedPackage)
+	}
+	return l, nil
+}
+
+// Save writes the lock file to path
+func (l *Lock) Save(path string) error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(b, '\n'), 0644)
+}
+
+// Locked returns every pinned package sorted by name
+func (l *Lock) Locked() []LockedPackage {
+	locked := make([]LockedPackage, 0, len(l.Packages))
+	for _, p := range l.Packages {
+		locked = append(locked, p)
+	}
+	sort.Slice(locked, func(i, j int) bool { return locked[i].Name < locked[j].Name })
+	return locked
+}
+
+// PinnedImage returns the image a package should run, its repository with the
+// pinned digest. ok is false when the package isn't pinned or was pinned for a
+// different repository, for example before it was upgraded.
+func (l *Lock) PinnedImage(packageName string, repository string) (image string, ok bool) {
+	p, found := l.Packages[packageName]
+	if !found || p.Repository != repository {
+		return repository, false
+	}
+	// A digest already in the repository is replaced by the pinned one
+	if i := strings.Index(repository, "@"); i != -1 {
+		repository = repository[:i]
+	}
+	return repository + "@" + p.Digest, true
+}
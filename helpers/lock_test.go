@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	defer tempConfigDir(t)()
+
+	l, err := LoadLock(LockPath())
+	if err != nil {
+		t.Fatal("Missing lock file should be empty, got", err)
+	}
+	l.Packages["testing"] = LockedPackage{"testing", "1.0", "sunshinekitty/testing:1.0", "sha256:4a5b6c7d8e9f4a5b6c7d8e9f4a5b6c7d"}
+	if err = l.Save(LockPath()); err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(LockPath()) != LockFileName {
+		t.Errorf("Lock should be kept in %s, got %s", LockFileName, LockPath())
+	}
+
+	loaded, err := LoadLock(LockPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Locked(), l.Locked()) {
+		t.Errorf("Lock should round trip, got %+v", loaded.Locked())
+	}
+
+	image, ok := loaded.PinnedImage("testing", "sunshinekitty/testing:1.0")
+	if !ok || image != "sunshinekitty/testing:1.0@sha256:4a5b6c7d8e9f4a5b6c7d8e9f4a5b6c7d" {
+		t.Errorf("Pinned package should run its digest, got %s", image)
+	}
+	if image, ok = loaded.PinnedImage("testing", "sunshinekitty/testing:2.0"); ok || image != "sunshinekitty/testing:2.0" {
+		t.Errorf("Pin for another repository shouldn't be used, got %s", image)
+	}
+	if _, ok = loaded.PinnedImage("other", "sunshinekitty/other:1.0"); ok {
+		t.Error("Unpinned package shouldn't be pinned")
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`)
+	expected := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull",
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("Challenge should be parsed, got %v", params)
+	}
+	if params = parseChallenge(`Basic realm="registry"`); len(params) != 0 {
+		t.Errorf("Only Bearer challenges should be parsed, got %v", params)
+	}
+}
+
+func TestRegistryAPI(t *testing.T) {
+	tests := []struct {
+		image, base, repository string
+	}{
+		{"nginx:1.13", "https://registry-1.docker.io", "library/nginx"},
+		{"sunshinekitty/testing", "https://registry-1.docker.io", "sunshinekitty/testing"},
+		{"docker.io/sunshinekitty/testing", "https://registry-1.docker.io", "sunshinekitty/testing"},
+		{"registry.example.com:5000/team/app:1.0", "https://registry.example.com:5000", "team/app"},
+		{"localhost:5000/app", "http://localhost:5000", "app"},
+	}
+	for _, test := range tests {
+		ref, err := ParseReference(test.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if base, repository := registryAPI(ref); base != test.base || repository != test.repository {
+			t.Errorf("Registry API of %s should be %s %s, got %s %s", test.image, test.base, test.repository, base, repository)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Log levels, a message is only printed when its level is at or below the
+// level set with SetLogLevel
+const (
+	LogError = iota
+	LogWarning
+	LogInfo
+	LogDebug
+)
+
+// logLevelNames are the names log messages are printed with
+var logLevelNames = map[int]string{
+	LogError:   "error",
+	LogWarning: "warning",
+	LogInfo:    "info",
+	LogDebug:   "debug",
+}
+
+var (
+	logMu    sync.Mutex
+	logLevel = LogInfo
+	logJSON  bool
+	// logStdout is where info messages are written as text, they're the output
+	// of commands such as "Installed testing"
+	logStdout io.Writer = os.Stdout
+	// logStderr is where every other message, and every message as json, is written
+	logStderr io.Writer = os.Stderr
+)
+
+// logEntry represents a log message written as json
+type logEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// SetLogLevel sets the most verbose level of message printed
+func SetLogLevel(level int) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	logLevel = level
+}
+
+// SetLogJSON writes every log message to stderr as a line of json when enabled
+func SetLogJSON(enabled bool) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	logJSON = enabled
+}
+
+// LogEnabled returns true when messages of level are printed
+func LogEnabled(level int) bool {
+	logMu.Lock()
+	defer logMu.Unlock()
+	return level <= logLevel
+}
+
+// Debugf logs details only wanted with --verbose, such as commands run
+func Debugf(format string, args ...interface{}) {
+	logf(LogDebug, format, args...)
+}
+
+// Infof logs the outcome of a command, suppressed by --quiet
+func Infof(format string, args ...interface{}) {
+	logf(LogInfo, format, args...)
+}
+
+// Warnf logs a problem that doesn't stop a command
+func Warnf(format string, args ...interface{}) {
+	logf(LogWarning, format, args...)
+}
+
+// Error logs an error, errors are always printed
+func Error(message string) {
+	logf(LogError, "%s", message)
+}
+
+// logf writes a message at level if it's enabled
+func logf(level int, format string, args ...interface{}) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	if level > logLevel {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+
+	if logJSON {
+		b, err := json.Marshal(logEntry{time.Now().UTC().Format(time.RFC3339Nano), logLevelNames[level], message})
+		if err != nil {
+			return
+		}
+		fmt.Fprintln(logStderr, string(b))
+		return
+	}
+	switch level {
+	case LogInfo, LogError:
+		// Errors have always been printed to stdout, scripts may rely on it
+		fmt.Fprintln(logStdout, message)
+	default:
+		fmt.Fprintf(logStderr, "%s: %s\n", logLevelNames[level], message)
+	}
+}
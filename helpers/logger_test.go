@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// captureLogs sends log messages to buffers, the returned func restores the
+// log settings
+func captureLogs() (*bytes.Buffer, *bytes.Buffer, func()) {
+	var stdout, stderr bytes.Buffer
+	oldStdout, oldStderr := logStdout, logStderr
+	logStdout, logStderr = &stdout, &stderr
+	return &stdout, &stderr, func() {
+		logStdout, logStderr = oldStdout, oldStderr
+		SetLogLevel(LogInfo)
+		SetLogJSON(false)
+	}
+}
+
+func TestLogLevels(t *testing.T) {
+	stdout, stderr, restore := captureLogs()
+	defer restore()
+
+	Debugf("running %s", "docker")
+	Infof("Installed %s", "testing")
+	Warnf("%s isn't on your PATH", "bin")
+	if stdout.String() != "Installed testing\n" {
+		t.Errorf("Info should be printed to stdout, got %q", stdout.String())
+	}
+	if stderr.String() != "warning: bin isn't on your PATH\n" {
+		t.Errorf("Only warnings should be printed to stderr by default, got %q", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	SetLogLevel(LogError)
+	Infof("Installed %s", "testing")
+	Warnf("%s isn't on your PATH", "bin")
+	Error("Invalid package")
+	if stdout.String() != "Invalid package\n" || stderr.Len() != 0 {
+		t.Errorf("Only errors should be printed when quiet, got %q and %q", stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	SetLogLevel(LogDebug)
+	Debugf("running %s", "docker")
+	if !strings.Contains(stderr.String(), "debug: running docker") {
+		t.Errorf("Debug should be printed when verbose, got %q", stderr.String())
+	}
+}
+
+func TestLogJSON(t *testing.T) {
+	stdout, stderr, restore := captureLogs()
+	defer restore()
+
+	SetLogJSON(true)
+	Infof("Installed %s", "testing")
+	if stdout.Len() != 0 {
+		t.Errorf("JSON logs should only be written to stderr, got %q", stdout.String())
+	}
+	var entry logEntry
+	if err := json.Unmarshal(stderr.Bytes(), &entry); err != nil {
+		t.Fatalf("Log should be a line of json, got %q", stderr.String())
+	}
+	if entry.Level != "info" || entry.Message != "Installed testing" || entry.Time == "" {
+		t.Errorf("Log entry should have the time, level and message, got %+v", entry)
+	}
+}
@@ -0,0 +1,233 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// FormatTOML is the toml package manifest format
+	FormatTOML = "toml"
+	// FormatYAML is the yaml package manifest format
+	FormatYAML = "yaml"
+	// FormatJSON is the json package manifest format
+	FormatJSON = "json"
+)
+
+var (
+	// ManifestFileNames are the manifest names looked for in a directory, in order
+	ManifestFileNames = []string{"cr.toml", "cr.yaml", "cr.yml", "cr.json", "package.toml"}
+
+	// ErrManifestNotFound is thrown when a directory doesn't contain a manifest
+	ErrManifestNotFound = errors.New("no package manifest found (cr.toml, cr.yaml, cr.yml, cr.json or package.toml)")
+
+	tomlKey = regexp.MustCompile(`^[A-Za-z0-9_-]+\s*=`)
+	yamlKey = regexp.MustCompile(`^[A-Za-z0-9_-]+\s*:`)
+	utf8BOM = []byte("\xef\xbb\xbf")
+)
+
+// canonicalOmit holds Package fields managed by the registry rather than the
+// manifest, they change without the package changing so aren't canonical
+var canonicalOmit = []string{"CreatedAt", "Deprecated", "Pulls", "Signature", "SigningKey", "UpdatedAt", "Yanked"}
+
+// FormatFromPath returns the manifest format for a path based on its extension,
+// falling back to toml when the extension isn't recognized
+func FormatFromPath(path string) string {
+	if format := formatFromExt(path); format != "" {
+		return format
+	}
+	return FormatTOML
+}
+
+// DetectFormat returns the manifest format for a path and its contents, the
+// extension wins when it's recognized otherwise the contents are sniffed
+func DetectFormat(path string, b []byte) string {
+	if format := formatFromExt(path); format != "" {
+		return format
+	}
+	return SniffFormat(b)
+}
+
+// SniffFormat guesses the manifest format from its contents by looking at the
+// first line that isn't blank or a comment, falling back to toml
+func SniffFormat(b []byte) string {
+	for _, line := range strings.Split(string(bytes.TrimPrefix(b, utf8BOM)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "{"):
+			return FormatJSON
+		case strings.HasPrefix(line, "["), tomlKey.MatchString(line):
+			return FormatTOML
+		case line == "---", strings.HasPrefix(line, "- "), yamlKey.MatchString(line):
+			return FormatYAML
+		}
+		return FormatTOML
+	}
+	return FormatTOML
+}
+
+// ManifestPath resolves path to a manifest file, when path is a directory the
+// first of ManifestFileNames found in it is returned
+func ManifestPath(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range ManifestFileNames {
+		p := filepath.Join(path, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", ErrManifestNotFound
+}
+
+// LoadPackageToml loads a manifest of any supported format from a file or a
+// directory containing one and translates to PackageToml struct
+//
+// Deprecated: use LoadPackageTomlContext, this will be removed in the next release.
+func LoadPackageToml(path string) (*models.PackageToml, error) {
+	return LoadPackageTomlContext(context.Background(), path)
+}
+
+// LoadPackageTomlContext loads a manifest of any supported format from a file or a
+// directory containing one and translates to PackageToml struct
+func LoadPackageTomlContext(ctx context.Context, path string) (*models.PackageToml, error) {
+	p, err := ManifestPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return ConfigFileToPackageTomlContext(ctx, p)
+}
+
+func formatFromExt(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return FormatTOML
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	default:
+		return ""
+	}
+}
+
+// DecodePackageToml reads a manifest of the given format from r and translates to
+// PackageToml struct, every format produces the same struct and is validated the same way
+func DecodePackageToml(r io.Reader, format string) (*models.PackageToml, error) {
+	var returnPackageToml models.PackageToml
+	switch format {
+	case FormatTOML:
+		_, err := toml.DecodeReader(r, &returnPackageToml)
+		return &returnPackageToml, err
+	case FormatYAML:
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.Unmarshal(b, &returnPackageToml)
+		return &returnPackageToml, err
+	case FormatJSON:
+		err := json.NewDecoder(r).Decode(&returnPackageToml)
+		return &returnPackageToml, err
+	default:
+		return nil, fmt.Errorf("unknown manifest format \"%s\"", format)
+	}
+}
+
+// EncodePackageToml writes pt to w as toml. Output is deterministic, keys are
+// written in PackageToml field order followed by the port and volume tables so
+// decoding and re-encoding a manifest gives back the same bytes.
+func EncodePackageToml(w io.Writer, pt *models.PackageToml) error {
+	enc := toml.NewEncoder(w)
+	enc.Indent = ""
+	return enc.Encode(pt)
+}
+
+// WritePackageTomlFile writes pt as toml to the file at path, truncating it if it exists
+func WritePackageTomlFile(path string, pt *models.PackageToml) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = EncodePackageToml(f, pt); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ConfigJSONToPackageToml reads a json config from r and translates to PackageToml struct
+func ConfigJSONToPackageToml(r io.Reader) (*models.PackageToml, error) {
+	return DecodePackageToml(r, FormatJSON)
+}
+
+// CanonicalPackageJSON serializes a Package to a stable byte representation:
+// keys are sorted, there is no insignificant whitespace, ports and volumes are
+// normalized and registry managed fields (created/updated at, pulls) are left out.
+// Two packages describing the same manifest always serialize to the same bytes.
+func CanonicalPackageJSON(p *models.Package) ([]byte, error) {
+	return canonicalJSON(p, canonicalOmit)
+}
+
+// canonicalJSON serializes a Package like CanonicalPackageJSON, leaving out
+// the omit fields
+func canonicalJSON(p *models.Package, omit []string) ([]byte, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err = dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	for _, k := range omit {
+		delete(fields, k)
+	}
+
+	// Ports, volumes, env and keywords are stored as raw json so may differ in key case,
+	// order and spacing, round trip them through their models
+	if fields["Ports"], err = packagePorts(p); err != nil {
+		return nil, err
+	}
+	if fields["Volumes"], err = packageVolumes(p); err != nil {
+		return nil, err
+	}
+	if fields["Env"], err = packageEnv(p); err != nil {
+		return nil, err
+	}
+	if fields["Keywords"], err = packageKeywords(p); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err = enc.Encode(fields); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,227 @@
+package helpers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx/types"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	if FormatFromPath("cr.yaml") != FormatYAML {
+		t.Error("Format of \"cr.yaml\" should be yaml")
+	}
+	if FormatFromPath("config/CR.YML") != FormatYAML {
+		t.Error("Format of \"config/CR.YML\" should be yaml")
+	}
+	if FormatFromPath("package.toml") != FormatTOML {
+		t.Error("Format of \"package.toml\" should be toml")
+	}
+	if FormatFromPath("package") != FormatTOML {
+		t.Error("Format of \"package\" should be toml")
+	}
+}
+
+func TestConfigYAMLToPackageToml(t *testing.T) {
+	config := `package: testing
+repository: sunshinekitty/testing:latest
+command_start: start.sh
+port:
+  - local: "8080"
+    container: "80"
+volume:
+  - local: /tmp
+    container: /docker/path
+`
+	pt, err := ConfigYAMLToPackageToml(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt.Package != "testing" {
+		t.Error("Package should be \"testing\", got", pt.Package)
+	}
+	if pt.CommandStart == nil || *pt.CommandStart != "start.sh" {
+		t.Error("Command start should be \"start.sh\", got", pt.CommandStart)
+	}
+	if len(pt.Ports) != 1 || pt.Ports[0].Local != "8080" || pt.Ports[0].Container != "80" {
+		t.Error("Ports should be [8080:80], got", pt.Ports)
+	}
+	if len(pt.Volumes) != 1 || pt.Volumes[0].Local != "/tmp" || pt.Volumes[0].Container != "/docker/path" {
+		t.Error("Volumes should be [/tmp:/docker/path], got", pt.Volumes)
+	}
+	if err = ValidPackageToml(pt); err != nil {
+		t.Error("YAML config should be valid, got", err)
+	}
+
+	if _, err = DecodePackageToml(strings.NewReader(config), "xml"); err == nil {
+		t.Error("Unknown format should return an error")
+	}
+}
+
+func TestConfigJSONToPackageToml(t *testing.T) {
+	config := `{
+	"package": "testing",
+	"repository": "sunshinekitty/testing:latest",
+	"short_description": "A testing package",
+	"port": [{"local": "8080", "container": "80"}]
+}`
+	pt, err := ConfigJSONToPackageToml(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt.Package != "testing" {
+		t.Error("Package should be \"testing\", got", pt.Package)
+	}
+	if pt.ShortDescription == nil || *pt.ShortDescription != "A testing package" {
+		t.Error("Short description should be \"A testing package\", got", pt.ShortDescription)
+	}
+	if len(pt.Ports) != 1 || pt.Ports[0].Local != "8080" || pt.Ports[0].Container != "80" {
+		t.Error("Ports should be [8080:80], got", pt.Ports)
+	}
+	if err = ValidPackageToml(pt); err != nil {
+		t.Error("JSON config should be valid, got", err)
+	}
+}
+
+func TestCanonicalPackageJSON(t *testing.T) {
+	homepage := "https://example.com/?a=1&b=2"
+	portsA := types.JSONText(`[{"Local":"8080","Container":"80"}]`)
+	portsB := types.JSONText(`[ {"container": "80", "local": "8080"} ]`)
+	a := &models.Package{
+		Name:       "testing",
+		Repository: "sunshinekitty/testing",
+		Version:    "latest",
+		Homepage:   &homepage,
+		Ports:      &portsA,
+		Pulls:      10,
+		CreatedAt:  "2017-01-01",
+	}
+	b := &models.Package{
+		Name:       "testing",
+		Repository: "sunshinekitty/testing",
+		Version:    "latest",
+		Homepage:   &homepage,
+		Ports:      &portsB,
+		Pulls:      20,
+		CreatedAt:  "2017-06-01",
+	}
+	aJSON, err := CanonicalPackageJSON(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bJSON, err := CanonicalPackageJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(aJSON) != string(bJSON) {
+		t.Errorf("Canonical json should match:\n%s\n%s", aJSON, bJSON)
+	}
+	if strings.Contains(string(aJSON), "Pulls") || strings.Contains(string(aJSON), "CreatedAt") {
+		t.Error("Canonical json should not contain registry managed fields, got", string(aJSON))
+	}
+	if strings.ContainsAny(string(aJSON), " \n") {
+		t.Error("Canonical json should not contain whitespace, got", string(aJSON))
+	}
+	if !strings.Contains(string(aJSON), `"Homepage":"https://example.com/?a=1&b=2"`) {
+		t.Error("Canonical json should not escape html, got", string(aJSON))
+	}
+	if !strings.Contains(string(aJSON), `"Ports":[{"local":"8080","container":"80"}]`) {
+		t.Error("Canonical json should normalize ports, got", string(aJSON))
+	}
+}
+
+func TestSniffFormat(t *testing.T) {
+	if SniffFormat([]byte("  {\"package\": \"testing\"}")) != FormatJSON {
+		t.Error("Content starting with \"{\" should sniff as json")
+	}
+	if SniffFormat([]byte("# comment\n\npackage = \"testing\"")) != FormatTOML {
+		t.Error("Content with key = value should sniff as toml")
+	}
+	if SniffFormat([]byte("[[port]]\nlocal = \"80\"")) != FormatTOML {
+		t.Error("Content starting with a table should sniff as toml")
+	}
+	if SniffFormat([]byte("# comment\npackage: testing")) != FormatYAML {
+		t.Error("Content with key: value should sniff as yaml")
+	}
+	if SniffFormat([]byte("---\npackage: testing")) != FormatYAML {
+		t.Error("Content starting with a document marker should sniff as yaml")
+	}
+	if SniffFormat([]byte("")) != FormatTOML {
+		t.Error("Empty content should fall back to toml")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	if DetectFormat("cr.yaml", []byte("{}")) != FormatYAML {
+		t.Error("Recognized extension should win over contents")
+	}
+	if DetectFormat("manifest", []byte("{}")) != FormatJSON {
+		t.Error("Unrecognized extension should sniff contents")
+	}
+}
+
+func TestManifestPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err = ManifestPath(dir); err != ErrManifestNotFound {
+		t.Error("Empty directory should return ErrManifestNotFound, got", err)
+	}
+
+	for _, name := range []string{"package.toml", "cr.yml"} {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := ManifestPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != filepath.Join(dir, "cr.yml") {
+		t.Error("Manifest path should be cr.yml, got", p)
+	}
+	p, err = ManifestPath(filepath.Join(dir, "package.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != filepath.Join(dir, "package.toml") {
+		t.Error("Manifest path of a file should be itself, got", p)
+	}
+}
+
+func TestEncodePackageTomlRoundTrip(t *testing.T) {
+	pt, err := ConfigFileToPackageToml("../config/package-example.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var first bytes.Buffer
+	if err = EncodePackageToml(&first, pt); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ConfigBytesToPackageToml(first.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pt, decoded) {
+		t.Errorf("Decoded manifest should equal original:\n%+v\n%+v", pt, decoded)
+	}
+
+	var second bytes.Buffer
+	if err = EncodePackageToml(&second, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("Encoding should be deterministic:\n%s\n%s", first.String(), second.String())
+	}
+}
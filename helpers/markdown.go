@@ -0,0 +1,267 @@
+package helpers
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// ANSI escape codes RenderMarkdown styles text with
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiUnderline = "\x1b[4m"
+	ansiCyan      = "\x1b[36m"
+)
+
+var (
+	mdHeading  = match(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdBullet   = match(`^(\s*)[-*+]\s+(.*)$`)
+	mdQuote    = match(`^>\s?(.*)$`)
+	mdRule     = match(`^\s*((-\s*){3,}|(\*\s*){3,}|(_\s*){3,})$`)
+	mdFence    = match("^\\s*(```|~~~)")
+	mdImage    = match(`!\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	mdLink     = match(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	mdBold     = match(`(\*\*|__)([^*_]+)(\*\*|__)`)
+	mdCodeSpan = match("`([^`]+)`")
+)
+
+// RenderMarkdown renders Markdown such as a package's long description for a
+// terminal. Headings, lists, quotes, code and links are laid out as plain text,
+// color adds ANSI bold, underline and colors.
+func RenderMarkdown(md string, color bool) string {
+	style := func(s string, codes ...string) string {
+		return ansiStyle(color, s, codes...)
+	}
+
+	var out []string
+	fenced := false
+	for _, line := range strings.Split(strings.Replace(md, "\r\n", "\n", -1), "\n") {
+		if mdFence.MatchString(line) {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			out = append(out, "    "+style(line, ansiDim))
+			continue
+		}
+
+		switch {
+		case mdHeading.MatchString(line):
+			m := mdHeading.FindStringSubmatch(line)
+			text := renderMarkdownInline(m[2], color)
+			switch {
+			case color && len(m[1]) == 1:
+				out = append(out, style(text, ansiBold, ansiUnderline))
+			case color:
+				out = append(out, style(text, ansiBold))
+			case len(m[1]) <= 2:
+				// Without color the first two levels are underlined the way
+				// they can be written in Markdown
+				underline := "="
+				if len(m[1]) == 2 {
+					underline = "-"
+				}
+				out = append(out, text, strings.Repeat(underline, len([]rune(text))))
+			default:
+				out = append(out, text)
+			}
+		case mdRule.MatchString(line):
+			out = append(out, style(strings.Repeat("─", 40), ansiDim))
+		case mdBullet.MatchString(line):
+			m := mdBullet.FindStringSubmatch(line)
+			out = append(out, m[1]+"  • "+renderMarkdownInline(m[2], color))
+		case mdQuote.MatchString(line):
+			m := mdQuote.FindStringSubmatch(line)
+			out = append(out, "  │ "+style(renderMarkdownInline(m[1], color), ansiDim))
+		default:
+			out = append(out, renderMarkdownInline(line, color))
+		}
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), "\n") + "\n"
+}
+
+// renderMarkdownInline renders the images, links, bold text and code spans of a
+// single line of Markdown
+func renderMarkdownInline(line string, color bool) string {
+	style := func(s string, codes ...string) string {
+		return ansiStyle(color, s, codes...)
+	}
+
+	// Code spans are set aside first so nothing inside them is rendered
+	var spans []string
+	line = mdCodeSpan.ReplaceAllStringFunc(line, func(s string) string {
+		spans = append(spans, mdCodeSpan.FindStringSubmatch(s)[1])
+		return "\x00"
+	})
+
+	line = mdImage.ReplaceAllStringFunc(line, func(s string) string {
+		m := mdImage.FindStringSubmatch(s)
+		if m[1] == "" {
+			return "[image: " + m[2] + "]"
+		}
+		return "[image: " + m[1] + "]"
+	})
+	line = mdLink.ReplaceAllStringFunc(line, func(s string) string {
+		m := mdLink.FindStringSubmatch(s)
+		if m[1] == m[2] {
+			return style(m[2], ansiUnderline)
+		}
+		return m[1] + " (" + style(m[2], ansiUnderline) + ")"
+	})
+	line = mdBold.ReplaceAllStringFunc(line, func(s string) string {
+		return style(mdBold.FindStringSubmatch(s)[2], ansiBold)
+	})
+
+	for _, span := range spans {
+		if color {
+			span = style(span, ansiCyan)
+		} else {
+			span = "`" + span + "`"
+		}
+		line = strings.Replace(line, "\x00", span, 1)
+	}
+	return line
+}
+
+// RenderMarkdownHTML renders Markdown such as a package's long description as
+// HTML that's safe to put in a page. The elements RenderMarkdown lays out are
+// rendered, anything else including HTML in the Markdown is escaped, and only
+// http, https and mailto links and images are kept.
+func RenderMarkdownHTML(md string) string {
+	var b strings.Builder
+	// block is the element lines are being added to, empty between blocks
+	block, first := "", true
+	setBlock := func(tag string) {
+		if block == tag {
+			return
+		}
+		if block != "" {
+			b.WriteString("</" + block + ">\n")
+		}
+		if tag != "" {
+			b.WriteString("<" + tag + ">")
+		}
+		block, first = tag, true
+	}
+	addLine := func(tag string, line string) {
+		setBlock(tag)
+		if !first {
+			b.WriteString("\n")
+		}
+		b.WriteString(line)
+		first = false
+	}
+
+	fenced := false
+	for _, line := range strings.Split(strings.Replace(md, "\r\n", "\n", -1), "\n") {
+		if mdFence.MatchString(line) {
+			if fenced {
+				b.WriteString("</code></pre>\n")
+			} else {
+				setBlock("")
+				b.WriteString("<pre><code>")
+			}
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			b.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			setBlock("")
+		case mdHeading.MatchString(line):
+			m := mdHeading.FindStringSubmatch(line)
+			setBlock("")
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", len(m[1]), renderMarkdownInlineHTML(m[2]), len(m[1]))
+		case mdRule.MatchString(line):
+			setBlock("")
+			b.WriteString("<hr>\n")
+		case mdBullet.MatchString(line):
+			setBlock("ul")
+			b.WriteString("<li>" + renderMarkdownInlineHTML(mdBullet.FindStringSubmatch(line)[2]) + "</li>")
+		case mdQuote.MatchString(line):
+			addLine("blockquote", renderMarkdownInlineHTML(mdQuote.FindStringSubmatch(line)[1]))
+		default:
+			addLine("p", renderMarkdownInlineHTML(strings.TrimSpace(line)))
+		}
+	}
+	if fenced {
+		b.WriteString("</code></pre>\n")
+	}
+	setBlock("")
+	return b.String()
+}
+
+// renderMarkdownInlineHTML renders the images, links, bold text and code spans
+// of a single line of Markdown as HTML, escaping everything else
+func renderMarkdownInlineHTML(line string) string {
+	// Rendered elements are set aside so nothing inside them is rendered again
+	var held []string
+	hold := func(s string) string {
+		held = append(held, s)
+		return fmt.Sprintf("\x00%d\x00", len(held)-1)
+	}
+
+	line = mdCodeSpan.ReplaceAllStringFunc(strings.Replace(line, "\x00", "", -1), func(s string) string {
+		return hold("<code>" + html.EscapeString(mdCodeSpan.FindStringSubmatch(s)[1]) + "</code>")
+	})
+	line = html.EscapeString(line)
+	line = mdImage.ReplaceAllStringFunc(line, func(s string) string {
+		m := mdImage.FindStringSubmatch(s)
+		src, ok := safeMarkdownURL(m[2])
+		if !ok {
+			return m[1]
+		}
+		return hold(`<img src="` + src + `" alt="` + m[1] + `">`)
+	})
+	line = mdLink.ReplaceAllStringFunc(line, func(s string) string {
+		m := mdLink.FindStringSubmatch(s)
+		text := mdBold.ReplaceAllString(m[1], "<strong>$2</strong>")
+		href, ok := safeMarkdownURL(m[2])
+		if !ok {
+			return text
+		}
+		return hold(`<a href="` + href + `" rel="nofollow">` + text + `</a>`)
+	})
+	line = mdBold.ReplaceAllString(line, "<strong>$2</strong>")
+
+	for i := len(held) - 1; i >= 0; i-- {
+		line = strings.Replace(line, fmt.Sprintf("\x00%d\x00", i), held[i], 1)
+	}
+	return line
+}
+
+// safeMarkdownURL returns an escaped link from rendered Markdown ready to put
+// in an attribute, ok is false for anything but an absolute http, https or
+// mailto URL
+func safeMarkdownURL(escaped string) (string, bool) {
+	raw := html.UnescapeString(escaped)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", false
+		}
+	case "mailto":
+	default:
+		return "", false
+	}
+	return html.EscapeString(raw), true
+}
+
+// ansiStyle wraps s in ANSI codes when color is true
+func ansiStyle(color bool, s string, codes ...string) string {
+	if !color || s == "" {
+		return s
+	}
+	return strings.Join(codes, "") + s + ansiReset
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	md := "# Testing\n\nRun **it** with `cr run testing`, see [the docs](https://crackle.pm/docs).\n\n- one\n  * two\n> quoted\n\n```\n# not a heading\n```\n---\n![logo](https://crackle.pm/logo.png)"
+	want := "Testing\n=======\n\nRun it with `cr run testing`, see the docs (https://crackle.pm/docs).\n\n  • one\n    • two\n  │ quoted\n\n    # not a heading\n" +
+		strings.Repeat("─", 40) + "\n[image: logo]\n"
+	if got := RenderMarkdown(md, false); got != want {
+		t.Errorf("Markdown should render as\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestRenderMarkdownColor(t *testing.T) {
+	got := RenderMarkdown("## Usage\n**bold** `code`", true)
+	want := ansiBold + "Usage" + ansiReset + "\n" + ansiBold + "bold" + ansiReset + " " + ansiCyan + "code" + ansiReset + "\n"
+	if got != want {
+		t.Errorf("Markdown should render with color as %q, got %q", want, got)
+	}
+}
+
+func TestRenderMarkdownCodeSpan(t *testing.T) {
+	got := RenderMarkdown("`**not bold**` and [https://crackle.pm](https://crackle.pm)", false)
+	want := "`**not bold**` and https://crackle.pm\n"
+	if got != want {
+		t.Errorf("Code spans shouldn't be rendered, want %q got %q", want, got)
+	}
+}
+
+func TestRenderMarkdownHTML(t *testing.T) {
+	md := "# Testing <b>\n\nRun **it** with `<cr run>`,\nsee [the **docs**](https://crackle.pm/docs?a=1&b=2).\n\n- one\n- two\n> quoted\n\n```\n<script>\n```\n---\n![logo](https://crackle.pm/logo.png)"
+	want := "<h1>Testing &lt;b&gt;</h1>\n<p>Run <strong>it</strong> with <code>&lt;cr run&gt;</code>,\n" +
+		"see <a href=\"https://crackle.pm/docs?a=1&amp;b=2\" rel=\"nofollow\">the <strong>docs</strong></a>.</p>\n" +
+		"<ul><li>one</li><li>two</li></ul>\n<blockquote>quoted</blockquote>\n<pre><code>&lt;script&gt;\n</code></pre>\n<hr>\n" +
+		"<p><img src=\"https://crackle.pm/logo.png\" alt=\"logo\"></p>\n"
+	if got := RenderMarkdownHTML(md); got != want {
+		t.Errorf("Markdown should render as\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestRenderMarkdownHTMLUnsafe(t *testing.T) {
+	got := RenderMarkdownHTML(`[click](javascript:void) ![x](data:image/png;base64,AA) [up](/etc/passwd) <img src=x onerror="alert(1)">`)
+	want := "<p>click x up &lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>\n"
+	if got != want {
+		t.Errorf("Unsafe Markdown should render as %q, got %q", want, got)
+	}
+}
+
+func TestPackagePageURL(t *testing.T) {
+	if u := PackagePageURL("https://crackle.pm/", "testing"); u != "https://crackle.pm/package/testing" {
+		t.Error("Package page should be under /package/, got", u)
+	}
+	if u := PackagePageURL("https://crackle.pm", "testing"); u != "https://crackle.pm/package/testing" {
+		t.Error("Website without a trailing slash should still join, got", u)
+	}
+}
+
+func TestOpenURLContextInvalid(t *testing.T) {
+	if err := OpenURLContext(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Error("Only http(s) URLs should be opened")
+	}
+}
@@ -0,0 +1,206 @@
+package helpers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MetricsContentType is the content type of the Prometheus text format
+// WriteMetrics writes
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// LatencyBuckets are the upper bounds in seconds request latencies are counted in
+var LatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metricsEscaper escapes label values and help text the way Prometheus reads them
+var metricsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Metric is a family of samples written in the Prometheus text format
+type Metric interface {
+	writeMetric(w *bufio.Writer)
+}
+
+// CounterVec counts events by the values of its labels, such as requests by
+// route and status. It's safe to use from several goroutines.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec returns a CounterVec with nothing counted. Without labels it
+// starts at 0, rather than missing until the first event.
+func NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+	if len(labels) == 0 {
+		c.values[""] = 0
+	}
+	return c
+}
+
+// Inc counts an event with labelValues, given in the order of the labels
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[metricLabels(c.labels, labelValues)]++
+}
+
+func (c *CounterVec) writeMetric(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writeMetricHeader(w, c.name, c.help, "counter")
+	for _, labels := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labels, formatMetricValue(c.values[labels]))
+	}
+}
+
+// histogram counts the observations of one set of label values
+type histogram struct {
+	values  []string
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+// HistogramVec counts observations such as latencies in buckets by the
+// values of its labels. It's safe to use from several goroutines.
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu         sync.Mutex
+	histograms map[string]*histogram
+}
+
+// NewHistogramVec returns a HistogramVec counting observations in buckets,
+// which are sorted upper bounds
+func NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	return &HistogramVec{name: name, help: help, labels: labels, buckets: buckets, histograms: make(map[string]*histogram)}
+}
+
+// Observe counts v with labelValues, given in the order of the labels
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := metricLabels(h.labels, labelValues)
+	hist, ok := h.histograms[key]
+	if !ok {
+		hist = &histogram{values: labelValues, buckets: make([]uint64, len(h.buckets))}
+		h.histograms[key] = hist
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hist.buckets[i]++
+		}
+	}
+	hist.sum += v
+	hist.count++
+}
+
+func (h *HistogramVec) writeMetric(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	writeMetricHeader(w, h.name, h.help, "histogram")
+	keys := make([]string, 0, len(h.histograms))
+	for k := range h.histograms {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	labels := append(append([]string{}, h.labels...), "le")
+	for _, key := range keys {
+		hist := h.histograms[key]
+		values := make([]string, len(h.labels), len(labels))
+		copy(values, hist.values)
+		for i, upper := range h.buckets {
+			le := metricLabels(labels, append(values, formatMetricValue(upper)))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, le, hist.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, metricLabels(labels, append(values, "+Inf")), hist.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, key, formatMetricValue(hist.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, key, hist.count)
+	}
+}
+
+// GaugeFunc is a single value read whenever metrics are written, such as the
+// connections open to the database
+type GaugeFunc struct {
+	Name  string
+	Help  string
+	Value func() float64
+}
+
+func (g GaugeFunc) writeMetric(w *bufio.Writer) {
+	writeMetricHeader(w, g.Name, g.Help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.Name, formatMetricValue(g.Value()))
+}
+
+// CounterFunc is a single count read whenever metrics are written, such as
+// the connections to the database waited for
+type CounterFunc GaugeFunc
+
+func (c CounterFunc) writeMetric(w *bufio.Writer) {
+	writeMetricHeader(w, c.Name, c.Help, "counter")
+	fmt.Fprintf(w, "%s %s\n", c.Name, formatMetricValue(c.Value()))
+}
+
+// WriteMetrics writes metrics to w in the Prometheus text format
+func WriteMetrics(w io.Writer, metrics ...Metric) error {
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.writeMetric(bw)
+	}
+	return bw.Flush()
+}
+
+func writeMetricHeader(w *bufio.Writer, name string, help string, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, metricsEscaper.Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// metricLabels renders labels with their values as {name="value",...}, the
+// form samples are keyed by. Missing values are empty.
+func metricLabels(labels []string, values []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(labels))
+	for i, l := range labels {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		pairs[i] = fmt.Sprintf(`%s="%s"`, l, metricsEscaper.Replace(v))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatMetricValue formats a sample's value the way Prometheus reads it
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	requests := NewCounterVec("requests_total", "Requests answered.", "route", "status")
+	requests.Inc("/api/package/:name", "200")
+	requests.Inc("/api/package/:name", "200")
+	requests.Inc("/api/search", "429")
+	duration := NewHistogramVec("request_duration_seconds", "Time taken.", []float64{.1, 1}, "route")
+	duration.Observe(.05, "/api/search")
+	duration.Observe(.5, "/api/search")
+	open := GaugeFunc{Name: "connections_open", Help: "Open \"connections\".", Value: func() float64 { return 3 }}
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, requests, duration, open); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP requests_total Requests answered.
+# TYPE requests_total counter
+requests_total{route="/api/package/:name",status="200"} 2
+requests_total{route="/api/search",status="429"} 1
+# HELP request_duration_seconds Time taken.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{route="/api/search",le="0.1"} 1
+request_duration_seconds_bucket{route="/api/search",le="1"} 2
+request_duration_seconds_bucket{route="/api/search",le="+Inf"} 2
+request_duration_seconds_sum{route="/api/search"} 0.55
+request_duration_seconds_count{route="/api/search"} 2
+# HELP connections_open Open \"connections\".
+# TYPE connections_open gauge
+connections_open 3
+`
+	if buf.String() != expected {
+		t.Errorf("Metrics should be\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestCounterVecWithoutLabels(t *testing.T) {
+	publishes := NewCounterVec("publishes_total", "Versions published.")
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, publishes); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("\npublishes_total 0\n")) {
+		t.Errorf("publishes_total should start at 0, got\n%s", buf.String())
+	}
+	publishes.Inc()
+	buf.Reset()
+	WriteMetrics(&buf, publishes)
+	if !bytes.Contains(buf.Bytes(), []byte("\npublishes_total 1\n")) {
+		t.Errorf("publishes_total should be 1, got\n%s", buf.String())
+	}
+}
@@ -0,0 +1,142 @@
+package helpers
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration is a versioned change to the registry's schema, read from a pair of
+// <version>_<name>.up and .down files
+type Migration struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	UpSQL   string `json:"-"`
+	DownSQL string `json:"-"`
+}
+
+// MigrationStep is a migration run up or down, taking the schema from version
+// Before to After
+type MigrationStep struct {
+	Migration
+	Up     bool  `json:"up"`
+	Before int64 `json:"before"`
+	After  int64 `json:"after"`
+}
+
+// ParseMigrations reads the migrations in the root of fsys, oldest first. Every
+// migration needs both an up and a down file.
+func ParseMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		base, direction := splitMigrationFile(e.Name())
+		parts := strings.SplitN(base, "_", 2)
+		version, err := strconv.ParseInt(parts[0], 10, 64)
+		if direction == "" || len(parts) != 2 || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s isn't named <version>_<name>.up or .down", e.Name())
+		}
+		b, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		} else if m.Name != parts[1] {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", m.Name, parts[1], version)
+		}
+		if direction == "up" {
+			m.UpSQL = string(b)
+		} else {
+			m.DownSQL = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" || m.DownSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitMigrationFile splits a migration file name from its direction, which is
+// empty when it's neither up nor down
+func splitMigrationFile(name string) (base string, direction string) {
+	ext := path.Ext(name)
+	switch ext {
+	case ".up", ".down":
+		return strings.TrimSuffix(name, ext), ext[1:]
+	}
+	return name, ""
+}
+
+// LatestMigration returns the version of the newest migration, 0 without any
+func LatestMigration(migrations []Migration) int64 {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// MigrationPlan returns the steps taking a schema from version from to version
+// to, up migrations oldest first and down migrations newest first. Version 0
+// is a schema with no migrations, any other has to be one of migrations.
+func MigrationPlan(migrations []Migration, from int64, to int64) ([]MigrationStep, error) {
+	index := func(version int64) (int, error) {
+		if version == 0 {
+			return -1, nil
+		}
+		for i, m := range migrations {
+			if m.Version == version {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("there is no migration %d", version)
+	}
+	i, err := index(from)
+	if err != nil {
+		if from > LatestMigration(migrations) {
+			return nil, fmt.Errorf("the schema is at version %d, newer than this cr knows, upgrade cr", from)
+		}
+		return nil, fmt.Errorf("the schema is at version %d but %s", from, err)
+	}
+	j, err := index(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []MigrationStep
+	for ; i < j; i++ {
+		m := migrations[i+1]
+		steps = append(steps, MigrationStep{Migration: m, Up: true, Before: versionAt(migrations, i), After: m.Version})
+	}
+	for ; i > j; i-- {
+		m := migrations[i]
+		steps = append(steps, MigrationStep{Migration: m, Before: m.Version, After: versionAt(migrations, i-1)})
+	}
+	return steps, nil
+}
+
+// versionAt returns the version of migrations[i], 0 before the first
+func versionAt(migrations []Migration, i int) int64 {
+	if i < 0 {
+		return 0
+	}
+	return migrations[i].Version
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func testMigrations(t *testing.T) []Migration {
+	fsys := fstest.MapFS{
+		"30_add_c.up":   {Data: []byte("ALTER TABLE a ADD COLUMN c int;")},
+		"30_add_c.down": {Data: []byte("ALTER TABLE a DROP COLUMN c;")},
+		"10_init.up":    {Data: []byte("CREATE TABLE a (b int);")},
+		"10_init.down":  {Data: []byte("DROP TABLE a;")},
+		"20_add_d.up":   {Data: []byte("ALTER TABLE a ADD COLUMN d int;")},
+		"20_add_d.down": {Data: []byte("ALTER TABLE a DROP COLUMN d;")},
+	}
+	migrations, err := ParseMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrations
+}
+
+func TestParseMigrations(t *testing.T) {
+	migrations := testMigrations(t)
+	if len(migrations) != 3 {
+		t.Fatalf("Expected 3 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 10 || migrations[0].Name != "init" || migrations[0].UpSQL != "CREATE TABLE a (b int);" || migrations[0].DownSQL != "DROP TABLE a;" {
+		t.Errorf("Unexpected first migration %+v", migrations[0])
+	}
+	if migrations[2].Version != 30 || LatestMigration(migrations) != 30 {
+		t.Errorf("Expected migration 30 to be the latest, got %+v", migrations[2])
+	}
+
+	invalid := []fstest.MapFS{
+		{"10_init.up": {Data: []byte("CREATE TABLE a (b int);")}},
+		{"init.up": {}, "init.down": {}},
+		{"10_init.sql": {}},
+		{"10_init.up": {}, "10_init.down": {}, "10_other.up": {}, "10_other.down": {}},
+	}
+	for _, fsys := range invalid {
+		if _, err := ParseMigrations(fsys); err == nil {
+			t.Errorf("Migrations %v should be invalid", fsys)
+		}
+	}
+}
+
+func TestParseEmbeddedMigrations(t *testing.T) {
+	// The registry's own migrations have to parse, or cr server migrate can't run
+	migrations, err := ParseMigrations(os.DirFS("../db/migrations"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Error("Expected the registry's migrations")
+	}
+}
+
+func TestMigrationPlan(t *testing.T) {
+	migrations := testMigrations(t)
+	tests := []struct {
+		from, to int64
+		steps    []MigrationStep
+	}{
+		{0, 30, []MigrationStep{{Up: true, Before: 0, After: 10}, {Up: true, Before: 10, After: 20}, {Up: true, Before: 20, After: 30}}},
+		{20, 30, []MigrationStep{{Up: true, Before: 20, After: 30}}},
+		{30, 10, []MigrationStep{{Before: 30, After: 20}, {Before: 20, After: 10}}},
+		{10, 0, []MigrationStep{{Before: 10, After: 0}}},
+		{20, 20, nil},
+	}
+	for _, test := range tests {
+		steps, err := MigrationPlan(migrations, test.from, test.to)
+		if err != nil {
+			t.Errorf("Migrating %d to %d failed: %s", test.from, test.to, err)
+			continue
+		}
+		if len(steps) != len(test.steps) {
+			t.Errorf("Migrating %d to %d expected %d steps, got %+v", test.from, test.to, len(test.steps), steps)
+			continue
+		}
+		for i, s := range steps {
+			want := test.steps[i]
+			if s.Up != want.Up || s.Before != want.Before || s.After != want.After {
+				t.Errorf("Migrating %d to %d step %d expected %+v, got %+v", test.from, test.to, i, want, s)
+			}
+			if s.Up && s.Version != s.After || !s.Up && s.Version != s.Before {
+				t.Errorf("Migrating %d to %d step %d runs the wrong migration %d", test.from, test.to, i, s.Version)
+			}
+		}
+	}
+
+	for _, fromTo := range [][2]int64{{15, 30}, {40, 30}, {10, 25}} {
+		if _, err := MigrationPlan(migrations, fromTo[0], fromTo[1]); err == nil {
+			t.Errorf("Migrating %d to %d should fail", fromTo[0], fromTo[1])
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// APIURL parses the URL of a registry's API, adding the trailing slash API
+// paths are resolved against
+func APIURL(s string) (*url.URL, error) {
+	if !ValidURL(s) {
+		return nil, fmt.Errorf("%q isn't a http or https URL", s)
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u, nil
+}
+
+// Mirrors returns the APIs listed under crackle.mirrors in the client config,
+// read from when crackle.api is unreachable. Invalid URLs are skipped.
+func Mirrors() []*url.URL {
+	var mirrors []*url.URL
+	for _, m := range viper.GetStringSlice("crackle.mirrors") {
+		u, err := APIURL(m)
+		if err != nil {
+			Warnf("ignoring mirror: %s", err)
+			continue
+		}
+		mirrors = append(mirrors, u)
+	}
+	return mirrors
+}
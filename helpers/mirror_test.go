@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestAPIURL(t *testing.T) {
+	for s, want := range map[string]string{
+		"https://mirror.example.com/api":  "https://mirror.example.com/api/",
+		"https://mirror.example.com/api/": "https://mirror.example.com/api/",
+		"http://localhost:3813":           "http://localhost:3813/",
+	} {
+		u, err := APIURL(s)
+		if err != nil {
+			t.Errorf("APIURL(%q) should be valid, got %s", s, err)
+			continue
+		}
+		if u.String() != want {
+			t.Errorf("APIURL(%q) = %q, expected %q", s, u, want)
+		}
+	}
+	for _, s := range []string{"", "mirror.example.com/api", "ftp://mirror.example.com"} {
+		if _, err := APIURL(s); err == nil {
+			t.Errorf("APIURL(%q) should be invalid", s)
+		}
+	}
+}
+
+func TestMirrors(t *testing.T) {
+	viper.Set("crackle.mirrors", []string{"https://mirror.example.com/api", "not a url", "http://backup.example.com/api/"})
+	defer viper.Set("crackle.mirrors", nil)
+
+	mirrors := Mirrors()
+	if len(mirrors) != 2 {
+		t.Fatalf("Expected 2 mirrors, got %v", mirrors)
+	}
+	if mirrors[0].String() != "https://mirror.example.com/api/" || mirrors[1].String() != "http://backup.example.com/api/" {
+		t.Errorf("Unexpected mirrors %v", mirrors)
+	}
+}
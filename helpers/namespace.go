@@ -0,0 +1,55 @@
+package helpers
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// NamespaceSeparator separates the namespace of a package name, the user or
+	// org owning it, from its short name such as alice/tool
+	NamespaceSeparator = "/"
+	// NamespaceKey is the client config key of the namespace short package names
+	// are looked up in first
+	NamespaceKey = "crackle.namespace"
+
+	// maxNamespaceLength is the longest namespace, that of usernames and orgs
+	maxNamespaceLength = 40
+)
+
+// SplitPackageName splits a package name into its namespace and short name, the
+// namespace is empty for packages without one
+func SplitPackageName(n string) (namespace string, name string) {
+	if i := strings.Index(n, NamespaceSeparator); i != -1 {
+		return n[:i], n[i+1:]
+	}
+	return "", n
+}
+
+// ValidNamespace validates a namespace, namespaces follow the rules of package
+// names but can't be reserved
+func ValidNamespace(n string) bool {
+	return len(n) <= maxNamespaceLength && validNamePart(n)
+}
+
+// DefaultNamespace returns the namespace short package names are looked up in
+// first, empty when none is configured
+func DefaultNamespace() string {
+	return viper.GetString(NamespaceKey)
+}
+
+// QualifyPackageName returns a short package name in namespace, names already in
+// a namespace and an empty namespace leave it as it is
+func QualifyPackageName(namespace string, n string) string {
+	if namespace == "" || strings.Contains(n, NamespaceSeparator) {
+		return n
+	}
+	return namespace + NamespaceSeparator + n
+}
+
+// PackageFileName returns the name a package's files and containers are named
+// after, a namespace's separator becomes a "." which package names never hold
+func PackageFileName(n string) string {
+	return strings.Replace(n, NamespaceSeparator, ".", 1)
+}
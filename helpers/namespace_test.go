@@ -0,0 +1,57 @@
+package helpers
+
+import "testing"
+
+func TestValidNamespacedPackageName(t *testing.T) {
+	for _, n := range []string{"alice/tool", "acme/va-lid", "alice/help"} {
+		if !ValidPackageName(n) {
+			t.Errorf("Package name %q should be valid", n)
+		}
+	}
+	for _, n := range []string{"/tool", "alice/", "alice/tool/x", "Alice/tool", "alice//tool",
+		"abcdefghijklmnopqrstuvwxyzabcdefghijklmno/tool"} {
+		if ValidPackageName(n) {
+			t.Errorf("Package name %q should be invalid", n)
+		}
+	}
+	if ValidShortPackageName("alice/tool") {
+		t.Error("Short package names shouldn't have a namespace")
+	}
+	if ValidOrgName("acme/tools") {
+		t.Error("Org names shouldn't have a namespace")
+	}
+}
+
+func TestSplitPackageName(t *testing.T) {
+	split := map[string][2]string{
+		"tool":       {"", "tool"},
+		"alice/tool": {"alice", "tool"},
+	}
+	for n, expected := range split {
+		if namespace, name := SplitPackageName(n); namespace != expected[0] || name != expected[1] {
+			t.Errorf("%q should split into %q, got %q %q", n, expected, namespace, name)
+		}
+	}
+}
+
+func TestQualifyPackageName(t *testing.T) {
+	tests := []struct{ namespace, name, expected string }{
+		{"alice", "tool", "alice/tool"},
+		{"alice", "acme/tool", "acme/tool"},
+		{"", "tool", "tool"},
+	}
+	for _, test := range tests {
+		if n := QualifyPackageName(test.namespace, test.name); n != test.expected {
+			t.Errorf("%q in namespace %q should be %q, got %q", test.name, test.namespace, test.expected, n)
+		}
+	}
+}
+
+func TestPackageFileName(t *testing.T) {
+	if n := PackageFileName("alice/tool"); n != "alice.tool" {
+		t.Errorf("Namespaced package files should be named alice.tool, got %q", n)
+	}
+	if n := ContainerName("alice/tool"); n != "cr-alice.tool" {
+		t.Errorf("Namespaced package containers should be named cr-alice.tool, got %q", n)
+	}
+}
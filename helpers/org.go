@@ -0,0 +1,29 @@
+package helpers
+
+const (
+	// maxOrgNameLength is the longest an org's name can be, the same as a username
+	maxOrgNameLength = 40
+
+	// OrgRoleOwner lets an org member publish its packages and manage its members
+	OrgRoleOwner = "owner"
+	// OrgRolePublisher lets an org member publish its packages
+	OrgRolePublisher = "publisher"
+	// OrgRoleReader makes a user a member of an org without letting them change anything
+	OrgRoleReader = "reader"
+)
+
+// ValidOrgName validates an org's name, which follows the rules of package names
+func ValidOrgName(n string) bool {
+	return len(n) <= maxOrgNameLength && ValidShortPackageName(n)
+}
+
+// ValidOrgRole validates the role of an org member
+func ValidOrgRole(r string) bool {
+	return r == OrgRoleOwner || r == OrgRolePublisher || r == OrgRoleReader
+}
+
+// OrgRoleCanPublish reports whether an org member with role can publish the
+// org's packages, a role of "" isn't a member
+func OrgRoleCanPublish(role string) bool {
+	return role == OrgRoleOwner || role == OrgRolePublisher
+}
@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidOrgName(t *testing.T) {
+	valid := []string{"acme", "acme-corp", strings.Repeat("a", 40)}
+	for _, n := range valid {
+		if !ValidOrgName(n) {
+			t.Errorf("Org name %s should be valid", n)
+		}
+	}
+	invalid := []string{"", "Acme Corp", strings.Repeat("a", 41)}
+	for _, n := range invalid {
+		if ValidOrgName(n) {
+			t.Errorf("Org name %s should be invalid", n)
+		}
+	}
+}
+
+func TestOrgRoleCanPublish(t *testing.T) {
+	roles := map[string]bool{
+		OrgRoleOwner:     true,
+		OrgRolePublisher: true,
+		OrgRoleReader:    false,
+		"":               false,
+	}
+	for role, expected := range roles {
+		if !ValidOrgRole(role) && role != "" {
+			t.Errorf("Role %s should be valid", role)
+		}
+		if OrgRoleCanPublish(role) != expected {
+			t.Errorf("Role \"%s\" publishing should be %v", role, expected)
+		}
+	}
+	if ValidOrgRole("admin") {
+		t.Error("Role admin should be invalid")
+	}
+}
@@ -0,0 +1,143 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// OverrideFileName is the name of the local override file read from the working directory
+const OverrideFileName = ".cr.override.toml"
+
+// LoadOverride reads the override file in dir, returning nil when there isn't one
+//
+// Deprecated: use LoadOverrideContext, this will be removed in the next release.
+func LoadOverride(dir string) (*models.PackageToml, error) {
+	return LoadOverrideContext(context.Background(), dir)
+}
+
+// LoadOverrideContext reads the override file in dir, returning nil when there isn't one
+func LoadOverrideContext(ctx context.Context, dir string) (*models.PackageToml, error) {
+	return LoadOverrideFileContext(ctx, filepath.Join(dir, OverrideFileName))
+}
+
+// LoadOverrideFileContext reads the override file at path, returning nil when
+// there isn't one
+func LoadOverrideFileContext(ctx context.Context, path string) (*models.PackageToml, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return ConfigFileToPackageTomlContext(ctx, path)
+}
+
+// MergePackageToml returns a copy of base with the ports, volumes and env of
+// override merged over it. Override always wins: a port replaces the base port
+// on the same container port, a volume replaces the base volume on the same
+// container path and an env var replaces the base var of the same name, anything
+// else is added. All other fields of override are ignored. A nil override
+// returns base unchanged.
+func MergePackageToml(base, override *models.PackageToml) *models.PackageToml {
+	if override == nil {
+		return base
+	}
+	merged := *base
+
+	merged.Ports = make(models.Ports, 0, len(base.Ports)+len(override.Ports))
+	merged.Ports = append(merged.Ports, base.Ports...)
+	for _, o := range override.Ports {
+		replaced := false
+		for i, p := range merged.Ports {
+			if p.Container == o.Container {
+				merged.Ports[i] = o
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged.Ports = append(merged.Ports, o)
+		}
+	}
+
+	merged.Volumes = make(models.Volumes, 0, len(base.Volumes)+len(override.Volumes))
+	merged.Volumes = append(merged.Volumes, base.Volumes...)
+	for _, o := range override.Volumes {
+		replaced := false
+		for i, v := range merged.Volumes {
+			if v.Container == o.Container {
+				merged.Volumes[i] = o
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged.Volumes = append(merged.Volumes, o)
+		}
+	}
+
+	merged.Env = make(models.Env, len(base.Env)+len(override.Env))
+	for k, v := range base.Env {
+		merged.Env[k] = v
+	}
+	for k, v := range override.Env {
+		merged.Env[k] = v
+	}
+
+	return &merged
+}
+
+// RunOverrides holds port, volume and env mappings given on the command line in
+// docker's "local:container" and "KEY=value" forms
+type RunOverrides struct {
+	Ports   []string
+	Volumes []string
+	Env     []string
+}
+
+// ApplyOverrides merges command line mappings over pt with the same precedence
+// as MergePackageToml and validates the result. An env var given as just KEY
+// takes its value from the environment cr runs in, like docker's -e.
+func ApplyOverrides(pt *models.PackageToml, o RunOverrides) (*models.PackageToml, error) {
+	override := &models.PackageToml{Env: make(models.Env)}
+	for _, s := range o.Ports {
+		p, err := ParsePort(s)
+		if err != nil {
+			return nil, err
+		}
+		override.Ports = append(override.Ports, p)
+	}
+	for _, s := range o.Volumes {
+		v, err := ParseVolume(s)
+		if err != nil {
+			return nil, err
+		}
+		override.Volumes = append(override.Volumes, v)
+	}
+	for _, s := range o.Env {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) == 1 {
+			parts = append(parts, os.Getenv(parts[0]))
+		}
+		override.Env[parts[0]] = parts[1]
+	}
+
+	merged := MergePackageToml(pt, override)
+	if err := ValidPackageToml(merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// ParsePort parses a docker style "local:container" port mapping, a single
+// port maps the same port on both sides
+func ParsePort(s string) (models.Port, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) == 1 {
+		parts = append(parts, parts[0])
+	}
+	if len(parts) != 2 || !ValidPort(parts[0]) || !ValidPort(parts[1]) {
+		return models.Port{}, fmt.Errorf("Port \"%v\" should be in the form local:container", s)
+	}
+	return models.Port{Local: parts[0], Container: parts[1]}, nil
+}
@@ -0,0 +1,88 @@
+package helpers
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestMergePackageToml(t *testing.T) {
+	base := &models.PackageToml{
+		Package:    "testing",
+		Repository: "sunshinekitty/testing:latest",
+		Ports:      models.Ports{{Local: "8080", Container: "80"}},
+		Volumes:    models.Volumes{{Local: "/tmp", Container: "/data"}},
+		Env:        models.Env{"DEBUG": "false", "LANG": "C"},
+	}
+	override := &models.PackageToml{
+		Repository: "ignored:latest",
+		Ports:      models.Ports{{Local: "9090", Container: "80"}, {Local: "8443", Container: "443"}},
+		Volumes:    models.Volumes{{Local: "/srv", Container: "/srv"}},
+		Env:        models.Env{"DEBUG": "true"},
+	}
+
+	merged := MergePackageToml(base, override)
+	if merged.Repository != "sunshinekitty/testing:latest" {
+		t.Error("Repository should not be overridden, got", merged.Repository)
+	}
+	expectedPorts := models.Ports{{Local: "9090", Container: "80"}, {Local: "8443", Container: "443"}}
+	if !reflect.DeepEqual(merged.Ports, expectedPorts) {
+		t.Errorf("Ports should be %v, got %v", expectedPorts, merged.Ports)
+	}
+	expectedVolumes := models.Volumes{{Local: "/tmp", Container: "/data"}, {Local: "/srv", Container: "/srv"}}
+	if !reflect.DeepEqual(merged.Volumes, expectedVolumes) {
+		t.Errorf("Volumes should be %v, got %v", expectedVolumes, merged.Volumes)
+	}
+	expectedEnv := models.Env{"DEBUG": "true", "LANG": "C"}
+	if !reflect.DeepEqual(merged.Env, expectedEnv) {
+		t.Errorf("Env should be %v, got %v", expectedEnv, merged.Env)
+	}
+	if base.Ports[0].Local != "8080" || base.Env["DEBUG"] != "false" {
+		t.Error("Base should not be modified by a merge")
+	}
+
+	if MergePackageToml(base, nil) != base {
+		t.Error("Merging a nil override should return base")
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	base := &models.PackageToml{
+		Package:    "testing",
+		Repository: "sunshinekitty/testing:latest",
+		Ports:      models.Ports{{Local: "8080", Container: "80"}},
+		Env:        models.Env{"DEBUG": "false"},
+	}
+	os.Setenv("CR_TEST_PASSTHROUGH", "through")
+	defer os.Unsetenv("CR_TEST_PASSTHROUGH")
+
+	pt, err := ApplyOverrides(base, RunOverrides{
+		Ports:   []string{"9090:80", "443"},
+		Volumes: []string{`C:\data:/data`},
+		Env:     []string{"DEBUG=true", "CR_TEST_PASSTHROUGH"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPorts := models.Ports{{Local: "9090", Container: "80"}, {Local: "443", Container: "443"}}
+	if !reflect.DeepEqual(pt.Ports, expectedPorts) {
+		t.Errorf("Ports should be %v, got %v", expectedPorts, pt.Ports)
+	}
+	expectedVolumes := models.Volumes{{Local: `C:\data`, Container: "/data"}}
+	if !reflect.DeepEqual(pt.Volumes, expectedVolumes) {
+		t.Errorf("Volumes should be %v, got %v", expectedVolumes, pt.Volumes)
+	}
+	expectedEnv := models.Env{"DEBUG": "true", "CR_TEST_PASSTHROUGH": "through"}
+	if !reflect.DeepEqual(pt.Env, expectedEnv) {
+		t.Errorf("Env should be %v, got %v", expectedEnv, pt.Env)
+	}
+
+	if _, err = ApplyOverrides(base, RunOverrides{Ports: []string{"99999:80"}}); err == nil {
+		t.Error("Invalid port override should return an error")
+	}
+	if _, err = ApplyOverrides(base, RunOverrides{Env: []string{"1BAD=x"}}); err == nil {
+		t.Error("Invalid env override should fail validation")
+	}
+}
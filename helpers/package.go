@@ -2,15 +2,17 @@ package helpers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
-
-	"github.com/BurntSushi/toml"
-	"github.com/spf13/viper"
+	"unicode/utf8"
 
 	"github.com/sunshinekitty/cr/models"
 )
@@ -19,12 +21,21 @@ var (
 	match = regexp.MustCompile
 
 	packageName = match(`([a-z\d]){1}([a-z0-9-*_*]){0,48}([a-z\d]){1}`)
-	repoName    = match(`([A-Za-z\d\./:-]*){3,141}`)
+	repoName    = match(`^[A-Za-z\d][A-Za-z\d\./:-]*$`)
+	envName     = match(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	keyword     = match(`^[a-z0-9][a-z0-9-]{0,29}$`)
+
+	// MaxKeywords is the most keywords a package can have
+	MaxKeywords = 10
 
 	// ErrInvalidPackageName is thrown when an invalid package name is given
 	ErrInvalidPackageName = errors.New("package name is invalid")
+	// ErrReservedPackageName is thrown when a reserved package name is given
+	ErrReservedPackageName = errors.New("package name is reserved")
 	// ErrInvalidRepositoryName is thrown when an invalid repository name is given
 	ErrInvalidRepositoryName = errors.New("repository name is invalid")
+	// ErrRepositoryDigest is thrown when a repository is pinned by digest rather than tag
+	ErrRepositoryDigest = errors.New("repository must be tagged, digests are not supported")
 	// ErrInvalidPort is thrown when an invalid port is given
 	ErrInvalidPort = errors.New("port number is invalid")
 	// ErrInvalidVolume is thrown when an invalid volume mount is given
@@ -35,66 +46,192 @@ var (
 	ErrLongLongDescription = errors.New("long description is too long (>25000 chars)")
 	// ErrLongHomepage is thrown when home page is too long (>100)
 	ErrLongHomepage = errors.New("homepage is too long (>100 chars)")
+	// ErrInvalidHomepage is thrown when home page isn't a http(s) URL
+	ErrInvalidHomepage = errors.New("homepage is not a valid http(s) URL")
+	// ErrLongIcon is thrown when icon is too long (>200)
+	ErrLongIcon = errors.New("icon is too long (>200 chars)")
+	// ErrInvalidIcon is thrown when icon isn't a http(s) URL
+	ErrInvalidIcon = errors.New("icon is not a valid http(s) URL")
 	// ErrLongCommandStart is thrown when command start is too long (>100)
 	ErrLongCommandStart = errors.New("command start is too long (>100 chars)")
-	// ErrMissingUsername is thrown when a username isn't set in client config
-	ErrMissingUsername = errors.New("username is not set in client config")
+	// ErrInvalidCommandStart is thrown when command start can't be split in to args
+	ErrInvalidCommandStart = errors.New("command start is invalid")
+	// ErrLongTestCommand is thrown when test command is too long (>200)
+	ErrLongTestCommand = errors.New("test command is too long (>200 chars)")
+	// ErrInvalidTestCommand is thrown when test command can't be split in to args
+	ErrInvalidTestCommand = errors.New("test command is invalid")
+	// ErrInvalidOrg is thrown when the org a package is published under has an invalid name
+	ErrInvalidOrg = errors.New("org is invalid")
+	// ErrInvalidEnv is thrown when an invalid environment variable is given
+	ErrInvalidEnv = errors.New("environment variable is invalid")
+	// ErrInvalidKeyword is thrown when an invalid keyword is given or there are too many
+	ErrInvalidKeyword = errors.New("keyword is invalid")
+	// ErrInvalidUTF8 is thrown when a text field isn't valid UTF-8
+	ErrInvalidUTF8 = errors.New("text is not valid UTF-8")
+	// ErrEmptyDeprecation is thrown when a package is deprecated without a message
+	ErrEmptyDeprecation = errors.New("deprecation message is empty")
+	// ErrLongDeprecation is thrown when a deprecation message is too long (>200)
+	ErrLongDeprecation = errors.New("deprecation message is too long (>200 chars)")
 )
 
 // ConfigFileToCmd takes a path to a crackle package config and outputs a
 // docker command and args to run said package.
+//
+// Deprecated: use ConfigFileToCmdContext, this will be removed in the next release.
 func ConfigFileToCmd(path string) (string, string, error) {
-	var cmdBuff bytes.Buffer
+	return ConfigFileToCmdContext(context.Background(), path)
+}
 
-	pt, err := ConfigFileToPackageToml(path)
+// ConfigFileToCmdContext takes a path to a crackle package config and outputs a
+// docker command and args to run said package.
+func ConfigFileToCmdContext(ctx context.Context, path string) (string, string, error) {
+	pt, err := ConfigFileToPackageTomlContext(ctx, path)
 	if err != nil {
 		return "", "", err
 	}
 
-	cmdStart := ""
-	if pt.CommandStart != nil {
-		cmdStart = " " + *pt.CommandStart
+	runCmd, runArgs := PackageTomlToCmd(pt)
+	return runCmd, runArgs, nil
+}
+
+// PackageTomlToCmd takes a PackageToml struct and outputs a docker command and
+// args to run said package. Every arg is shell quoted so the args can be safely
+// pasted into a shell or split back with ShellSplit.
+func PackageTomlToCmd(pt *models.PackageToml) (string, string) {
+	runCmd, runArgs := PackageTomlToArgs(pt)
+	return runCmd, ShellJoin(runArgs)
+}
+
+// PackageTomlToArgs takes a PackageToml struct and outputs a docker command and
+// the individual args to run said package, ready to be executed without a shell.
+// Manifest values are always passed as a single arg so can't add docker flags,
+// command_start is split in to words with ShellSplit and any extra args are
+// appended after it, untouched. The container is labelled with PackageLabel and
+// only given a terminal when cr is Interactive.
+func PackageTomlToArgs(pt *models.PackageToml, extra ...string) (string, []string) {
+	return packageTomlToArgs(pt, nil, extra)
+}
+
+// packageTomlToArgs is PackageTomlToArgs with flags passed to docker run
+// before the package's own
+func packageTomlToArgs(pt *models.PackageToml, flags []string, extra []string) (string, []string) {
+	if Interactive() {
+		flags = append([]string{"-t"}, flags...)
 	}
+	flags = append(append(flags, "--rm"), packageLabelArgs(pt.Package)...)
+	args := dockerRunArgs(pt, flags...)
+	return DockerCmd(append(args[1:], extra...)...)
+}
 
-	cmdBuff.WriteString("docker run -t --rm ")
+// dockerRunArgs builds the docker run args for a PackageToml, flags are passed to
+// docker run before the package's own
+func dockerRunArgs(pt *models.PackageToml, flags ...string) []string {
+	args := append([]string{"docker", "run"}, flags...)
 
 	for _, p := range pt.Ports {
-		cmdBuff.WriteString(fmt.Sprintf("-p %s:%s ", p.Local, p.Container))
+		args = append(args, "-p", fmt.Sprintf("%s:%s", p.Local, p.Container))
 	}
 
 	for _, v := range pt.Volumes {
-		cmdBuff.WriteString(fmt.Sprintf("-v %s:%s ", v.Local, v.Container))
+		args = append(args, "-v", fmt.Sprintf("%s:%s", v.Local, v.Container))
+	}
+
+	for _, k := range pt.Env.Keys() {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", k, pt.Env[k]))
 	}
 
-	cmdBuff.WriteString(fmt.Sprintf("%s%s", pt.Repository, cmdStart))
+	// Nothing after -- is a docker flag, even a repository that got past validation
+	args = append(args, "--", pt.Repository)
+
+	if pt.CommandStart != nil {
+		// command_start is validated to split, fall back to a single arg regardless
+		cmdStart, err := ShellSplit(*pt.CommandStart)
+		if err != nil {
+			cmdStart = []string{*pt.CommandStart}
+		}
+		args = append(args, cmdStart...)
+	}
 
-	return "/usr/bin/env", cmdBuff.String(), nil
+	return args
 }
 
-// ConfigFileToPackageToml takes a path to toml config and translates to PackageToml struct
+// ConfigFileToPackageToml takes a path to a toml, yaml or json config and translates to PackageToml struct
+//
+// Deprecated: use ConfigFileToPackageTomlContext, this will be removed in the next release.
 func ConfigFileToPackageToml(path string) (*models.PackageToml, error) {
-	var returnPackageToml models.PackageToml
-	_, err := toml.DecodeFile(path, &returnPackageToml)
-	return &returnPackageToml, err
+	return ConfigFileToPackageTomlContext(context.Background(), path)
+}
+
+// ConfigFileToPackageTomlContext takes a path to a toml, yaml or json config and translates to PackageToml struct
+func ConfigFileToPackageTomlContext(ctx context.Context, path string) (*models.PackageToml, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	return DecodePackageToml(bytes.NewReader(b), DetectFormat(path, b))
+}
+
+// ConfigToPackageToml reads a toml config from r and translates to PackageToml struct
+func ConfigToPackageToml(r io.Reader) (*models.PackageToml, error) {
+	return DecodePackageToml(r, FormatTOML)
+}
+
+// ConfigYAMLToPackageToml reads a yaml config from r and translates to PackageToml struct
+func ConfigYAMLToPackageToml(r io.Reader) (*models.PackageToml, error) {
+	return DecodePackageToml(r, FormatYAML)
+}
+
+// ConfigBytesToPackageToml takes a toml config held in memory and translates to PackageToml struct
+func ConfigBytesToPackageToml(b []byte) (*models.PackageToml, error) {
+	return ConfigToPackageToml(bytes.NewReader(b))
 }
 
 // PackageTomlToPackage takes a PackageToml struct and converts it to a Package struct
+// owned by the logged in user
 func PackageTomlToPackage(pt *models.PackageToml) (*models.Package, error) {
-	splitRepository := strings.Split(pt.Repository, ":")
-	username := viper.GetString("crackle.auth.username")
-	if len(username) == 0 {
-		return nil, ErrMissingUsername
+	p, err := packageTomlToPackage(pt)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := LoadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	p.Owner = credentials.Username
+	return p, nil
+}
+
+// packageTomlToPackage converts a PackageToml struct to a Package struct without an owner
+func packageTomlToPackage(pt *models.PackageToml) (*models.Package, error) {
+	ref, err := ParseReference(pt.Repository)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Digest != "" {
+		return nil, ErrRepositoryDigest
+	}
+	version := ref.Tag
+	if version == "" {
+		version = "latest"
 	}
 	p := &models.Package{
 		CommandStart:     pt.CommandStart,
 		Homepage:         pt.Homepage,
+		Icon:             pt.Icon,
 		LongDescription:  pt.LongDescription,
 		Name:             pt.Package,
-		Pulls:            0,
+		Org:              pt.Org,
+		Private:          pt.Private,
 		ShortDescription: pt.ShortDescription,
-		Version:          splitRepository[1],
-		Repository:       splitRepository[0],
-		Owner:            username,
+		TestCommand:      pt.TestCommand,
+		Version:          version,
+		Repository:       ref.Name(),
 	}
 
 	ptPorts, err := json.Marshal(pt.Ports)
@@ -115,6 +252,24 @@ func PackageTomlToPackage(pt *models.PackageToml) (*models.Package, error) {
 		return nil, err
 	}
 
+	ptEnv, err := json.Marshal(pt.Env)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(ptEnv, &p.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	ptKeywords, err := json.Marshal(pt.Keywords)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(ptKeywords, &p.Keywords)
+	if err != nil {
+		return nil, err
+	}
+
 	return p, nil
 }
 
@@ -123,9 +278,13 @@ func PackageToPackageToml(p *models.Package) (*models.PackageToml, error) {
 	pt := &models.PackageToml{
 		CommandStart:     p.CommandStart,
 		Homepage:         p.Homepage,
+		Icon:             p.Icon,
 		LongDescription:  p.LongDescription,
 		Package:          p.Name,
+		Org:              p.Org,
+		Private:          p.Private,
 		ShortDescription: p.ShortDescription,
+		TestCommand:      p.TestCommand,
 		Repository:       fmt.Sprintf("%s:%s", p.Repository, p.Version),
 	}
 
@@ -147,143 +306,224 @@ func PackageToPackageToml(p *models.Package) (*models.PackageToml, error) {
 		return nil, err
 	}
 
+	pEnv, err := json.Marshal(p.Env)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(pEnv, &pt.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	pKeywords, err := json.Marshal(p.Keywords)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(pKeywords, &pt.Keywords)
+	if err != nil {
+		return nil, err
+	}
+
 	return pt, nil
 }
 
 // ValidPackageToml validates a PackageToml object
 func ValidPackageToml(pt *models.PackageToml) error {
+	if IsReservedName(pt.Package) {
+		return ErrReservedPackageName
+	}
 	if !ValidPackageName(pt.Package) {
 		return ErrInvalidPackageName
 	}
 	if !ValidRepositoryName(pt.Repository) {
 		return ErrInvalidRepositoryName
 	}
+	if err := ValidRegistry(pt.Repository); err != nil {
+		return err
+	}
 	for _, port := range pt.Ports {
 		if !ValidPort(port.Container) {
-			ErrInvalidPort = fmt.Errorf("Container port \"%v\" is invalid", port.Container)
-			return ErrInvalidPort
+			return fmt.Errorf("%w: container port \"%v\"", ErrInvalidPort, port.Container)
 		}
 		if !ValidPort(port.Local) {
-			ErrInvalidPort = fmt.Errorf("Local port \"%v\" is invalid", port.Local)
-			return ErrInvalidPort
+			return fmt.Errorf("%w: local port \"%v\"", ErrInvalidPort, port.Local)
 		}
 	}
 	for _, volume := range pt.Volumes {
+		if err := validUTF8("volume", volume.Container, volume.Local); err != nil {
+			return err
+		}
 		if len(volume.Container) > 4351 {
-			ErrInvalidVolume = fmt.Errorf("Container volume \"%v\" is too long", volume.Container)
-			return ErrInvalidVolume
+			return fmt.Errorf("%w: container volume \"%v\" is too long", ErrInvalidVolume, volume.Container)
 		}
 		if len(volume.Local) > 4351 {
-			ErrInvalidVolume = fmt.Errorf("Local volume \"%v\" is too long", volume.Local)
-			return ErrInvalidVolume
+			return fmt.Errorf("%w: local volume \"%v\" is too long", ErrInvalidVolume, volume.Local)
 		}
-	}
-	if pt.ShortDescription != nil {
-		if len(fmt.Sprintf("%s", *pt.ShortDescription)) > 200 {
-			return ErrLongShortDescription
+		if !ValidVolume(volume) {
+			return fmt.Errorf("%w: \"%v:%v\", container paths must be absolute and paths can't contain \":\" other than a Windows drive letter",
+				ErrInvalidVolume, volume.Local, volume.Container)
 		}
 	}
-	if pt.LongDescription != nil {
-		if len(fmt.Sprintf("%s", *pt.LongDescription)) > 25000 {
-			return ErrLongLongDescription
+	for k, v := range pt.Env {
+		if !ValidEnvName(k) {
+			return fmt.Errorf("%w: name \"%v\"", ErrInvalidEnv, k)
 		}
-	}
-	if pt.Homepage != nil {
-		if len(fmt.Sprintf("%s", *pt.Homepage)) > 100 {
-			return ErrLongHomepage
+		if err := validUTF8("env", v); err != nil {
+			return err
 		}
 	}
-	if pt.CommandStart != nil {
-		if len(fmt.Sprintf("%s", *pt.CommandStart)) > 100 {
-			return ErrLongCommandStart
+	if len(pt.Keywords) > MaxKeywords {
+		return fmt.Errorf("%w: package has %d keywords, at most %d are allowed", ErrInvalidKeyword, len(pt.Keywords), MaxKeywords)
+	}
+	for _, k := range pt.Keywords {
+		if !ValidKeyword(k) {
+			return fmt.Errorf("%w: \"%v\", keywords are lower case letters, numbers and dashes", ErrInvalidKeyword, k)
 		}
 	}
-	return nil
-}
-
-// ValidPackage validates a Package object
-func ValidPackage(p *models.Package) error {
-	if !ValidPackageName(p.Name) {
-		return ErrInvalidPackageName
+	if err := validText("short_description", pt.ShortDescription, 200, ErrLongShortDescription); err != nil {
+		return err
 	}
-	if !ValidRepositoryName(fmt.Sprintf("%s:%s", p.Repository, p.Version)) {
-		return ErrInvalidRepositoryName
+	if err := validText("long_description", pt.LongDescription, 25000, ErrLongLongDescription); err != nil {
+		return err
 	}
-
-	portsBytes, err := json.Marshal(p.Ports)
-	ports := new(models.Ports)
-	if err != nil {
+	if err := validText("homepage", pt.Homepage, 100, ErrLongHomepage); err != nil {
 		return err
 	}
-	err = json.Unmarshal(portsBytes, &ports)
-	for _, port := range *ports {
-		if !ValidPort(port.Container) {
-			ErrInvalidPort = fmt.Errorf("Container port \"%v\" is invalid", port.Container)
-			return ErrInvalidPort
+	if pt.Homepage != nil && !ValidURL(*pt.Homepage) {
+		return fmt.Errorf("%w: \"%v\"", ErrInvalidHomepage, *pt.Homepage)
+	}
+	if err := validText("icon", pt.Icon, 200, ErrLongIcon); err != nil {
+		return err
+	}
+	if pt.Icon != nil && !ValidURL(*pt.Icon) {
+		return fmt.Errorf("%w: \"%v\"", ErrInvalidIcon, *pt.Icon)
+	}
+	if err := validText("command_start", pt.CommandStart, 100, ErrLongCommandStart); err != nil {
+		return err
+	}
+	if pt.CommandStart != nil {
+		if _, err := ShellSplit(*pt.CommandStart); err != nil {
+			return fmt.Errorf("%w: \"%v\": %s", ErrInvalidCommandStart, *pt.CommandStart, err)
 		}
-		if !ValidPort(port.Local) {
-			ErrInvalidPort = fmt.Errorf("Local port \"%v\" is invalid", port.Local)
-			return ErrInvalidPort
+	}
+	if pt.Org != nil && !ValidOrgName(*pt.Org) {
+		return fmt.Errorf("%w: \"%v\", org names follow the rules of package names", ErrInvalidOrg, *pt.Org)
+	}
+	if err := validText("test_command", pt.TestCommand, 200, ErrLongTestCommand); err != nil {
+		return err
+	}
+	if pt.TestCommand != nil {
+		if _, err := ShellSplit(*pt.TestCommand); err != nil {
+			return fmt.Errorf("%w: \"%v\": %s", ErrInvalidTestCommand, *pt.TestCommand, err)
 		}
 	}
+	return nil
+}
 
-	volumesBytes, err := json.Marshal(p.Volumes)
-	volumes := new(models.Volumes)
+// ValidPackage validates a Package object, it's held to the same rules as the
+// PackageToml it was created from
+func ValidPackage(p *models.Package) error {
+	pt, err := PackageToPackageToml(p)
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(volumesBytes, &volumes)
-	for _, volume := range *ports {
-		if len(volume.Container) > 4351 {
-			ErrInvalidVolume = fmt.Errorf("Container volume \"%v\" is too long", volume.Container)
-			return ErrInvalidVolume
-		}
-		if len(volume.Local) > 4351 {
-			ErrInvalidVolume = fmt.Errorf("Local volume \"%v\" is too long", volume.Local)
-			return ErrInvalidVolume
-		}
+	return ValidPackageToml(pt)
+}
+
+// ValidDeprecation validates the message a package is deprecated with
+func ValidDeprecation(message string) error {
+	if strings.TrimSpace(message) == "" {
+		return ErrEmptyDeprecation
 	}
+	return validText("deprecation message", &message, 200, ErrLongDeprecation)
+}
 
-	if p.ShortDescription != nil {
-		if len(fmt.Sprintf("%s", *p.ShortDescription)) > 200 {
-			return ErrLongShortDescription
-		}
+// validText checks an optional text field is valid UTF-8 and at most max
+// characters long, returning errLong when it's too long
+func validText(field string, s *string, max int, errLong error) error {
+	if s == nil {
+		return nil
 	}
-	if p.LongDescription != nil {
-		if len(fmt.Sprintf("%s", *p.LongDescription)) > 25000 {
-			return ErrLongLongDescription
-		}
+	if err := validUTF8(field, *s); err != nil {
+		return err
 	}
-	if p.Homepage != nil {
-		if len(fmt.Sprintf("%s", *p.Homepage)) > 100 {
-			return ErrLongHomepage
-		}
+	if utf8.RuneCountInString(*s) > max {
+		return errLong
 	}
-	if p.CommandStart != nil {
-		if len(fmt.Sprintf("%s", *p.CommandStart)) > 100 {
-			return ErrLongCommandStart
+	return nil
+}
+
+// validUTF8 checks every value of a field is valid UTF-8
+func validUTF8(field string, values ...string) error {
+	for _, v := range values {
+		if !utf8.ValidString(v) {
+			return fmt.Errorf("%w: %s \"%v\"", ErrInvalidUTF8, field, v)
 		}
 	}
-
 	return nil
 }
 
-// ValidPackageName validates a package's name
+// ValidPackageName validates a package's name, either a short name or a short
+// name in a namespace such as alice/tool
 func ValidPackageName(n string) bool {
-	if len(n) == 0 {
-		return false
+	namespace, name := SplitPackageName(n)
+	if name == n {
+		return ValidShortPackageName(n)
 	}
-	return len(packageName.FindString(n)) == len(n)
+	return ValidNamespace(namespace) && validNamePart(name)
+}
+
+// ValidShortPackageName validates a package's name without a namespace,
+// reserved names are never valid
+func ValidShortPackageName(n string) bool {
+	return validNamePart(n) && !IsReservedName(n)
+}
+
+// validNamePart validates either side of a namespaced package name
+func validNamePart(n string) bool {
+	return len(n) > 0 && len(packageName.FindString(n)) == len(n)
+}
+
+// SplitPackageVersion splits a "package@version" argument, the version is empty
+// when none is given
+func SplitPackageVersion(s string) (string, string) {
+	if i := strings.Index(s, "@"); i != -1 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
 }
 
 // ValidRepositoryName validates a repository name
 func ValidRepositoryName(n string) bool {
 	// We could pull in Docker and use their regexp matching, but I don't think it really matters
-	// We should just verify it meets database constraints and is alphanumeric and/or ":" and/or "/"'s
+	// We should just verify it meets database constraints and is alphanumeric and/or ":" and/or "/"'s.
+	// It can't start with "-" so it's never taken for a docker flag.
 	if len(n) > 141 || len(n) < 3 {
 		return false
 	}
-	return len(repoName.FindString(n)) == len(n)
+	return repoName.MatchString(n)
+}
+
+// ValidEnvName validates an environment variable name
+func ValidEnvName(n string) bool {
+	return envName.MatchString(n)
+}
+
+// ValidKeyword validates a search keyword
+func ValidKeyword(k string) bool {
+	return keyword.MatchString(k)
+}
+
+// ValidURL validates a URL is absolute with a http or https scheme and a host
+func ValidURL(s string) bool {
+	if strings.ContainsAny(s, " \t\r\n") {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
 // ValidPort validate's a port number
@@ -1,8 +1,14 @@
 package helpers
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/sunshinekitty/cr/models"
 )
 
 func TestValidPackageName(t *testing.T) {
@@ -53,6 +59,19 @@ func TestValidPackageName(t *testing.T) {
 	}
 }
 
+func TestSplitPackageVersion(t *testing.T) {
+	split := map[string][2]string{
+		"testing":       {"testing", ""},
+		"testing@1.0.0": {"testing", "1.0.0"},
+		"testing@":      {"testing", ""},
+	}
+	for s, expected := range split {
+		if name, version := SplitPackageVersion(s); name != expected[0] || version != expected[1] {
+			t.Errorf("\"%s\" should split to %q, got %q %q", s, expected, name, version)
+		}
+	}
+}
+
 func TestValidRepositoryName(t *testing.T) {
 	if ValidRepositoryName("\"") {
 		t.Error("Repository name \"\"\" should be invalid")
@@ -65,6 +84,9 @@ func TestValidRepositoryName(t *testing.T) {
 	if ValidRepositoryName("space ") {
 		t.Error("Repository name \"space \" should be invalid")
 	}
+	if ValidRepositoryName("--privileged") || ValidRepositoryName("-v/:/host") {
+		t.Error("Repository names starting with - should be invalid")
+	}
 	if ValidRepositoryName("a/") {
 		t.Error("Repository name \"a/\" should be invalid")
 	}
@@ -97,3 +119,239 @@ func TestValidPort(t *testing.T) {
 		t.Error("Port \"65535\" should be valid")
 	}
 }
+
+func TestConfigToPackageToml(t *testing.T) {
+	config := `package = "testing"
+repository = "sunshinekitty/testing:latest"
+
+[[port]]
+local = "8080"
+container = "80"
+`
+	pt, err := ConfigToPackageToml(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt.Package != "testing" {
+		t.Error("Package should be \"testing\", got", pt.Package)
+	}
+	if pt.Repository != "sunshinekitty/testing:latest" {
+		t.Error("Repository should be \"sunshinekitty/testing:latest\", got", pt.Repository)
+	}
+	if len(pt.Ports) != 1 || pt.Ports[0].Local != "8080" || pt.Ports[0].Container != "80" {
+		t.Error("Ports should be [8080:80], got", pt.Ports)
+	}
+
+	pt, err = ConfigBytesToPackageToml([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt.Package != "testing" {
+		t.Error("Package should be \"testing\", got", pt.Package)
+	}
+
+	if _, err = ConfigBytesToPackageToml([]byte("package = ")); err == nil {
+		t.Error("Invalid toml should return an error")
+	}
+}
+
+func TestValidEnvName(t *testing.T) {
+	if !ValidEnvName("DEBUG") {
+		t.Error("Env name \"DEBUG\" should be valid")
+	}
+	if !ValidEnvName("_private_1") {
+		t.Error("Env name \"_private_1\" should be valid")
+	}
+	if ValidEnvName("1DEBUG") {
+		t.Error("Env name \"1DEBUG\" should be invalid")
+	}
+	if ValidEnvName("DE BUG") {
+		t.Error("Env name \"DE BUG\" should be invalid")
+	}
+	if ValidEnvName("") {
+		t.Error("Env name \"\" should be invalid")
+	}
+}
+
+func TestValidKeywords(t *testing.T) {
+	for _, k := range []string{"web", "static-site", "http2"} {
+		if !ValidKeyword(k) {
+			t.Errorf("Keyword \"%s\" should be valid", k)
+		}
+	}
+	for _, k := range []string{"", "Web", "-web", "web server", "a-very-long-keyword-that-goes-on"} {
+		if ValidKeyword(k) {
+			t.Errorf("Keyword \"%s\" should be invalid", k)
+		}
+	}
+
+	pt := &models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing", Keywords: []string{"web", "Web"}}
+	if err := ValidPackageToml(pt); !errors.Is(err, ErrInvalidKeyword) {
+		t.Error("Package with an invalid keyword should return ErrInvalidKeyword, got", err)
+	}
+	pt.Keywords = []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}
+	if err := ValidPackageToml(pt); !errors.Is(err, ErrInvalidKeyword) {
+		t.Error("Package with too many keywords should return ErrInvalidKeyword, got", err)
+	}
+	pt.Keywords = pt.Keywords[:MaxKeywords]
+	if err := ValidPackageToml(pt); err != nil {
+		t.Error("Package with valid keywords should be valid, got", err)
+	}
+}
+
+func TestReservedPackageName(t *testing.T) {
+	if !IsReservedName("docker") {
+		t.Error("Package name \"docker\" should be reserved")
+	}
+	if ValidPackageName("help") {
+		t.Error("Package name \"help\" should be invalid")
+	}
+	if IsReservedName("testing") {
+		t.Error("Package name \"testing\" should not be reserved")
+	}
+
+	viper.Set("reserved_names", []string{"Internal"})
+	defer viper.Set("reserved_names", nil)
+	if !IsReservedName("internal") {
+		t.Error("Package name \"internal\" should be reserved by config")
+	}
+	if err := ValidPackageToml(&models.PackageToml{Package: "internal", Repository: "internal:1.0"}); err != ErrReservedPackageName {
+		t.Error("Reserved package should return ErrReservedPackageName, got", err)
+	}
+}
+
+func TestValidPackageTomlText(t *testing.T) {
+	// 200 characters but 400 bytes
+	short := strings.Repeat("é", 200)
+	pt := &models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing:1.0", ShortDescription: &short}
+	if err := ValidPackageToml(pt); err != nil {
+		t.Error("Short description of 200 non-ASCII characters should be valid, got", err)
+	}
+	short = strings.Repeat("é", 201)
+	if err := ValidPackageToml(pt); err != ErrLongShortDescription {
+		t.Error("Short description of 201 characters should return ErrLongShortDescription, got", err)
+	}
+	short = "invalid \xff"
+	if err := ValidPackageToml(pt); !errors.Is(err, ErrInvalidUTF8) || !strings.Contains(err.Error(), "short_description") {
+		t.Error("Short description with invalid UTF-8 should return ErrInvalidUTF8 naming it, got", err)
+	}
+
+	pt = &models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing:1.0", Env: models.Env{"NAME": "\xfe"}}
+	if err := ValidPackageToml(pt); !errors.Is(err, ErrInvalidUTF8) {
+		t.Error("Env value with invalid UTF-8 should return ErrInvalidUTF8, got", err)
+	}
+	if ErrInvalidUTF8.Error() != "text is not valid UTF-8" {
+		t.Error("Validating packages shouldn't change ErrInvalidUTF8, got", ErrInvalidUTF8)
+	}
+
+	test := "curl -f 'http://localhost/health"
+	pt = &models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing:1.0", TestCommand: &test}
+	if err := ValidPackageToml(pt); !errors.Is(err, ErrInvalidTestCommand) {
+		t.Error("Test command with an unterminated quote should return ErrInvalidTestCommand, got", err)
+	}
+
+	org := "Acme Corp"
+	pt = &models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing:1.0", Org: &org}
+	if err := ValidPackageToml(pt); !errors.Is(err, ErrInvalidOrg) {
+		t.Error("Package with an invalid org should return ErrInvalidOrg, got", err)
+	}
+}
+
+func TestValidURL(t *testing.T) {
+	if !ValidURL("https://crackle.sh") {
+		t.Error("URL \"https://crackle.sh\" should be valid")
+	}
+	if !ValidURL("http://example.com/path?q=1") {
+		t.Error("URL \"http://example.com/path?q=1\" should be valid")
+	}
+	if ValidURL("crackle.sh") {
+		t.Error("URL \"crackle.sh\" should be invalid")
+	}
+	if ValidURL("ftp://example.com") {
+		t.Error("URL \"ftp://example.com\" should be invalid")
+	}
+	if ValidURL("javascript:alert(1)") {
+		t.Error("URL \"javascript:alert(1)\" should be invalid")
+	}
+	if ValidURL("https://exa mple.com") {
+		t.Error("URL \"https://exa mple.com\" should be invalid")
+	}
+	if ValidURL("https://") {
+		t.Error("URL \"https://\" should be invalid")
+	}
+}
+
+func TestValidDeprecation(t *testing.T) {
+	if err := ValidDeprecation("use testing2 instead"); err != nil {
+		t.Error("Deprecation message should be valid, got", err)
+	}
+	if err := ValidDeprecation("  "); err != ErrEmptyDeprecation {
+		t.Error("Blank deprecation message should be invalid, got", err)
+	}
+	if err := ValidDeprecation(strings.Repeat("a", 201)); err != ErrLongDeprecation {
+		t.Error("Deprecation message over 200 chars should be invalid, got", err)
+	}
+}
+
+func TestValidPackageTomlURLs(t *testing.T) {
+	homepage := "example.com"
+	pt := &models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing:1.0", Homepage: &homepage}
+	if err := ValidPackageToml(pt); !errors.Is(err, ErrInvalidHomepage) || !strings.Contains(err.Error(), "example.com") {
+		t.Error("Invalid homepage should return ErrInvalidHomepage naming it, got", err)
+	}
+	homepage = "https://example.com"
+	icon := "/icon.png"
+	pt.Icon = &icon
+	if err := ValidPackageToml(pt); !errors.Is(err, ErrInvalidIcon) || !strings.Contains(err.Error(), "/icon.png") {
+		t.Error("Invalid icon should return ErrInvalidIcon naming it, got", err)
+	}
+	if ErrInvalidHomepage.Error() != "homepage is not a valid http(s) URL" || ErrInvalidIcon.Error() != "icon is not a valid http(s) URL" {
+		t.Error("Validating packages shouldn't change ErrInvalidHomepage or ErrInvalidIcon")
+	}
+	icon = "https://example.com/icon.png"
+	if err := ValidPackageToml(pt); err != nil {
+		t.Error("Valid homepage and icon should be valid, got", err)
+	}
+}
+
+func TestPackageTomlToPackageRepository(t *testing.T) {
+	defer tempConfigDir(t)()
+	if _, err := PackageTomlToPackage(&models.PackageToml{Package: "testing", Repository: "img"}); err != ErrNotLoggedIn {
+		t.Error("Converting without logging in should return ErrNotLoggedIn, got", err)
+	}
+	if err := SaveCredentials(&Credentials{Username: "tester", Token: "token"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := PackageTomlToPackage(&models.PackageToml{Package: "testing", Repository: "myreg:5000/img:1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Repository != "myreg:5000/img" || p.Version != "1.0" {
+		t.Error("Repository should be \"myreg:5000/img\" version \"1.0\", got", p.Repository, p.Version)
+	}
+
+	p, err = PackageTomlToPackage(&models.PackageToml{Package: "testing", Repository: "sunshinekitty/testing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Repository != "sunshinekitty/testing" || p.Version != "latest" {
+		t.Error("Untagged repository should default to version \"latest\", got", p.Version)
+	}
+
+	_, err = PackageTomlToPackage(&models.PackageToml{Package: "testing", Repository: "img@sha256:0123456789abcdef0123456789abcdef"})
+	if err != ErrRepositoryDigest {
+		t.Error("Repository with digest should return ErrRepositoryDigest, got", err)
+	}
+}
+
+func TestConfigFileToPackageTomlContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ConfigFileToPackageTomlContext(ctx, "../config/package-example.toml"); err != context.Canceled {
+		t.Error("Canceled context should return context.Canceled, got", err)
+	}
+	if _, _, err := ConfigFileToCmdContext(ctx, "../config/package-example.toml"); err != context.Canceled {
+		t.Error("Canceled context should return context.Canceled, got", err)
+	}
+}
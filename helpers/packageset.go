@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+)
+
+// PackageSetEntry represents a package in a PackageSet, without a version the
+// latest is installed
+type PackageSetEntry struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version,omitempty"`
+}
+
+// PackageSet represents a set of packages to install together, such as a
+// team's standard toolbox
+type PackageSet struct {
+	Packages []PackageSetEntry `toml:"package"`
+}
+
+// StateToPackageSet returns a PackageSet of every installed package at its
+// installed version
+func StateToPackageSet(s *State) *PackageSet {
+	set := &PackageSet{}
+	for _, p := range s.Installed() {
+		set.Packages = append(set.Packages, PackageSetEntry{Name: p.Name, Version: p.Version})
+	}
+	return set
+}
+
+// EncodePackageSet writes a PackageSet as toml
+func EncodePackageSet(w io.Writer, set *PackageSet) error {
+	enc := toml.NewEncoder(w)
+	enc.Indent = ""
+	return enc.Encode(set)
+}
+
+// DecodePackageSet reads a PackageSet from toml, every package name is validated
+func DecodePackageSet(r io.Reader) (*PackageSet, error) {
+	set := &PackageSet{}
+	if _, err := toml.DecodeReader(r, set); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, p := range set.Packages {
+		if !ValidPackageName(p.Name) {
+			return nil, fmt.Errorf("package \"%s\" is invalid", p.Name)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("package \"%s\" is listed more than once", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return set, nil
+}
@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPackageSet(t *testing.T) {
+	s := &State{Packages: make(map[string]InstalledPackage)}
+	s.Packages["webserver"] = InstalledPackage{Name: "webserver", Version: "2.0"}
+	s.Packages["testing"] = InstalledPackage{Name: "testing", Version: "1.0"}
+
+	set := StateToPackageSet(s)
+	expected := []PackageSetEntry{{"testing", "1.0"}, {"webserver", "2.0"}}
+	if !reflect.DeepEqual(set.Packages, expected) {
+		t.Errorf("Installed packages should be listed by name, got %+v", set.Packages)
+	}
+
+	var b bytes.Buffer
+	if err := EncodePackageSet(&b, set); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodePackageSet(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, set) {
+		t.Errorf("Package set should round trip, got %+v", decoded)
+	}
+}
+
+func TestDecodePackageSetInvalid(t *testing.T) {
+	invalid := []string{
+		"[[package]]\nname = \"Not A Package\"\n",
+		"[[package]]\nname = \"testing\"\n\n[[package]]\nname = \"testing\"\nversion = \"1.0\"\n",
+	}
+	for _, s := range invalid {
+		if _, err := DecodePackageSet(strings.NewReader(s)); err == nil {
+			t.Errorf("Package set %q should be invalid", s)
+		}
+	}
+}
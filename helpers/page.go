@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxPageSize is the most results a list endpoint returns at once, clients
+// follow the next token for more
+const MaxPageSize = 100
+
+// ErrInvalidPageToken is thrown when a next token wasn't handed out for the
+// same list and order
+var ErrInvalidPageToken = errors.New("page token is invalid")
+
+// PageKey is a column a list is ordered by, lists are paged by the values of
+// these columns in the last row of a page. Numeric columns computed by a query
+// have their values cast, since SQLite only compares them to text as text.
+type PageKey struct {
+	Column  string
+	Desc    bool
+	Numeric bool
+}
+
+// EncodePageToken returns the opaque next token for a page ending on a row
+// with values for each PageKey
+func EncodePageToken(values ...string) string {
+	b, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodePageToken returns the values of a next token, which has to hold one
+// for each of n keys
+func DecodePageToken(token string, n int) ([]string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var values []string
+	if err = json.Unmarshal(b, &values); err != nil || len(values) != n {
+		return nil, ErrInvalidPageToken
+	}
+	return values, nil
+}
+
+// PageCondition returns a SQL condition matching the rows after values when
+// ordered by keys, placeholders are numbered on from len(args) and the values
+// are appended to args. Each row comes after the previous one so pages stay
+// right while rows are added.
+func PageCondition(keys []PageKey, values []string, args []interface{}) (string, []interface{}) {
+	var or []string
+	for i, k := range keys {
+		var and []string
+		for _, prev := range keys[:i] {
+			args = append(args, values[len(and)])
+			and = append(and, fmt.Sprintf("%s = %s", prev.Column, prev.placeholder(len(args))))
+		}
+		op := ">"
+		if k.Desc {
+			op = "<"
+		}
+		args = append(args, values[i])
+		and = append(and, fmt.Sprintf("%s %s %s", k.Column, op, k.placeholder(len(args))))
+		or = append(or, strings.Join(and, " AND "))
+	}
+	return "(" + strings.Join(or, " OR ") + ")", args
+}
+
+// placeholder returns the nth placeholder of a value of k
+func (k PageKey) placeholder(n int) string {
+	if k.Numeric {
+		return fmt.Sprintf("CAST($%d AS numeric)", n)
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+// PageOrder returns the ORDER BY clause for keys
+func PageOrder(keys []PageKey) string {
+	order := make([]string, len(keys))
+	for i, k := range keys {
+		order[i] = k.Column
+		if k.Desc {
+			order[i] += " DESC"
+		}
+	}
+	return strings.Join(order, ", ")
+}
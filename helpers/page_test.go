@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPageToken(t *testing.T) {
+	token := EncodePageToken("12", "testing")
+	values, err := DecodePageToken(token, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, []string{"12", "testing"}) {
+		t.Errorf("Page token should round trip, got %q", values)
+	}
+	if _, err = DecodePageToken(token, 1); err != ErrInvalidPageToken {
+		t.Error("Page token for two keys should be invalid for one, got", err)
+	}
+	if _, err = DecodePageToken("not a token!", 1); err != ErrInvalidPageToken {
+		t.Error("Garbage should be an invalid page token, got", err)
+	}
+}
+
+func TestPageCondition(t *testing.T) {
+	keys := []PageKey{{Column: "pulls", Desc: true}, {Column: "name"}}
+	cond, args := PageCondition(keys, []string{"12", "testing"}, []interface{}{"%q%"})
+	expected := "(pulls < $2 OR pulls = $3 AND name > $4)"
+	if cond != expected {
+		t.Errorf("Page condition should be %q, got %q", expected, cond)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"%q%", "12", "12", "testing"}) {
+		t.Errorf("Page condition args are wrong, got %q", args)
+	}
+	if order := PageOrder(keys); order != "pulls DESC, name" {
+		t.Errorf("Page order should be \"pulls DESC, name\", got %q", order)
+	}
+}
+
+func TestPageConditionNumeric(t *testing.T) {
+	keys := []PageKey{{Column: "rank", Desc: true, Numeric: true}, {Column: "name"}}
+	cond, _ := PageCondition(keys, []string{"0.5", "testing"}, nil)
+	expected := "(rank < CAST($1 AS numeric) OR rank = CAST($2 AS numeric) AND name > $3)"
+	if cond != expected {
+		t.Errorf("Page condition should be %q, got %q", expected, cond)
+	}
+}
@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ProjectConfigFileName is the name of a project's config, found in the
+	// working directory or the closest directory above it
+	ProjectConfigFileName = ".cr.toml"
+	// LockPathKey is the project config key of the project's lock file, relative
+	// to the project config
+	LockPathKey = "lock.path"
+	// OverridePathKey is the project config key of the override file runs from
+	// the project merge in, relative to the project config
+	OverridePathKey = "run.override"
+)
+
+// projectKeys are the keys a project config can set. Those that could send
+// credentials or images elsewhere, or loosen cr's checks, are left to the user.
+var projectKeys = []string{
+	NamespaceKey,
+	RegistryKey,
+	LockPathKey,
+	"run.detach",
+	OverridePathKey,
+	"run.pull",
+	"search.limit",
+	"search.sort",
+}
+
+// projectDir is the directory of the project config in use, empty when there
+// isn't one
+var projectDir string
+
+// FindProjectConfig returns the project config in dir or the closest directory
+// above it, empty when there's none
+func FindProjectConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ProjectConfigFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProjectConfig decodes the project config at path, checking it only sets
+// projectKeys with valid values
+func LoadProjectConfig(path string) (map[string]interface{}, error) {
+	config := make(map[string]interface{})
+	md, err := toml.DecodeFile(path, &config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	for _, key := range md.Keys() {
+		v, _ := lookupNested(config, key)
+		if _, table := v.(map[string]interface{}); !table && !projectKey(key.String()) {
+			return nil, fmt.Errorf("%s: %s can't be set in a project config, only %s", path, key, strings.Join(projectKeys, ", "))
+		}
+	}
+	for _, k := range projectKeys {
+		v, ok := lookupNested(config, strings.Split(k, "."))
+		if !ok {
+			continue
+		}
+		s, err := LookupSetting(k)
+		if err != nil {
+			// lock.path and run.override aren't client settings
+			if p, ok := v.(string); !ok || p == "" {
+				return nil, fmt.Errorf("%s: %s should be a path", path, k)
+			}
+			continue
+		}
+		if _, err = s.Parse(settingValues(v)); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+	return config, nil
+}
+
+// MergeProjectConfig merges the project config found from the working
+// directory over the client config, returning its path or empty when there's
+// none. Flags and environment variables still win over it.
+func MergeProjectConfig() (string, error) {
+	path, err := FindProjectConfig(".")
+	if err != nil || path == "" {
+		return "", err
+	}
+	config, err := LoadProjectConfig(path)
+	if err != nil {
+		return "", err
+	}
+	if err = viper.MergeConfigMap(config); err != nil {
+		return "", fmt.Errorf("%s: %s", path, err)
+	}
+	projectDir = filepath.Dir(path)
+	return path, nil
+}
+
+// ProjectLockPath returns the lock file cr lock writes and cr sync reads, the
+// project config's lock.path or cr.lock next to it, and cr.lock in the working
+// directory outside a project
+func ProjectLockPath() string {
+	return projectPath(viper.GetString(LockPathKey), LockFileName)
+}
+
+// ProjectOverridePath returns the override file runs merge in, the project
+// config's run.override or .cr.override.toml in the working directory
+func ProjectOverridePath() string {
+	if p := viper.GetString(OverridePathKey); p != "" {
+		return projectPath(p, OverrideFileName)
+	}
+	return OverrideFileName
+}
+
+// projectPath resolves path relative to the project config, def when path is
+// empty. Outside a project both are relative to the working directory.
+func projectPath(path string, def string) string {
+	if path == "" {
+		path = def
+	}
+	if filepath.IsAbs(path) || projectDir == "" {
+		return path
+	}
+	return filepath.Join(projectDir, path)
+}
+
+// projectKey reports whether a project config can set key
+func projectKey(key string) bool {
+	for _, k := range projectKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cr-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	nested := filepath.Join(dir, "src", "app")
+	if err = os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if path, err := FindProjectConfig(nested); err != nil || strings.HasPrefix(path, dir) {
+		t.Errorf("A project without a project config shouldn't find one, got %q %v", path, err)
+	}
+	config := filepath.Join(dir, ProjectConfigFileName)
+	if err = ioutil.WriteFile(config, []byte("[crackle]\nregistry = \"work\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := FindProjectConfig(nested); err != nil || path != config {
+		t.Errorf("The project config above the directory should be found, got %q %v", path, err)
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cr-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ProjectConfigFileName)
+
+	valid := "[crackle]\nregistry = \"work\"\n\n[lock]\npath = \"deploy/cr.lock\"\n\n[run]\npull = \"always\"\noverride = \"dev.override.toml\"\n"
+	if err = ioutil.WriteFile(path, []byte(valid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadProjectConfig(path); err != nil {
+		t.Error("A project config of project keys should load, got", err)
+	}
+
+	for _, invalid := range []string{
+		"[crackle]\napi = \"https://evil.example.com/api/\"\n",
+		"[registries.work]\napi = \"https://evil.example.com/api/\"\n",
+		"[run]\nverify_signatures = false\n",
+		"[run]\npull = \"sometimes\"\n",
+		"[lock]\npath = \"\"\n",
+	} {
+		if err = ioutil.WriteFile(path, []byte(invalid), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = LoadProjectConfig(path); err == nil {
+			t.Errorf("Project config %q should be invalid", invalid)
+		}
+	}
+}
+
+func TestProjectPaths(t *testing.T) {
+	defer func() {
+		projectDir = ""
+		viper.Set(LockPathKey, nil)
+		viper.Set(OverridePathKey, nil)
+	}()
+
+	if ProjectLockPath() != LockFileName || ProjectOverridePath() != OverrideFileName {
+		t.Error("Outside a project the lock and override files should be in the working directory")
+	}
+	projectDir = "/src/app"
+	if p := ProjectLockPath(); p != "/src/app/cr.lock" {
+		t.Error("The lock file should default to the project's directory, got", p)
+	}
+	viper.Set(LockPathKey, "deploy/cr.lock")
+	viper.Set(OverridePathKey, "dev.override.toml")
+	if p := ProjectLockPath(); p != "/src/app/deploy/cr.lock" {
+		t.Error("lock.path should be relative to the project config, got", p)
+	}
+	if p := ProjectOverridePath(); p != "/src/app/dev.override.toml" {
+		t.Error("run.override should be relative to the project config, got", p)
+	}
+}
@@ -0,0 +1,215 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// pullBarWidth is the width of the progress bar drawn for each layer
+const pullBarWidth = 30
+
+// errNoDockerSocket is thrown when the docker API can't be reached through a unix socket
+var errNoDockerSocket = errors.New("docker isn't reached through a unix socket")
+
+// pullMessage is a line of the json stream the docker API sends while pulling
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+// layerProgress is how far along a layer of an image being pulled is
+type layerProgress struct {
+	status  string
+	current int64
+	total   int64
+}
+
+// pullProgress tracks the layers of an image being pulled, in the order docker
+// first mentioned them
+type pullProgress struct {
+	order  []string
+	layers map[string]*layerProgress
+}
+
+// newPullProgress returns a pullProgress with no layers
+func newPullProgress() *pullProgress {
+	return &pullProgress{layers: make(map[string]*layerProgress)}
+}
+
+// Update records a message from docker, it returns true when a layer's status
+// changed rather than just its progress. Messages about the image as a whole,
+// which have no layer id, are ignored.
+func (p *pullProgress) Update(m pullMessage) bool {
+	if m.ID == "" || strings.HasPrefix(m.Status, "Pulling from") {
+		return false
+	}
+	l, ok := p.layers[m.ID]
+	if !ok {
+		l = &layerProgress{}
+		p.layers[m.ID] = l
+		p.order = append(p.order, m.ID)
+	}
+	changed := l.status != m.Status
+	l.status = m.Status
+	l.current, l.total = m.ProgressDetail.Current, m.ProgressDetail.Total
+	return changed
+}
+
+// Line renders the progress of a layer, with a bar while it's downloading or
+// extracting
+func (p *pullProgress) Line(id string) string {
+	l := p.layers[id]
+	if l == nil {
+		return ""
+	}
+	if l.total <= 0 {
+		return fmt.Sprintf("%s: %s", id, l.status)
+	}
+	done := int(l.current * pullBarWidth / l.total)
+	if done > pullBarWidth {
+		done = pullBarWidth
+	}
+	bar := strings.Repeat("=", done)
+	if done < pullBarWidth {
+		bar += ">" + strings.Repeat(" ", pullBarWidth-done-1)
+	}
+	return fmt.Sprintf("%s: %s [%s] %s/%s", id, l.status, bar, HumanSize(l.current), HumanSize(l.total))
+}
+
+// Lines renders the progress of every layer
+func (p *pullProgress) Lines() []string {
+	lines := make([]string, len(p.order))
+	for i, id := range p.order {
+		lines[i] = p.Line(id)
+	}
+	return lines
+}
+
+// HumanSize formats a number of bytes the way docker does, such as 12.3MB
+func HumanSize(bytes int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	size := float64(bytes)
+	i := 0
+	for size >= 1000 && i < len(units)-1 {
+		size /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d%s", bytes, units[0])
+	}
+	return fmt.Sprintf("%.1f%s", size, units[i])
+}
+
+// dockerDaemonSocket returns the unix socket of the docker daemon, ok is false when
+// docker is reached some other way
+func dockerDaemonSocket() (socket string, ok bool) {
+	host := os.Getenv("DOCKER_HOST")
+	switch {
+	case host == "" && runtime.GOOS != "windows":
+		return dockerSocket, true
+	case strings.HasPrefix(host, "unix://"):
+		return strings.TrimPrefix(host, "unix://"), true
+	}
+	return "", false
+}
+
+// pullImageAPIContext pulls image through the docker API, sending every message
+// of its progress to progress
+func pullImageAPIContext(ctx context.Context, image string, progress func(pullMessage)) error {
+	socket, ok := dockerDaemonSocket()
+	if !ok {
+		return errNoDockerSocket
+	}
+	ref, err := ParseReference(image)
+	if err != nil {
+		return err
+	}
+	tag := ref.Tag
+	if ref.Digest != "" {
+		tag = ref.Digest
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+	params := url.Values{"fromImage": {ref.Name()}, "tag": {tag}}
+	req, err := http.NewRequest("POST", "http://docker/images/create?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		if json.Unmarshal(b, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return fmt.Errorf("docker pull: %s", apiErr.Message)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var m pullMessage
+		if err = dec.Decode(&m); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if m.Error != "" {
+			return fmt.Errorf("docker pull: %s", m.Error)
+		}
+		progress(m)
+	}
+}
+
+// pullProgressPrinter returns a func printing the progress of a pull to w. At
+// a terminal a bar is redrawn for each layer, otherwise a line is printed each
+// time a layer's status changes.
+func pullProgressPrinter(w io.Writer, redraw bool) func(pullMessage) {
+	p := newPullProgress()
+	drawn := 0
+	return func(m pullMessage) {
+		changed := p.Update(m)
+		if !redraw {
+			if changed {
+				fmt.Fprintln(w, p.Line(m.ID))
+			}
+			return
+		}
+		if drawn > 0 {
+			fmt.Fprintf(w, "\x1b[%dA", drawn)
+		}
+		lines := p.Lines()
+		for _, line := range lines {
+			fmt.Fprintf(w, "\x1b[2K%s\n", line)
+		}
+		drawn = len(lines)
+	}
+}
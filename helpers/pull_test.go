@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func pullMessageOf(id string, status string, current int64, total int64) pullMessage {
+	m := pullMessage{ID: id, Status: status}
+	m.ProgressDetail.Current, m.ProgressDetail.Total = current, total
+	return m
+}
+
+func TestPullProgress(t *testing.T) {
+	p := newPullProgress()
+	if p.Update(pullMessageOf("latest", "Pulling from library/alpine", 0, 0)) {
+		t.Error("The image's own messages shouldn't add a layer")
+	}
+	if !p.Update(pullMessageOf("a1b2", "Pulling fs layer", 0, 0)) {
+		t.Error("A new layer should be a status change")
+	}
+	p.Update(pullMessageOf("c3d4", "Waiting", 0, 0))
+	if !p.Update(pullMessageOf("a1b2", "Downloading", 500, 2000)) {
+		t.Error("Starting to download should be a status change")
+	}
+	if p.Update(pullMessageOf("a1b2", "Downloading", 1000, 2000)) {
+		t.Error("Downloading more shouldn't be a status change")
+	}
+
+	lines := p.Lines()
+	expected := []string{
+		"a1b2: Downloading [===============>              ] 1.0kB/2.0kB",
+		"c3d4: Waiting",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Progress should have %d lines, got %q", len(expected), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d should be %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestPullProgressPrinter(t *testing.T) {
+	var buf bytes.Buffer
+	progress := pullProgressPrinter(&buf, false)
+	progress(pullMessageOf("a1b2", "Downloading", 1, 10))
+	progress(pullMessageOf("a1b2", "Downloading", 5, 10))
+	progress(pullMessageOf("a1b2", "Download complete", 0, 0))
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 {
+		t.Errorf("Without a terminal only status changes should be printed, got %q", lines)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := map[int64]string{0: "0B", 999: "999B", 1000: "1.0kB", 12345678: "12.3MB", 2500000000: "2.5GB"}
+	for n, expected := range tests {
+		if s := HumanSize(n); s != expected {
+			t.Errorf("HumanSize(%d) should be %q, got %q", n, expected, s)
+		}
+	}
+}
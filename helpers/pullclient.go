@@ -0,0 +1,50 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// PullClientHeader carries the PullClientID of a machine when it records a
+// pull, so the registry counts each machine once a day
+const PullClientHeader = "X-Crackle-Client"
+
+// pullClientBytes is the number of random bytes in a PullClientID
+const pullClientBytes = 16
+
+// PullClientPath returns the location of the file holding this machine's PullClientID
+func PullClientPath() string {
+	return fmt.Sprintf("%s/client_id", DataDir())
+}
+
+// PullClientID returns the random id this machine records pulls under, it's
+// made the first time it's needed. It identifies nothing but the machine's
+// config dir, "" is returned when it can't be kept.
+func PullClientID() string {
+	b, err := ioutil.ReadFile(PullClientPath())
+	if id := strings.TrimSpace(string(b)); err == nil && len(id) == pullClientBytes*2 {
+		return id
+	}
+	if err != nil && !os.IsNotExist(err) {
+		Debugf("couldn't read %s: %s", PullClientPath(), err)
+		return ""
+	}
+
+	random := make([]byte, pullClientBytes)
+	if _, err = rand.Read(random); err != nil {
+		return ""
+	}
+	id := hex.EncodeToString(random)
+	if err = EnsureConfigDirs(); err == nil {
+		err = writeFileAtomic(PullClientPath(), []byte(id+"\n"), 0600)
+	}
+	if err != nil {
+		Debugf("couldn't save %s: %s", PullClientPath(), err)
+		return ""
+	}
+	return id
+}
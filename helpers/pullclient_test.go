@@ -0,0 +1,15 @@
+package helpers
+
+import "testing"
+
+func TestPullClientID(t *testing.T) {
+	defer tempConfigDir(t)()
+
+	id := PullClientID()
+	if len(id) != pullClientBytes*2 {
+		t.Fatalf("PullClientID should be %d hex characters, got %q", pullClientBytes*2, id)
+	}
+	if again := PullClientID(); again != id {
+		t.Errorf("PullClientID should be kept, got %q then %q", id, again)
+	}
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// RateLimitLimitHeader is the number of requests allowed in a window
+	RateLimitLimitHeader = "RateLimit-Limit"
+	// RateLimitRemainingHeader is the number of requests left in the current window
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	// RateLimitResetHeader is the number of seconds until the current window ends
+	RateLimitResetHeader = "RateLimit-Reset"
+)
+
+// RateLimit is where a key stands against its limit after a request
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Duration
+	Allowed   bool
+}
+
+// ResetSeconds returns Reset rounded up to whole seconds, as the RateLimit-Reset
+// and Retry-After headers give it
+func (r RateLimit) ResetSeconds() string {
+	return strconv.Itoa(int((r.Reset + time.Second - 1) / time.Second))
+}
+
+// rateWindow counts the requests of a key since start
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimiter counts the requests of each key, such as a token or an IP, over
+// fixed windows. It's safe to use from several goroutines.
+type RateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+	swept   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter with no requests counted
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{windows: make(map[string]*rateWindow)}
+}
+
+// Allow counts a request of key made at now, it's allowed while key has made
+// no more than limit requests in the current window
+func (l *RateLimiter) Allow(key string, limit int, window time.Duration, now time.Time) RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Windows that ended are forgotten once a window, so idle keys don't pile up
+	if now.Sub(l.swept) >= window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= window {
+				delete(l.windows, k)
+			}
+		}
+		l.swept = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	w.count++
+
+	r := RateLimit{Limit: limit, Reset: w.start.Add(window).Sub(now), Allowed: w.count <= limit}
+	if r.Allowed {
+		r.Remaining = limit - w.count
+	}
+	return r
+}
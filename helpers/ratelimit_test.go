@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter()
+	now := time.Date(2017, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	for i := 2; i >= 0; i-- {
+		r := l.Allow("ip:10.0.0.1", 3, time.Minute, now)
+		if !r.Allowed || r.Remaining != i {
+			t.Fatalf("Request should be allowed with %d remaining, got %+v", i, r)
+		}
+	}
+	r := l.Allow("ip:10.0.0.1", 3, time.Minute, now.Add(20*time.Second))
+	if r.Allowed || r.Remaining != 0 {
+		t.Errorf("Fourth request should be refused, got %+v", r)
+	}
+	if r.ResetSeconds() != "40" {
+		t.Errorf("Window should reset in 40 seconds, got %s", r.ResetSeconds())
+	}
+	if r = l.Allow("ip:10.0.0.2", 3, time.Minute, now); !r.Allowed {
+		t.Errorf("Other keys should be counted apart, got %+v", r)
+	}
+	if r = l.Allow("ip:10.0.0.1", 3, time.Minute, now.Add(time.Minute)); !r.Allowed || r.Remaining != 2 {
+		t.Errorf("Request in the next window should be allowed, got %+v", r)
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l := NewRateLimiter()
+	now := time.Date(2017, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	l.Allow("ip:10.0.0.1", 3, time.Minute, now)
+	l.Allow("ip:10.0.0.2", 3, time.Minute, now.Add(2*time.Minute))
+	if len(l.windows) != 1 {
+		t.Errorf("Ended windows should be forgotten, got %d", len(l.windows))
+	}
+}
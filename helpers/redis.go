@@ -0,0 +1,228 @@
+package helpers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// redisTimeout is how long connecting to Redis and each command can take
+	redisTimeout = time.Second
+	// maxIdleRedisConns is how many connections to Redis are kept open between commands
+	maxIdleRedisConns = 16
+)
+
+// ErrInvalidRedisURL is thrown when a Redis URL isn't redis://[:password@]host[:port][/db]
+var ErrInvalidRedisURL = errors.New("Redis URL is invalid, use redis://[:password@]host[:port][/db]")
+
+// RedisError is an error Redis answered a command with
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// RedisClient sends commands to a Redis server over a pool of connections. It's
+// safe to use from several goroutines.
+type RedisClient struct {
+	Addr     string
+	Password string
+	DB       int
+
+	mu   sync.Mutex
+	idle []*redisConn
+}
+
+// redisConn is a connection to Redis, ready for its next command
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// NewRedisClient returns a client of the Redis server at a URL such as
+// redis://:password@localhost:6379/0, nothing is connected to until it's used
+func NewRedisClient(rawURL string) (*RedisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Hostname() == "" {
+		return nil, ErrInvalidRedisURL
+	}
+	c := &RedisClient{Addr: u.Host}
+	if u.Port() == "" {
+		c.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.Password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if c.DB, err = strconv.Atoi(db); err != nil || c.DB < 0 {
+			return nil, ErrInvalidRedisURL
+		}
+	}
+	return c, nil
+}
+
+// Do sends a command and returns Redis' answer: a string, []byte, int64, nil
+// or []interface{} of them. Errors Redis answers with are RedisErrors, in
+// arrays they're elements.
+func (c *RedisClient) Do(args ...string) (interface{}, error) {
+	conn, err := c.conn()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.do(args...)
+	if _, ok := err.(RedisError); err != nil && !ok {
+		// The connection's state is unknown after a network error
+		conn.Close()
+		return nil, err
+	}
+	c.release(conn)
+	return reply, err
+}
+
+// HGet returns a field of a hash, ok is false when it isn't set
+func (c *RedisClient) HGet(key string, field string) (value []byte, ok bool, err error) {
+	reply, err := c.Do("HGET", key, field)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok = reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: HGET answered %T", reply)
+	}
+	return value, true, nil
+}
+
+// HSet sets a field of a hash, which expires after ttl
+func (c *RedisClient) HSet(key string, field string, value []byte, ttl time.Duration) error {
+	if _, err := c.Do("HSET", key, field, string(value)); err != nil {
+		return err
+	}
+	_, err := c.Do("PEXPIRE", key, strconv.FormatInt(int64(ttl/time.Millisecond), 10))
+	return err
+}
+
+// Del deletes keys
+func (c *RedisClient) Del(keys ...string) error {
+	_, err := c.Do(append([]string{"DEL"}, keys...)...)
+	return err
+}
+
+// conn returns an idle connection, or a new one authenticated and on the
+// client's DB
+func (c *RedisClient) conn() (*redisConn, error) {
+	c.mu.Lock()
+	if n := len(c.idle); n > 0 {
+		conn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return conn, nil
+	}
+	c.mu.Unlock()
+
+	nc, err := net.DialTimeout("tcp", c.Addr, redisTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{Conn: nc, r: bufio.NewReader(nc)}
+	if c.Password != "" {
+		if _, err = conn.do("AUTH", c.Password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if c.DB != 0 {
+		if _, err = conn.do("SELECT", strconv.Itoa(c.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// release keeps a connection for the next command, or closes it when enough are
+func (c *RedisClient) release(conn *redisConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.idle) >= maxIdleRedisConns {
+		conn.Close()
+		return
+	}
+	c.idle = append(c.idle, conn)
+}
+
+// do writes a command as an array of bulk strings and reads its answer
+func (conn *redisConn) do(args ...string) (interface{}, error) {
+	if err := conn.SetDeadline(time.Now().Add(redisTimeout)); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(conn, b.String()); err != nil {
+		return nil, err
+	}
+	return readRedisReply(conn.r)
+}
+
+// readRedisReply reads an answer in the Redis serialization protocol
+func readRedisReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: malformed answer %q", line)
+	}
+	kind, rest := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, RedisError(rest)
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		// Errors in an array are its elements, so the rest of it is still read
+		replies := make([]interface{}, n)
+		for i := range replies {
+			replies[i], err = readRedisReply(r)
+			if redisErr, ok := err.(RedisError); ok {
+				replies[i] = redisErr
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		return replies, nil
+	}
+	return nil, fmt.Errorf("redis: malformed answer %q", line)
+}
@@ -0,0 +1,135 @@
+package helpers
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRedisClient(t *testing.T) {
+	c, err := NewRedisClient("redis://:secret@cache.example.com/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Addr != "cache.example.com:6379" || c.Password != "secret" || c.DB != 2 {
+		t.Errorf("Unexpected client %+v", c)
+	}
+	for _, u := range []string{"http://localhost:6379", "redis://", "redis://localhost/db"} {
+		if _, err = NewRedisClient(u); err != ErrInvalidRedisURL {
+			t.Errorf("%s should return ErrInvalidRedisURL, got %v", u, err)
+		}
+	}
+}
+
+func TestReadRedisReply(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("+OK\r\n:3\r\n$5\r\nhello\r\n$-1\r\n*2\r\n$1\r\na\r\n-ERR nested\r\n-ERR wrong\r\n"))
+	expected := []string{"OK", "3", "[104 101 108 108 111]", "<nil>", "[[97] redis: ERR nested]"}
+	for _, e := range expected {
+		reply, err := readRedisReply(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(reply); s != e {
+			t.Errorf("Expected reply %s, got %s", e, s)
+		}
+	}
+	if _, err := readRedisReply(r); err != RedisError("ERR wrong") {
+		t.Errorf("Expected RedisError, got %v", err)
+	}
+}
+
+// fakeRedis serves HGET, HSET, PEXPIRE, DEL and AUTH from a map, answering
+// AUTH only with password
+func fakeRedis(t *testing.T, password string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	hashes := map[string]map[string]string{}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					reply, err := readRedisReply(r)
+					if err != nil {
+						return
+					}
+					args := []string{}
+					for _, arg := range reply.([]interface{}) {
+						args = append(args, string(arg.([]byte)))
+					}
+					switch args[0] {
+					case "AUTH":
+						if args[1] != password {
+							fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+							continue
+						}
+						fmt.Fprint(conn, "+OK\r\n")
+					case "HGET":
+						v, ok := hashes[args[1]][args[2]]
+						if !ok {
+							fmt.Fprint(conn, "$-1\r\n")
+							continue
+						}
+						fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+					case "HSET":
+						if hashes[args[1]] == nil {
+							hashes[args[1]] = map[string]string{}
+						}
+						hashes[args[1]][args[2]] = args[3]
+						fmt.Fprint(conn, ":1\r\n")
+					case "PEXPIRE":
+						fmt.Fprint(conn, ":1\r\n")
+					case "DEL":
+						for _, k := range args[1:] {
+							delete(hashes, k)
+						}
+						fmt.Fprintf(conn, ":%d\r\n", len(args)-1)
+					default:
+						fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+					}
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestRedisClient(t *testing.T) {
+	c := &RedisClient{Addr: fakeRedis(t, "secret"), Password: "secret"}
+	if _, ok, err := c.HGet("crackle:package:testing", "/api/package/testing"); ok || err != nil {
+		t.Errorf("Nothing should be cached yet, got %v %v", ok, err)
+	}
+	if err := c.HSet("crackle:package:testing", "/api/package/testing", []byte("{\r\n}"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	v, ok, err := c.HGet("crackle:package:testing", "/api/package/testing")
+	if !ok || err != nil || string(v) != "{\r\n}" {
+		t.Errorf("Expected the cached value, got %q %v %v", v, ok, err)
+	}
+	if _, err = c.Do("FLUSHALL"); err != RedisError("ERR unknown command 'FLUSHALL'") {
+		t.Errorf("Expected RedisError, got %v", err)
+	}
+	// The connection is still usable after an error answer
+	if err = c.Del("crackle:package:testing"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ = c.HGet("crackle:package:testing", "/api/package/testing"); ok {
+		t.Error("The hash should be deleted")
+	}
+
+	wrong := &RedisClient{Addr: c.Addr, Password: "wrong"}
+	if _, _, err = wrong.HGet("crackle:search", "/api/search"); err != RedisError("WRONGPASS invalid password") {
+		t.Errorf("Expected a wrong password, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	referenceTag    = match(`^[\w][\w.-]{0,127}$`)
+	referenceDigest = match(`^[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+)
+
+// Reference represents a docker image reference split in to its parts, for
+// myreg:5000/team/img:1.0@sha256:... the registry is "myreg:5000", repository
+// "team/img", tag "1.0" and digest "sha256:...". Images on Docker Hub have
+// no registry.
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses a docker image reference, the registry, tag and digest
+// are optional
+func ParseReference(s string) (*Reference, error) {
+	r := &Reference{}
+	name := s
+
+	if i := strings.Index(name, "@"); i != -1 {
+		r.Digest = name[i+1:]
+		name = name[:i]
+		if !referenceDigest.MatchString(r.Digest) {
+			return nil, fmt.Errorf("Digest \"%v\" of image \"%v\" is invalid", r.Digest, s)
+		}
+	}
+
+	// The tag follows the last colon, unless that colon is a registry port
+	if i := strings.LastIndex(name, ":"); i != -1 && i > strings.LastIndex(name, "/") {
+		r.Tag = name[i+1:]
+		name = name[:i]
+		if !referenceTag.MatchString(r.Tag) {
+			return nil, fmt.Errorf("Tag \"%v\" of image \"%v\" is invalid", r.Tag, s)
+		}
+	}
+
+	// The first component is a registry when it looks like a host
+	if i := strings.Index(name, "/"); i != -1 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			r.Registry = first
+			name = name[i+1:]
+		}
+	}
+
+	if len(name) == 0 {
+		return nil, fmt.Errorf("Image \"%v\" has no repository", s)
+	}
+	for _, component := range strings.Split(name, "/") {
+		if len(component) == 0 || strings.Contains(component, ":") {
+			return nil, fmt.Errorf("Repository \"%v\" of image \"%v\" is invalid", name, s)
+		}
+	}
+	r.Repository = name
+
+	return r, nil
+}
+
+// Name returns the registry and repository of a Reference without tag or digest
+func (r *Reference) Name() string {
+	if r.Registry == "" {
+		return r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the full reference
+func (r *Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package helpers
+
+import "testing"
+
+func TestParseReference(t *testing.T) {
+	valid := map[string]Reference{
+		"sunshinekitty/testing":              {Repository: "sunshinekitty/testing"},
+		"sunshinekitty/testing:latest":       {Repository: "sunshinekitty/testing", Tag: "latest"},
+		"redis:4.0":                          {Repository: "redis", Tag: "4.0"},
+		"myreg:5000/img":                     {Registry: "myreg:5000", Repository: "img"},
+		"myreg:5000/img:tag":                 {Registry: "myreg:5000", Repository: "img", Tag: "tag"},
+		"localhost/team/img:1":               {Registry: "localhost", Repository: "team/img", Tag: "1"},
+		"us.gcr.io/my-project/my-image:test": {Registry: "us.gcr.io", Repository: "my-project/my-image", Tag: "test"},
+		"img@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef": {
+			Repository: "img", Digest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		"myreg:5000/img:1.0@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef": {
+			Registry: "myreg:5000", Repository: "img", Tag: "1.0", Digest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+	}
+	for s, expected := range valid {
+		r, err := ParseReference(s)
+		if err != nil {
+			t.Errorf("Reference \"%s\" should be valid, got %s", s, err)
+			continue
+		}
+		if *r != expected {
+			t.Errorf("Reference \"%s\" should parse to %+v, got %+v", s, expected, *r)
+		}
+		if r.String() != s {
+			t.Errorf("Reference \"%s\" should format back to itself, got %s", s, r.String())
+		}
+	}
+
+	invalid := []string{"", "img:", ":tag", "img@", "img@sha256:xyz", "a//b", "myreg:5000/", "img:-tag"}
+	for _, s := range invalid {
+		if _, err := ParseReference(s); err == nil {
+			t.Errorf("Reference \"%s\" should be invalid", s)
+		}
+	}
+}
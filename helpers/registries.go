@@ -0,0 +1,128 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// RegistriesKey is the client config table the Crackle registries cr can
+	// use are kept in, each a table of its name holding api, token and mirrors
+	RegistriesKey = "registries"
+	// RegistryKey is the client config key of the registry commands use when
+	// none is chosen, crackle.api is used when it's unset
+	RegistryKey = "crackle.registry"
+	// RegistrySeparator separates a registry's name from a package name in a
+	// package argument such as work:tool
+	RegistrySeparator = ":"
+)
+
+// ErrUnknownRegistry is thrown when a registry isn't in the client config
+var ErrUnknownRegistry = errors.New("unknown registry")
+
+// activeRegistry is the registry UseRegistry pointed cr at
+var activeRegistry string
+
+// Registry represents a Crackle registry in the client config
+type Registry struct {
+	Name    string   `json:"name"`
+	API     string   `json:"api"`
+	Mirrors []string `json:"mirrors,omitempty"`
+	// Token is used over credentials saved by cr login, it's never shown
+	Token   string `json:"-"`
+	Default bool   `json:"default"`
+}
+
+// ValidRegistryName validates the name of a registry in the client config,
+// which follows the rules of package names
+func ValidRegistryName(n string) bool {
+	return validNamePart(n)
+}
+
+// Registries returns the registries in the client config sorted by name
+func Registries() []Registry {
+	var registries []Registry
+	for name := range viper.GetStringMap(RegistriesKey) {
+		r, err := LookupRegistry(name)
+		if err != nil {
+			continue
+		}
+		registries = append(registries, *r)
+	}
+	sort.Slice(registries, func(i, j int) bool { return registries[i].Name < registries[j].Name })
+	return registries
+}
+
+// LookupRegistry returns the registry called name in the client config,
+// ErrUnknownRegistry is thrown when there's none
+func LookupRegistry(name string) (*Registry, error) {
+	if !ValidRegistryName(name) || !viper.IsSet(RegistriesKey+"."+name) {
+		return nil, ErrUnknownRegistry
+	}
+	key := RegistriesKey + "." + name + "."
+	return &Registry{
+		Name:    name,
+		API:     viper.GetString(key + "api"),
+		Mirrors: viper.GetStringSlice(key + "mirrors"),
+		Token:   viper.GetString(key + "token"),
+		Default: viper.GetString(RegistryKey) == name,
+	}, nil
+}
+
+// SetRegistry writes a registry to the client config, keeping a token already
+// there. An empty api removes it.
+func SetRegistry(name string, api string) error {
+	if api == "" {
+		return SetClientConfig(RegistriesKey+"."+name, nil)
+	}
+	return SetClientConfig(RegistriesKey+"."+name+".api", api)
+}
+
+// UseRegistry points cr at the registry called name for the rest of the
+// command, its api and mirrors stand in for crackle.api and crackle.mirrors
+// and its token for saved credentials
+func UseRegistry(name string) error {
+	r, err := LookupRegistry(name)
+	if err != nil {
+		return fmt.Errorf("no registry %s in the client config, see cr registry list", name)
+	}
+	api, err := APIURL(r.API)
+	if err != nil {
+		return fmt.Errorf("registry %s: %s", name, err)
+	}
+	viper.Set("crackle.api", api.String())
+	viper.Set("crackle.mirrors", r.Mirrors)
+	activeRegistry = name
+	return nil
+}
+
+// registryToken returns the token of the registry cr is using, empty when it
+// has none and credentials saved by cr login should be used
+func registryToken() string {
+	if activeRegistry == "" {
+		return ""
+	}
+	r, err := LookupRegistry(activeRegistry)
+	if err != nil {
+		return ""
+	}
+	return r.Token
+}
+
+// SplitRegistryPrefix splits the registry off a package argument such as
+// work:tool, the registry is empty when the argument has no prefix naming a
+// registry in the client config
+func SplitRegistryPrefix(s string) (registry string, name string) {
+	i := strings.Index(s, RegistrySeparator)
+	if i == -1 {
+		return "", s
+	}
+	if _, err := LookupRegistry(s[:i]); err != nil {
+		return "", s
+	}
+	return s[:i], s[i+1:]
+}
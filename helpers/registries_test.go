@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestRegistries(t *testing.T) {
+	viper.Set(RegistriesKey, map[string]interface{}{
+		"work":   map[string]interface{}{"api": "https://crackle.work.example.com/api", "token": "secret"},
+		"public": map[string]interface{}{"api": "https://api.crackle.pm/api/"},
+	})
+	viper.Set(RegistryKey, "public")
+	defer func() {
+		viper.Set(RegistriesKey, nil)
+		viper.Set(RegistryKey, nil)
+		viper.Set("crackle.api", nil)
+		viper.Set("crackle.mirrors", nil)
+		activeRegistry = ""
+	}()
+
+	registries := Registries()
+	if len(registries) != 2 || registries[0].Name != "public" || !registries[0].Default || registries[1].Default {
+		t.Errorf("Registries should be listed by name with the default marked, got %+v", registries)
+	}
+	if _, err := LookupRegistry("home"); err != ErrUnknownRegistry {
+		t.Error("Looking up a registry that isn't configured should return ErrUnknownRegistry, got", err)
+	}
+
+	tests := []struct {
+		arg      string
+		registry string
+		name     string
+	}{
+		{"work:tool", "work", "tool"},
+		{"work:alice/tool@1.0.0", "work", "alice/tool@1.0.0"},
+		{"home:tool", "", "home:tool"},
+		{"tool", "", "tool"},
+	}
+	for _, test := range tests {
+		if registry, name := SplitRegistryPrefix(test.arg); registry != test.registry || name != test.name {
+			t.Errorf("%s should split into %q and %q, got %q and %q", test.arg, test.registry, test.name, registry, name)
+		}
+	}
+
+	if err := UseRegistry("home"); err == nil {
+		t.Error("Using a registry that isn't configured should fail")
+	}
+	if err := UseRegistry("work"); err != nil {
+		t.Fatal("Using a configured registry should work, got", err)
+	}
+	if api := viper.GetString("crackle.api"); api != "https://crackle.work.example.com/api/" {
+		t.Error("Using a registry should point crackle.api at it, got", api)
+	}
+	os.Unsetenv(TokenEnv)
+	if c, err := LoadCredentials(); err != nil || c.Token != "secret" {
+		t.Errorf("The token of the registry in use should be used, got %+v %v", c, err)
+	}
+}
@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DockerHub is the registry of images that don't name one
+const DockerHub = "docker.io"
+
+// ErrRegistryNotAllowed is thrown when a repository's registry isn't in crackle.allowed_registries
+var ErrRegistryNotAllowed = errors.New("registry is not allowed")
+
+// AllowedRegistries returns the registries listed under crackle.allowed_registries
+// in the client config, an empty list allows every registry
+func AllowedRegistries() []string {
+	var registries []string
+	for _, r := range viper.GetStringSlice("crackle.allowed_registries") {
+		if r = normalizeRegistry(r); r != "" {
+			registries = append(registries, r)
+		}
+	}
+	return registries
+}
+
+// RegistryAllowed returns true when a registry is in the allow-list, images on
+// Docker Hub have no registry and are matched as "docker.io"
+func RegistryAllowed(registry string) bool {
+	allowed := AllowedRegistries()
+	if len(allowed) == 0 {
+		return true
+	}
+	registry = normalizeRegistry(registry)
+	if registry == "" {
+		registry = DockerHub
+	}
+	for _, r := range allowed {
+		if registry == r {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidRegistry validates that a repository points at an allowed registry
+func ValidRegistry(repository string) error {
+	ref, err := ParseReference(repository)
+	if err != nil {
+		return err
+	}
+	if !RegistryAllowed(ref.Registry) {
+		registry := ref.Registry
+		if registry == "" {
+			registry = DockerHub
+		}
+		return fmt.Errorf("%w: \"%v\" of repository \"%v\", allowed registries are: %v",
+			ErrRegistryNotAllowed, registry, repository, strings.Join(AllowedRegistries(), ", "))
+	}
+	return nil
+}
+
+// normalizeRegistry lower cases a registry and maps Docker Hub's aliases to "docker.io"
+func normalizeRegistry(r string) string {
+	r = strings.ToLower(strings.TrimSpace(r))
+	r = strings.TrimPrefix(strings.TrimPrefix(r, "https://"), "http://")
+	r = strings.TrimSuffix(r, "/")
+	switch r {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return DockerHub
+	}
+	return r
+}
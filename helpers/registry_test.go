@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidRegistry(t *testing.T) {
+	viper.Set("crackle.allowed_registries", nil)
+	if err := ValidRegistry("sunshinekitty/testing:latest"); err != nil {
+		t.Error("Every registry should be allowed without an allow-list, got", err)
+	}
+
+	viper.Set("crackle.allowed_registries", []string{"Registry.Example.com", "myreg:5000", "index.docker.io"})
+	defer viper.Set("crackle.allowed_registries", nil)
+
+	allowed := []string{
+		"registry.example.com/team/img:1.0",
+		"myreg:5000/img",
+		"redis:4.0",
+		"docker.io/library/redis",
+	}
+	for _, r := range allowed {
+		if err := ValidRegistry(r); err != nil {
+			t.Errorf("Repository \"%s\" should be allowed, got %s", r, err)
+		}
+	}
+
+	disallowed := []string{
+		"evil.example.com/team/img:1.0",
+		"myreg:5001/img",
+		"registry.example.com.evil.com/img",
+		"localhost/img",
+	}
+	for _, r := range disallowed {
+		if err := ValidRegistry(r); !errors.Is(err, ErrRegistryNotAllowed) {
+			t.Errorf("Repository \"%s\" should return ErrRegistryNotAllowed, got %v", r, err)
+		}
+	}
+
+	viper.Set("crackle.allowed_registries", []string{"registry.example.com"})
+	if err := ValidRegistry("redis:4.0"); !errors.Is(err, ErrRegistryNotAllowed) {
+		t.Error("Docker Hub should only be allowed when listed, got", err)
+	}
+}
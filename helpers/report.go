@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ReportOpen is the status of a report no admin has looked at yet
+	ReportOpen = "open"
+	// ReportDismissed is the status of a report an admin found nothing wrong with
+	ReportDismissed = "dismissed"
+	// ReportRemoved is the status of a report whose package an admin removed
+	ReportRemoved = "removed"
+
+	// maxReportReasonLength is the longest reason a package can be reported for
+	maxReportReasonLength = 1000
+)
+
+// ReportStatuses are every status a report can have
+var ReportStatuses = []string{ReportOpen, ReportDismissed, ReportRemoved}
+
+var (
+	// ErrEmptyReportReason is thrown when a package is reported without saying why
+	ErrEmptyReportReason = errors.New("Say why the package is reported")
+	// ErrLongReportReason is thrown when the reason a package is reported for is too long
+	ErrLongReportReason = fmt.Errorf("reason is too long (>%d chars)", maxReportReasonLength)
+)
+
+// ValidReportReason validates why a user reported a package
+func ValidReportReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrEmptyReportReason
+	}
+	return validText("reason", &reason, maxReportReasonLength, ErrLongReportReason)
+}
+
+// ValidReportStatus validates a status to list reports by or set on one
+func ValidReportStatus(status string) error {
+	for _, s := range ReportStatuses {
+		if s == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("Status \"%s\" is invalid, use one of %s", status, strings.Join(ReportStatuses, ", "))
+}
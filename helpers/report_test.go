@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidReportReason(t *testing.T) {
+	if err := ValidReportReason("Mines bitcoin in its entrypoint"); err != nil {
+		t.Errorf("Reason should be valid, got %s", err)
+	}
+	if err := ValidReportReason("  "); err != ErrEmptyReportReason {
+		t.Errorf("A blank reason should be ErrEmptyReportReason, got %v", err)
+	}
+	if err := ValidReportReason(strings.Repeat("a", maxReportReasonLength+1)); err != ErrLongReportReason {
+		t.Errorf("A long reason should be ErrLongReportReason, got %v", err)
+	}
+}
+
+func TestValidReportStatus(t *testing.T) {
+	for _, status := range ReportStatuses {
+		if err := ValidReportStatus(status); err != nil {
+			t.Errorf("%s should be valid, got %s", status, err)
+		}
+	}
+	for _, status := range []string{"", "closed", "Open"} {
+		if err := ValidReportStatus(status); err == nil {
+			t.Errorf("%s should be invalid", status)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// DefaultReservedNames are package names that can never be published, they
+	// would be confused with cr itself, its commands or the Crackle service
+	DefaultReservedNames = []string{
+		"admin", "api", "cr", "crackle", "docker", "exec", "get", "help", "install",
+		"login", "logout", "official", "publish", "registry", "root", "run",
+		"search", "system", "upload", "version", "www",
+	}
+	// DefaultBlockedNames are offensive package names that can never be published
+	DefaultBlockedNames = []string{"cunt", "fuck", "nazi", "shit"}
+)
+
+// ReservedNames returns every package name that can't be published, the defaults
+// plus any listed under reserved_names in the client or server config
+func ReservedNames() []string {
+	names := make([]string, 0, len(DefaultReservedNames)+len(DefaultBlockedNames))
+	names = append(names, DefaultReservedNames...)
+	names = append(names, DefaultBlockedNames...)
+	for _, n := range viper.GetStringSlice("reserved_names") {
+		names = append(names, strings.ToLower(strings.TrimSpace(n)))
+	}
+	return names
+}
+
+// IsReservedName returns true when a package name is reserved or blocked
+func IsReservedName(n string) bool {
+	for _, r := range ReservedNames() {
+		if n == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SearchSortRelevance sorts search results by how well they match the query,
+	// best first. It's the default when there's a query.
+	SearchSortRelevance = "relevance"
+	// SearchSortPulls sorts search results by most pulled first
+	SearchSortPulls = "pulls"
+	// SearchSortUpdated sorts search results by most recently updated first
+	SearchSortUpdated = "updated"
+	// SearchSortName sorts search results by name
+	SearchSortName = "name"
+
+	// DefaultSearchLimit is the number of search results returned when no limit is given
+	DefaultSearchLimit = 20
+	// MaxSearchLimit is the most search results returned at once
+	MaxSearchLimit = 100
+)
+
+// SearchWeights tunes how relevant a package is to a search query. Its rank is
+// the sum of each weight times a score of about 0 to 1: how well its text
+// matches, whether its name is the query, how popular it is, with
+// PullsMidpoint pulls scoring half, and how recently it was published, halving
+// every RecencyHalfLife days.
+type SearchWeights struct {
+	Text            float64
+	ExactName       float64
+	Pulls           float64
+	PullsMidpoint   int
+	Recency         float64
+	RecencyHalfLife int
+}
+
+// DefaultSearchWeights rank an exact name first, then popular packages over
+// ones that only match a little better
+var DefaultSearchWeights = SearchWeights{
+	Text:            1,
+	ExactName:       1,
+	Pulls:           0.5,
+	PullsMidpoint:   1000,
+	Recency:         0.1,
+	RecencyHalfLife: 180,
+}
+
+// RankSQL returns the SQL expression ranking a package for a query. text is the
+// expression of its text match, and query, pulls and age those of the query,
+// its total pulls and the days since its latest version was published. Weights
+// of 0 leave their score out.
+func (w SearchWeights) RankSQL(text string, query string, pulls string, age string) string {
+	var terms []string
+	if w.Text != 0 {
+		terms = append(terms, fmt.Sprintf("%s * %s", formatWeight(w.Text), text))
+	}
+	if w.ExactName != 0 {
+		terms = append(terms, fmt.Sprintf("%s * CASE WHEN name = %s THEN 1 ELSE 0 END", formatWeight(w.ExactName), query))
+	}
+	if w.Pulls != 0 && w.PullsMidpoint > 0 {
+		terms = append(terms, fmt.Sprintf("%s * %s / (%[2]s + %d.0)", formatWeight(w.Pulls), pulls, w.PullsMidpoint))
+	}
+	// In whole days so a package's rank, and the next tokens holding it, only
+	// change once a day
+	if w.Recency != 0 && w.RecencyHalfLife > 0 {
+		terms = append(terms, fmt.Sprintf("%s * power(0.5, %s / %d.0)",
+			formatWeight(w.Recency), age, w.RecencyHalfLife))
+	}
+	if len(terms) == 0 {
+		return "0"
+	}
+	return strings.Join(terms, " + ")
+}
+
+// formatWeight formats a weight as a SQL number
+func formatWeight(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// ValidSearchSort validates a search sort order
+func ValidSearchSort(s string) bool {
+	return s == SearchSortRelevance || s == SearchSortPulls || s == SearchSortUpdated || s == SearchSortName
+}
+
+// EscapeLike escapes the wildcards of a SQL LIKE pattern so s only matches itself
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
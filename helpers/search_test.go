@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchWeightsRankSQL(t *testing.T) {
+	rank := DefaultSearchWeights.RankSQL("ts_rank(v, q)", "$1", "pulls", "(current_date - updated_at::date)")
+	for _, term := range []string{"1 * ts_rank(v, q)", "CASE WHEN name = $1 THEN 1", "0.5 * pulls / (pulls + 1000.0)", "/ 180.0)"} {
+		if !strings.Contains(rank, term) {
+			t.Errorf("Default rank should contain %s, got %s", term, rank)
+		}
+	}
+
+	w := SearchWeights{Text: 2, Recency: 0.25, RecencyHalfLife: 30}
+	rank = w.RankSQL("ts_rank(v, q)", "$1", "pulls", "(current_date - updated_at::date)")
+	expected := "2 * ts_rank(v, q) + 0.25 * power(0.5, (current_date - updated_at::date) / 30.0)"
+	if rank != expected {
+		t.Errorf("Expected %s, got %s", expected, rank)
+	}
+	if rank = (SearchWeights{}).RankSQL("ts_rank(v, q)", "$1", "pulls", "(current_date - updated_at::date)"); rank != "0" {
+		t.Errorf("Zero weights should rank everything 0, got %s", rank)
+	}
+}
+
+func TestValidSearchWeightSettings(t *testing.T) {
+	s := LookupServerSetting("search.pulls_weight")
+	if s == nil {
+		t.Fatal("search.pulls_weight should be a server setting")
+	}
+	if value, err := s.Parse([]string{"0.75"}); err != nil || value != 0.75 {
+		t.Errorf("0.75 should parse to 0.75, got %v, %v", value, err)
+	}
+	for _, v := range []string{"-1", "lots", "+Inf"} {
+		if _, err := s.Parse([]string{v}); err == nil {
+			t.Errorf("%s should be an invalid weight", v)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semverPattern matches versions such as 1, 1.2, v1.2.3 and 1.2.3-rc.1+build,
+// missing numbers are 0
+var semverPattern = match(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// rangeVersionPattern matches a version in a range, where numbers can be left
+// out or wildcards
+var rangeVersionPattern = match(`^v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?$`)
+
+// ErrInvalidVersionRange is thrown when a version range can't be parsed
+var ErrInvalidVersionRange = errors.New("version range is invalid")
+
+// semver is a parsed semantic version
+type semver struct {
+	n   [3]int
+	pre string
+}
+
+// parseSemver parses a version, ok is false for tags such as latest that
+// aren't versions
+func parseSemver(version string) (v semver, ok bool) {
+	m := semverPattern.FindStringSubmatch(version)
+	if m == nil {
+		return v, false
+	}
+	for i := range v.n {
+		v.n[i], _ = strconv.Atoi(m[i+1])
+	}
+	v.pre = m[4]
+	return v, true
+}
+
+// compare returns -1, 0 or 1 as v is older, the same as or newer than o. A
+// prerelease is older than its release.
+func (v semver) compare(o semver) int {
+	for i := range v.n {
+		switch {
+		case v.n[i] < o.n[i]:
+			return -1
+		case v.n[i] > o.n[i]:
+			return 1
+		}
+	}
+	switch {
+	case v.pre == o.pre:
+		return 0
+	case v.pre == "":
+		return 1
+	case o.pre == "":
+		return -1
+	}
+	return comparePrerelease(v.pre, o.pre)
+}
+
+// comparePrerelease compares the dot separated identifiers of prereleases,
+// numbers numerically and anything else alphabetically
+func comparePrerelease(a string, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case errA == nil && errB != nil:
+			return -1
+		case errA != nil && errB == nil:
+			return 1
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	switch {
+	case len(pa) < len(pb):
+		return -1
+	case len(pa) > len(pb):
+		return 1
+	}
+	return 0
+}
+
+// versionBound is a single comparison a version has to satisfy
+type versionBound struct {
+	op string
+	v  semver
+}
+
+func (b versionBound) matches(v semver) bool {
+	c := v.compare(b.v)
+	switch b.op {
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return c == 0
+}
+
+// VersionRange is a parsed range of versions such as ^1.2, ~1.2.3, 1.x or
+// ">=1.0 <2.0", alternatives are separated by ||
+type VersionRange struct {
+	// sets are alternatives, a version matches when it satisfies every bound
+	// of any of them
+	sets [][]versionBound
+}
+
+// ParseVersionRange parses a range of versions like npm's: exact versions,
+// ^ and ~ ranges, x or * wildcards, partial versions, comparisons joined by
+// spaces and alternatives joined by ||. * or an empty range matches any version.
+func ParseVersionRange(r string) (*VersionRange, error) {
+	vr := &VersionRange{}
+	for _, alternative := range strings.Split(r, "||") {
+		set := []versionBound{}
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 && strings.TrimSpace(r) != "" {
+			return nil, invalidVersionRange(r)
+		}
+		for _, field := range fields {
+			bounds, err := parseRangeField(field)
+			if err != nil {
+				return nil, invalidVersionRange(r)
+			}
+			set = append(set, bounds...)
+		}
+		vr.sets = append(vr.sets, set)
+	}
+	return vr, nil
+}
+
+// invalidVersionRange returns ErrInvalidVersionRange naming the range r
+func invalidVersionRange(r string) error {
+	return fmt.Errorf("%w: \"%s\", use a version, ^1.2, ~1.2.3, 1.x or >=1.0 <2.0", ErrInvalidVersionRange, r)
+}
+
+// parseRangeField parses one comparison or range of a version range into the
+// bounds it sets
+func parseRangeField(field string) ([]versionBound, error) {
+	op := ""
+	for _, o := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(field, o) {
+			op, field = o, field[len(o):]
+			break
+		}
+	}
+	m := rangeVersionPattern.FindStringSubmatch(field)
+	if m == nil {
+		return nil, ErrInvalidVersionRange
+	}
+	// given is how many numbers the version gives before any wildcard
+	var v semver
+	given := 0
+	for i := range v.n {
+		if m[i+1] == "" || strings.ContainsAny(m[i+1], "xX*") {
+			break
+		}
+		v.n[i], _ = strconv.Atoi(m[i+1])
+		given++
+	}
+	v.pre = m[4]
+	if v.pre != "" && given < 3 {
+		return nil, ErrInvalidVersionRange
+	}
+
+	// upper returns the version just above every version starting with the
+	// first n numbers
+	upper := func(n int) semver {
+		u := semver{}
+		copy(u.n[:n], v.n[:n])
+		u.n[n-1]++
+		return u
+	}
+	lower := versionBound{">=", v}
+	switch op {
+	case ">", ">=", "<", "<=":
+		if given == 0 {
+			if op == ">=" || op == "<=" {
+				return nil, nil
+			}
+			return nil, ErrInvalidVersionRange
+		}
+		// Partial versions compare like their missing numbers are 0, except
+		// > and <= take in every version starting with them
+		if given < 3 && (op == ">" || op == "<=") {
+			bound := upper(given)
+			if op == ">" {
+				return []versionBound{{">=", bound}}, nil
+			}
+			return []versionBound{{"<", bound}}, nil
+		}
+		return []versionBound{{op, v}}, nil
+	case "^":
+		if given == 0 {
+			return nil, nil
+		}
+		// The first number that isn't 0 can't change, or the last given
+		n := 1
+		for n < given && v.n[n-1] == 0 {
+			n++
+		}
+		return []versionBound{lower, {"<", upper(n)}}, nil
+	case "~":
+		if given == 0 {
+			return nil, nil
+		}
+		n := 2
+		if given == 1 {
+			n = 1
+		}
+		return []versionBound{lower, {"<", upper(n)}}, nil
+	}
+	switch given {
+	case 0:
+		return nil, nil
+	case 3:
+		return []versionBound{{"=", v}}, nil
+	}
+	return []versionBound{lower, {"<", upper(given)}}, nil
+}
+
+// Match reports whether a version is in the range. Prereleases only match
+// bounds on the same major, minor and patch with a prerelease, so ranges don't
+// pick them up by accident.
+func (r *VersionRange) Match(version string) bool {
+	v, ok := parseSemver(version)
+	if !ok {
+		return false
+	}
+	for _, set := range r.sets {
+		if matchBounds(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchBounds(set []versionBound, v semver) bool {
+	for _, b := range set {
+		if !b.matches(v) {
+			return false
+		}
+	}
+	if v.pre == "" {
+		return true
+	}
+	for _, b := range set {
+		if b.v.pre != "" && b.v.n == v.n {
+			return true
+		}
+	}
+	return false
+}
+
+// BestVersion returns the newest of versions in the range, ok is false when
+// none is
+func (r *VersionRange) BestVersion(versions []string) (best string, ok bool) {
+	var bestVersion semver
+	for _, version := range versions {
+		if !r.Match(version) {
+			continue
+		}
+		v, _ := parseSemver(version)
+		if !ok || v.compare(bestVersion) > 0 {
+			best, bestVersion, ok = version, v, true
+		}
+	}
+	return best, ok
+}
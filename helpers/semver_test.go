@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVersionRangeMatch(t *testing.T) {
+	cases := []struct {
+		r       string
+		match   []string
+		nomatch []string
+	}{
+		{"^1.2", []string{"1.2.0", "1.9.9", "v1.3"}, []string{"1.1.9", "2.0.0", "latest", "1.3.0-rc.1"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0", "0.2.2"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.10"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"1.x", []string{"1.0.0", "1.5.2"}, []string{"2.0.0", "0.9.0"}},
+		{"1.2", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"1.2.3", []string{"1.2.3", "v1.2.3"}, []string{"1.2.4"}},
+		{">=1.0 <2.0", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.9"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9", "1.0.0"}, []string{"1.3.0"}},
+		{"^1.0 || ^3.0", []string{"1.4.0", "3.1.0"}, []string{"2.0.0"}},
+		{"*", []string{"0.0.1", "10.0.0"}, []string{"1.0.0-beta", "latest"}},
+		{"1.2.3-rc.1", []string{"1.2.3-rc.1"}, []string{"1.2.3"}},
+		{">=1.2.3-rc.1", []string{"1.2.3-rc.2", "1.2.3", "1.4.0"}, []string{"1.4.0-rc.1", "1.2.3-beta"}},
+	}
+	for _, c := range cases {
+		r, err := ParseVersionRange(c.r)
+		if err != nil {
+			t.Errorf("Range %s should parse, got %s", c.r, err)
+			continue
+		}
+		for _, v := range c.match {
+			if !r.Match(v) {
+				t.Errorf("Range %s should match %s", c.r, v)
+			}
+		}
+		for _, v := range c.nomatch {
+			if r.Match(v) {
+				t.Errorf("Range %s shouldn't match %s", c.r, v)
+			}
+		}
+	}
+}
+
+func TestParseVersionRangeInvalid(t *testing.T) {
+	for _, r := range []string{"^latest", "1.2.3.4", ">", "1.x-rc.1", "||", "~>1.2"} {
+		if _, err := ParseVersionRange(r); !errors.Is(err, ErrInvalidVersionRange) || !strings.Contains(err.Error(), r) {
+			t.Errorf("Range %s should return ErrInvalidVersionRange naming it, got %v", r, err)
+		}
+	}
+	if ErrInvalidVersionRange.Error() != "version range is invalid" {
+		t.Error("Parsing ranges shouldn't change ErrInvalidVersionRange, got", ErrInvalidVersionRange)
+	}
+}
+
+func TestBestVersion(t *testing.T) {
+	r, err := ParseVersionRange("^1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []string{"1.2.0", "1.10.0", "1.9.3", "2.0.0", "latest", "1.11.0-rc.1"}
+	if best, ok := r.BestVersion(versions); !ok || best != "1.10.0" {
+		t.Errorf("Best version of ^1.2 should be 1.10.0, got %s", best)
+	}
+	if _, ok := r.BestVersion([]string{"0.1.0", "2.0.0"}); ok {
+		t.Error("No version should match ^1.2")
+	}
+}
@@ -0,0 +1,343 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/viper"
+)
+
+const (
+	// SettingString is a Setting holding a single value
+	SettingString = "string"
+	// SettingList is a Setting holding a list of values
+	SettingList = "list"
+	// SettingBool is a Setting holding true or false
+	SettingBool = "bool"
+	// SettingInt is a Setting holding a number
+	SettingInt = "int"
+	// SettingDuration is a Setting holding a duration such as 1m30s
+	SettingDuration = "duration"
+	// SettingFloat is a Setting holding a decimal number
+	SettingFloat = "float"
+)
+
+var (
+	// ErrUnknownSetting is thrown when a key isn't a Setting cr knows about
+	ErrUnknownSetting = errors.New("unknown setting")
+	// ErrInvalidSetting is thrown when a value isn't valid for a Setting
+	ErrInvalidSetting = errors.New("invalid value")
+)
+
+// Setting represents a config key, of the client config that can be changed
+// with cr config or of server.toml
+type Setting struct {
+	Key         string
+	Kind        string
+	Description string
+	// valid checks a single value, for a SettingList each value is checked
+	valid func(string) bool
+}
+
+// Settings are the client config keys cr config can read and write
+var Settings = []Setting{
+	{"crackle.api", SettingString, "URL of the Crackle API", ValidURL},
+	{"crackle.mirrors", SettingList, "URLs of Crackle APIs read from when crackle.api is unreachable", ValidURL},
+	{"crackle.web", SettingString, "URL of the Crackle website cr open links to", ValidURL},
+	{"crackle.username", SettingString, "Username cr login uses by default", validSettingWord},
+	{"crackle.allowed_registries", SettingList, "Only run and publish images from these registries", validSettingWord},
+	{"crackle.registry", SettingString, "Registry of the [registries] table commands use by default", ValidRegistryName},
+	{"crackle.namespace", SettingString, "Namespace short package names are looked up in first", ValidNamespace},
+	{"reserved_names", SettingList, "Package names that can't be published", ValidShortPackageName},
+	{"run.detach", SettingBool, "Run packages in the background by default", nil},
+	{"run.pull", SettingString, "Default pull policy of cr run, always, missing or never", ValidPullPolicy},
+	{"run.verify_signatures", SettingBool, "Only run packages signed by their publisher", nil},
+	{"search.sort", SettingString, "Default sort of cr search, relevance, pulls, updated or name", ValidSearchSort},
+	{"search.limit", SettingInt, "Default number of cr search results", validSearchLimit},
+}
+
+// ClientEnvPrefix is the prefix of environment variables setting client config
+// keys, with dots as underscores: CR_CRACKLE_API sets crackle.api
+const ClientEnvPrefix = "cr"
+
+// clientEnvAliases are shorter environment variables for keys CI commonly
+// sets, the key's own CR_ variable wins over them
+var clientEnvAliases = map[string]string{
+	"crackle.api": "CR_REGISTRY_URL",
+	RegistryKey:   "CR_REGISTRY",
+}
+
+// BindClientEnv makes CR_ environment variables win over the client config and
+// project config for every key, below flags
+func BindClientEnv() {
+	viper.SetEnvPrefix(ClientEnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	for key, env := range clientEnvAliases {
+		viper.BindEnv(key, env)
+	}
+}
+
+// ClientEnv returns the environment variable setting key, and its alias when
+// it has one
+func ClientEnv(key string) []string {
+	envs := []string{strings.ToUpper(ClientEnvPrefix + "_" + strings.Replace(key, ".", "_", -1))}
+	if alias, ok := clientEnvAliases[key]; ok {
+		envs = append(envs, alias)
+	}
+	return envs
+}
+
+// EnvOverride returns the environment variable key is set by, empty when it
+// isn't set by one
+func EnvOverride(key string) string {
+	for _, env := range ClientEnv(key) {
+		if _, ok := os.LookupEnv(env); ok {
+			return env
+		}
+	}
+	return ""
+}
+
+// ValidClientEnv checks the value of every Setting set by an environment
+// variable, lists are separated by spaces
+func ValidClientEnv() error {
+	for _, s := range Settings {
+		env := EnvOverride(s.Key)
+		if env == "" {
+			continue
+		}
+		values := []string{os.Getenv(env)}
+		if s.Kind == SettingList {
+			values = strings.Fields(values[0])
+		}
+		if _, err := s.Parse(values); err != nil {
+			return fmt.Errorf("%s: %s", env, err)
+		}
+	}
+	return nil
+}
+
+// validSettingWord checks a value is a single word
+func validSettingWord(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t\r\n/")
+}
+
+// validSearchLimit checks a search limit is in range
+func validSearchLimit(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0 && n <= MaxSearchLimit
+}
+
+// LookupSetting returns the Setting for key, ErrUnknownSetting is thrown when
+// there's none
+func LookupSetting(key string) (*Setting, error) {
+	for i := range Settings {
+		if Settings[i].Key == strings.ToLower(key) {
+			return &Settings[i], nil
+		}
+	}
+	ErrUnknownSetting = fmt.Errorf("unknown setting \"%s\", see `cr config list`", key)
+	return nil, ErrUnknownSetting
+}
+
+// Parse validates values and converts them to the Setting's kind, only a
+// SettingList takes more than one value
+func (s *Setting) Parse(values []string) (interface{}, error) {
+	if s.Kind != SettingList && len(values) != 1 {
+		ErrInvalidSetting = fmt.Errorf("%s takes a single value", s.Key)
+		return nil, ErrInvalidSetting
+	}
+	for _, v := range values {
+		if s.valid != nil && !s.valid(v) {
+			ErrInvalidSetting = fmt.Errorf("\"%s\" is an invalid value for %s", v, s.Key)
+			return nil, ErrInvalidSetting
+		}
+	}
+
+	switch s.Kind {
+	case SettingList:
+		return values, nil
+	case SettingBool:
+		b, err := strconv.ParseBool(values[0])
+		if err != nil {
+			ErrInvalidSetting = fmt.Errorf("%s should be true or false", s.Key)
+			return nil, ErrInvalidSetting
+		}
+		return b, nil
+	case SettingInt:
+		n, err := strconv.Atoi(values[0])
+		if err != nil {
+			ErrInvalidSetting = fmt.Errorf("%s should be a number", s.Key)
+			return nil, ErrInvalidSetting
+		}
+		return n, nil
+	case SettingFloat:
+		f, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			ErrInvalidSetting = fmt.Errorf("%s should be a number such as 0.5", s.Key)
+			return nil, ErrInvalidSetting
+		}
+		return f, nil
+	case SettingDuration:
+		d, err := time.ParseDuration(values[0])
+		if err != nil {
+			ErrInvalidSetting = fmt.Errorf("%s should be a duration such as 1m30s", s.Key)
+			return nil, ErrInvalidSetting
+		}
+		return d, nil
+	}
+	return values[0], nil
+}
+
+// Value returns the Setting's current value formatted for display
+func (s *Setting) Value() string {
+	if s.Kind == SettingList {
+		return strings.Join(viper.GetStringSlice(s.Key), ", ")
+	}
+	return viper.GetString(s.Key)
+}
+
+// ValidClientConfig decodes the client config file at path and checks the value
+// of every Setting in it, keys cr doesn't know about are ignored
+func ValidClientConfig(path string) error {
+	config := make(map[string]interface{})
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		return err
+	}
+	for _, s := range Settings {
+		value, ok := lookupNested(config, strings.Split(s.Key, "."))
+		if !ok {
+			continue
+		}
+		if _, err := s.Parse(settingValues(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settingValues returns a decoded config value as the values Setting.Parse
+// takes, each item of a list
+func settingValues(value interface{}) []string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return []string{fmt.Sprint(value)}
+	}
+	var values []string
+	for _, v := range list {
+		values = append(values, fmt.Sprint(v))
+	}
+	return values
+}
+
+// ClientConfigPath returns the client config file in use, or where one should
+// be written when there isn't one yet
+func ClientConfigPath() string {
+	if p := viper.ConfigFileUsed(); p != "" {
+		return p
+	}
+	return filepath.Join(ConfigDir(), "client.toml")
+}
+
+// SetClientConfig writes key to the client config file, a nil value removes it.
+// Only the file is read and written, so defaults and environment variables
+// aren't saved in to it.
+func SetClientConfig(key string, value interface{}) error {
+	path := ClientConfigPath()
+	config := make(map[string]interface{})
+	if _, err := os.Stat(path); err == nil {
+		if _, err = toml.DecodeFile(path, &config); err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	if value == nil {
+		unsetNested(config, strings.Split(key, "."))
+	} else {
+		setNested(config, strings.Split(key, "."), value)
+	}
+
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(config); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b.Bytes(), 0644)
+}
+
+// setNested sets the value at path in nested tables, creating tables as needed
+func setNested(config map[string]interface{}, path []string, value interface{}) {
+	for _, k := range path[:len(path)-1] {
+		table, ok := config[k].(map[string]interface{})
+		if !ok {
+			table = make(map[string]interface{})
+			config[k] = table
+		}
+		config = table
+	}
+	config[path[len(path)-1]] = value
+}
+
+// lookupNested returns the value at path in nested tables
+func lookupNested(config map[string]interface{}, path []string) (interface{}, bool) {
+	for _, k := range path[:len(path)-1] {
+		table, ok := config[k].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		config = table
+	}
+	value, ok := config[path[len(path)-1]]
+	return value, ok
+}
+
+// unsetNested removes the value at path in nested tables, dropping tables left empty
+func unsetNested(config map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(config, path[0])
+		return
+	}
+	table, ok := config[path[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+	unsetNested(table, path[1:])
+	if len(table) == 0 {
+		delete(config, path[0])
+	}
+}
+
+// writeFileAtomic writes b to a temporary file next to path and renames it over
+// path, so a failed write never leaves it half written
+func writeFileAtomic(path string, b []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
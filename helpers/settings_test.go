@@ -0,0 +1,122 @@
+package helpers
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSettingParse(t *testing.T) {
+	tests := []struct {
+		key      string
+		values   []string
+		expected interface{}
+	}{
+		{"crackle.api", []string{"https://crackle.example.com/api/"}, "https://crackle.example.com/api/"},
+		{"crackle.allowed_registries", []string{"docker.io", "registry.example.com"}, []string{"docker.io", "registry.example.com"}},
+		{"run.detach", []string{"true"}, true},
+		{"search.limit", []string{"50"}, 50},
+		{"search.sort", []string{"relevance"}, "relevance"},
+	}
+	for _, test := range tests {
+		s, err := LookupSetting(test.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := s.Parse(test.values)
+		if err != nil {
+			t.Errorf("%s %q should be valid, got %s", test.key, test.values, err)
+		} else if !reflect.DeepEqual(value, test.expected) {
+			t.Errorf("%s %q should parse to %#v, got %#v", test.key, test.values, test.expected, value)
+		}
+	}
+
+	invalid := []struct {
+		key    string
+		values []string
+	}{
+		{"crackle.api", []string{"crackle.example.com"}},
+		{"crackle.api", []string{"https://a.example.com/", "https://b.example.com/"}},
+		{"crackle.allowed_registries", []string{"docker.io", "not a registry"}},
+		{"reserved_names", []string{"Invalid_Name!"}},
+		{"run.detach", []string{"sometimes"}},
+		{"search.sort", []string{"stars"}},
+		{"search.limit", []string{"1000"}},
+	}
+	for _, test := range invalid {
+		s, err := LookupSetting(test.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = s.Parse(test.values); err == nil {
+			t.Errorf("%s %q should be invalid", test.key, test.values)
+		}
+	}
+
+	if _, err := LookupSetting("crackle.token"); err == nil {
+		t.Error("Unknown settings shouldn't be found")
+	}
+}
+
+func TestSetNested(t *testing.T) {
+	config := map[string]interface{}{"crackle": map[string]interface{}{"api": "https://api.crackle.pm/api/"}}
+	setNested(config, []string{"crackle", "username"}, "sunshinekitty")
+	setNested(config, []string{"run", "detach"}, true)
+	expected := map[string]interface{}{
+		"crackle": map[string]interface{}{"api": "https://api.crackle.pm/api/", "username": "sunshinekitty"},
+		"run":     map[string]interface{}{"detach": true},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Settings should be set in nested tables, got %v", config)
+	}
+
+	unsetNested(config, []string{"run", "detach"})
+	unsetNested(config, []string{"search", "limit"})
+	delete(expected, "run")
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Unsetting should drop tables left empty, got %v", config)
+	}
+}
+
+func TestClientEnv(t *testing.T) {
+	BindClientEnv()
+	defer func() {
+		for _, env := range []string{"CR_SEARCH_LIMIT", "CR_CRACKLE_API", "CR_REGISTRY_URL", "CR_AUTH_TOKEN"} {
+			os.Unsetenv(env)
+		}
+	}()
+
+	os.Setenv("CR_SEARCH_LIMIT", "30")
+	if limit := viper.GetInt("search.limit"); limit != 30 {
+		t.Error("CR_SEARCH_LIMIT should set search.limit, got", limit)
+	}
+	os.Setenv("CR_REGISTRY_URL", "https://alias.example.com/api/")
+	if api := viper.GetString("crackle.api"); api != "https://alias.example.com/api/" {
+		t.Error("CR_REGISTRY_URL should set crackle.api, got", api)
+	}
+	os.Setenv("CR_CRACKLE_API", "https://key.example.com/api/")
+	if api := viper.GetString("crackle.api"); api != "https://key.example.com/api/" {
+		t.Error("CR_CRACKLE_API should win over CR_REGISTRY_URL, got", api)
+	}
+	if env := EnvOverride("crackle.api"); env != "CR_CRACKLE_API" {
+		t.Error("crackle.api should be overridden by CR_CRACKLE_API, got", env)
+	}
+	if env := EnvOverride("run.detach"); env != "" {
+		t.Error("run.detach shouldn't be overridden, got", env)
+	}
+	if err := ValidClientEnv(); err != nil {
+		t.Error("Valid environment variables should pass, got", err)
+	}
+	os.Setenv("CR_SEARCH_LIMIT", "0")
+	if err := ValidClientEnv(); err == nil {
+		t.Error("An invalid CR_SEARCH_LIMIT should fail")
+	}
+
+	os.Unsetenv(TokenEnv)
+	os.Setenv("CR_AUTH_TOKEN", "secret")
+	if c, err := LoadCredentials(); err != nil || c.Token != "secret" {
+		t.Errorf("CR_AUTH_TOKEN should be used like CR_TOKEN, got %+v %v", c, err)
+	}
+}
@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+var (
+	shellSafe = match(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+	// ErrUnterminatedQuote is thrown when a quote is opened but never closed
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+	// ErrTrailingBackslash is thrown when a string ends with an unescaped backslash
+	ErrTrailingBackslash = errors.New("trailing backslash")
+)
+
+// ShellQuote quotes s so a POSIX shell reads it back as exactly one word. Strings
+// made only of safe characters are returned as is, anything else is wrapped in
+// single quotes.
+func ShellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
+
+// ShellJoin quotes every arg with ShellQuote and joins them with spaces
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = ShellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ShellSplit splits s in to words the way a POSIX shell would, honouring single
+// quotes, double quotes and backslash escapes. No expansion of any kind is done.
+func ShellSplit(s string) ([]string, error) {
+	var (
+		args  []string
+		word  bytes.Buffer
+		inArg bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end == -1 {
+				return nil, ErrUnterminatedQuote
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				// Inside double quotes a backslash only escapes these
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("$`\"\\", s[i+1]) != -1 {
+					i++
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, ErrUnterminatedQuote
+			}
+			inArg = true
+		case '\\':
+			if i+1 >= len(s) {
+				return nil, ErrTrailingBackslash
+			}
+			i++
+			word.WriteByte(s[i])
+			inArg = true
+		case ' ', '\t', '\n':
+			if inArg {
+				args = append(args, word.String())
+				word.Reset()
+				inArg = false
+			}
+		default:
+			word.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
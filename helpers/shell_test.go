@@ -0,0 +1,121 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestShellQuote(t *testing.T) {
+	quoted := map[string]string{
+		"docker":         "docker",
+		"8080:80":        "8080:80",
+		"":               "''",
+		"with space":     "'with space'",
+		"it's":           `'it'"'"'s'`,
+		"$(rm -rf /)":    "'$(rm -rf /)'",
+		`C:\My Files:/d`: `'C:\My Files:/d'`,
+	}
+	for s, expected := range quoted {
+		if q := ShellQuote(s); q != expected {
+			t.Errorf("ShellQuote(%q) should be %s, got %s", s, expected, q)
+		}
+	}
+}
+
+func TestShellSplit(t *testing.T) {
+	split := map[string][]string{
+		"start.sh":                   {"start.sh"},
+		"  npm   run start ":         {"npm", "run", "start"},
+		`echo 'hello world'`:         {"echo", "hello world"},
+		`echo "say \"hi\" \n"`:       {"echo", `say "hi" \n`},
+		`echo a\ b`:                  {"echo", "a b"},
+		`echo ''`:                    {"echo", ""},
+		`sh -c 'echo $HOME; exit 1'`: {"sh", "-c", "echo $HOME; exit 1"},
+	}
+	for s, expected := range split {
+		args, err := ShellSplit(s)
+		if err != nil {
+			t.Errorf("ShellSplit(%q) should succeed, got %s", s, err)
+		}
+		if !reflect.DeepEqual(args, expected) {
+			t.Errorf("ShellSplit(%q) should be %q, got %q", s, expected, args)
+		}
+	}
+
+	if _, err := ShellSplit(`echo 'unterminated`); err != ErrUnterminatedQuote {
+		t.Error("Unterminated single quote should return ErrUnterminatedQuote, got", err)
+	}
+	if _, err := ShellSplit(`echo "unterminated`); err != ErrUnterminatedQuote {
+		t.Error("Unterminated double quote should return ErrUnterminatedQuote, got", err)
+	}
+	if _, err := ShellSplit(`echo \`); err != ErrTrailingBackslash {
+		t.Error("Trailing backslash should return ErrTrailingBackslash, got", err)
+	}
+}
+
+func FuzzShellQuote(f *testing.F) {
+	for _, s := range []string{"", "plain", "with space", "it's", `"double"`, "$(id)", "`id`", "a\nb", `back\slash`} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		args, err := ShellSplit(ShellQuote(s))
+		if err != nil {
+			t.Fatalf("ShellSplit(ShellQuote(%q)) failed: %s", s, err)
+		}
+		if len(args) != 1 || args[0] != s {
+			t.Fatalf("ShellSplit(ShellQuote(%q)) should be one word, got %q", s, args)
+		}
+	})
+}
+
+func TestPackageTomlToArgsExtra(t *testing.T) {
+	cmdStart := "npm run"
+	pt := &models.PackageToml{Repository: "sunshinekitty/testing:latest", CommandStart: &cmdStart}
+	_, args := PackageTomlToArgs(pt, "--", "-v", "a b")
+	expected := []string{"sunshinekitty/testing:latest", "npm", "run", "--", "-v", "a b"}
+	if len(args) < len(expected) || !reflect.DeepEqual(args[len(args)-len(expected):], expected) {
+		t.Errorf("Extra args should be appended after command_start, got %q", args)
+	}
+}
+
+// FuzzPackageTomlToCmd checks manifest fields can never add args to the docker
+// command, each one must come back as exactly the arg it was put in as
+func FuzzPackageTomlToCmd(f *testing.F) {
+	f.Add("sunshinekitty/testing:latest", "/tmp", "/data", "value", "start.sh")
+	f.Add("us.gcr.io/my-project/my-image:test", "C:\\My Files", "/data", "it's", "npm run 'start'")
+	f.Add("--privileged", "x -v /:/host", "/data; rm -rf /", "$(id)", "`id`")
+	f.Fuzz(func(t *testing.T, repository, local, container, value, cmdStart string) {
+		if strings.HasPrefix(repository, "-") && ValidRepositoryName(repository) {
+			t.Fatalf("Repository %q starting with - should be invalid", repository)
+		}
+		pt := &models.PackageToml{
+			Repository:   repository,
+			Volumes:      models.Volumes{{Local: local, Container: container}},
+			Env:          models.Env{"VALUE": value},
+			CommandStart: &cmdStart,
+		}
+		_, expected := PackageTomlToArgs(pt)
+		_, runArgs := PackageTomlToCmd(pt)
+		args, err := ShellSplit(runArgs)
+		if err != nil {
+			t.Fatalf("ShellSplit(%q) failed: %s", runArgs, err)
+		}
+		if !reflect.DeepEqual(args, expected) {
+			t.Fatalf("Quoted command should split back to %q, got %q", expected, args)
+		}
+
+		// docker run -t --rm --label <label> -v <volume> -e <env> -- <image> [command_start...]
+		if args[7] != local+":"+container {
+			t.Fatalf("Volume should be a single arg, got %q", args)
+		}
+		if args[9] != "VALUE="+value {
+			t.Fatalf("Env should be a single arg, got %q", args)
+		}
+		if args[10] != "--" || args[11] != repository {
+			t.Fatalf("Image should follow the flags after --, got %q", args)
+		}
+	})
+}
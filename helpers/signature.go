@@ -0,0 +1,145 @@
+package helpers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// signatureOmit holds Package fields a signature doesn't cover on top of
+// canonicalOmit. IconURL, LongDescriptionURL and ReadmeURL are object storage
+// URLs the registry signs afresh for every request. Namespace is part of the
+// Name, which is covered. Owner isn't in a manifest, the registry takes it
+// from whoever publishes and only accepts signatures by their registered key.
+// Private is package wide, a later version's publish changes it for this one.
+var signatureOmit = append([]string{"IconURL", "LongDescriptionURL", "Namespace", "Owner", "Private", "ReadmeURL"},
+	canonicalOmit...)
+
+var (
+	// ErrNoSigningKey is thrown when signing without a key, cr key generate makes one
+	ErrNoSigningKey = errors.New("no signing key, generate one with `cr key generate`")
+	// ErrInvalidSigningKey is thrown when a public or private key can't be decoded
+	ErrInvalidSigningKey = errors.New("signing key is invalid")
+	// ErrUnsigned is thrown when verifying a package that wasn't signed
+	ErrUnsigned = errors.New("package isn't signed")
+	// ErrBadSignature is thrown when a signature doesn't match a manifest, it
+	// was changed since it was signed or signed by another key
+	ErrBadSignature = errors.New("package signature doesn't match, it was tampered with")
+)
+
+// SigningKeyPath returns the location of the private key packages are signed with
+func SigningKeyPath() string {
+	return fmt.Sprintf("%s/signing.key", ConfigDir())
+}
+
+// GenerateSigningKey returns a new ed25519 private key
+func GenerateSigningKey() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	return key, err
+}
+
+// SaveSigningKey writes key's seed to SigningKeyPath, readable only by the user
+func SaveSigningKey(key ed25519.PrivateKey) error {
+	if err := EnsureConfigDirs(); err != nil {
+		return err
+	}
+	return writeFileAtomic(SigningKeyPath(), []byte(base64.StdEncoding.EncodeToString(key.Seed())+"\n"), 0600)
+}
+
+// LoadSigningKey reads the private key at SigningKeyPath, ErrNoSigningKey is
+// returned when there isn't one
+func LoadSigningKey() (ed25519.PrivateKey, error) {
+	b, err := ioutil.ReadFile(SigningKeyPath())
+	if os.IsNotExist(err) {
+		return nil, ErrNoSigningKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s: %s", SigningKeyPath(), ErrInvalidSigningKey)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// EncodePublicKey returns the base64 public key of a private key, the form
+// it's registered with Crackle in
+func EncodePublicKey(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// decodePublicKey decodes a base64 ed25519 public key
+func decodePublicKey(publicKey string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, ErrInvalidSigningKey
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// ValidSigningKey validates a base64 ed25519 public key
+func ValidSigningKey(publicKey string) error {
+	_, err := decodePublicKey(publicKey)
+	return err
+}
+
+// KeyFingerprint returns a short form of a public key to tell keys apart by,
+// like ssh's SHA256 fingerprints
+func KeyFingerprint(publicKey string) string {
+	sum := sha256.Sum256([]byte(publicKey))
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// CanonicalManifest serializes a manifest to the bytes it's signed as: the
+// CanonicalPackageJSON of its Package without the fields the registry sets, so
+// the manifest published and the config it's installed as sign the same
+func CanonicalManifest(pt *models.PackageToml) ([]byte, error) {
+	p, err := packageTomlToPackage(pt)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalJSON(p, signatureOmit)
+}
+
+// SignManifest returns the base64 signature of a manifest by key
+func SignManifest(key ed25519.PrivateKey, pt *models.PackageToml) (string, error) {
+	b, err := CanonicalManifest(pt)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, b)), nil
+}
+
+// VerifyManifest checks signature is publicKey's signature of a manifest,
+// ErrUnsigned is returned when either is empty and ErrBadSignature when it
+// doesn't match
+func VerifyManifest(publicKey string, signature string, pt *models.PackageToml) error {
+	if publicKey == "" || signature == "" {
+		return ErrUnsigned
+	}
+	key, err := decodePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrBadSignature
+	}
+	b, err := CanonicalManifest(pt)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, b, sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
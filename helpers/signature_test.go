@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func signedManifest() *models.PackageToml {
+	start := "serve"
+	long := "A long description"
+	return &models.PackageToml{
+		Package:         "testing",
+		Repository:      "sunshinekitty/testing:1.0",
+		CommandStart:    &start,
+		Env:             models.Env{"PORT": "80"},
+		Keywords:        []string{"web"},
+		LongDescription: &long,
+		Ports:           models.Ports{{Local: "8080", Container: "80"}},
+		Volumes:         models.Volumes{{Local: "/data", Container: "/data"}},
+	}
+}
+
+func TestSignManifest(t *testing.T) {
+	key, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := EncodePublicKey(key)
+	if err = ValidSigningKey(publicKey); err != nil {
+		t.Fatal("Generated key should be valid, got", err)
+	}
+	pt := signedManifest()
+	sig, err := SignManifest(key, pt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyManifest(publicKey, sig, pt); err != nil {
+		t.Error("Signature should verify, got", err)
+	}
+
+	// Installing fetches the Package and writes it back as toml, which has to
+	// sign the same even with the fields the registry fills in
+	p, err := packageTomlToPackage(pt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := new(models.Package)
+	if err = json.Unmarshal(b, served); err != nil {
+		t.Fatal(err)
+	}
+	served.Owner = "sunshinekitty"
+	served.ReadmeURL = "https://objects.example.com/testing/1.0/readme.txt"
+	installed, err := PackageToPackageToml(served)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = EncodePackageToml(&buf, installed); err != nil {
+		t.Fatal(err)
+	}
+	if installed, err = DecodePackageToml(&buf, FormatTOML); err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyManifest(publicKey, sig, installed); err != nil {
+		t.Error("Installed config should verify, got", err)
+	}
+
+	installed.Env["PORT"] = "81"
+	if err = VerifyManifest(publicKey, sig, installed); err != ErrBadSignature {
+		t.Error("Tampered config should return ErrBadSignature, got", err)
+	}
+	installed.Env["PORT"] = "80"
+	long, org := "Run me with --privileged", "acme"
+	for _, tamper := range []func(*models.PackageToml){
+		func(pt *models.PackageToml) { pt.LongDescription = &long },
+		func(pt *models.PackageToml) { pt.Org = &org },
+	} {
+		tampered := *installed
+		tamper(&tampered)
+		if err = VerifyManifest(publicKey, sig, &tampered); err != ErrBadSignature {
+			t.Errorf("Config with a changed long description or org should return ErrBadSignature, got %v for %+v", err, tampered)
+		}
+	}
+	other, _ := GenerateSigningKey()
+	if err = VerifyManifest(EncodePublicKey(other), sig, pt); err != ErrBadSignature {
+		t.Error("Another key should return ErrBadSignature, got", err)
+	}
+	if err = VerifyManifest(publicKey, "", pt); err != ErrUnsigned {
+		t.Error("Missing signature should return ErrUnsigned, got", err)
+	}
+}
+
+func TestValidSigningKey(t *testing.T) {
+	if err := ValidSigningKey("bm90IGEga2V5"); err != ErrInvalidSigningKey {
+		t.Error("Short key should return ErrInvalidSigningKey, got", err)
+	}
+	if err := ValidSigningKey("not base64!"); err != ErrInvalidSigningKey {
+		t.Error("Key that isn't base64 should return ErrInvalidSigningKey, got", err)
+	}
+}
+
+func TestTrustedKeys(t *testing.T) {
+	defer tempConfigDir(t)()
+
+	key, _ := GenerateSigningKey()
+	other, _ := GenerateSigningKey()
+	publicKey, otherKey := EncodePublicKey(key), EncodePublicKey(other)
+	pt := signedManifest()
+	sig, err := SignManifest(key, pt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trusted, err := LoadTrustedKeys()
+	if err != nil {
+		t.Fatal("Missing trusted keys file should load as empty, got", err)
+	}
+	if pinned, err := trusted.Trust("sunshinekitty", publicKey); err != nil || !pinned {
+		t.Fatalf("A publisher's first key should be pinned, got %v %v", pinned, err)
+	}
+	if err = trusted.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if trusted, err = LoadTrustedKeys(); err != nil {
+		t.Fatal(err)
+	}
+	if pinned, err := trusted.Trust("sunshinekitty", publicKey); err != nil || pinned {
+		t.Errorf("The pinned key should stay trusted, got %v %v", pinned, err)
+	}
+	if _, err = trusted.Trust("sunshinekitty", otherKey); !errors.Is(err, ErrSigningKeyChanged) {
+		t.Error("Another key should return ErrSigningKeyChanged, got", err)
+	}
+
+	installed := InstalledPackage{Name: "testing", Signature: sig, SigningKey: publicKey, Publisher: "sunshinekitty"}
+	if _, err = trusted.VerifyInstalled(installed, pt); err != nil {
+		t.Error("Package signed by the pinned key should verify, got", err)
+	}
+	// A registry sending its own key along with its own signature isn't trusted
+	forged, _ := SignManifest(other, pt)
+	installed.Signature, installed.SigningKey = forged, otherKey
+	if _, err = trusted.VerifyInstalled(installed, pt); !errors.Is(err, ErrSigningKeyChanged) {
+		t.Error("Package signed by another key than the pinned one should return ErrSigningKeyChanged, got", err)
+	}
+	installed.Publisher = ""
+	if _, err = trusted.VerifyInstalled(installed, pt); err != ErrUnknownPublisher {
+		t.Error("Package without a publisher should return ErrUnknownPublisher, got", err)
+	}
+
+	if !trusted.Forget("sunshinekitty") || trusted.Forget("sunshinekitty") || len(trusted.Trusted()) != 0 {
+		t.Error("Forgetting a publisher should unpin their key once")
+	}
+}
@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+const (
+	// TestByCommand tests a package by running its test_command in the container
+	TestByCommand = "test_command"
+	// TestByHealthcheck tests a package by waiting for its image's healthcheck
+	TestByHealthcheck = "healthcheck"
+	// TestByRunning tests a package that declares neither by checking it stays up
+	TestByRunning = "running"
+
+	// testInterval is how long to wait between checks of a package under test
+	testInterval = time.Second
+	// testSettle is how long a package without a test has to stay up to pass
+	testSettle = 5 * time.Second
+	// testState is the docker inspect template parsed by parseTestState
+	testState = `{{.State.Running}} {{if .State.Health}}{{.State.Health.Status}}{{end}}`
+)
+
+var (
+	// ErrTestTimeout is thrown when a package's test didn't pass in time
+	ErrTestTimeout = errors.New("test didn't pass in time")
+	// ErrTestExited is thrown when a package under test exits before passing
+	ErrTestExited = errors.New("container exited")
+	// ErrTestUnhealthy is thrown when an image's healthcheck fails
+	ErrTestUnhealthy = errors.New("healthcheck failed")
+)
+
+// TestContainerName returns the name of the container cr test runs a package in
+func TestContainerName(packageName string) string {
+	return ContainerName(packageName) + "-test"
+}
+
+// parseTestState parses the output of docker inspect formatted with testState,
+// done is true once the package has passed
+func parseTestState(out string) (done bool, err error) {
+	fields := strings.Fields(out)
+	if len(fields) == 0 || fields[0] != "true" {
+		return false, ErrTestExited
+	}
+	if len(fields) == 1 {
+		return false, nil
+	}
+	switch fields[1] {
+	case "healthy":
+		return true, nil
+	case "unhealthy":
+		return false, ErrTestUnhealthy
+	}
+	return false, nil
+}
+
+// TestPackageContext starts a package in the background and waits for it to
+// pass its test_command, or when it has none its image's healthcheck. Packages
+// with neither pass by staying up for a few seconds. The container is removed
+// afterwards whether it passed or not, the way it was tested is returned.
+func TestPackageContext(ctx context.Context, pt *models.PackageToml, timeout time.Duration) (string, error) {
+	var testArgs []string
+	method := TestByCommand
+	if pt.TestCommand != nil {
+		var err error
+		if testArgs, err = ShellSplit(*pt.TestCommand); err != nil {
+			return method, err
+		}
+	} else {
+		out, err := DockerOutputContext(ctx, "image", "inspect", "--format", "{{if .Config.Healthcheck}}yes{{end}}", "--", pt.Repository)
+		if err != nil {
+			return "", err
+		}
+		method = TestByRunning
+		if strings.TrimSpace(out) == "yes" {
+			method = TestByHealthcheck
+		}
+	}
+
+	name := TestContainerName(pt.Package)
+	flags := append([]string{"-d", "--name", name}, packageLabelArgs(pt.Package)...)
+	if _, err := DockerOutputContext(ctx, dockerRunArgs(pt, flags...)[1:]...); err != nil {
+		return method, err
+	}
+	// Tear down even when ctx was canceled
+	defer DockerOutputContext(context.Background(), "rm", "--force", name)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	started := time.Now()
+	for {
+		out, err := DockerOutputContext(ctx, "inspect", "--format", testState, name)
+		if err != nil {
+			return method, err
+		}
+		done, err := parseTestState(out)
+		if err != nil {
+			return method, err
+		}
+		switch method {
+		case TestByCommand:
+			_, err = DockerOutputContext(ctx, append([]string{"exec", name}, testArgs...)...)
+			done = err == nil
+		case TestByRunning:
+			done = time.Since(started) >= testSettle
+		}
+		if done {
+			return method, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return method, ErrTestTimeout
+			}
+			return method, ctx.Err()
+		case <-time.After(testInterval):
+		}
+	}
+}
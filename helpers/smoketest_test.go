@@ -0,0 +1,25 @@
+package helpers
+
+import "testing"
+
+func TestParseTestState(t *testing.T) {
+	tests := []struct {
+		out  string
+		done bool
+		err  error
+	}{
+		{"true \n", false, nil},
+		{"true starting\n", false, nil},
+		{"true healthy\n", true, nil},
+		{"true unhealthy\n", false, ErrTestUnhealthy},
+		{"false \n", false, ErrTestExited},
+		{"false healthy\n", false, ErrTestExited},
+		{"", false, ErrTestExited},
+	}
+	for _, test := range tests {
+		done, err := parseTestState(test.out)
+		if done != test.done || err != test.err {
+			t.Errorf("parseTestState(%q) should be %t, %v, got %t, %v", test.out, test.done, test.err, done, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// InstalledPackage represents a package installed on this machine
+type InstalledPackage struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Repository  string    `json:"repository"`
+	InstalledAt time.Time `json:"installed_at"`
+	// Deprecated is the package's deprecation message when it was installed
+	Deprecated string `json:"deprecated,omitempty"`
+	// Signature is the publisher's signature of the installed config, made by
+	// the SigningKey the registry recorded, which is only trusted once it's
+	// pinned for the Publisher in TrustedKeys
+	Signature  string `json:"signature,omitempty"`
+	SigningKey string `json:"signing_key,omitempty"`
+	Publisher  string `json:"publisher,omitempty"`
+}
+
+// State represents the packages installed on this machine, it's kept in
+// StatePath as json
+type State struct {
+	Packages map[string]InstalledPackage `json:"packages"`
+}
+
+// StatePath returns the location of the state file
+func StatePath() string {
+	return fmt.Sprintf("%s/state.json", DataDir())
+}
+
+// LoadState reads the state file, a missing state file is an empty State
+func LoadState() (*State, error) {
+	s := &State{Packages: make(map[string]InstalledPackage)}
+	b, err := ioutil.ReadFile(StatePath())
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %s", StatePath(), err)
+	}
+	if s.Packages == nil {
+		s.Packages = make(map[string]InstalledPackage)
+	}
+	return s, nil
+}
+
+// Save writes the state file, it's written to a temporary file first so a
+// failed write never leaves it half written
+func (s *State) Save() error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(StatePath(), b, 0600)
+}
+
+// Add records a package as installed now, replacing any version installed before
+func (s *State) Add(p *models.Package) {
+	installed := InstalledPackage{
+		Name:        p.Name,
+		Version:     p.Version,
+		Repository:  p.Repository,
+		InstalledAt: time.Now().UTC(),
+	}
+	if p.Deprecated != nil {
+		installed.Deprecated = *p.Deprecated
+	}
+	if p.Signature != nil && p.SigningKey != nil {
+		installed.Signature, installed.SigningKey, installed.Publisher = *p.Signature, *p.SigningKey, p.Owner
+	}
+	s.Packages[p.Name] = installed
+}
+
+// Remove forgets an installed package
+func (s *State) Remove(name string) {
+	delete(s.Packages, name)
+}
+
+// Installed returns every installed package sorted by name
+func (s *State) Installed() []InstalledPackage {
+	installed := make([]InstalledPackage, 0, len(s.Packages))
+	for _, p := range s.Packages {
+		installed = append(installed, p)
+	}
+	sort.Slice(installed, func(i, j int) bool { return installed[i].Name < installed[j].Name })
+	return installed
+}
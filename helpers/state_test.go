@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestState(t *testing.T) {
+	defer tempConfigDir(t)()
+
+	s, err := LoadState()
+	if err != nil {
+		t.Fatal("Missing state file should load as empty, got", err)
+	}
+	if len(s.Installed()) != 0 {
+		t.Error("Missing state file should have no packages, got", s.Installed())
+	}
+
+	s.Add(&models.Package{Name: "zeta", Version: "1.0", Repository: "example/zeta"})
+	s.Add(&models.Package{Name: "alpha", Version: "1.0", Repository: "example/alpha"})
+	s.Add(&models.Package{Name: "alpha", Version: "2.0", Repository: "example/alpha"})
+	if err = s.Save(); err != nil {
+		t.Fatal("State should save, got", err)
+	}
+
+	s, err = LoadState()
+	if err != nil {
+		t.Fatal("Saved state should load, got", err)
+	}
+	installed := s.Installed()
+	if len(installed) != 2 || installed[0].Name != "alpha" || installed[1].Name != "zeta" {
+		t.Fatalf("Installed packages should be sorted by name, got %+v", installed)
+	}
+	if installed[0].Version != "2.0" || installed[0].InstalledAt.IsZero() {
+		t.Errorf("Reinstalling should replace the installed version, got %+v", installed[0])
+	}
+
+	message := "use beta instead"
+	s.Add(&models.Package{Name: "alpha", Version: "3.0", Repository: "example/alpha", Deprecated: &message})
+	if s.Packages["alpha"].Deprecated != message {
+		t.Errorf("Deprecated package should keep its message, got %+v", s.Packages["alpha"])
+	}
+
+	s.Remove("zeta")
+	if _, ok := s.Packages["zeta"]; ok {
+		t.Error("Removed package should be forgotten")
+	}
+}
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// StatsPeriodDay groups pull counts by day
+	StatsPeriodDay = "day"
+	// StatsPeriodWeek groups pull counts by week
+	StatsPeriodWeek = "week"
+	// StatsPeriodMonth groups pull counts by month
+	StatsPeriodMonth = "month"
+
+	// MaxStatsRangeDays is the most days of pull counts returned at once
+	MaxStatsRangeDays = 366
+)
+
+// ErrInvalidStatsRange is thrown when a stats range such as 30d can't be parsed
+var ErrInvalidStatsRange = errors.New("range is invalid")
+
+// ValidStatsPeriod returns true for a period pull counts can be grouped by
+func ValidStatsPeriod(period string) bool {
+	switch period {
+	case StatsPeriodDay, StatsPeriodWeek, StatsPeriodMonth:
+		return true
+	}
+	return false
+}
+
+// ParseStatsRange parses how far back pull counts go, such as 30d or 12w, into
+// a number of days
+func ParseStatsRange(s string) (int, error) {
+	for suffix, unit := range map[string]int{"d": 1, "w": 7} {
+		if n := strings.TrimSuffix(s, suffix); n != s {
+			count, err := strconv.Atoi(n)
+			if err == nil && count > 0 && count*unit <= MaxStatsRangeDays {
+				return count * unit, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: \"%s\", use up to %d days such as 30d or 12w", ErrInvalidStatsRange, s, MaxStatsRangeDays)
+}
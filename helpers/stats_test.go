@@ -0,0 +1,28 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseStatsRange(t *testing.T) {
+	tests := map[string]int{
+		"1d":   1,
+		"30d":  30,
+		"12w":  84,
+		"366d": MaxStatsRangeDays,
+	}
+	for r, expected := range tests {
+		if days, err := ParseStatsRange(r); err != nil || days != expected {
+			t.Errorf("Range %q should be %d days, got %d %v", r, expected, days, err)
+		}
+	}
+	for _, r := range []string{"", "d", "0d", "-1d", "30", "1y", "367d", "53w"} {
+		if _, err := ParseStatsRange(r); !errors.Is(err, ErrInvalidStatsRange) {
+			t.Errorf("Range %q should return ErrInvalidStatsRange, got %v", r, err)
+		}
+	}
+	if ErrInvalidStatsRange.Error() != "range is invalid" {
+		t.Error("Parsing ranges shouldn't change ErrInvalidStatsRange, got", ErrInvalidStatsRange)
+	}
+}
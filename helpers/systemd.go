@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+var (
+	systemdSafe = match(`^[A-Za-z0-9_@+=:,./-]+$`)
+
+	// ErrInvalidRestartPolicy is thrown when a restart policy isn't one docker understands
+	ErrInvalidRestartPolicy = errors.New("restart policy is invalid")
+)
+
+// SystemdRestart maps a docker restart policy (no, always, unless-stopped or
+// on-failure[:max-retries]) to systemd's Restart= and the StartLimitBurst= that
+// caps retries, a burst of 0 means no cap
+func SystemdRestart(policy string) (string, int, error) {
+	switch policy {
+	case "no", "always", "on-failure":
+		return policy, 0, nil
+	case "unless-stopped":
+		// systemd never restarts a unit that was stopped by hand
+		return "always", 0, nil
+	}
+	if strings.HasPrefix(policy, "on-failure:") {
+		retries, err := strconv.Atoi(strings.TrimPrefix(policy, "on-failure:"))
+		if err == nil && retries > 0 {
+			return "on-failure", retries, nil
+		}
+	}
+	ErrInvalidRestartPolicy = fmt.Errorf("Restart policy \"%v\" is invalid, use no, always, unless-stopped or on-failure[:max-retries]", policy)
+	return "", 0, ErrInvalidRestartPolicy
+}
+
+// PackageToSystemdUnit renders a user-level systemd unit running a Package in a
+// named container, restart is a docker restart policy mapped with SystemdRestart.
+// The unit is meant to be saved as ~/.config/systemd/user/<SystemdUnitName>.
+func PackageToSystemdUnit(p *models.Package, restart string) (string, error) {
+	restartSystemd, burst, err := SystemdRestart(restart)
+	if err != nil {
+		return "", err
+	}
+	pt, err := PackageToPackageToml(p)
+	if err != nil {
+		return "", err
+	}
+
+	container := ContainerName(p.Name)
+	// No tty as there's no terminal attached under systemd
+	run := dockerRunArgs(pt, append([]string{"--rm", "--name", container}, packageLabelArgs(p.Name)...)...)
+
+	description := fmt.Sprintf("%s (cr package)", p.Name)
+	if pt.ShortDescription != nil && *pt.ShortDescription != "" {
+		description = strings.Join(strings.Fields(*pt.ShortDescription), " ")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "[Unit]")
+	fmt.Fprintf(&b, "Description=%s\n", strings.Replace(description, "%", "%%", -1))
+	if pt.Homepage != nil && ValidURL(*pt.Homepage) {
+		fmt.Fprintf(&b, "Documentation=%s\n", strings.Replace(*pt.Homepage, "%", "%%", -1))
+	}
+	if burst > 0 {
+		fmt.Fprintln(&b, "StartLimitIntervalSec=0")
+		fmt.Fprintf(&b, "StartLimitBurst=%d\n", burst)
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Service]")
+	// A container left over from a crash would stop the new one starting
+	fmt.Fprintf(&b, "ExecStartPre=-%s\n", systemdJoin([]string{"/usr/bin/env", "docker", "rm", "-f", container}))
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdJoin(append([]string{"/usr/bin/env"}, run...)))
+	fmt.Fprintf(&b, "ExecStop=%s\n", systemdJoin([]string{"/usr/bin/env", "docker", "stop", container}))
+	fmt.Fprintf(&b, "Restart=%s\n", restartSystemd)
+	if restartSystemd != "no" {
+		fmt.Fprintln(&b, "RestartSec=5")
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Install]")
+	fmt.Fprintln(&b, "WantedBy=default.target")
+
+	return b.String(), nil
+}
+
+// SystemdUnitName returns the unit file name for a package
+func SystemdUnitName(name string) string {
+	return fmt.Sprintf("cr-%s.service", PackageFileName(name))
+}
+
+// systemdJoin quotes args for a systemd Exec line, where "%" starts a specifier
+// and "$" an environment variable so both are always escaped
+func systemdJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if !systemdSafe.MatchString(a) {
+			r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+			a = `"` + r.Replace(a) + `"`
+		}
+		quoted[i] = strings.NewReplacer("%", "%%", "$", "$$").Replace(a)
+	}
+	return strings.Join(quoted, " ")
+}
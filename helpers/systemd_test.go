@@ -0,0 +1,80 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx/types"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestSystemdRestart(t *testing.T) {
+	valid := map[string]struct {
+		restart string
+		burst   int
+	}{
+		"no":             {"no", 0},
+		"always":         {"always", 0},
+		"unless-stopped": {"always", 0},
+		"on-failure":     {"on-failure", 0},
+		"on-failure:3":   {"on-failure", 3},
+	}
+	for policy, expected := range valid {
+		restart, burst, err := SystemdRestart(policy)
+		if err != nil {
+			t.Errorf("Restart policy \"%s\" should be valid, got %s", policy, err)
+		}
+		if restart != expected.restart || burst != expected.burst {
+			t.Errorf("Restart policy \"%s\" should map to %s/%d, got %s/%d", policy, expected.restart, expected.burst, restart, burst)
+		}
+	}
+
+	for _, policy := range []string{"", "sometimes", "on-failure:", "on-failure:0", "on-failure:x"} {
+		if _, _, err := SystemdRestart(policy); err != ErrInvalidRestartPolicy {
+			t.Errorf("Restart policy \"%s\" should return ErrInvalidRestartPolicy, got %v", policy, err)
+		}
+	}
+}
+
+func TestPackageToSystemdUnit(t *testing.T) {
+	shortDescription := "Serves   files\\nover HTTP"
+	cmdStart := "serve --root '/srv/my files'"
+	ports := types.JSONText(`[{"local":"8080","container":"80"}]`)
+	env := types.JSONText(`{"PRICE":"$5 or 100%"}`)
+	p := &models.Package{
+		CommandStart:     &cmdStart,
+		Env:              &env,
+		Name:             "webserver",
+		Ports:            &ports,
+		Repository:       "sunshinekitty/webserver",
+		ShortDescription: &shortDescription,
+		Version:          "1.0",
+	}
+
+	unit, err := PackageToSystemdUnit(p, "on-failure:3")
+	if err != nil {
+		t.Fatal("Package should render a unit, got", err)
+	}
+	expected := []string{
+		"Description=Serves files\\nover HTTP\n",
+		"StartLimitBurst=3\n",
+		"ExecStartPre=-/usr/bin/env docker rm -f cr-webserver\n",
+		`ExecStart=/usr/bin/env docker run --rm --name cr-webserver --label cr.package=webserver -p 8080:80 -e "PRICE=$$5 or 100%%" -- sunshinekitty/webserver:1.0 serve --root "/srv/my files"` + "\n",
+		"ExecStop=/usr/bin/env docker stop cr-webserver\n",
+		"Restart=on-failure\n",
+		"WantedBy=default.target\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(unit, line) {
+			t.Errorf("Unit should contain %q, got:\n%s", line, unit)
+		}
+	}
+	if strings.Contains(unit, " -t ") {
+		t.Error("Unit shouldn't allocate a tty, got:\n", unit)
+	}
+
+	if _, err = PackageToSystemdUnit(p, "sometimes"); err != ErrInvalidRestartPolicy {
+		t.Error("Invalid restart policy should return ErrInvalidRestartPolicy, got", err)
+	}
+}
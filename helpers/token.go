@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// tokenBytes is the number of random bytes in an API token
+	tokenBytes = 32
+	// maxTokenNameLength is the longest name a token can be given
+	maxTokenNameLength = 60
+
+	// ScopeRead lets a token read what's private to its user, every scope includes it
+	ScopeRead = "read"
+	// ScopePublish lets a token publish and change any package its user owns
+	ScopePublish = "publish"
+	// ScopeAdmin lets a token do anything its user can, including managing
+	// owners, transfers and other tokens
+	ScopeAdmin = "admin"
+	// ScopePackagePrefix prefixes a scope letting a token publish and change a
+	// single package, such as package:testing
+	ScopePackagePrefix = "package:"
+)
+
+var (
+	// DefaultTokenScopes are the scopes of a token issued by logging in
+	DefaultTokenScopes = []string{ScopeRead, ScopePublish, ScopeAdmin}
+	// DefaultCreatedTokenScopes are the scopes of a token created without any given
+	DefaultCreatedTokenScopes = []string{ScopeRead, ScopePublish}
+
+	// ErrNoScopes is thrown when a token is created without any scopes
+	ErrNoScopes = errors.New("A token needs at least one scope")
+	// ErrInvalidScope is thrown when a token scope isn't read, publish, admin or package:<name>
+	ErrInvalidScope = errors.New("Scope is invalid, use read, publish, admin or package:<name>")
+	// ErrLongTokenName is thrown when a token's name is too long
+	ErrLongTokenName = fmt.Errorf("Token name should be at most %d characters", maxTokenNameLength)
+)
+
+// NewToken returns a new random API token
+func NewToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken returns the hash of an API token that's stored in place of it, so
+// tokens can't be recovered from the database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// BearerToken returns the token of a "Bearer <token>" Authorization header, or
+// "" when there isn't one
+func BearerToken(header string) string {
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// ValidScope validates a token scope
+func ValidScope(s string) bool {
+	switch s {
+	case ScopeRead, ScopePublish, ScopeAdmin:
+		return true
+	}
+	return strings.HasPrefix(s, ScopePackagePrefix) && ValidPackageName(strings.TrimPrefix(s, ScopePackagePrefix))
+}
+
+// ValidTokenRequest validates the name and scopes a token is created with
+func ValidTokenRequest(name string, scopes []string) error {
+	if len(name) > maxTokenNameLength {
+		return ErrLongTokenName
+	}
+	if len(scopes) == 0 {
+		return ErrNoScopes
+	}
+	for _, s := range scopes {
+		if !ValidScope(s) {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, s)
+		}
+	}
+	return nil
+}
+
+// HasScope reports whether a token with scopes is allowed scope, on the package
+// name when it isn't "". admin allows everything, a package scope allows
+// publishing its package and any scope allows reading.
+func HasScope(scopes []string, scope string, name string) bool {
+	for _, s := range scopes {
+		switch {
+		case s == ScopeAdmin, s == scope, scope == ScopeRead:
+			return true
+		case scope == ScopePublish && name != "" && s == ScopePackagePrefix+name:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewToken(t *testing.T) {
+	a, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 64 || a == b {
+		t.Errorf("Tokens should be 64 random hex characters, got %s and %s", a, b)
+	}
+	if HashToken(a) == a || HashToken(a) != HashToken(a) || HashToken(a) == HashToken(b) {
+		t.Error("Token hashes should be stable and differ from the token")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	headers := map[string]string{
+		"Bearer abc123":   "abc123",
+		"bearer  abc123 ": "abc123",
+		"Basic abc123":    "",
+		"abc123":          "",
+		"":                "",
+	}
+	for header, expected := range headers {
+		if token := BearerToken(header); token != expected {
+			t.Errorf("Bearer token of \"%s\" should be \"%s\", got \"%s\"", header, expected, token)
+		}
+	}
+}
+
+func TestValidTokenRequest(t *testing.T) {
+	valid := [][]string{
+		{ScopeRead},
+		{ScopeRead, ScopePublish, ScopeAdmin},
+		{"package:testing"},
+	}
+	for _, scopes := range valid {
+		if err := ValidTokenRequest("ci", scopes); err != nil {
+			t.Errorf("Scopes %v should be valid, got %s", scopes, err)
+		}
+	}
+	invalid := [][]string{
+		{},
+		{"write"},
+		{"package:"},
+		{"package:Not Valid"},
+	}
+	for _, scopes := range invalid {
+		if err := ValidTokenRequest("ci", scopes); err == nil {
+			t.Errorf("Scopes %v should be invalid", scopes)
+		}
+	}
+	if err := ValidTokenRequest("ci", []string{ScopeRead, "write"}); !errors.Is(err, ErrInvalidScope) || !strings.Contains(err.Error(), "write") {
+		t.Errorf("An invalid scope should return ErrInvalidScope naming it, got %v", err)
+	}
+	if ErrInvalidScope.Error() != "Scope is invalid, use read, publish, admin or package:<name>" {
+		t.Error("Validating scopes shouldn't change ErrInvalidScope, got", ErrInvalidScope)
+	}
+	if err := ValidTokenRequest(strings.Repeat("a", 61), []string{ScopeRead}); err != ErrLongTokenName {
+		t.Errorf("A 61 character name should be too long, got %v", err)
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		scopes   []string
+		scope    string
+		name     string
+		expected bool
+	}{
+		{[]string{ScopeRead}, ScopeRead, "", true},
+		{[]string{ScopeRead}, ScopePublish, "testing", false},
+		{[]string{ScopePublish}, ScopeRead, "", true},
+		{[]string{ScopePublish}, ScopePublish, "testing", true},
+		{[]string{ScopePublish}, ScopeAdmin, "", false},
+		{[]string{ScopeAdmin}, ScopePublish, "testing", true},
+		{[]string{"package:testing"}, ScopePublish, "testing", true},
+		{[]string{"package:testing"}, ScopePublish, "other", false},
+		{[]string{"package:testing"}, ScopePublish, "", false},
+		{[]string{"package:testing"}, ScopeAdmin, "testing", false},
+		{[]string{}, ScopeRead, "", false},
+	}
+	for _, test := range tests {
+		if HasScope(test.scopes, test.scope, test.name) != test.expected {
+			t.Errorf("HasScope(%v, %s, %s) should be %v", test.scopes, test.scope, test.name, test.expected)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// OTPHeader holds a two-factor code sent with a request, and is set to
+	// "required" on responses refusing a request without a fresh one
+	OTPHeader = "X-Crackle-OTP"
+	// OTPRequired is OTPHeader's value on responses asking for a code
+	OTPRequired = "required"
+
+	// totpSecretBytes is the number of random bytes in a TOTP secret
+	totpSecretBytes = 20
+	// totpDigits is the number of digits in a code
+	totpDigits = 6
+	// totpPeriod is how long a code lasts
+	totpPeriod = 30 * time.Second
+	// totpSkew is how many periods either side of now a code is still accepted
+	// in, for clocks that have drifted
+	totpSkew = 1
+)
+
+// totpEncoding encodes TOTP secrets the way authenticator apps expect them
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret returns a new random base32 TOTP secret
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPStep returns the period t falls in, codes are counted by it
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod/time.Second)
+}
+
+// TOTPCode returns the RFC 6238 code of a base32 secret for a step, using
+// HMAC-SHA1 like every authenticator app
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("TOTP secret isn't base32: %s", err)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0xf
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, code%1000000), nil
+}
+
+// VerifyTOTP returns the step a code of a secret was made for when it's valid
+// at t and newer than the step last used, so a code can't be used twice. ok
+// is false for any other code.
+func VerifyTOTP(secret string, code string, t time.Time, lastUsed int64) (step int64, ok bool) {
+	code = strings.Replace(strings.TrimSpace(code), " ", "", -1)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	now := TOTPStep(t)
+	for s := now - totpSkew; s <= now+totpSkew; s++ {
+		if s <= lastUsed {
+			continue
+		}
+		want, err := TOTPCode(secret, s)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPURI returns the otpauth URI authenticator apps add an account with,
+// usually shown as a QR code
+func TOTPURI(issuer string, username string, secret string) string {
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + username,
+		RawQuery: url.Values{"secret": {secret}, "issuer": {issuer}}.Encode(),
+	}
+	return u.String()
+}
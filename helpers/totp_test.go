@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 secret of RFC 6238's test vectors, base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238's 8 digit codes, cut to 6
+	cases := map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"}
+	for unix, want := range cases {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Code at %d should be %s, got %s", unix, want, got)
+		}
+	}
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("Invalid secret should fail")
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := TOTPStep(now)
+	if got, ok := VerifyTOTP(rfc6238Secret, "081 804", now, 0); !ok || got != step {
+		t.Errorf("Current code should be valid at step %d, got %d %v", step, got, ok)
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, "081804", now.Add(30*time.Second), 0); !ok {
+		t.Error("Code of the previous step should still be valid")
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, "081804", now.Add(2*time.Minute), 0); ok {
+		t.Error("Old code should be invalid")
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, "081804", now, step); ok {
+		t.Error("Used code should be invalid")
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, "000000", now, 0); ok {
+		t.Error("Wrong code should be invalid")
+	}
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("Secret should be 32 base32 characters, got %s", secret)
+	}
+	code, err := TOTPCode(secret, 1)
+	if err != nil || len(code) != 6 {
+		t.Errorf("Secret should make 6 digit codes, got %s %v", code, err)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Crackle", "alice", rfc6238Secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Crackle:alice?") || !strings.Contains(uri, "secret="+rfc6238Secret) {
+		t.Errorf("URI should add alice's secret, got %s", uri)
+	}
+}
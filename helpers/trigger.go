@@ -0,0 +1,135 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// DefaultTriggerTags is the pattern of tags a trigger publishes unless it's
+	// given one, every tag
+	DefaultTriggerTags = "*"
+	// GitHubEventHeader holds the event a GitHub webhook was sent for, GHCR
+	// pushes are package or registry_package events
+	GitHubEventHeader = "X-GitHub-Event"
+
+	// maxTriggerTagsLength is the longest pattern of tags a trigger can have
+	maxTriggerTagsLength = 128
+)
+
+var (
+	// ErrInvalidTriggerTags is thrown when a trigger's pattern of tags isn't a valid glob
+	ErrInvalidTriggerTags = errors.New("trigger tags are invalid")
+	// ErrInvalidImagePush is thrown when a webhook's body isn't a Docker Hub or GitHub push event
+	ErrInvalidImagePush = errors.New("body isn't a Docker Hub or GitHub package event")
+)
+
+// ImagePush represents a tag pushed to an image, Image is the image's name
+// including its registry
+type ImagePush struct {
+	Image string
+	Tag   string
+}
+
+// ValidTriggerTags validates the pattern of tags a trigger publishes, a glob
+// such as v* or 1.*
+func ValidTriggerTags(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil || pattern == "" || len(pattern) > maxTriggerTagsLength {
+		return fmt.Errorf("%w: \"%s\", use a glob such as v* of at most %d characters", ErrInvalidTriggerTags, pattern, maxTriggerTagsLength)
+	}
+	return nil
+}
+
+// TriggerWants reports whether a trigger publishing pattern publishes tag
+func TriggerWants(pattern string, tag string) bool {
+	ok, _ := path.Match(pattern, tag)
+	return ok
+}
+
+// ParseImagePush returns the tag pushed by a Docker Hub webhook's body, or by a
+// GitHub webhook's when githubEvent, its GitHubEventHeader, is set. The push is
+// nil for events that aren't a tag being pushed to a container image, such as
+// GitHub's ping.
+func ParseImagePush(githubEvent string, body []byte) (*ImagePush, error) {
+	if githubEvent != "" {
+		return parseGitHubPush(githubEvent, body)
+	}
+
+	var hub struct {
+		PushData struct {
+			Tag string
+		} `json:"push_data"`
+		Repository struct {
+			RepoName string `json:"repo_name"`
+		}
+	}
+	if err := json.Unmarshal(body, &hub); err != nil || hub.Repository.RepoName == "" {
+		return nil, ErrInvalidImagePush
+	}
+	if hub.PushData.Tag == "" {
+		return nil, nil
+	}
+	return &ImagePush{Image: DockerHub + "/" + hub.Repository.RepoName, Tag: hub.PushData.Tag}, nil
+}
+
+// parseGitHubPush returns the tag pushed by a GitHub package or
+// registry_package event, whose package is under the event's name
+func parseGitHubPush(event string, body []byte) (*ImagePush, error) {
+	if event != "package" && event != "registry_package" {
+		return nil, nil
+	}
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, ErrInvalidImagePush
+	}
+	var action string
+	var pkg struct {
+		Name           string
+		PackageType    string `json:"package_type"`
+		Owner          struct{ Login string }
+		PackageVersion struct {
+			ContainerMetadata struct {
+				Tag struct{ Name string }
+			} `json:"container_metadata"`
+		} `json:"package_version"`
+	}
+	if json.Unmarshal(payload["action"], &action) != nil || json.Unmarshal(payload[event], &pkg) != nil || pkg.Name == "" {
+		return nil, ErrInvalidImagePush
+	}
+	tag := pkg.PackageVersion.ContainerMetadata.Tag.Name
+	if action != "published" && action != "updated" || !strings.EqualFold(pkg.PackageType, "container") || tag == "" {
+		return nil, nil
+	}
+	return &ImagePush{Image: fmt.Sprintf("ghcr.io/%s/%s", pkg.Owner.Login, pkg.Name), Tag: tag}, nil
+}
+
+// SameImage reports whether two image names, without tags, are the same image.
+// Names are compared ignoring case, with Docker Hub's aliases and the library/
+// prefix of its official images.
+func SameImage(a string, b string) bool {
+	ra, err := ParseReference(a)
+	if err != nil {
+		return false
+	}
+	rb, err := ParseReference(b)
+	if err != nil {
+		return false
+	}
+	return canonicalImage(ra) == canonicalImage(rb)
+}
+
+// canonicalImage returns an image's registry and repository, lower cased and
+// with Docker Hub images always under docker.io/library/ or docker.io/<user>/
+func canonicalImage(r *Reference) string {
+	registry, repository := normalizeRegistry(r.Registry), strings.ToLower(r.Repository)
+	if registry == "" {
+		registry = DockerHub
+	}
+	if registry == DockerHub && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry + "/" + repository
+}
@@ -0,0 +1,86 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidTriggerTags(t *testing.T) {
+	for _, tags := range []string{"*", "v*", "1.*", "[0-9]*"} {
+		if err := ValidTriggerTags(tags); err != nil {
+			t.Errorf("%s should be valid, got %s", tags, err)
+		}
+	}
+	for _, tags := range []string{"", "[", "v[1-"} {
+		if err := ValidTriggerTags(tags); !errors.Is(err, ErrInvalidTriggerTags) {
+			t.Errorf("%s should return ErrInvalidTriggerTags, got %v", tags, err)
+		}
+	}
+	if !TriggerWants("v*", "v1.2.3") || TriggerWants("v*", "1.2.3") || !TriggerWants("*", "latest") {
+		t.Error("TriggerWants matched the wrong tags")
+	}
+}
+
+func TestParseImagePush(t *testing.T) {
+	tests := []struct {
+		event    string
+		body     string
+		expected *ImagePush
+	}{
+		{"", `{"push_data": {"tag": "1.2.3", "pusher": "alice"}, "repository": {"repo_name": "alice/tool", "name": "tool"}}`,
+			&ImagePush{Image: "docker.io/alice/tool", Tag: "1.2.3"}},
+		{"", `{"push_data": {"tag": ""}, "repository": {"repo_name": "alice/tool"}}`, nil},
+		{"package", `{"action": "published", "package": {"name": "tool", "package_type": "CONTAINER", "owner": {"login": "alice"},
+			"package_version": {"container_metadata": {"tag": {"name": "v2.0.0"}}}}}`,
+			&ImagePush{Image: "ghcr.io/alice/tool", Tag: "v2.0.0"}},
+		{"registry_package", `{"action": "published", "registry_package": {"name": "tool", "package_type": "container", "owner": {"login": "alice"},
+			"package_version": {"container_metadata": {"tag": {"name": "2.0.1"}}}}}`,
+			&ImagePush{Image: "ghcr.io/alice/tool", Tag: "2.0.1"}},
+		{"package", `{"action": "published", "package": {"name": "lib", "package_type": "npm", "owner": {"login": "alice"}}}`, nil},
+		{"ping", `{"zen": "Keep it logically awesome."}`, nil},
+	}
+	for _, test := range tests {
+		push, err := ParseImagePush(test.event, []byte(test.body))
+		if err != nil {
+			t.Errorf("%s should parse, got %s", test.body, err)
+			continue
+		}
+		if (push == nil) != (test.expected == nil) || push != nil && *push != *test.expected {
+			t.Errorf("Expected %+v from %s, got %+v", test.expected, test.body, push)
+		}
+	}
+
+	for _, body := range []string{`not json`, `{"push_data": {"tag": "1.0"}}`} {
+		if _, err := ParseImagePush("", []byte(body)); err != ErrInvalidImagePush {
+			t.Errorf("%s should be ErrInvalidImagePush, got %v", body, err)
+		}
+	}
+	if _, err := ParseImagePush("package", []byte(`{"action": "published"}`)); err != ErrInvalidImagePush {
+		t.Errorf("A package event without a package should be ErrInvalidImagePush, got %v", err)
+	}
+}
+
+func TestSameImage(t *testing.T) {
+	same := [][2]string{
+		{"postgres", "docker.io/library/postgres"},
+		{"alice/tool", "docker.io/alice/tool"},
+		{"index.docker.io/alice/tool", "docker.io/Alice/Tool"},
+		{"ghcr.io/alice/tool", "ghcr.io/alice/tool"},
+	}
+	for _, s := range same {
+		if !SameImage(s[0], s[1]) {
+			t.Errorf("%s and %s should be the same image", s[0], s[1])
+		}
+	}
+	different := [][2]string{
+		{"alice/tool", "ghcr.io/alice/tool"},
+		{"alice/tool", "bob/tool"},
+		{"postgres", "alice/postgres"},
+		{"alice/tool", "not an image"},
+	}
+	for _, d := range different {
+		if SameImage(d[0], d[1]) {
+			t.Errorf("%s and %s shouldn't be the same image", d[0], d[1])
+		}
+	}
+}
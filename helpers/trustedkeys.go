@@ -0,0 +1,125 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+var (
+	// ErrSigningKeyChanged is thrown when a publisher's packages are signed by
+	// another key than the one pinned for them
+	ErrSigningKeyChanged = errors.New("signing key changed")
+	// ErrUnknownPublisher is thrown when verifying a package installed before
+	// its publisher was recorded
+	ErrUnknownPublisher = errors.New("publisher unknown, reinstall it")
+)
+
+// TrustedKey represents the signing key pinned for a publisher
+type TrustedKey struct {
+	Publisher   string `json:"publisher"`
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// TrustedKeys represents the signing keys trusted for each publisher, the
+// first key a publisher's package arrives with is pinned and kept in
+// TrustedKeysPath as json. The registry could otherwise send any key with a
+// package it changed.
+type TrustedKeys struct {
+	Keys map[string]string `json:"keys"`
+}
+
+// TrustedKeysPath returns the location of the trusted keys file
+func TrustedKeysPath() string {
+	return fmt.Sprintf("%s/trusted_keys.json", ConfigDir())
+}
+
+// LoadTrustedKeys reads the trusted keys file, a missing file trusts no keys
+func LoadTrustedKeys() (*TrustedKeys, error) {
+	k := &TrustedKeys{Keys: make(map[string]string)}
+	b, err := ioutil.ReadFile(TrustedKeysPath())
+	if os.IsNotExist(err) {
+		return k, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, k); err != nil {
+		return nil, fmt.Errorf("%s: %s", TrustedKeysPath(), err)
+	}
+	if k.Keys == nil {
+		k.Keys = make(map[string]string)
+	}
+	return k, nil
+}
+
+// Save writes the trusted keys file, readable only by the user
+func (k *TrustedKeys) Save() error {
+	if err := EnsureConfigDirs(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(TrustedKeysPath(), b, 0600)
+}
+
+// Trust pins key for publisher when none is pinned yet, reporting whether it
+// was. ErrSigningKeyChanged is returned when another key is pinned.
+func (k *TrustedKeys) Trust(publisher string, key string) (bool, error) {
+	if err := ValidSigningKey(key); err != nil {
+		return false, err
+	}
+	pinned, ok := k.Keys[publisher]
+	if !ok {
+		k.Keys[publisher] = key
+		return true, nil
+	}
+	if pinned != key {
+		return false, fmt.Errorf("%w: %s's packages were signed by %s, they're now signed by %s", ErrSigningKeyChanged,
+			publisher, KeyFingerprint(pinned), KeyFingerprint(key))
+	}
+	return false, nil
+}
+
+// Forget unpins the key of a publisher, reporting whether one was pinned
+func (k *TrustedKeys) Forget(publisher string) bool {
+	_, ok := k.Keys[publisher]
+	delete(k.Keys, publisher)
+	return ok
+}
+
+// Trusted returns every pinned key sorted by publisher
+func (k *TrustedKeys) Trusted() []TrustedKey {
+	trusted := make([]TrustedKey, 0, len(k.Keys))
+	for publisher, key := range k.Keys {
+		trusted = append(trusted, TrustedKey{Publisher: publisher, Key: key, Fingerprint: KeyFingerprint(key)})
+	}
+	sort.Slice(trusted, func(i, j int) bool { return trusted[i].Publisher < trusted[j].Publisher })
+	return trusted
+}
+
+// VerifyInstalled checks an installed package's config is signed by the key
+// pinned for its publisher, pinning the key it was installed with when none
+// is and reporting whether it was. ErrUnsigned is returned for unsigned
+// packages.
+func (k *TrustedKeys) VerifyInstalled(installed InstalledPackage, pt *models.PackageToml) (bool, error) {
+	if installed.SigningKey == "" || installed.Signature == "" {
+		return false, ErrUnsigned
+	}
+	if installed.Publisher == "" {
+		return false, ErrUnknownPublisher
+	}
+	pinned, err := k.Trust(installed.Publisher, installed.SigningKey)
+	if err != nil {
+		return false, err
+	}
+	return pinned, VerifyManifest(k.Keys[installed.Publisher], installed.Signature, pt)
+}
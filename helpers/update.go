@@ -0,0 +1,207 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ReleasesURL is the GitHub API endpoint for the latest cr release
+	ReleasesURL = "https://api.github.com/repos/sunshinekitty/cr/releases/latest"
+	// ChecksumsAssetName is the release asset holding the sha256 of every binary
+	ChecksumsAssetName = "checksums.txt"
+)
+
+var (
+	// ErrNoReleaseAsset is thrown when a release has no binary for this platform
+	ErrNoReleaseAsset = errors.New("no release for this platform")
+	// ErrChecksumMismatch is thrown when a downloaded binary doesn't match its checksum
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// Release represents a GitHub release of cr
+type Release struct {
+	TagName string         `json:"tag_name"`
+	Assets  []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset represents a file attached to a Release
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release's version without the leading v of its tag
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// asset returns the URL of the asset called name
+func (r *Release) asset(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// ReleaseAssetName returns the name of the release binary for this platform
+func ReleaseAssetName() string {
+	name := fmt.Sprintf("cr_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// CompareVersions compares dotted versions such as 1.2.0 numerically, returning
+// -1, 0 or 1. A leading v and anything after a - or + are ignored.
+func CompareVersions(a string, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for len(pa) < len(pb) {
+		pa = append(pa, 0)
+	}
+	for len(pb) < len(pa) {
+		pb = append(pb, 0)
+	}
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts parses the numbers of a dotted version, invalid numbers are 0
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i != -1 {
+		version = version[:i]
+	}
+	var parts []int
+	for _, p := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(p)
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// LatestReleaseContext fetches the latest cr Release from GitHub
+func LatestReleaseContext(ctx context.Context) (*Release, error) {
+	b, err := httpGetContext(ctx, ReleasesURL)
+	if err != nil {
+		return nil, err
+	}
+	release := new(Release)
+	if err = json.Unmarshal(b, release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// DownloadReleaseContext downloads the binary for this platform from release and
+// checks it against the release's checksums
+func DownloadReleaseContext(ctx context.Context, release *Release) ([]byte, error) {
+	name := ReleaseAssetName()
+	binaryURL, ok := release.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: release %s has no %s", ErrNoReleaseAsset, release.TagName, name)
+	}
+	checksumsURL, ok := release.asset(ChecksumsAssetName)
+	if !ok {
+		return nil, fmt.Errorf("%w: release %s has no %s", ErrNoReleaseAsset, release.TagName, ChecksumsAssetName)
+	}
+
+	checksums, err := httpGetContext(ctx, checksumsURL)
+	if err != nil {
+		return nil, err
+	}
+	expected, ok := parseChecksums(string(checksums))[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no checksum for %s", ErrChecksumMismatch, ChecksumsAssetName, name)
+	}
+
+	b, err := httpGetContext(ctx, binaryURL)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	if hex.EncodeToString(sum[:]) != strings.ToLower(expected) {
+		return nil, fmt.Errorf("%w: %s doesn't match its checksum", ErrChecksumMismatch, name)
+	}
+	return b, nil
+}
+
+// parseChecksums parses sha256sum output, mapping file names to checksums
+func parseChecksums(checksums string) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks files read in binary mode with a *
+		sums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	return sums
+}
+
+// httpGetContext fetches url, any status but 200 is an error
+func httpGetContext(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// ReplaceExecutable replaces the running executable with b. The new binary is
+// written next to it and renamed over it, so it's never left half written.
+func ReplaceExecutable(b []byte) error {
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		// A running executable can't be replaced on Windows, but it can be moved
+		old := path + ".old"
+		os.Remove(old)
+		if err = os.Rename(path, old); err != nil {
+			return err
+		}
+		if err = writeFileAtomic(path, b, 0755); err != nil {
+			os.Rename(old, path)
+			return err
+		}
+		return nil
+	}
+	return writeFileAtomic(path, b, 0755)
+}
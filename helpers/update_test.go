@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0", 0},
+		{"0.0.0", "0.1.0", -1},
+		{"1.10.0", "1.9.3", 1},
+		{"2.0.0-rc1", "2.0.0", 0},
+		{"1.2.3", "1.2.4", -1},
+	}
+	for _, test := range tests {
+		if c := CompareVersions(test.a, test.b); c != test.expected {
+			t.Errorf("CompareVersions(%q, %q) should be %d, got %d", test.a, test.b, test.expected, c)
+		}
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	checksums := "4a5b  cr_linux_amd64\n9f8e *cr_windows_amd64.exe\n\nnot a checksum line here\n"
+	sums := parseChecksums(checksums)
+	if sums["cr_linux_amd64"] != "4a5b" {
+		t.Errorf("Checksum of cr_linux_amd64 should be 4a5b, got %q", sums["cr_linux_amd64"])
+	}
+	if sums["cr_windows_amd64.exe"] != "9f8e" {
+		t.Errorf("Binary mode checksums should be parsed, got %q", sums["cr_windows_amd64.exe"])
+	}
+	if len(sums) != 2 {
+		t.Errorf("Only checksum lines should be parsed, got %v", sums)
+	}
+}
+
+func TestDownloadRelease(t *testing.T) {
+	binary := []byte("cr binary")
+	sum := sha256.Sum256(binary)
+	checksums := hex.EncodeToString(sum[:]) + "  " + ReleaseAssetName() + "\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + ReleaseAssetName():
+			w.Write(binary)
+		case "/tampered":
+			w.Write([]byte("something else"))
+		case "/" + ChecksumsAssetName:
+			w.Write([]byte(checksums))
+		case "/empty":
+		}
+	}))
+	defer server.Close()
+	asset := func(name string, path string) ReleaseAsset {
+		return ReleaseAsset{Name: name, URL: server.URL + "/" + path}
+	}
+
+	release := &Release{TagName: "v1.2.0", Assets: []ReleaseAsset{
+		asset(ReleaseAssetName(), ReleaseAssetName()), asset(ChecksumsAssetName, ChecksumsAssetName)}}
+	if b, err := DownloadReleaseContext(context.Background(), release); err != nil || !bytes.Equal(b, binary) {
+		t.Errorf("A release matching its checksum should download, got %q %v", b, err)
+	}
+
+	tests := []struct {
+		assets []ReleaseAsset
+		err    error
+	}{
+		{[]ReleaseAsset{asset(ChecksumsAssetName, ChecksumsAssetName)}, ErrNoReleaseAsset},
+		{[]ReleaseAsset{asset(ReleaseAssetName(), ReleaseAssetName())}, ErrNoReleaseAsset},
+		{[]ReleaseAsset{asset(ReleaseAssetName(), "tampered"), asset(ChecksumsAssetName, ChecksumsAssetName)}, ErrChecksumMismatch},
+		{[]ReleaseAsset{asset(ReleaseAssetName(), ReleaseAssetName()), asset(ChecksumsAssetName, "empty")}, ErrChecksumMismatch},
+	}
+	for _, test := range tests {
+		release.Assets = test.assets
+		if _, err := DownloadReleaseContext(context.Background(), release); !errors.Is(err, test.err) {
+			t.Errorf("Release with assets %+v should return %v, got %v", test.assets, test.err, err)
+		}
+	}
+	if ErrNoReleaseAsset.Error() != "no release for this platform" || ErrChecksumMismatch.Error() != "checksum mismatch" {
+		t.Error("Downloading releases shouldn't change ErrNoReleaseAsset or ErrChecksumMismatch")
+	}
+}
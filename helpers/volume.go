@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+var windowsDrive = match(`^[A-Za-z]:[\\/]`)
+
+// IsWindowsPath returns true for a Windows drive letter path such as C:\data or C:/data
+func IsWindowsPath(p string) bool {
+	return windowsDrive.MatchString(p)
+}
+
+// ParseVolume parses a docker style "local:container" volume mount. The local
+// path may be a Windows path, the colon after its drive letter isn't treated
+// as a separator so C:\data:/data mounts C:\data at /data.
+func ParseVolume(s string) (models.Volume, error) {
+	drive := ""
+	if IsWindowsPath(s) {
+		drive, s = s[:2], s[2:]
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return models.Volume{}, fmt.Errorf("Volume \"%v%v\" should be in the form local:container", drive, s)
+	}
+	v := models.Volume{Local: drive + parts[0], Container: parts[1]}
+	if !ValidVolume(v) {
+		return models.Volume{}, fmt.Errorf("Volume \"%v%v\" is invalid", drive, s)
+	}
+	return v, nil
+}
+
+// ValidVolume validates a volume can be passed to docker as "local:container"
+// and split back unambiguously. The local path may be relative, absolute or a
+// Windows drive letter path, the container path must be absolute and neither
+// may contain any other colons.
+func ValidVolume(v models.Volume) bool {
+	local := v.Local
+	if IsWindowsPath(local) {
+		local = local[2:]
+	}
+	if len(local) == 0 || strings.Contains(local, ":") {
+		return false
+	}
+	return strings.HasPrefix(v.Container, "/") && !strings.Contains(v.Container, ":")
+}
@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestParseVolume(t *testing.T) {
+	valid := map[string]models.Volume{
+		"/tmp:/data":         {Local: "/tmp", Container: "/data"},
+		"dist:/var/www":      {Local: "dist", Container: "/var/www"},
+		`C:\data:/data`:      {Local: `C:\data`, Container: "/data"},
+		"c:/Users/me:/work":  {Local: "c:/Users/me", Container: "/work"},
+		`D:\My Files:/files`: {Local: `D:\My Files`, Container: "/files"},
+	}
+	for s, expected := range valid {
+		v, err := ParseVolume(s)
+		if err != nil {
+			t.Errorf("Volume \"%s\" should be valid, got %s", s, err)
+		}
+		if v != expected {
+			t.Errorf("Volume \"%s\" should parse to %v, got %v", s, expected, v)
+		}
+	}
+
+	invalid := []string{"/tmp", "/tmp:", ":/data", "/tmp:/data:ro", "C:\\data", "/tmp:data", "C:/a:b:/data"}
+	for _, s := range invalid {
+		if _, err := ParseVolume(s); err == nil {
+			t.Errorf("Volume \"%s\" should be invalid", s)
+		}
+	}
+}
+
+func TestValidVolume(t *testing.T) {
+	if !ValidVolume(models.Volume{Local: `C:\data`, Container: "/data"}) {
+		t.Error("Volume with Windows local path should be valid")
+	}
+	if ValidVolume(models.Volume{Local: "/tmp", Container: `C:\data`}) {
+		t.Error("Volume with Windows container path should be invalid")
+	}
+	if ValidVolume(models.Volume{Local: "/tmp:/etc", Container: "/data"}) {
+		t.Error("Volume with colon in local path should be invalid")
+	}
+}
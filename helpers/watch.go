@@ -0,0 +1,137 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+// watchIgnored are directory names never watched, they change often without
+// anything a package runs changing
+var watchIgnored = []string{".git", ".hg", ".svn", "node_modules"}
+
+// WatchContainerName returns the name of the container cr watch runs a package in
+func WatchContainerName(packageName string) string {
+	return ContainerName(packageName) + "-watch"
+}
+
+// PackageTomlToWatchArgs is PackageTomlToArgs for running a package in the
+// foreground in a container named with WatchContainerName, so it can be stopped
+// when something changes
+func PackageTomlToWatchArgs(pt *models.PackageToml, extra ...string) (string, []string) {
+	return packageTomlToArgs(pt, []string{"--name", WatchContainerName(pt.Package)}, extra)
+}
+
+// WatchPaths returns the local directories and files a package mounts, which
+// cr watch watches when no path is given. Named volumes and paths that don't
+// exist are left out.
+func WatchPaths(pt *models.PackageToml) []string {
+	var paths []string
+	for _, v := range pt.Volumes {
+		if _, err := os.Stat(v.Local); err == nil {
+			paths = append(paths, v.Local)
+		}
+	}
+	return paths
+}
+
+// watchIgnoredPath returns true when any part of path is in watchIgnored
+func watchIgnoredPath(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		for _, ignored := range watchIgnored {
+			if part == ignored {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// watchTree adds path and every directory under it to w
+func watchTree(w *fsnotify.Watcher, path string) error {
+	return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			// Only a file given as the path itself is watched on its own
+			if p == path {
+				return w.Add(p)
+			}
+			return nil
+		}
+		if p != path && watchIgnoredPath(info.Name()) {
+			return filepath.SkipDir
+		}
+		return w.Add(p)
+	})
+}
+
+// WatchChangesContext watches paths and the directories under them, sending
+// the path of the last change once nothing has changed for debounce. New
+// directories are watched as they're created. The channel is closed when ctx
+// is done.
+func WatchChangesContext(ctx context.Context, paths []string, debounce time.Duration) (<-chan string, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		if err = watchTree(w, p); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+
+	changes := make(chan string)
+	go func() {
+		defer close(changes)
+		defer w.Close()
+		var (
+			settled <-chan time.Time
+			last    string
+		)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				// Only permissions changing doesn't change what runs
+				if e.Op == fsnotify.Chmod || watchIgnoredPath(e.Name) {
+					continue
+				}
+				if e.Op&fsnotify.Create != 0 {
+					if info, err := os.Stat(e.Name); err == nil && info.IsDir() {
+						if err = watchTree(w, e.Name); err != nil {
+							Debugf("watching %s: %s", e.Name, err)
+						}
+					}
+				}
+				last = e.Name
+				settled = time.After(debounce)
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				Debugf("watching: %s", err)
+			case <-settled:
+				settled = nil
+				select {
+				case changes <- last:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
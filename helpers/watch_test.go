@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sunshinekitty/cr/models"
+)
+
+func TestWatchPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cr-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pt := &models.PackageToml{Volumes: models.Volumes{
+		{Local: dir, Container: "/src"},
+		{Local: "pgdata", Container: "/var/lib/postgresql/data"},
+		{Local: filepath.Join(dir, "missing"), Container: "/missing"},
+	}}
+	paths := WatchPaths(pt)
+	if len(paths) != 1 || paths[0] != dir {
+		t.Errorf("Only %s should be watched, got %q", dir, paths)
+	}
+}
+
+func TestWatchIgnoredPath(t *testing.T) {
+	tests := map[string]bool{
+		"src/main.go":                 false,
+		"src/.git/HEAD":               true,
+		"web/node_modules/x/index.js": true,
+		"gitignore":                   false,
+	}
+	for path, expected := range tests {
+		if watchIgnoredPath(path) != expected {
+			t.Errorf("watchIgnoredPath(%q) should be %t", path, expected)
+		}
+	}
+}
+
+func TestWatchChangesContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cr-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := WatchChangesContext(ctx, []string{dir}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "main.go")
+	if err = ioutil.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case changed := <-changes:
+		if changed != path {
+			t.Errorf("Change should be to %s, got %s", path, changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Writing a file should have been seen as a change")
+	}
+
+	cancel()
+	if _, ok := <-changes; ok {
+		t.Error("Changes should be closed once the context is done")
+	}
+}
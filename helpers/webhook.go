@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strings"
+)
+
+const (
+	// WebhookEventPublish is sent when a version of a package is published
+	WebhookEventPublish = "publish"
+	// WebhookEventYank is sent when a version of a package is yanked
+	WebhookEventYank = "yank"
+	// WebhookEventUnyank is sent when a yanked version of a package is restored
+	WebhookEventUnyank = "unyank"
+	// WebhookEventDeprecate is sent when a package is deprecated
+	WebhookEventDeprecate = "deprecate"
+	// WebhookEventUndeprecate is sent when a package's deprecation is undone
+	WebhookEventUndeprecate = "undeprecate"
+
+	// WebhookSignatureHeader holds the HMAC-SHA256 of a webhook's body, keyed by
+	// its secret, as sha256=<hex>
+	WebhookSignatureHeader = "X-Crackle-Signature"
+	// WebhookEventHeader holds the event a webhook was sent for
+	WebhookEventHeader = "X-Crackle-Event"
+
+	// maxWebhookURLLength is the longest URL a webhook can be sent to
+	maxWebhookURLLength = 200
+)
+
+// WebhookEvents are every event a webhook can be sent for
+var WebhookEvents = []string{WebhookEventPublish, WebhookEventYank, WebhookEventUnyank, WebhookEventDeprecate, WebhookEventUndeprecate}
+
+var (
+	// ErrInvalidWebhookURL is thrown when a webhook's URL isn't a http(s) URL
+	ErrInvalidWebhookURL = errors.New("webhook URL is invalid")
+	// ErrInvalidWebhookEvent is thrown when a webhook subscribes to an unknown event
+	ErrInvalidWebhookEvent = errors.New("webhook event is invalid")
+	// ErrPrivateWebhookHost is thrown when a webhook's host resolves to an
+	// address of the registry's own network, such as a loopback or private one
+	ErrPrivateWebhookHost = errors.New("webhook host isn't a public address")
+)
+
+// ValidWebhook validates the URL a webhook is sent to and the events it's sent
+// for, no events means every one
+func ValidWebhook(url string, events []string) error {
+	if len(url) > maxWebhookURLLength || !ValidURL(url) {
+		return fmt.Errorf("%w: \"%s\", use a http(s) URL of at most %d characters", ErrInvalidWebhookURL, url, maxWebhookURLLength)
+	}
+	for _, e := range events {
+		if !validWebhookEvent(e) {
+			return fmt.Errorf("%w: \"%s\", use %s", ErrInvalidWebhookEvent, e, strings.Join(WebhookEvents, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidWebhookHost resolves the host of a webhook's URL, which has to be valid,
+// and checks every address it resolves to is public. The registry calls
+// webhooks from inside its own network, publishers could otherwise reach hosts
+// there through them.
+func ValidWebhookHost(ctx context.Context, url string) error {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return fmt.Errorf("%w: \"%s\"", ErrInvalidWebhookURL, url)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: \"%s\" doesn't resolve", ErrInvalidWebhookURL, u.Hostname())
+	}
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return fmt.Errorf("%w: \"%s\" resolves to %s", ErrPrivateWebhookHost, u.Hostname(), addr.IP)
+		}
+	}
+	return nil
+}
+
+// PublicIP reports whether ip is a public unicast address, not a loopback,
+// private, link local, unspecified or multicast one
+func PublicIP(ip net.IP) bool {
+	return ip != nil && ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
+
+// validWebhookEvent validates a webhook event
+func validWebhookEvent(e string) bool {
+	for _, event := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookWants reports whether a webhook subscribed to events is sent event,
+// no events means every one
+func WebhookWants(events []string, event string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// SignWebhook returns the WebhookSignatureHeader of a webhook body
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook reports whether signature is the WebhookSignatureHeader of a
+// webhook body, for receivers checking it came from the registry
+func VerifyWebhook(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, body)), []byte(signature))
+}
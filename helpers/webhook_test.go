@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestValidWebhook(t *testing.T) {
+	if err := ValidWebhook("https://ci.example.com/hooks/crackle", nil); err != nil {
+		t.Error("Webhook without events should be valid, got", err)
+	}
+	if err := ValidWebhook("https://ci.example.com/hooks/crackle", []string{WebhookEventPublish, WebhookEventYank}); err != nil {
+		t.Error("Webhook with known events should be valid, got", err)
+	}
+	if err := ValidWebhook("ftp://ci.example.com", nil); !errors.Is(err, ErrInvalidWebhookURL) {
+		t.Error("Webhook to a ftp URL should return ErrInvalidWebhookURL, got", err)
+	}
+	if err := ValidWebhook("https://ci.example.com", []string{"delete"}); !errors.Is(err, ErrInvalidWebhookEvent) {
+		t.Error("Webhook with an unknown event should return ErrInvalidWebhookEvent, got", err)
+	}
+	if ErrInvalidWebhookURL.Error() != "webhook URL is invalid" || ErrInvalidWebhookEvent.Error() != "webhook event is invalid" {
+		t.Error("Validating webhooks shouldn't change their errors")
+	}
+}
+
+func TestValidWebhookHost(t *testing.T) {
+	for _, url := range []string{"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data", "https://10.0.0.5/hook", "http://0.0.0.0/hook"} {
+		if err := ValidWebhookHost(context.Background(), url); !errors.Is(err, ErrPrivateWebhookHost) {
+			t.Errorf("Webhook to %s should return ErrPrivateWebhookHost, got %v", url, err)
+		}
+	}
+	if err := ValidWebhookHost(context.Background(), "https://93.184.216.34/hook"); err != nil {
+		t.Error("Webhook to a public address should be valid, got", err)
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0", "::", "224.0.0.1"} {
+		if PublicIP(net.ParseIP(ip)) {
+			t.Errorf("%s shouldn't be public", ip)
+		}
+	}
+	for _, ip := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		if !PublicIP(net.ParseIP(ip)) {
+			t.Errorf("%s should be public", ip)
+		}
+	}
+}
+
+func TestWebhookWants(t *testing.T) {
+	if !WebhookWants(nil, WebhookEventYank) {
+		t.Error("Webhook without events should want every event")
+	}
+	if WebhookWants([]string{WebhookEventPublish}, WebhookEventYank) {
+		t.Error("Webhook subscribed to publish shouldn't want yank")
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"Event":"publish"}`)
+	signature := SignWebhook("secret", body)
+	if len(signature) != len("sha256=")+64 || signature[:7] != "sha256=" {
+		t.Errorf("Signature should be sha256=<hex>, got %s", signature)
+	}
+	if !VerifyWebhook("secret", body, signature) {
+		t.Error("Signature should verify with the same secret and body")
+	}
+	if VerifyWebhook("other", body, signature) || VerifyWebhook("secret", []byte("{}"), signature) {
+		t.Error("Signature shouldn't verify with another secret or body")
+	}
+}
@@ -6,17 +6,28 @@ import (
 
 	"github.com/spf13/viper"
 	"github.com/sunshinekitty/cr/cmd"
+	"github.com/sunshinekitty/cr/helpers"
 )
 
 func main() {
+	// Files kept in ~/.cr before XDG directories move once, it's read from
+	// while anything is left there
+	if moved, err := helpers.MigrateLegacyDir(); err != nil {
+		helpers.Warnf("couldn't move %s to %s and %s: %s", helpers.LegacyDir(), helpers.ConfigDir(), helpers.DataDir(), err)
+	} else if moved != 0 {
+		helpers.Warnf("moved %s to %s and %s, installed packages now run from %s", helpers.LegacyDir(), helpers.ConfigDir(), helpers.DataDir(), helpers.BinDir())
+	}
+
 	// Viper config
 	viper.SetConfigName("client")
 	viper.SetConfigType("toml")
 	viper.AddConfigPath("/etc/crackle/")
-	viper.AddConfigPath("$HOME/.cr")
+	viper.AddConfigPath(helpers.ConfigDir())
+	viper.AddConfigPath(helpers.LegacyDir())
 	viper.AddConfigPath(".")
 	viper.SetDefault("crackle.api", "https://api.crackle.pm/api/")
-	viper.AutomaticEnv()
+	viper.SetDefault("crackle.web", "https://crackle.pm/")
+	helpers.BindClientEnv()
 	// We fail silently here since client config isn't needed for web-server
 	_ = viper.ReadInConfig()
 	if err := cmd.Root.Execute(); err != nil {
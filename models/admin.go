@@ -0,0 +1,21 @@
+package models
+
+// Moderation represents why an admin suspended a user, removed a package or
+// reserved a name
+type Moderation struct {
+	Reason string
+}
+
+// ReservedName represents a package name admins have kept anyone from publishing
+type ReservedName struct {
+	Name       string
+	Reason     *string `json:",omitempty"`
+	ReservedBy string  `db:"reserved_by"`
+	CreatedAt  string  `db:"created_at"`
+}
+
+// ReservedNames represents a list of ReservedName structs, Next is the token of the page after it
+type ReservedNames struct {
+	Reserved []ReservedName
+	Next     string `json:",omitempty"`
+}
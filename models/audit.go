@@ -0,0 +1,20 @@
+package models
+
+// AuditEntry represents a change made to the registry, who made it and from
+// where. Package is empty for changes to tokens and orgs, Detail says what else
+// the change was about such as a version or a username.
+type AuditEntry struct {
+	ID        int
+	Package   *string `db:"name" json:",omitempty"`
+	Action    string
+	Actor     string
+	IP        string
+	Detail    *string `json:",omitempty"`
+	CreatedAt string  `db:"created_at"`
+}
+
+// AuditLog represents a list of AuditEntry structs, newest first, Next is the token of the page after it
+type AuditLog struct {
+	Entry []AuditEntry
+	Next  string `json:",omitempty"`
+}
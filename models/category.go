@@ -0,0 +1,38 @@
+package models
+
+// Category represents a category of the registry's taxonomy, which packages are
+// browsed by. Packages is how many packages the caller can see are in it.
+type Category struct {
+	Slug        string
+	Title       string
+	Description *string `json:",omitempty"`
+	Packages    int
+	CreatedAt   string `db:"created_at"`
+}
+
+// Categories represents every Category, alphabetically
+type Categories struct {
+	Category []Category
+}
+
+// PackageCategories represents the slugs of the categories a package is in
+type PackageCategories struct {
+	Categories []string
+}
+
+// Collection represents a list of packages curated by the registry's admins,
+// Package holds the latest version of each when a single collection is read
+type Collection struct {
+	Slug        string
+	Title       string
+	Description *string `json:",omitempty"`
+	Packages    []string
+	Package     []Package `json:",omitempty"`
+	UpdatedBy   *string   `json:",omitempty"`
+	UpdatedAt   string
+}
+
+// Collections represents every Collection, alphabetically
+type Collections struct {
+	Collection []Collection
+}
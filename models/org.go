@@ -0,0 +1,20 @@
+package models
+
+// Org represents an organization whose members share ownership of its packages
+type Org struct {
+	Name      string
+	CreatedAt string `db:"created_at"`
+}
+
+// OrgMember represents a user's membership of an Org, Role is owner, publisher or reader
+type OrgMember struct {
+	Username string
+	Role     string
+	AddedAt  string `db:"added_at"`
+}
+
+// OrgMembers represents a list of OrgMember structs, Next is the token of the page after it
+type OrgMembers struct {
+	Member []OrgMember
+	Next   string `json:",omitempty"`
+}
@@ -1,54 +1,199 @@
 package models
 
-import "github.com/jmoiron/sqlx/types"
+import (
+	"sort"
 
-// Package represents a package in the package table
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Package represents a package in the package table, the Org it belongs to is
+// kept in the package_orgs table. Namespace is the user or org a name such as
+// alice/tool is namespaced by. On registries with object storage the long
+// description, rendered readme and a copy of the icon are kept there under the
+// Key fields, and served from signed URLs. A Signature is made over the
+// canonical manifest by the publisher's SigningKey, the registry sets the key.
 type Package struct {
-	CommandStart     *string `db:"command_start"`
-	CreatedAt        string  `db:"created_at"`
-	Homepage         *string
-	LongDescription  *string `db:"long_description"`
-	Name             string
-	Owner            string
-	Pulls            int
-	Ports            *types.JSONText
-	Repository       string
-	ShortDescription *string `db:"short_description"`
-	UpdatedAt        string  `db:"updated_at"`
-	Version          string
-	Volumes          *types.JSONText
-}
-
-// Packages represents a list of Package structs
+	CommandStart       *string `db:"command_start"`
+	CreatedAt          string  `db:"created_at"`
+	Deprecated         *string
+	DescriptionKey     *string `db:"description_key" json:"-"`
+	Env                *types.JSONText
+	Homepage           *string
+	Icon               *string
+	IconKey            *string `db:"icon_key" json:"-"`
+	IconURL            string  `db:"-" json:",omitempty"`
+	Keywords           *types.JSONText
+	LongDescription    *string `db:"long_description"`
+	LongDescriptionURL string  `db:"-" json:",omitempty"`
+	Name               string
+	Namespace          string  `db:"-" json:",omitempty"`
+	Org                *string `db:"-"`
+	Owner              string
+	Pulls              int
+	Ports              *types.JSONText
+	Private            bool
+	ReadmeKey          *string `db:"readme_key" json:"-"`
+	ReadmeURL          string  `db:"-" json:",omitempty"`
+	Repository         string
+	ShortDescription   *string `db:"short_description"`
+	Signature          *string
+	SigningKey         *string `db:"signing_key"`
+	TestCommand        *string `db:"test_command"`
+	UpdatedAt          string  `db:"updated_at"`
+	Version            string
+	Volumes            *types.JSONText
+	Yanked             bool
+}
+
+// Packages represents a list of Package structs, Next is the token of the page after it
 type Packages struct {
 	Package []Package
+	Next    string `json:",omitempty"`
+}
+
+// Readme represents a package's long description rendered as sanitized HTML
+// for web pages and as text for a terminal
+type Readme struct {
+	Name    string
+	Version string
+	HTML    string
+	Text    string
+}
+
+// Deprecation represents the message shown to users of a deprecated package
+type Deprecation struct {
+	Message string
+}
+
+// PackageVersion represents a single published version of a package
+type PackageVersion struct {
+	Version   string
+	CreatedAt string `db:"created_at"`
+	Yanked    bool
+}
+
+// PackageVersions represents a list of PackageVersion structs, Next is the token of the page after it
+type PackageVersions struct {
+	Version []PackageVersion
+	Next    string `json:",omitempty"`
+}
+
+// Owner represents a user allowed to publish and manage a package
+type Owner struct {
+	Username string
+	AddedAt  string `db:"added_at"`
+}
+
+// Owners represents a list of Owner structs, Next is the token of the page after it
+type Owners struct {
+	Owner []Owner
+	Next  string `json:",omitempty"`
+}
+
+// Transfer represents a package's ownership offered to another user, it
+// changes hands once they accept
+type Transfer struct {
+	Name      string
+	From      string `db:"from_user"`
+	To        string `db:"to_user"`
+	CreatedAt string `db:"created_at"`
+}
+
+// Transfers represents a list of Transfer structs, Next is the token of the page after it
+type Transfers struct {
+	Transfer []Transfer
+	Next     string `json:",omitempty"`
+}
+
+// PullCount represents the pulls of a Package in a period starting on Date,
+// Unique counts each client once a day
+type PullCount struct {
+	Date   string `json:"date"`
+	Pulls  int    `json:"pulls"`
+	Unique int    `json:"unique" db:"unique_pulls"`
+}
+
+// PackageStats represents the pulls of a Package over time, Total counts each
+// client once a day
+type PackageStats struct {
+	Name   string      `json:"name"`
+	Period string      `json:"period"`
+	Range  string      `json:"range"`
+	Total  int         `json:"total"`
+	Pulls  []PullCount `json:"pulls"`
+}
+
+// TrendingPackage represents the latest version of a Package and how often it
+// was pulled over a recent period
+type TrendingPackage struct {
+	Package
+	RecentPulls int `db:"recent_pulls"`
+}
+
+// TrendingPackages represents a list of TrendingPackage structs, most pulled first, Next is the token of the page after it
+type TrendingPackages struct {
+	Period  string
+	Package []TrendingPackage
+	Next    string `json:",omitempty"`
+}
+
+// SearchResult represents the latest version of a Package matching a search
+// and how well it matched, a higher Rank is a better match
+type SearchResult struct {
+	Package
+	Rank float64 `json:",omitempty"`
+}
+
+// SearchResults represents a list of SearchResult structs, Next is the token of the page after it
+type SearchResults struct {
+	Package []SearchResult
+	Next    string `json:",omitempty"`
 }
 
 // PackageToml represents a raw toml config object
 type PackageToml struct {
-	Package          string  `toml:"package"`
-	Repository       string  `toml:"repository"`
-	CommandStart     *string `toml:"command_start"`
-	Homepage         *string `toml:"homepage"`
-	LongDescription  *string `toml:"long_description"`
-	Ports            Ports   `toml:"port"`
-	ShortDescription *string `toml:"short_description"`
-	Volumes          Volumes `toml:"volume"`
+	Package          string   `toml:"package" yaml:"package" json:"package"`
+	Repository       string   `toml:"repository" yaml:"repository" json:"repository"`
+	CommandStart     *string  `toml:"command_start" yaml:"command_start" json:"command_start"`
+	Env              Env      `toml:"env" yaml:"env" json:"env"`
+	Homepage         *string  `toml:"homepage" yaml:"homepage" json:"homepage"`
+	Icon             *string  `toml:"icon" yaml:"icon" json:"icon"`
+	Keywords         []string `toml:"keywords" yaml:"keywords" json:"keywords"`
+	LongDescription  *string  `toml:"long_description" yaml:"long_description" json:"long_description"`
+	Org              *string  `toml:"org" yaml:"org" json:"org"`
+	Ports            Ports    `toml:"port" yaml:"port" json:"port"`
+	Private          bool     `toml:"private" yaml:"private" json:"private"`
+	ShortDescription *string  `toml:"short_description" yaml:"short_description" json:"short_description"`
+	TestCommand      *string  `toml:"test_command" yaml:"test_command" json:"test_command"`
+	Volumes          Volumes  `toml:"volume" yaml:"volume" json:"volume"`
 }
 
 // Port represents a port forward config
 type Port struct {
-	Local     string `toml:"local"`
-	Container string `toml:"container"`
+	Local     string `toml:"local" yaml:"local" json:"local"`
+	Container string `toml:"container" yaml:"container" json:"container"`
 }
 
 // Ports represents a list of ports
 type Ports []Port
 
+// Env represents environment variables set in the container
+type Env map[string]string
+
+// Keys returns the environment variable names in sorted order
+func (e Env) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Volume represents a volume forward config
 type Volume struct {
-	Local     string `toml:"local"`
-	Container string `toml:"container"`
+	Local     string `toml:"local" yaml:"local" json:"local"`
+	Container string `toml:"container" yaml:"container" json:"container"`
 }
 
 // Volumes represents a list of volumes
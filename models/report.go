@@ -0,0 +1,30 @@
+package models
+
+// Report represents a user flagging a package, or one of its versions, as
+// malicious or abusive to the registry's admins. Status is open until an admin
+// dismisses it or removes the package, Resolution says why.
+type Report struct {
+	ID         int
+	Package    string  `db:"name"`
+	Version    *string `json:",omitempty"`
+	Reason     string
+	Reporter   *string `json:",omitempty"`
+	Status     string
+	CreatedAt  string  `db:"created_at"`
+	ResolvedBy *string `db:"resolved_by" json:",omitempty"`
+	ResolvedAt *string `db:"resolved_at" json:",omitempty"`
+	Resolution *string `json:",omitempty"`
+}
+
+// Reports represents a list of Report structs, oldest first, Next is the token of the page after it
+type Reports struct {
+	Report []Report
+	Next   string `json:",omitempty"`
+}
+
+// ReportResolution represents an admin changing the status of a Report, Reason
+// is kept as its Resolution
+type ReportResolution struct {
+	Status string
+	Reason string
+}
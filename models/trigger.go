@@ -0,0 +1,25 @@
+package models
+
+// Trigger represents a URL Docker Hub or GHCR webhooks call when an image tag
+// is pushed, publishing the tag as a new version of a package when it matches
+// Tags. URL holds the trigger's secret and is only known when it's created.
+type Trigger struct {
+	ID            int
+	Tags          string
+	URL           string  `json:",omitempty"`
+	CreatedBy     *string `json:",omitempty"`
+	CreatedAt     string  `json:",omitempty"`
+	LastTag       *string `json:",omitempty"`
+	LastTriggered *string `json:",omitempty"`
+}
+
+// Triggers represents a list of Trigger structs
+type Triggers struct {
+	Trigger []Trigger
+}
+
+// TriggerResult represents the answer to a pushed tag a Trigger didn't
+// publish, Message says why
+type TriggerResult struct {
+	Message string
+}
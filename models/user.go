@@ -0,0 +1,104 @@
+package models
+
+// Login represents the credentials exchanged for a Token
+type Login struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Identity represents the user an API token authenticates as
+type Identity struct {
+	Username string   `json:"username"`
+	Packages int      `json:"packages"`
+	Scopes   []string `json:"scopes"`
+	// Email is only set once it's verified, PendingEmail is waiting to be
+	Email         string   `json:"email,omitempty"`
+	PendingEmail  string   `json:"pending_email,omitempty"`
+	Notifications []string `json:"notifications"`
+	// TwoFactor is set when logging in needs a TOTP code, TwoFactorPublish
+	// when publishing and yanking need a fresh one too
+	TwoFactor        bool `json:"two_factor"`
+	TwoFactorPublish bool `json:"two_factor_publish"`
+	// SigningKey is the public key the user's package signatures are checked with
+	SigningKey string `json:"signing_key,omitempty"`
+}
+
+// SigningKey represents the base64 ed25519 public key a user signs packages with
+type SigningKey struct {
+	PublicKey string `json:"public_key"`
+}
+
+// TwoFactorSetup represents the TOTP secret of two-factor authentication being
+// turned on, URI adds it to an authenticator app
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorRequest represents a TOTP code turning two-factor authentication on,
+// or changing whether publishing needs a fresh code
+type TwoFactorRequest struct {
+	Code    string `json:"code"`
+	Publish bool   `json:"publish"`
+}
+
+// EmailRequest represents an email address a user wants to verify
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+// EmailVerification represents the code emailed to an address being verified
+type EmailVerification struct {
+	Code string `json:"code"`
+}
+
+// NotificationSettings represents the kinds of email a user chose to be sent
+type NotificationSettings struct {
+	Notifications []string `json:"notifications"`
+}
+
+// Subscription represents a package a user is emailed about when a version of
+// it is published
+type Subscription struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at" db:"created_at"`
+}
+
+// Subscriptions represents a list of Subscription structs, Next is the token of the page after it
+type Subscriptions struct {
+	Subscription []Subscription `json:"subscriptions"`
+	Next         string         `json:",omitempty"`
+}
+
+// Token represents an API token issued to a user by logging in or created
+// with scopes, the token itself is only known when it's issued
+type Token struct {
+	ID        int      `json:"id,omitempty"`
+	Username  string   `json:"username"`
+	Token     string   `json:"token,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	CreatedAt string   `json:"created_at,omitempty"`
+	LastUsed  *string  `json:"last_used,omitempty"`
+}
+
+// Tokens represents a list of Token structs
+type Tokens struct {
+	Token []Token `json:"tokens"`
+}
+
+// TokenRequest represents the name and scopes of a token to create
+type TokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// GithubDevice represents a GitHub login in progress, the user enters UserCode
+// at VerificationURI while cr polls with DeviceCode every Interval seconds
+type GithubDevice struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code,omitempty"`
+	VerificationURI string `json:"verification_uri,omitempty"`
+	ExpiresIn       int    `json:"expires_in,omitempty"`
+	Interval        int    `json:"interval,omitempty"`
+}
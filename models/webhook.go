@@ -0,0 +1,31 @@
+package models
+
+// Webhook represents a URL the registry calls when a package changes, no
+// Events means every one. Secret signs each call and is only known when the
+// webhook is created.
+type Webhook struct {
+	ID           int
+	URL          string
+	Events       []string
+	Secret       string  `json:",omitempty"`
+	CreatedBy    *string `json:",omitempty"`
+	CreatedAt    string  `json:",omitempty"`
+	LastStatus   *int    `json:",omitempty"`
+	LastDelivery *string `json:",omitempty"`
+}
+
+// Webhooks represents a list of Webhook structs
+type Webhooks struct {
+	Webhook []Webhook
+}
+
+// WebhookEvent represents the body of a call to a Webhook, Message is the
+// deprecation message of a deprecate event
+type WebhookEvent struct {
+	Event     string
+	Package   string
+	Version   string  `json:",omitempty"`
+	Message   *string `json:",omitempty"`
+	Sender    string
+	Timestamp string
+}
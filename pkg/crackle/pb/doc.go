@@ -0,0 +1,10 @@
+// Package pb is the gRPC client and server of the Crackle registry, generated
+// from registry.proto. The server answers each call with the REST handler of its
+// endpoint. Dial a registry serving it with cr server --grpc:
+//
+//	conn, err := grpc.NewClient("crackle.example.com:3814", grpc.WithTransportCredentials(creds))
+//	registry := pb.NewRegistryClient(conn)
+//	v, err := registry.Resolve(ctx, &pb.ResolveRequest{Name: "testing", Range: "^1.2"})
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative registry.proto
@@ -0,0 +1,354 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/labstack/echo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunshinekitty/cr/helpers"
+	"github.com/sunshinekitty/cr/models"
+	"github.com/sunshinekitty/cr/pkg/crackle/pb"
+)
+
+// forwardedMetadata are the request headers gRPC calls can send as metadata
+var forwardedMetadata = []string{"Authorization", "User-Agent", helpers.OTPHeader, helpers.PullClientHeader}
+
+// returnedHeaders are the response headers gRPC calls get back as metadata
+var returnedHeaders = []string{
+	helpers.RateLimitLimitHeader, helpers.RateLimitRemainingHeader, helpers.RateLimitResetHeader,
+	"Retry-After", helpers.OTPHeader,
+}
+
+// NewGRPC returns a gRPC server for the registry API served by e, over TLS
+// when config isn't nil. It's only a facade over the REST API: each call is
+// made into the REST request it stands for and sent through e.ServeHTTP, so
+// both share the same handlers and middleware but gRPC saves no round trips.
+// cr itself talks REST.
+func NewGRPC(e *echo.Echo, config *tls.Config) *grpc.Server {
+	var opts []grpc.ServerOption
+	if config != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	}
+	s := grpc.NewServer(opts...)
+	pb.RegisterRegistryServer(s, &registryService{e: e})
+	return s
+}
+
+// registryService answers gRPC calls with the handlers of the REST API
+type registryService struct {
+	pb.UnimplementedRegistryServer
+	e *echo.Echo
+}
+
+// Publish publishes a version of a package
+func (s *registryService) Publish(ctx context.Context, r *pb.PublishRequest) (*pb.Package, error) {
+	if r.Package == nil {
+		return nil, status.Error(codes.InvalidArgument, "A package is required")
+	}
+	p, err := modelPackage(r.Package)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	published := new(models.Package)
+	if err = s.call(ctx, "POST", "package/", p, published); err != nil {
+		return nil, err
+	}
+	return pbPackage(published)
+}
+
+// Resolve returns the newest version of a package in a range
+func (s *registryService) Resolve(ctx context.Context, r *pb.ResolveRequest) (*pb.PackageVersion, error) {
+	if !helpers.ValidPackageName(r.Name) {
+		return nil, status.Errorf(codes.NotFound, "Package %s doesn't exist", r.Name)
+	}
+	params := url.Values{}
+	params.Set("range", r.Range)
+	v := new(models.PackageVersion)
+	if err := s.call(ctx, "GET", fmt.Sprintf("%s/resolve?%s", packagePath(r.Name), params.Encode()), nil, v); err != nil {
+		return nil, err
+	}
+	return &pb.PackageVersion{Version: v.Version, CreatedAt: v.CreatedAt, Yanked: v.Yanked}, nil
+}
+
+// Search returns a page of the latest version of packages matching a query
+func (s *registryService) Search(ctx context.Context, r *pb.SearchRequest) (*pb.SearchResponse, error) {
+	params := url.Values{}
+	for key, value := range map[string]string{"q": r.Query, "owner": r.Owner, "keyword": r.Keyword, "sort": r.Sort, "next": r.Next} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	if r.Limit != 0 {
+		params.Set("limit", strconv.Itoa(int(r.Limit)))
+	}
+	results := new(models.SearchResults)
+	if err := s.call(ctx, "GET", "search?"+params.Encode(), nil, results); err != nil {
+		return nil, err
+	}
+
+	resp := &pb.SearchResponse{Next: results.Next}
+	for i := range results.Package {
+		p, err := pbPackage(&results.Package[i].Package)
+		if err != nil {
+			return nil, err
+		}
+		resp.Results = append(resp.Results, &pb.SearchResult{Package: p, Rank: results.Package[i].Rank})
+	}
+	return resp, nil
+}
+
+// call sends a request for the API endpoint at path through the REST API,
+// with the call's metadata as headers, and decodes its answer into v. Answers
+// that aren't 2xx are returned as errors with the gRPC code closest to their
+// status.
+func (s *registryService) call(ctx context.Context, method string, path string, body interface{}, v interface{}) error {
+	var buf io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		buf = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, "/api/"+path, buf)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	req = req.WithContext(ctx)
+	req.RequestURI = req.URL.RequestURI()
+	req.Header.Set("Accept", echo.MIMEApplicationJSON)
+	if body != nil {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, h := range forwardedMetadata {
+		if values := md.Get(h); len(values) > 0 {
+			req.Header.Set(h, values[0])
+		}
+	}
+	// Rate limits count against the caller's address, as for REST requests
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	resp := newResponseRecorder()
+	s.e.ServeHTTP(resp, req)
+
+	returned := metadata.MD{}
+	for _, h := range returnedHeaders {
+		if value := resp.header.Get(h); value != "" {
+			returned.Set(h, value)
+		}
+	}
+	if len(returned) > 0 {
+		grpc.SetHeader(ctx, returned)
+	}
+	if resp.status < 200 || resp.status > 299 {
+		return status.Error(grpcCode(resp.status), errorMessage(resp))
+	}
+	if err = json.Unmarshal(resp.body.Bytes(), v); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// responseRecorder keeps the answer to a request sent through the REST API
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: http.Header{}, status: http.StatusOK}
+}
+
+// Header implements http.ResponseWriter
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+// Write implements http.ResponseWriter
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+// errorMessage returns the message of an error answer, or its status' text
+func errorMessage(r *responseRecorder) string {
+	var e struct{ Message string }
+	if json.Unmarshal(r.body.Bytes(), &e) == nil && e.Message != "" {
+		return e.Message
+	}
+	return http.StatusText(r.status)
+}
+
+// grpcCode returns the gRPC code standing for an HTTP status
+func grpcCode(s int) codes.Code {
+	switch s {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// packagePath returns the path of a package name's endpoints, namespaced names
+// are served under ns/
+func packagePath(name string) string {
+	namespace, short := helpers.SplitPackageName(name)
+	if namespace != "" {
+		return fmt.Sprintf("ns/%s/package/%s", url.PathEscape(namespace), url.PathEscape(short))
+	}
+	return "package/" + url.PathEscape(short)
+}
+
+// pbPackage converts a Package to its gRPC message
+func pbPackage(p *models.Package) (*pb.Package, error) {
+	m := &pb.Package{
+		Name:               p.Name,
+		Version:            p.Version,
+		Repository:         p.Repository,
+		Owner:              p.Owner,
+		Org:                stringValue(p.Org),
+		Namespace:          p.Namespace,
+		CommandStart:       stringValue(p.CommandStart),
+		TestCommand:        stringValue(p.TestCommand),
+		Homepage:           stringValue(p.Homepage),
+		Icon:               stringValue(p.Icon),
+		IconUrl:            p.IconURL,
+		ShortDescription:   stringValue(p.ShortDescription),
+		LongDescription:    stringValue(p.LongDescription),
+		LongDescriptionUrl: p.LongDescriptionURL,
+		ReadmeUrl:          p.ReadmeURL,
+		Deprecated:         stringValue(p.Deprecated),
+		Private:            p.Private,
+		Yanked:             p.Yanked,
+		Pulls:              int64(p.Pulls),
+		Signature:          stringValue(p.Signature),
+		SigningKey:         stringValue(p.SigningKey),
+		CreatedAt:          p.CreatedAt,
+		UpdatedAt:          p.UpdatedAt,
+	}
+	var ports models.Ports
+	var volumes models.Volumes
+	for _, field := range []struct {
+		text *types.JSONText
+		v    interface{}
+	}{{p.Env, &m.Env}, {p.Keywords, &m.Keywords}, {p.Ports, &ports}, {p.Volumes, &volumes}} {
+		if field.text == nil {
+			continue
+		}
+		if err := field.text.Unmarshal(field.v); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	for _, port := range ports {
+		m.Ports = append(m.Ports, &pb.Port{Local: port.Local, Container: port.Container})
+	}
+	for _, volume := range volumes {
+		m.Volumes = append(m.Volumes, &pb.Volume{Local: volume.Local, Container: volume.Container})
+	}
+	return m, nil
+}
+
+// modelPackage converts a gRPC message to the Package it stands for, empty
+// fields aren't set
+func modelPackage(m *pb.Package) (*models.Package, error) {
+	p := &models.Package{
+		Name:             m.Name,
+		Version:          m.Version,
+		Repository:       m.Repository,
+		Owner:            m.Owner,
+		Org:              stringPtr(m.Org),
+		CommandStart:     stringPtr(m.CommandStart),
+		TestCommand:      stringPtr(m.TestCommand),
+		Homepage:         stringPtr(m.Homepage),
+		Icon:             stringPtr(m.Icon),
+		ShortDescription: stringPtr(m.ShortDescription),
+		LongDescription:  stringPtr(m.LongDescription),
+		Private:          m.Private,
+		Signature:        stringPtr(m.Signature),
+	}
+	var ports models.Ports
+	for _, port := range m.Ports {
+		ports = append(ports, models.Port{Local: port.Local, Container: port.Container})
+	}
+	var volumes models.Volumes
+	for _, volume := range m.Volumes {
+		volumes = append(volumes, models.Volume{Local: volume.Local, Container: volume.Container})
+	}
+	var err error
+	if p.Env, err = jsonText(m.Env, len(m.Env)); err != nil {
+		return nil, err
+	}
+	if p.Keywords, err = jsonText(m.Keywords, len(m.Keywords)); err != nil {
+		return nil, err
+	}
+	if p.Ports, err = jsonText(ports, len(ports)); err != nil {
+		return nil, err
+	}
+	if p.Volumes, err = jsonText(volumes, len(volumes)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// jsonText returns v as JSON for a Package field, nil when it has no elements
+func jsonText(v interface{}, n int) (*types.JSONText, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	text := types.JSONText(b)
+	return &text, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func stringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	_ "embed" // for the OpenAPI spec
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// openAPISpec describes every endpoint Routes registers as OpenAPI 3, keep it
+// up to date when they change and regenerate client from it
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// ReadOpenAPI returns the OpenAPI spec of the registry API
+func ReadOpenAPI(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, openAPISpec)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"github.com/labstack/gommon/log"
+
+	"github.com/sunshinekitty/cr/handlers"
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// New returns an Echo instance serving the Crackle registry API under /api,
+// handlers.DB has to be connected before it serves requests
+func New() *echo.Echo {
+	// Echo instance
+	e := echo.New()
+
+	// App config
+	e.HideBanner = true
+	e.Logger.SetLevel(log.INFO)
+
+	// Middleware
+	e.Use(handlers.Metrics)
+	e.Use(middleware.Logger())
+	e.Use(middleware.Recover())
+	e.Use(handlers.MirrorReadOnly)
+
+	// Monitoring and the API's spec sit outside it, where tools expect them
+	e.GET("/healthz", handlers.Healthz)
+	e.GET("/readyz", handlers.Readyz)
+	e.GET("/metrics", handlers.ReadMetrics)
+	e.GET("/openapi.json", ReadOpenAPI)
+
+	Routes(e.Group("/api"))
+	return e
+}
+
+// Routes registers every registry API endpoint on g
+func Routes(g *echo.Group) {
+	// Scopes an API token needs, CreatePackage checks its own
+	read := handlers.RequireScope(helpers.ScopeRead)
+	publish := handlers.RequireScope(helpers.ScopePublish)
+	admin := handlers.RequireScope(helpers.ScopeAdmin)
+	// Rate limits come before RequireAuth so requests with bad tokens count too
+	publishLimit := handlers.RateLimit("publish")
+	searchLimit := handlers.RateLimit("search")
+	loginLimit := handlers.RateLimit("login")
+
+	// Route => handler
+	g.POST("/login", handlers.Login, loginLimit)
+	g.POST("/login/github", handlers.StartGithubLogin, loginLimit)
+	g.POST("/login/github/token", handlers.GithubLogin, loginLimit)
+	g.DELETE("/login", handlers.Logout, handlers.RequireAuth)
+	g.GET("/whoami", handlers.WhoAmI, handlers.RequireAuth)
+	g.PUT("/account/email", handlers.SetEmail, handlers.RequireAuth, admin)
+	g.POST("/account/email/verify", handlers.VerifyEmail, handlers.RequireAuth, admin)
+	g.PUT("/account/notifications", handlers.SetNotifications, handlers.RequireAuth, admin)
+	g.POST("/account/2fa", handlers.StartTwoFactor, handlers.RequireAuth, admin)
+	g.PUT("/account/2fa", handlers.EnableTwoFactor, loginLimit, handlers.RequireAuth, admin)
+	g.DELETE("/account/2fa", handlers.DisableTwoFactor, loginLimit, handlers.RequireAuth, admin)
+	g.PUT("/account/signing-key", handlers.SetSigningKey, handlers.RequireAuth, admin)
+	g.DELETE("/account/signing-key", handlers.RemoveSigningKey, handlers.RequireAuth, admin)
+	g.GET("/subscriptions", handlers.ReadSubscriptions, handlers.RequireAuth, read)
+	g.GET("/tokens", handlers.ReadTokens, handlers.RequireAuth, read)
+	g.POST("/tokens", handlers.CreateToken, handlers.RequireAuth, admin)
+	g.DELETE("/tokens/:id", handlers.RevokeToken, handlers.RequireAuth, admin)
+	g.GET("/transfers", handlers.ReadTransfers, handlers.RequireAuth, read)
+	g.POST("/orgs", handlers.CreateOrg, handlers.RequireAuth, admin)
+	g.GET("/orgs/:org/members", handlers.ReadOrgMembers)
+	g.PUT("/orgs/:org/members/:username", handlers.SetOrgMember, handlers.RequireAuth, admin)
+	g.DELETE("/orgs/:org/members/:username", handlers.RemoveOrgMember, handlers.RequireAuth, admin)
+
+	g.POST("/package/", handlers.CreatePackage, publishLimit, handlers.RequireAuth, handlers.RequirePublishOTP)
+	// Docker Hub and GHCR webhooks authenticate with the trigger's secret in the URL
+	g.POST("/triggers/:token", handlers.PublishFromTrigger, publishLimit)
+	// Packages in a namespace, such as alice/tool, are served under /ns/alice
+	packageRoutes(g.Group("/package/:name"), read, publish, admin, loginLimit)
+	packageRoutes(g.Group("/ns/:namespace/package/:name"), read, publish, admin, loginLimit)
+
+	// Badges are embedded in READMEs, so they're only of public packages
+	for _, prefix := range []string{"/badge/:name", "/ns/:namespace/badge/:name"} {
+		g.GET(prefix+"/version.svg", handlers.ReadVersionBadge)
+		g.GET(prefix+"/pulls.svg", handlers.ReadPullsBadge)
+	}
+
+	g.GET("/search", handlers.SearchPackages, searchLimit, handlers.OptionalAuth, handlers.Cache)
+	g.GET("/trending", handlers.ReadTrending, handlers.OptionalAuth)
+	g.GET("/recent", handlers.ReadRecent, handlers.OptionalAuth)
+	g.GET("/categories", handlers.ReadCategories, handlers.OptionalAuth)
+	g.GET("/collections", handlers.ReadCollections)
+	g.GET("/collections/:slug", handlers.ReadCollection, handlers.OptionalAuth)
+
+	g.GET("/audit", handlers.ReadAuditLog, handlers.RequireAuth, read)
+
+	// Moderation is only open to the registry's admins
+	moderate := []echo.MiddlewareFunc{handlers.RequireAuth, admin, handlers.RequireRegistryAdmin}
+	g.PUT("/admin/users/:username/suspension", handlers.SuspendUser, moderate...)
+	g.DELETE("/admin/users/:username/suspension", handlers.UnsuspendUser, moderate...)
+	g.GET("/admin/reserved", handlers.ReadReservedNames, moderate...)
+	g.GET("/admin/reports", handlers.ReadReports, moderate...)
+	g.PUT("/admin/reports/:id", handlers.ResolveReport, moderate...)
+	g.PUT("/admin/categories/:slug", handlers.SetCategory, moderate...)
+	g.DELETE("/admin/categories/:slug", handlers.DeleteCategory, moderate...)
+	g.PUT("/admin/collections/:slug", handlers.SetCollection, moderate...)
+	g.DELETE("/admin/collections/:slug", handlers.DeleteCollection, moderate...)
+	for _, prefix := range []string{"/admin/package/:name", "/admin/ns/:namespace/package/:name"} {
+		g.DELETE(prefix, handlers.RemovePackage, moderate...)
+		g.PUT(prefix+"/reservation", handlers.ReserveName, moderate...)
+		g.DELETE(prefix+"/reservation", handlers.UnreserveName, moderate...)
+	}
+
+	g.GET("/version", handlers.Version)
+}
+
+// packageRoutes registers the endpoints of a package on p, whose prefix has the
+// package's name as a param. Reads of private packages need OptionalAuth, reads
+// of packages the registry doesn't have are federated to its upstreams, reads
+// of public ones are cached. Routes checking a two-factor code share loginLimit.
+func packageRoutes(p *echo.Group, read echo.MiddlewareFunc, publish echo.MiddlewareFunc, admin echo.MiddlewareFunc,
+	loginLimit echo.MiddlewareFunc) {
+	p.GET("", handlers.ReadPackage, handlers.Federate, handlers.OptionalAuth, handlers.Cache)
+	p.PUT("", handlers.UpdatePackage, handlers.RequireAuth, publish)
+	p.DELETE("", handlers.DeletePackage, handlers.RequireAuth, publish)
+	p.GET("/versions", handlers.ReadPackageVersions, handlers.Federate, handlers.OptionalAuth, handlers.Cache)
+	p.GET("/readme", handlers.ReadPackageReadme, handlers.Federate, handlers.OptionalAuth, handlers.Cache)
+	p.GET("/resolve", handlers.ResolvePackageVersion, handlers.Federate, handlers.OptionalAuth, handlers.Cache)
+	p.PUT("/transfer", handlers.OfferTransfer, handlers.RequireAuth, admin)
+	p.DELETE("/transfer", handlers.CancelTransfer, handlers.RequireAuth, admin)
+	p.POST("/transfer/accept", handlers.AcceptTransfer, handlers.RequireAuth, admin)
+	p.GET("/owners", handlers.ReadPackageOwners, handlers.Federate, handlers.OptionalAuth)
+	p.PUT("/owners/:username", handlers.AddPackageOwner, handlers.RequireAuth, admin)
+	p.DELETE("/owners/:username", handlers.RemovePackageOwner, handlers.RequireAuth, admin)
+	p.PUT("/versions/:version/yank", handlers.YankPackageVersion, loginLimit, handlers.RequireAuth, publish, handlers.RequirePublishOTP)
+	p.DELETE("/versions/:version/yank", handlers.UnyankPackageVersion, loginLimit, handlers.RequireAuth, publish, handlers.RequirePublishOTP)
+	p.PUT("/deprecate", handlers.DeprecatePackage, handlers.RequireAuth, publish)
+	p.DELETE("/deprecate", handlers.UndeprecatePackage, handlers.RequireAuth, publish)
+	p.POST("/versions/:version/pulls", handlers.RecordPull, handlers.OptionalAuth)
+	p.GET("/stats", handlers.ReadPackageStats, handlers.OptionalAuth)
+	p.GET("/webhooks", handlers.ReadWebhooks, handlers.RequireAuth, publish)
+	p.POST("/webhooks", handlers.CreateWebhook, handlers.RequireAuth, publish)
+	p.DELETE("/webhooks/:id", handlers.DeleteWebhook, handlers.RequireAuth, publish)
+	p.GET("/triggers", handlers.ReadTriggers, handlers.RequireAuth, publish)
+	p.POST("/triggers", handlers.CreateTrigger, handlers.RequireAuth, publish)
+	p.DELETE("/triggers/:id", handlers.DeleteTrigger, handlers.RequireAuth, publish)
+	p.GET("/audit", handlers.ReadPackageAudit, handlers.RequireAuth, read)
+	p.PUT("/subscription", handlers.Subscribe, handlers.RequireAuth, read)
+	p.DELETE("/subscription", handlers.Unsubscribe, handlers.RequireAuth, read)
+	p.POST("/report", handlers.ReportPackage, handlers.RequireAuth, read)
+	p.GET("/categories", handlers.ReadPackageCategories, handlers.OptionalAuth)
+	p.PUT("/categories", handlers.SetPackageCategories, handlers.RequireAuth, publish)
+}
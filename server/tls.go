@@ -0,0 +1,80 @@
+package server
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/sunshinekitty/cr/helpers"
+)
+
+// TLS returns the TLS config the registry is served with, set under [tls] in
+// server.toml, nil when it's served over plain HTTP. Certificates are read from
+// cert_file and key_file, or obtained and renewed from Let's Encrypt, or
+// another ACME directory, for acme_hosts.
+//
+// redirect answers plain HTTP requests to the server bound to tlsBind: ACME
+// HTTP challenges are answered and every other request is redirected to HTTPS.
+func TLS(tlsBind string) (config *tls.Config, redirect http.Handler, err error) {
+	to := &httpsRedirect{}
+	if _, port, splitErr := net.SplitHostPort(tlsBind); splitErr == nil && port != "443" {
+		to.port = port
+	}
+
+	if hosts := viper.GetStringSlice("tls.acme_hosts"); len(hosts) > 0 {
+		cache := viper.GetString("tls.acme_cache")
+		if cache == "" {
+			cache = filepath.Join(helpers.CacheDir(), "acme")
+		}
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(hosts...),
+			Cache:      autocert.DirCache(cache),
+			Email:      viper.GetString("tls.acme_email"),
+		}
+		if directory := viper.GetString("tls.acme_directory"); directory != "" {
+			m.Client = &acme.Client{DirectoryURL: directory}
+		}
+		return m.TLSConfig(), m.HTTPHandler(to), nil
+	}
+
+	certFile, keyFile := viper.GetString("tls.cert_file"), viper.GetString("tls.key_file")
+	if certFile == "" && keyFile == "" {
+		return nil, nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	config = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	return config, to, nil
+}
+
+// httpsRedirect redirects requests to the same URL over HTTPS, on port when
+// it isn't the default
+type httpsRedirect struct {
+	port string
+}
+
+func (h *httpsRedirect) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "Use HTTPS", http.StatusBadRequest)
+		return
+	}
+	host := r.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	if h.port != "" {
+		host = net.JoinHostPort(host, h.port)
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusFound)
+}